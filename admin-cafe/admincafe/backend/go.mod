@@ -1,11 +0,0 @@
-module backend
-
-go 1.25.3
-
-require (
-	github.com/gorilla/mux v1.8.1
-	github.com/lib/pq v1.10.9
-	golang.org/x/crypto v0.40.0
-)
-
-require github.com/rs/cors v1.11.1 // indirect
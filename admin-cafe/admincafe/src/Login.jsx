@@ -3,6 +3,7 @@ import { useNavigate } from "react-router-dom";
 import { AiOutlineEye, AiOutlineEyeInvisible } from "react-icons/ai";
 import "./Login.css";
 import coffeeImg from "./assets/gambarbiji.png";
+import { errorMessage } from "./apiError";
 
 export default function Login() {
   const navigate = useNavigate();
@@ -10,6 +11,29 @@ export default function Login() {
   const [showPassword, setShowPassword] = useState(false);
   const [role, setRole] = useState("admin");
 
+  const changeTemporaryPassword = async (token, oldPassword) => {
+    const newPassword = window.prompt(
+      "Password kamu bersifat sementara. Masukkan password baru (minimal 8 karakter):"
+    );
+    if (!newPassword) return false;
+
+    const res = await fetch("http://localhost:8080/auth/change-password", {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+        Authorization: `Bearer ${token}`,
+      },
+      body: JSON.stringify({ old_password: oldPassword, new_password: newPassword }),
+    });
+
+    if (!res.ok) {
+      alert(await errorMessage(res, "Gagal mengganti password"));
+      return false;
+    }
+    alert("Password berhasil diganti");
+    return true;
+  };
+
   const handleSubmit = async (e) => {
     e.preventDefault();
     setLoading(true);
@@ -36,9 +60,16 @@ export default function Login() {
       // Response valid JSON
       const data = await res.json();
 
+      // Password sementara (mis. admin default) wajib diganti dulu
+      if (data.must_change_password) {
+        const changed = await changeTemporaryPassword(data.token, password);
+        if (!changed) return;
+      }
+
       localStorage.setItem("isLoggedIn", "true");
       localStorage.setItem("role", data.role);
       localStorage.setItem("username", data.username);
+      localStorage.setItem("token", data.token);
 
       // Navigate sesuai role
       if (data.role === "superadmin" || data.role === "admin") navigate("/adminapprove");
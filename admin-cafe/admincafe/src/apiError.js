@@ -0,0 +1,13 @@
+// Backend mengirim error sebagai JSON: { error, code, fields, request_id }.
+// errorMessage mengambil pesan yang bisa ditampilkan ke user; response
+// non-JSON (mis. dari proxy) tetap dibaca sebagai teks.
+export async function errorMessage(res, fallback = 'Terjadi kesalahan') {
+  const text = await res.text().catch(() => '');
+  try {
+    const data = JSON.parse(text);
+    if (data && data.error) return data.error;
+  } catch {
+    // bukan JSON
+  }
+  return text.trim() || fallback;
+}
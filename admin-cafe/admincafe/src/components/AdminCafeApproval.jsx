@@ -2,6 +2,11 @@ import React, { useEffect, useState } from "react";
 import { AiOutlineCheck, AiOutlineClose } from "react-icons/ai";
 import "./AdminCafeApproval.css";
 
+// Token session dari login
+const authHeader = () => ({
+  Authorization: `Bearer ${localStorage.getItem("token") || ""}`,
+});
+
 export default function AdminCafeApproval() {
   const [cafes, setCafes] = useState([]); // selalu array
   const [loading, setLoading] = useState(false);
@@ -12,7 +17,9 @@ export default function AdminCafeApproval() {
   const fetchCafes = async () => {
     setLoading(true);
     try {
-      const res = await fetch("http://localhost:8080/all-cafes");
+      const res = await fetch("http://localhost:8080/all-cafes", {
+        headers: authHeader(),
+      });
       if (!res.ok) throw new Error("Gagal fetch cafes");
 
       const data = await res.json();
@@ -45,7 +52,7 @@ export default function AdminCafeApproval() {
 
       const res = await fetch(endpoint, {
         method: "POST",
-        headers: { "Content-Type": "application/json" },
+        headers: { "Content-Type": "application/json", ...authHeader() },
         body: JSON.stringify({ cafe_id: id }),
       });
 
@@ -58,6 +58,7 @@ function Admincafe() {
       localStorage.removeItem("isLoggedIn");
       localStorage.removeItem("username");
       localStorage.removeItem("role");
+      localStorage.removeItem("token");
       navigate("/login");
     }
   };
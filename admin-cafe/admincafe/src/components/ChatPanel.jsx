@@ -0,0 +1,210 @@
+import React, { useEffect, useRef, useState } from 'react';
+import './ChatPanel.css';
+import { errorMessage } from '../apiError';
+
+const API = 'http://localhost:8080';
+// Interval polling jika stream tidak tersedia / terputus
+const POLL_INTERVAL = 5000;
+
+// Token session dari login
+const authHeader = () => ({
+  Authorization: `Bearer ${localStorage.getItem('token') || ''}`,
+});
+
+// ==============================
+// Baca stream SSE lewat fetch (EventSource tidak bisa kirim header
+// Authorization). Mengembalikan fungsi untuk menutup stream.
+// ==============================
+const openStream = (onEvent, onClose) => {
+  const controller = new AbortController();
+
+  (async () => {
+    try {
+      const res = await fetch(`${API}/chat/stream`, {
+        headers: authHeader(),
+        signal: controller.signal,
+      });
+      if (!res.ok || !res.body) throw new Error('Stream tidak tersedia');
+
+      const reader = res.body.getReader();
+      const decoder = new TextDecoder();
+      let buffer = '';
+      for (;;) {
+        const { done, value } = await reader.read();
+        if (done) break;
+        buffer += decoder.decode(value, { stream: true });
+
+        let idx;
+        while ((idx = buffer.indexOf('\n\n')) >= 0) {
+          const chunk = buffer.slice(0, idx);
+          buffer = buffer.slice(idx + 2);
+          const data = chunk
+            .split('\n')
+            .filter((line) => line.startsWith('data: '))
+            .map((line) => line.slice(6))
+            .join('\n');
+          if (data) onEvent(JSON.parse(data));
+        }
+      }
+    } catch (err) {
+      if (err.name !== 'AbortError') console.error(err);
+    }
+    if (!controller.signal.aborted) onClose();
+  })();
+
+  return () => controller.abort();
+};
+
+export default function ChatPanel({ threadId, onClose }) {
+  const [thread, setThread] = useState(null);
+  const [messages, setMessages] = useState([]);
+  const [text, setText] = useState('');
+  const [streaming, setStreaming] = useState(false);
+  const lastIdRef = useRef(0);
+  const meRef = useRef(null);
+
+  const appendMessages = (list) => {
+    if (!list.length) return;
+    setMessages((prev) => {
+      const known = new Set(prev.map((m) => m.id));
+      return [...prev, ...list.filter((m) => !known.has(m.id))];
+    });
+    lastIdRef.current = Math.max(lastIdRef.current, ...list.map((m) => m.id));
+  };
+
+  const markRead = async (messageId) => {
+    if (!messageId) return;
+    try {
+      await fetch(`${API}/chat/threads/${threadId}/read`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json', ...authHeader() },
+        body: JSON.stringify({ message_id: messageId }),
+      });
+    } catch (err) {
+      console.error(err);
+    }
+  };
+
+  const fetchThread = async () => {
+    const res = await fetch(`${API}/chat/threads/${threadId}`, { headers: authHeader() });
+    if (res.ok) setThread(await res.json());
+  };
+
+  // Polling: ambil pesan setelah id terakhir yang sudah diterima
+  const fetchNewMessages = async () => {
+    const res = await fetch(
+      `${API}/chat/threads/${threadId}/messages?after_id=${lastIdRef.current}`,
+      { headers: authHeader() },
+    );
+    if (!res.ok) return;
+    const data = await res.json();
+    appendMessages(data.messages || []);
+    if (data.messages && data.messages.length) markRead(lastIdRef.current);
+  };
+
+  // Muat awal: thread + 50 pesan terakhir
+  useEffect(() => {
+    meRef.current = localStorage.getItem('username');
+    lastIdRef.current = 0;
+    setMessages([]);
+
+    (async () => {
+      try {
+        await fetchThread();
+        const res = await fetch(`${API}/chat/threads/${threadId}/messages`, { headers: authHeader() });
+        if (!res.ok) throw new Error('Gagal fetch pesan');
+        const data = await res.json();
+        appendMessages(data.messages || []);
+        markRead(lastIdRef.current);
+      } catch (err) {
+        console.error(err);
+      }
+    })();
+  }, [threadId]);
+
+  // Stream live; jika gagal / putus, jatuh ke polling
+  useEffect(() => {
+    let closeStream = null;
+    let pollTimer = null;
+
+    const startPolling = () => {
+      setStreaming(false);
+      if (!pollTimer) pollTimer = setInterval(fetchNewMessages, POLL_INTERVAL);
+    };
+
+    closeStream = openStream((event) => {
+      setStreaming(true);
+      if (event.thread_id !== threadId) return;
+      if (event.type === 'message') {
+        appendMessages([event.message]);
+        markRead(event.message.id);
+      } else if (event.type === 'read') {
+        setThread((prev) =>
+          prev && {
+            ...prev,
+            participants: prev.participants.map((p) =>
+              p.user_id === event.user_id ? { ...p, last_read_message_id: event.message_id } : p,
+            ),
+          },
+        );
+      }
+    }, startPolling);
+
+    return () => {
+      if (closeStream) closeStream();
+      if (pollTimer) clearInterval(pollTimer);
+    };
+  }, [threadId]);
+
+  const send = async (e) => {
+    e.preventDefault();
+    if (!text.trim()) return;
+    try {
+      const res = await fetch(`${API}/chat/threads/${threadId}/messages`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json', ...authHeader() },
+        body: JSON.stringify({ body: text }),
+      });
+      if (!res.ok) throw new Error(await errorMessage(res));
+      appendMessages([await res.json()]);
+      setText('');
+    } catch (err) {
+      console.error(err);
+      alert('Gagal mengirim pesan');
+    }
+  };
+
+  // Read receipt: pesan sudah dibaca oleh peserta lain
+  const readBy = (message) =>
+    (thread?.participants || [])
+      .filter((p) => p.user_id !== message.sender_id && p.last_read_message_id >= message.id)
+      .map((p) => p.username);
+
+  return (
+    <div className="chat-panel">
+      <div className="chat-panel-header">
+        <div>
+          <h3>{thread ? thread.subject : 'Chat'}</h3>
+          <small>{streaming ? '● Live' : '○ Polling'}</small>
+        </div>
+        <button onClick={onClose}>✕</button>
+      </div>
+      <div className="chat-panel-messages">
+        {messages.map((m) => (
+          <div key={m.id} className={`chat-message ${m.sender_name === meRef.current ? 'mine' : ''}`}>
+            <strong>{m.sender_name}</strong>
+            <p>{m.body}</p>
+            <small>
+              {new Date(m.created_at).toLocaleTimeString('id-ID', { hour: '2-digit', minute: '2-digit' })}
+              {readBy(m).length > 0 && ` · Dibaca ${readBy(m).join(', ')}`}
+            </small>
+          </div>
+        ))}
+      </div>
+      <form className="chat-panel-form" onSubmit={send}>
+        <input value={text} onChange={(e) => setText(e.target.value)} placeholder="Tulis pesan..." />
+        <button type="submit">Kirim</button>
+      </form>
+    </div>
+  );
+}
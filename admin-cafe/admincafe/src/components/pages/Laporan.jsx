@@ -1,21 +1,78 @@
-import React, { useState } from "react";
+import React, { useEffect, useState } from "react";
 import "./Laporan.css";
 import { Line } from "react-chartjs-2";
 import { FaStar, FaHome, FaUtensils } from "react-icons/fa";
 import "chart.js/auto";
+import { errorMessage } from "../../apiError";
+
+const REPORT_URL = "http://localhost:8080/reports/sales";
+
+const formatRupiah = (value) => `Rp. ${Math.round(value || 0).toLocaleString("id-ID")}`;
+
+const formatTanggal = (value) =>
+  new Date(`${value}T00:00:00`).toLocaleDateString("id-ID", {
+    day: "numeric",
+    month: "long",
+    year: "numeric",
+  });
 
 const Laporan = () => {
-  const [startDate, setStartDate] = useState("2025-09-01");
-  const [endDate, setEndDate] = useState("2025-09-10");
+  const today = new Date().toISOString().slice(0, 10);
+  const monthAgo = new Date(Date.now() - 29 * 24 * 60 * 60 * 1000).toISOString().slice(0, 10);
+
+  const [startDate, setStartDate] = useState(monthAgo);
+  const [endDate, setEndDate] = useState(today);
+  const [report, setReport] = useState(null);
+
+  const fetchReport = async () => {
+    try {
+      const res = await fetch(`${REPORT_URL}?start=${startDate}&end=${endDate}&granularity=day`, {
+        headers: { Authorization: `Bearer ${localStorage.getItem("token") || ""}` },
+      });
+      if (!res.ok) throw new Error(await errorMessage(res));
+      setReport(await res.json());
+    } catch (err) {
+      console.error(err);
+      alert(`Gagal mengambil laporan: ${err.message}`);
+    }
+  };
+
+  // File diunduh lewat fetch karena endpoint butuh header Authorization
+  const downloadExport = async (format) => {
+    try {
+      const res = await fetch(
+        `${REPORT_URL}/export?format=${format}&start=${startDate}&end=${endDate}&granularity=day`,
+        { headers: { Authorization: `Bearer ${localStorage.getItem("token") || ""}` } }
+      );
+      if (!res.ok) throw new Error(await errorMessage(res));
+
+      const disposition = res.headers.get("Content-Disposition") || "";
+      const match = disposition.match(/filename="([^"]+)"/);
+      const url = URL.createObjectURL(await res.blob());
+      const link = document.createElement("a");
+      link.href = url;
+      link.download = match ? match[1] : `laporan-${startDate}_${endDate}.${format}`;
+      link.click();
+      URL.revokeObjectURL(url);
+    } catch (err) {
+      console.error(err);
+      alert(`Gagal export laporan: ${err.message}`);
+    }
+  };
+
+  useEffect(() => {
+    fetchReport();
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, []);
 
-  // Data simulasi (nanti bisa diganti dari backend)
-  const dataPenjualan = [100000, 600000, 900000, 850000, 920000, 600000, 450000, 550000, 300000, 100000];
-  const tanggal = ["1", "2", "3", "4", "5", "6", "7", "8", "9", "10"];
+  const series = report?.series || [];
+  const dataPenjualan = series.map((p) => p.revenue);
+  const tanggal = series.map((p) => Number(p.period_start.slice(8, 10)).toString());
 
-  const totalPenjualan = "Rp. 12.023.000";
-  const totalPesanan = 370;
-  const ratingRata = "4.6/5";
-  const menuTerlaris = ["Extra Jose Susu", "Pukis Pey", "Pentolan James"];
+  const totalPenjualan = formatRupiah(report?.total_revenue);
+  const totalPesanan = report?.total_orders || 0;
+  const ratingRata = `${report?.average_rating || 0}/5`;
+  const menuTerlaris = (report?.top_menus || []).slice(0, 3).map((m) => m.name);
 
   const chartData = {
     labels: tanggal,
@@ -40,11 +97,14 @@ const Laporan = () => {
     },
   };
 
-  const daftarMenu = [
-    { tanggal: "1 September 2025", menu: "Extra Jose Susu, Pukis pey, Pentolan James", terjual: 67, pendapatan: "Rp. 9.000.000" },
-    { tanggal: "2 September 2025", menu: "Extra Jose Susu, Pukis pey, Pentolan James", terjual: 67, pendapatan: "Rp. 7.400.000" },
-    { tanggal: "3 September 2025", menu: "Extra Jose Susu, Pukis pey, Pentolan James", terjual: 67, pendapatan: "Rp. 5.890.000" },
-  ];
+  const daftarMenu = series
+    .filter((p) => p.items_sold > 0)
+    .map((p) => ({
+      tanggal: formatTanggal(p.period_start),
+      menu: p.top_menus.map((m) => m.name).join(", "),
+      terjual: p.items_sold,
+      pendapatan: formatRupiah(p.revenue),
+    }));
 
   return (
     <div className="laporan-container">
@@ -68,12 +128,12 @@ const Laporan = () => {
             onChange={(e) => setEndDate(e.target.value)}
           />
         </label>
-        <button className="btn-oke">Oke</button>
+        <button className="btn-oke" onClick={fetchReport}>Oke</button>
 
         <div className="export-section">
           <span>Export :</span>
-          <button className="export-btn">Pdf</button>
-          <button className="export-btn">Excel</button>
+          <button className="export-btn" onClick={() => downloadExport("pdf")}>Pdf</button>
+          <button className="export-btn" onClick={() => downloadExport("xlsx")}>Excel</button>
         </div>
       </div>
 
@@ -1,13 +1,22 @@
 import React, { useEffect, useState } from "react";
 import "./Menu.css";
 import { FaTrash, FaEdit, FaSearch, FaExclamationTriangle, FaSync } from "react-icons/fa";
+import { errorMessage } from "../../apiError";
 
-const API_URL = "http://localhost:8080/menus";
-const UPLOAD_URL = "http://localhost:8080/upload";
+const BASE_URL = "http://localhost:8080";
+const API_URL = `${BASE_URL}/cafe/menus`;
+const UPLOAD_URL = `${BASE_URL}/cafe/upload`;
+
+const authHeader = () => ({
+  Authorization: `Bearer ${localStorage.getItem("token") || ""}`,
+});
+
+// Gambar upload disimpan sebagai path "/uploads/..." di backend
+const imageSrc = (img) => (img && img.startsWith("/") ? `${BASE_URL}${img}` : img);
 
 // Simple fetch dengan logging detail
 const apiFetch = async (url, options = {}) => {
-  const fullUrl = url.startsWith('http') ? url : `http://localhost:8080${url}`;
+  const fullUrl = url.startsWith('http') ? url : `${BASE_URL}${url}`;
   
   console.group('🌐 API Request');
   console.log('URL:', fullUrl);
@@ -18,13 +27,14 @@ const apiFetch = async (url, options = {}) => {
 
   try {
     const response = await fetch(fullUrl, {
+      ...options,
       mode: 'cors',
       credentials: 'omit',
       headers: {
         'Content-Type': 'application/json',
+        ...authHeader(),
         ...options.headers,
       },
-      ...options,
     });
 
     console.group('📨 API Response');
@@ -33,14 +43,14 @@ const apiFetch = async (url, options = {}) => {
     console.groupEnd();
 
     if (!response.ok) {
-      let errorMessage = `HTTP ${response.status}`;
+      let message = `HTTP ${response.status}`;
       try {
-        const errorData = await response.text();
-        errorMessage += ` - ${errorData}`;
+        const errorData = await errorMessage(response, response.statusText);
+        message += ` - ${errorData}`;
       } catch (e) {
-        errorMessage += ` - ${response.statusText}`;
+        message += ` - ${response.statusText}`;
       }
-      throw new Error(errorMessage);
+      throw new Error(message);
     }
 
     // Untuk response tanpa content
@@ -94,7 +104,7 @@ const Menu = () => {
       
       const data = await apiFetch(API_URL);
       console.log('✅ fetchMenus success, data:', data);
-      setMenus(data || []);
+      setMenus(data?.menus || []);
       
     } catch (err) {
       console.error('❌ fetchMenus failed:', err);
@@ -110,8 +120,8 @@ const Menu = () => {
       setError("");
       console.log('🔍 Testing connection to backend...');
       
-      const health = await apiFetch('http://localhost:8080/health');
-      alert(`✅ Backend Connected!\n\nStatus: ${health.status}\nService: ${health.service}\nTime: ${health.time}`);
+      const health = await apiFetch(`${BASE_URL}/healthz`);
+      alert(`✅ Backend Connected!\n\nStatus: ${health.status}`);
       
     } catch (err) {
       const errorMsg = `❌ Cannot connect to backend\n\nPlease check:\n1. Backend server is running\n2. Port 8080 is available\n3. No firewall blocking\n\nError: ${err.message}`;
@@ -137,16 +147,17 @@ const Menu = () => {
       console.log('📤 Uploading image...');
       const response = await fetch(UPLOAD_URL, { 
         method: "POST", 
+        headers: authHeader(),
         body: data,
       });
       
       if (!response.ok) {
-        throw new Error(`Upload failed: ${response.status}`);
+        throw new Error(await errorMessage(response, `Upload failed: ${response.status}`));
       }
       
       const result = await response.json();
       setFormData({ ...formData, img: result.url });
-      setPreviewImg(result.url);
+      setPreviewImg(imageSrc(result.url));
     } catch (err) {
       console.error("Upload gagal:", err);
       alert("Upload gagal: " + err.message);
@@ -176,31 +187,51 @@ const Menu = () => {
       return;
     }
 
-    const price = parseFloat(formData.price);
-    const discount = parseFloat(formData.discount || 0);
-    const discountedPrice = discount > 0 ? price - (price * discount) / 100 : price;
-    
-    const newData = { 
-      ...formData, 
-      price, 
-      discount, 
-      discountedPrice 
+    const newData = {
+      name: formData.name,
+      price: parseFloat(formData.price),
+      category: formData.category,
+      status: formData.status,
+      img: formData.img || "",
+    };
+    // Diskon disimpan lewat endpoint terpisah karena bisa butuh
+    // persetujuan super admin
+    const discount = {
+      discount: parseFloat(formData.discount || 0),
+      start_date: formData.startDate || "",
+      end_date: formData.endDate || "",
     };
+    const discountChanged = editItem
+      ? discount.discount !== (editItem.discount || 0) ||
+        discount.start_date !== (editItem.startDate || "") ||
+        discount.end_date !== (editItem.endDate || "")
+      : discount.discount > 0;
 
     try {
       console.log('💾 Saving menu...', newData);
       
+      let saved;
       if (editItem) {
-        await apiFetch(`${API_URL}/${editItem.id}`, {
+        saved = await apiFetch(`${API_URL}/${editItem.id}`, {
           method: "PUT",
           body: JSON.stringify(newData),
         });
       } else {
-        await apiFetch(API_URL, {
+        saved = await apiFetch(API_URL, {
           method: "POST",
           body: JSON.stringify(newData),
         });
       }
+
+      if (discountChanged) {
+        const result = await apiFetch(`${API_URL}/${saved.id}/discount`, {
+          method: "PUT",
+          body: JSON.stringify(discount),
+        });
+        if (result?.change_request) {
+          alert(result.message);
+        }
+      }
       
       setShowPopup(false);
       fetchMenus();
@@ -217,7 +248,7 @@ const Menu = () => {
       price: menu.price?.toString() || "",
       discount: menu.discount ? menu.discount.toString() : "",
     });
-    setPreviewImg(menu.img || "");
+    setPreviewImg(imageSrc(menu.img) || "");
     setShowPopup(true);
   };
 
@@ -309,7 +340,7 @@ const Menu = () => {
               <tr key={item.id}>
                 <td>
                   {item.img ? (
-                    <img src={item.img} alt={item.name} className="menu-image" />
+                    <img src={imageSrc(item.img)} alt={item.name} className="menu-image" />
                   ) : (
                     <div className="no-image">No Image</div>
                   )}
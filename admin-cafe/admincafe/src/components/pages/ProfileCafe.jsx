@@ -14,8 +14,14 @@ import {
   FaBug
 } from "react-icons/fa";
 import "./ProfileCafe.css";
+import { errorMessage } from "../../apiError";
 
 // Fungsi helper untuk mendapatkan full image URL
+// Token session dari login
+const authHeader = () => ({
+  Authorization: `Bearer ${localStorage.getItem("token") || ""}`,
+});
+
 const getFullImageUrl = (url) => {
   if (!url) return "../../src/assets/maincafe.jpg";
   
@@ -89,9 +95,11 @@ function ProfileCafe() {
     try {
       console.log('🔍 === DEBUG OPERATIONAL HOURS ===');
       
-      const dbResponse = await fetch('http://localhost:8080/cafe/operational-hours');
+      const dbResponse = await fetch('http://localhost:8080/cafe/operational-hours', {
+        headers: authHeader(),
+      });
       if (dbResponse.ok) {
-        const dbData = await dbResponse.json();
+        const dbData = (await dbResponse.json()).operational_hours || [];
         console.log('🗄️ Database operational hours (RAW):', dbData);
         
         console.log('📅 Detailed database hours:');
@@ -211,7 +219,9 @@ function ProfileCafe() {
       console.log('🔄 Fetching cafe profile...');
       setIsLoading(true);
       
-      const response = await fetch('http://localhost:8080/cafe/profile');
+      const response = await fetch('http://localhost:8080/cafe/profile', {
+        headers: authHeader(),
+      });
       console.log('📡 Response status:', response.status);
       
       if (response.ok) {
@@ -351,10 +361,12 @@ function ProfileCafe() {
 
   const fetchGallery = async () => {
     try {
-      const response = await fetch('http://localhost:8080/cafe/gallery');
+      const response = await fetch('http://localhost:8080/cafe/gallery', {
+        headers: authHeader(),
+      });
       if (response.ok) {
         const data = await response.json();
-        setGallery(data.map(item => ({
+        setGallery((data.gallery || []).map(item => ({
           id: item.id,
           src: item.image_url,
           urutan: item.urutan
@@ -384,6 +396,7 @@ function ProfileCafe() {
         
         const response = await fetch('http://localhost:8080/cafe/profile/image', {
           method: 'POST',
+          headers: authHeader(),
           body: formData
         });
         
@@ -396,7 +409,7 @@ function ProfileCafe() {
           await testImageLoad(result.image_url);
           alert('Gambar profile berhasil diupload!');
         } else {
-          const errorText = await response.text();
+          const errorText = await errorMessage(response);
           alert('Gagal upload gambar profile: ' + errorText);
         }
       } catch (error) {
@@ -408,15 +421,9 @@ function ProfileCafe() {
   const handleDeleteProfileImage = async () => {
     if (window.confirm('Apakah Anda yakin ingin menghapus gambar profile?')) {
       try {
-        const response = await fetch('http://localhost:8080/cafe/profile', {
-          method: 'PUT',
-          headers: {
-            'Content-Type': 'application/json',
-          },
-          body: JSON.stringify({
-            ...formData,
-            main_image: ""
-          })
+        const response = await fetch('http://localhost:8080/cafe/profile/image', {
+          method: 'DELETE',
+          headers: authHeader(),
         });
         
         if (response.ok) {
@@ -425,6 +432,8 @@ function ProfileCafe() {
             main_image: ""
           }));
           alert('Gambar profile berhasil dihapus!');
+        } else {
+          alert('Gagal menghapus gambar profile: ' + await errorMessage(response));
         }
       } catch (error) {
         console.error('Error deleting profile image:', error);
@@ -436,13 +445,14 @@ function ProfileCafe() {
   const handleDeleteImage = async (id) => {
     try {
       const response = await fetch(`http://localhost:8080/cafe/gallery/${id}`, {
-        method: 'DELETE'
+        method: 'DELETE',
+        headers: authHeader(),
       });
       if (response.ok) {
         setGallery(gallery.filter((img) => img.id !== id));
         alert('Gambar berhasil dihapus dari galeri!');
       } else {
-        alert('Gagal menghapus gambar!');
+        alert('Gagal menghapus gambar! ' + await errorMessage(response));
       }
     } catch (error) {
       alert('Error menghapus gambar: ' + error.message);
@@ -467,6 +477,7 @@ function ProfileCafe() {
 
       const response = await fetch('http://localhost:8080/cafe/gallery', {
         method: 'POST',
+        headers: authHeader(),
         body: uploadFormData
       });
       
@@ -479,7 +490,7 @@ function ProfileCafe() {
         }]);
         alert('Gambar berhasil ditambahkan ke galeri!');
       } else {
-        alert('Gagal menambahkan gambar ke galeri!');
+        alert('Gagal menambahkan gambar ke galeri! ' + await errorMessage(response));
       }
     } catch (error) {
       alert('Error menambahkan gambar: ' + error.message);
@@ -557,14 +568,17 @@ function ProfileCafe() {
   const updateSocialMedia = async () => {
     try {
       await fetch('http://localhost:8080/cafe/social-media/all', {
-        method: 'DELETE'
+        method: 'DELETE',
+        headers: authHeader(),
       });
 
-      for (const sm of formData.social_media) {
+      // Platform tanpa URL tidak disimpan
+      for (const sm of formData.social_media.filter(sm => sm.url && sm.url.trim())) {
         await fetch('http://localhost:8080/cafe/social-media', {
           method: 'POST',
           headers: {
             'Content-Type': 'application/json',
+            ...authHeader(),
           },
           body: JSON.stringify({
             platform: sm.platform,
@@ -599,12 +613,13 @@ function ProfileCafe() {
         method: 'PUT',
         headers: {
           'Content-Type': 'application/json',
+          ...authHeader(),
         },
-        body: JSON.stringify(operationalData)
+        body: JSON.stringify({ operational_hours: operationalData })
       });
       
       if (!response.ok) {
-        const errorText = await response.text();
+        const errorText = await errorMessage(response);
         throw new Error(`HTTP ${response.status}: ${errorText}`);
       }
       
@@ -623,12 +638,13 @@ function ProfileCafe() {
         method: 'PUT',
         headers: {
           'Content-Type': 'application/json',
+          ...authHeader(),
         },
-        body: JSON.stringify(formData.facilities)
+        body: JSON.stringify({ facilities: formData.facilities })
       });
       
       if (!response.ok) {
-        throw new Error('Gagal menyimpan fasilitas');
+        throw new Error(await errorMessage(response, 'Gagal menyimpan fasilitas'));
       }
     } catch (error) {
       console.error('Error updating facilities:', error);
@@ -653,6 +669,7 @@ function ProfileCafe() {
         method: 'PUT',
         headers: {
           'Content-Type': 'application/json',
+          ...authHeader(),
         },
         body: JSON.stringify({
           id: formData.id,
@@ -666,10 +683,17 @@ function ProfileCafe() {
       });
 
       if (!profileResponse.ok) {
-        const errorText = await profileResponse.text();
+        const errorText = await errorMessage(profileResponse);
         throw new Error(`Gagal menyimpan profile: ${errorText}`);
       }
 
+      // 202: nama/alamat masuk antrean persetujuan super admin
+      let pendingMessage = '';
+      if (profileResponse.status === 202) {
+        const result = await profileResponse.json();
+        pendingMessage = '\n' + result.message;
+      }
+
       // Update social media
       await updateSocialMedia();
 
@@ -681,7 +705,7 @@ function ProfileCafe() {
 
       console.log("🎉 Semua data berhasil disimpan!");
       setShowEdit(false);
-      alert('Profile berhasil disimpan!');
+      alert('Profile berhasil disimpan!' + pendingMessage);
       
       // Refresh data
       await fetchCafeProfile();
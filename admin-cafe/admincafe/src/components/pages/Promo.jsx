@@ -1,6 +1,11 @@
 import React, { useState, useEffect, useMemo } from "react";
 import "./Promo.css";
 import { FaChevronUp, FaChevronDown, FaSpinner, FaTags, FaPercent, FaCalendarAlt, FaShoppingBag } from "react-icons/fa";
+import { errorMessage } from "../../apiError";
+
+const authHeader = () => ({
+  Authorization: `Bearer ${localStorage.getItem("token") || ""}`,
+});
 
 const Promo = () => {
   const [promos, setPromos] = useState([]);
@@ -17,20 +22,18 @@ const Promo = () => {
       setLoading(true);
       console.log('🔄 Fetching promo menus...');
       
-      const response = await fetch('http://localhost:8080/api/v1/promos');
+      const response = await fetch('http://localhost:8080/cafe/promos', {
+        headers: authHeader(),
+      });
       
       if (!response.ok) {
-        throw new Error(`HTTP error! status: ${response.status}`);
+        throw new Error(await errorMessage(response, `HTTP error! status: ${response.status}`));
       }
       
       const result = await response.json();
       console.log('📦 API Response:', result);
       
-      if (result.error) {
-        throw new Error(result.message);
-      }
-      
-      const promoData = result.data || [];
+      const promoData = result.promos || [];
       console.log('✅ Promo data received:', promoData.length, 'items');
       
       setPromos(promoData);
@@ -110,7 +113,7 @@ const Promo = () => {
         
         <div className="stats-grid">
           <div className="stat-card">
-            <div className="stat-number">{promos.length}</div>
+            <div className="stat-number">{promos.filter((p) => p.status === "active").length}</div>
             <div className="stat-label">Promo Aktif</div>
           </div>
           <div className="stat-card">
@@ -160,8 +163,8 @@ const Promo = () => {
                     <FaPercent className="badge-icon" />
                     {promo.discount}% OFF
                   </div>
-                  <div className="status-badge active">
-                    AKTIF
+                  <div className={`status-badge ${promo.status}`}>
+                    {promo.status === "scheduled" ? "TERJADWAL" : "AKTIF"}
                   </div>
                 </div>
 
@@ -1,9 +1,17 @@
 import React, { useEffect, useState } from "react";
 import "./Ulasan.css";
 import { FaStar, FaReply, FaPaperPlane, FaTimes, FaUser, FaImage, FaSync } from "react-icons/fa";
+import { errorMessage } from "../../apiError";
 
-const API_URL = "http://localhost:8080/ulasan";
-const ADMIN_API_URL = "http://localhost:8080/admin/ulasan";
+const BASE_URL = "http://localhost:8080";
+const API_URL = `${BASE_URL}/cafe/reviews`;
+
+const authHeader = () => ({
+  Authorization: `Bearer ${localStorage.getItem("token") || ""}`,
+});
+
+// Gambar hasil upload disimpan sebagai path /uploads/...
+const imageSrc = (url) => (url && url.startsWith("/") ? `${BASE_URL}${url}` : url);
 
 const Ulasan = () => {
   const [reviews, setReviews] = useState([]);
@@ -23,15 +31,15 @@ const Ulasan = () => {
       setError(null);
       
       console.log("🔄 Fetching reviews from:", API_URL);
-      const response = await fetch(API_URL);
+      const response = await fetch(API_URL, { headers: authHeader() });
       
       if (!response.ok) {
-        throw new Error(`HTTP error! status: ${response.status}`);
+        throw new Error(await errorMessage(response, `HTTP error! status: ${response.status}`));
       }
       
       const data = await response.json();
       console.log("✅ Data received from backend:", data);
-      setReviews(data);
+      setReviews(data.reviews || []);
       
     } catch (err) {
       console.error("❌ Gagal ambil data ulasan:", err);
@@ -63,10 +71,11 @@ const Ulasan = () => {
     try {
       console.log("📤 Sending reply for review:", reviewId);
       
-      const response = await fetch(`${ADMIN_API_URL}/${reviewId}/reply`, {
+      const response = await fetch(`${API_URL}/${reviewId}/reply`, {
         method: "PUT",
         headers: {
           "Content-Type": "application/json",
+          ...authHeader(),
         },
         body: JSON.stringify({
           reply: replyText
@@ -74,7 +83,7 @@ const Ulasan = () => {
       });
 
       if (!response.ok) {
-        throw new Error(`Gagal mengirim balasan: ${response.status}`);
+        throw new Error(await errorMessage(response, `Gagal mengirim balasan: ${response.status}`));
       }
 
       const result = await response.json();
@@ -88,7 +97,7 @@ const Ulasan = () => {
       
     } catch (error) {
       console.error("❌ Gagal mengirim balasan:", error);
-      alert("Gagal mengirim balasan! Periksa koneksi backend.");
+      alert(`Gagal mengirim balasan! ${error.message}`);
     } finally {
       setSaving(false);
     }
@@ -100,12 +109,13 @@ const Ulasan = () => {
     try {
       console.log("🗑️ Deleting reply for review:", reviewId);
       
-      const response = await fetch(`${ADMIN_API_URL}/${reviewId}/reply`, {
+      const response = await fetch(`${API_URL}/${reviewId}/reply`, {
         method: "DELETE",
+        headers: authHeader(),
       });
 
       if (!response.ok) {
-        throw new Error(`Gagal menghapus balasan: ${response.status}`);
+        throw new Error(await errorMessage(response, `Gagal menghapus balasan: ${response.status}`));
       }
 
       const result = await response.json();
@@ -117,7 +127,7 @@ const Ulasan = () => {
       
     } catch (error) {
       console.error("❌ Gagal menghapus balasan:", error);
-      alert("Gagal menghapus balasan! Periksa koneksi backend.");
+      alert(`Gagal menghapus balasan! ${error.message}`);
     }
   };
 
@@ -137,7 +147,7 @@ const Ulasan = () => {
   const testBackendConnection = async () => {
     try {
       setError("");
-      const response = await fetch(API_URL);
+      const response = await fetch(`${BASE_URL}/healthz`);
       if (response.ok) {
         alert("✅ Backend terhubung dengan baik!");
         fetchReviews();
@@ -218,7 +228,7 @@ const Ulasan = () => {
               <div className="ulasan-header-card">
                 <div className="user-info">
                   <img 
-                    src={review.avatar} 
+                    src={imageSrc(review.avatar)} 
                     alt={review.name}
                     className="user-avatar"
                     onError={(e) => {
@@ -258,7 +268,7 @@ const Ulasan = () => {
                 {review.image && (
                   <div className="ulasan-image-container">
                     <img 
-                      src={review.image} 
+                      src={imageSrc(review.image)} 
                       alt="Ulasan pengunjung" 
                       className="ulasan-image"
                       onError={(e) => {
@@ -1,6 +1,45 @@
-import React from 'react';
+import React, { useEffect, useState } from 'react';
 import './DashboardPage.css';
 
+const API = 'http://localhost:8080';
+
+// Token session dari login
+const authHeader = () => ({
+  Authorization: `Bearer ${localStorage.getItem('token') || ''}`,
+});
+
+// Angka kartu ditampilkan 5 digit seperti desain, "-" jika belum tersedia
+const formatCount = (n, digits = 5) => (n === null || n === undefined ? '-' : String(n).padStart(digits, '0'));
+
+const SEGMENTS = [
+    { key: 'loyal', color: '#8e44ad' },
+    { key: 'new', color: '#e74c3c' },
+    { key: 'premium', color: '#27ae60' },
+];
+
+// Grafik garis sederhana pengunjung per segmen
+const VisitorChart = ({ series }) => {
+    const width = 600;
+    const height = 180;
+    const max = Math.max(1, ...series.flatMap((p) => SEGMENTS.map((s) => p[s.key])));
+    const x = (i) => (series.length > 1 ? (i / (series.length - 1)) * width : width / 2);
+    const y = (v) => height - (v / max) * (height - 10);
+
+    return (
+        <svg viewBox={`0 0 ${width} ${height}`} className="d-chart-lines" preserveAspectRatio="none">
+            {SEGMENTS.map((s) => (
+                <polyline
+                    key={s.key}
+                    fill="none"
+                    stroke={s.color}
+                    strokeWidth="2"
+                    points={series.map((p, i) => `${x(i)},${y(p[s.key])}`).join(' ')}
+                />
+            ))}
+        </svg>
+    );
+};
+
 const StatCard = ({ title, value, icon, colorClass }) => (
     <div className={`d-stat-card ${colorClass}`}>
         <div className="stat-info">
@@ -12,11 +51,30 @@ const StatCard = ({ title, value, icon, colorClass }) => (
 );
 
 const DashboardPage = () => {
+    const [data, setData] = useState(null);
+
+    // ==============================
+    // Ambil statistik dashboard dari backend
+    // ==============================
+    useEffect(() => {
+        const fetchStats = async () => {
+            try {
+                const res = await fetch(`${API}/admin/stats`, { headers: authHeader() });
+                if (!res.ok) throw new Error('Gagal fetch statistik');
+                setData(await res.json());
+            } catch (err) {
+                console.error(err);
+            }
+        };
+        fetchStats();
+    }, []);
+
+    const byRole = data?.accounts.by_role || {};
     const stats = [
-        { title: 'User', value: '01171', icon: '👤', colorClass: 'bg-purple' },
-        { title: 'Kafe Terdaftar', value: '00317', icon: '☕', colorClass: 'bg-orange' },
-        { title: 'Event Organizer', value: '00317', icon: '📅', colorClass: 'bg-blue' },
-        { title: 'Admin Aktif', value: '004', icon: '👮', colorClass: 'bg-green' },
+        { title: 'User', value: formatCount(data && (byRole.customer || 0)), icon: '👤', colorClass: 'bg-purple' },
+        { title: 'Kafe Terdaftar', value: formatCount(data && data.registrations.cafe.verified), icon: '☕', colorClass: 'bg-orange' },
+        { title: 'Event Organizer', value: formatCount(data?.events.organizers), icon: '📅', colorClass: 'bg-blue' },
+        { title: 'Admin Aktif', value: formatCount(data && data.active_admins, 3), icon: '👮', colorClass: 'bg-green' },
     ];
 
     return (
@@ -34,9 +92,11 @@ const DashboardPage = () => {
                         <button className="d-btn-blue">Lihat</button>
                     </div>
                     <div className="d-chart-placeholder">
-                        <div className="d-chart-lines">📈 [Grafik Placeholder]</div>
+                        {data ? <VisitorChart series={data.visitors.series} /> : <div className="d-chart-lines">Memuat...</div>}
                         <div className="d-chart-legend">
-                            <span>🟣 Loyal</span> <span>🔴 Baru</span> <span>🟢 Premium</span>
+                            <span>🟣 Loyal {data ? data.visitors.loyal : ''}</span>{' '}
+                            <span>🔴 Baru {data ? data.visitors.new : ''}</span>{' '}
+                            <span>🟢 Premium {data ? data.visitors.premium : ''}</span>
                         </div>
                     </div>
                 </div>
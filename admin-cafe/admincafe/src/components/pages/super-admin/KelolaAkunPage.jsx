@@ -1,5 +1,15 @@
-import React from 'react';
+import React, { useEffect, useState } from 'react';
 import './KelolaAkunPage.css';
+import { errorMessage } from '../../../apiError';
+
+const API = 'http://localhost:8080';
+
+// Token session dari login
+const authHeader = () => ({
+  Authorization: `Bearer ${localStorage.getItem('token') || ''}`,
+});
+
+const formatCount = (n, digits = 5) => (n === null || n === undefined ? '-' : String(n).padStart(digits, '0'));
 
 const AccountSummaryCard = ({ title, value, icon, color }) => (
   <div className="account-summary-card" style={{ backgroundColor: color }}>
@@ -11,12 +21,84 @@ const AccountSummaryCard = ({ title, value, icon, color }) => (
   </div>
 );
 
+const STATUS_LABELS = {
+  active: 'Aktif',
+  unverified: 'Belum Verifikasi',
+  suspended: 'Ditangguhkan',
+  deleted: 'Dihapus',
+};
+
 const KelolaAkunPage = () => {
+  const [data, setData] = useState(null);
+  const [accounts, setAccounts] = useState([]);
+  const [filter, setFilter] = useState({ q: '', role: '', status: '' });
+
+  useEffect(() => {
+    const fetchStats = async () => {
+      try {
+        const res = await fetch(`${API}/admin/stats`, { headers: authHeader() });
+        if (!res.ok) throw new Error('Gagal fetch statistik akun');
+        setData(await res.json());
+      } catch (err) {
+        console.error(err);
+      }
+    };
+    fetchStats();
+  }, []);
+
+  // ==============================
+  // Daftar akun + filter
+  // ==============================
+  const fetchAccounts = async () => {
+    try {
+      const params = new URLSearchParams(Object.entries(filter).filter(([, v]) => v));
+      const res = await fetch(`${API}/admin/accounts?${params}`, { headers: authHeader() });
+      if (!res.ok) throw new Error('Gagal fetch akun');
+      const result = await res.json();
+      setAccounts(result.accounts || []);
+    } catch (err) {
+      console.error(err);
+      setAccounts([]);
+    }
+  };
+
+  useEffect(() => {
+    fetchAccounts();
+  }, [filter]);
+
+  // Jalankan aksi kelola akun lalu muat ulang daftar
+  const runAction = async (account, path, method = 'POST', body) => {
+    try {
+      const res = await fetch(`${API}/admin/accounts/${account.id}${path}`, {
+        method,
+        headers: { 'Content-Type': 'application/json', ...authHeader() },
+        body: body ? JSON.stringify(body) : undefined,
+      });
+      if (!res.ok) throw new Error(await errorMessage(res));
+      fetchAccounts();
+    } catch (err) {
+      console.error(err);
+      alert(err.message || 'Gagal memproses akun');
+    }
+  };
+
+  const suspend = (account) => {
+    const reason = prompt(`Alasan menangguhkan akun ${account.username}:`);
+    if (reason) runAction(account, '/suspend', 'POST', { reason });
+  };
+
+  const remove = (account) => {
+    if (window.confirm(`Hapus akun ${account.username}? Akun bisa dipulihkan kembali.`)) {
+      runAction(account, '', 'DELETE');
+    }
+  };
+
+  const byRole = data?.accounts.by_role || {};
   const accountSummaries = [
-    { title: 'User', value: '01171', icon: '👤', color: '#fcfcfc' },
-    { title: 'Cafe', value: '00317', icon: '☕', color: '#fcfcfc' },
-    { title: 'Event Organizer', value: '00317', icon: '🗓️', color: '#fcfcfc' },
-    { title: 'ADMIN', value: '004', icon: '👨‍💼', color: '#fcfcfc' },
+    { title: 'User', value: formatCount(data && (byRole.customer || 0)), icon: '👤', color: '#fcfcfc' },
+    { title: 'Cafe', value: formatCount(data && (byRole.cafe || 0)), icon: '☕', color: '#fcfcfc' },
+    { title: 'Event Organizer', value: formatCount(data?.events.organizers), icon: '🗓️', color: '#fcfcfc' },
+    { title: 'ADMIN', value: formatCount(data && (byRole.admin || 0), 3), icon: '👨‍💼', color: '#fcfcfc' },
   ];
 
   return (
@@ -26,7 +108,65 @@ const KelolaAkunPage = () => {
         {accountSummaries.map((item, index) => <AccountSummaryCard key={index} {...item} />)}
       </div>
       <div className="admin-greeting-card">
-        <h2>Halo, SUPER ADMIN X Y</h2>
+        <h2>Halo, SUPER ADMIN {localStorage.getItem('username') || ''}</h2>
+      </div>
+
+      <div className="account-table-card">
+        <div className="account-filters">
+          <input
+            placeholder="Cari username / email"
+            value={filter.q}
+            onChange={(e) => setFilter({ ...filter, q: e.target.value })}
+          />
+          <select value={filter.role} onChange={(e) => setFilter({ ...filter, role: e.target.value })}>
+            <option value="">Semua Role</option>
+            <option value="customer">User</option>
+            <option value="cafe">Cafe</option>
+            <option value="admin">Admin</option>
+          </select>
+          <select value={filter.status} onChange={(e) => setFilter({ ...filter, status: e.target.value })}>
+            <option value="">Semua Status</option>
+            {Object.entries(STATUS_LABELS).map(([value, label]) => (
+              <option key={value} value={value}>{label}</option>
+            ))}
+          </select>
+        </div>
+        <table className="account-table">
+          <thead>
+            <tr>
+              <th>Username</th>
+              <th>Email</th>
+              <th>Role</th>
+              <th>Status</th>
+              <th>Aksi</th>
+            </tr>
+          </thead>
+          <tbody>
+            {accounts.map((a) => (
+              <tr key={a.id}>
+                <td>{a.username}</td>
+                <td>{a.email || '-'}</td>
+                <td>{a.role}</td>
+                <td title={a.suspended_reason || ''}>{STATUS_LABELS[a.status] || a.status}</td>
+                <td className="account-actions">
+                  {a.status === 'deleted' ? (
+                    <button onClick={() => runAction(a, '/restore')}>Pulihkan</button>
+                  ) : (
+                    <>
+                      {a.status === 'suspended' ? (
+                        <button onClick={() => runAction(a, '/unsuspend')}>Aktifkan</button>
+                      ) : (
+                        <button onClick={() => suspend(a)}>Tangguhkan</button>
+                      )}
+                      <button disabled={!a.active_sessions} onClick={() => runAction(a, '/logout')}>Logout Paksa</button>
+                      <button onClick={() => remove(a)}>Hapus</button>
+                    </>
+                  )}
+                </td>
+              </tr>
+            ))}
+          </tbody>
+        </table>
       </div>
     </div>
   );
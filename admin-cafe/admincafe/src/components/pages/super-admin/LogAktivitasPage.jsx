@@ -1,51 +1,172 @@
-import React from 'react';
+import React, { useEffect, useState } from 'react';
 import './LogAktivitasPage.css';
+import ChatPanel from '../../ChatPanel';
+import { errorMessage } from '../../../apiError';
 
-const ActivityCard = ({ title, date, status, subject, claim, variant }) => (
-    <div className={`activity-card ${variant}`}>
+const API = 'http://localhost:8080';
+
+// Token session dari login
+const authHeader = () => ({
+  Authorization: `Bearer ${localStorage.getItem('token') || ''}`,
+});
+
+const formatDate = (iso) =>
+  new Date(iso).toLocaleDateString('id-ID', { day: 'numeric', month: 'short', year: 'numeric' });
+
+const ActivityCard = ({ log, onConfirm, onHistory, onChat }) => (
+    <div className={`activity-card ${log.acknowledged_at ? 'light' : 'dark'}`}>
       <div className="act-content">
-        <h3 className="act-title">{title}</h3>
+        <h3 className="act-title">{log.title}</h3>
         <div className="act-meta">
-            <span className="act-date">{date}</span>
-            <div className="act-status">Operasional : <span className="status-pill">{status}</span></div>
+            <span className="act-date">{formatDate(log.created_at)}</span>
+            <div className="act-status">
+              Operasional : <span className="status-pill">{log.acknowledged_at ? 'Dikonfirmasi' : 'Aktif'}</span>
+            </div>
         </div>
         <div className="act-details">
-            <p>Perihal : {subject}</p>
-            <p>{claim}</p>
+            <p>Perihal : {log.subject || log.action}</p>
+            <p>{log.claim || (log.actor_name ? `Oleh : ${log.actor_name}` : '')}</p>
         </div>
       </div>
       <div className="act-actions">
-        <button className="btn-act confirm"><span className="icon-check">✔</span> Konfirmasi</button>
-        <button className="btn-act history"><span className="icon-dots">•••</span> Riwayat</button>
-        <button className="btn-act chat"><span className="icon-chat">💬</span> Buka Chat</button>
+        <button className="btn-act confirm" disabled={!!log.acknowledged_at} onClick={() => onConfirm(log.id)}>
+          <span className="icon-check">✔</span> Konfirmasi
+        </button>
+        <button className="btn-act history" disabled={!log.entity_id} onClick={() => onHistory(log)}>
+          <span className="icon-dots">•••</span> Riwayat
+        </button>
+        <button className="btn-act chat" onClick={() => onChat(log)}>
+          <span className="icon-chat">💬</span> Buka Chat
+        </button>
       </div>
     </div>
 );
 
 const LogAktivitasPage = () => {
-  const logs = [
-    { title: 'Kafe xxx mengirimkan perubahan Diskon', date: '7 Sep 2025', status: 'Aktif', subject: 'Diskon 20% sd 20rb', claim: 'Klaim : Anggota Premium', variant: 'dark' },
-    { title: 'Kafe xxx mengirimkan perubahan Lokasi', date: '7 Sep 2025', status: 'Aktif', subject: 'Perubahan Lokasi', claim: 'Klaim : SUPER ADMIN', variant: 'light' },
-    { title: 'Kafe xxx melakukan perubahan foto profil', date: '7 Sep 2025', status: 'Aktif', subject: 'Perubahan Akun', claim: '', variant: 'light' },
-    { title: 'USER Delon berlangganan PREMIUM', date: '7 Sep 2025', status: 'Aktif', subject: 'Berlangganan', claim: 'Klaim : Anggota Premium', variant: 'dark' },
-    { title: 'Kafe xxx mengirimkan perubahan Diskon', date: '7 Sep 2025', status: 'Aktif', subject: 'Diskon 20% sd 20rb', claim: 'Klaim : Anggota Premium', variant: 'dark' },
-    { title: 'Kafe xxx mengirimkan perubahan Lokasi', date: '7 Sep 2025', status: 'Aktif', subject: 'Perubahan Lokasi', claim: 'Datang : 75', variant: 'light' },
-  ];
+  const [logs, setLogs] = useState([]);
+  const [unacknowledged, setUnacknowledged] = useState(0);
+  // Log audit tidak bisa dihapus; yang sudah dikonfirmasi hanya disembunyikan
+  const [hideAcknowledged, setHideAcknowledged] = useState(false);
+  const [history, setHistory] = useState(null);
+  const [chatThreadId, setChatThreadId] = useState(null);
+
+  // ==============================
+  // Ambil log aktivitas dari backend
+  // ==============================
+  const fetchLogs = async () => {
+    try {
+      const query = hideAcknowledged ? '?acknowledged=false' : '';
+      const res = await fetch(`${API}/admin/activity${query}`, { headers: authHeader() });
+      if (!res.ok) throw new Error('Gagal fetch log aktivitas');
+
+      const data = await res.json();
+      setLogs(data.activities || []);
+      setUnacknowledged(data.unacknowledged || 0);
+    } catch (err) {
+      console.error(err);
+      setLogs([]);
+    }
+  };
+
+  useEffect(() => {
+    fetchLogs();
+  }, [hideAcknowledged]);
+
+  // ==============================
+  // Konfirmasi satu / semua log
+  // ==============================
+  const acknowledge = async (id) => {
+    const url = id ? `${API}/admin/activity/${id}/acknowledge` : `${API}/admin/activity/acknowledge`;
+    try {
+      const res = await fetch(url, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json', ...authHeader() },
+        body: id ? undefined : JSON.stringify({ ids: [] }),
+      });
+      if (!res.ok) throw new Error('Gagal konfirmasi log');
+      fetchLogs();
+    } catch (err) {
+      console.error(err);
+      alert('Gagal mengonfirmasi log aktivitas');
+    }
+  };
+
+  const showHistory = async (log) => {
+    try {
+      const res = await fetch(
+        `${API}/admin/activity/history/${encodeURIComponent(log.entity_type)}/${encodeURIComponent(log.entity_id)}`,
+        { headers: authHeader() },
+      );
+      if (!res.ok) throw new Error('Gagal fetch riwayat');
+      const data = await res.json();
+      setHistory({ log, items: data.activities || [] });
+    } catch (err) {
+      console.error(err);
+      alert('Gagal mengambil riwayat');
+    }
+  };
+
+  // ==============================
+  // Buka (atau buat) thread chat untuk entitas log ini bersama pelakunya
+  // ==============================
+  const openChat = async (log) => {
+    const withActor = log.actor_user_id && ['cafe', 'admin'].includes(log.actor_role);
+    try {
+      const res = await fetch(`${API}/chat/threads`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json', ...authHeader() },
+        body: JSON.stringify({
+          subject: log.title,
+          entity_type: log.entity_type,
+          entity_id: log.entity_id,
+          participant_ids: withActor ? [log.actor_user_id] : [],
+        }),
+      });
+      if (!res.ok) throw new Error(await errorMessage(res));
+      const thread = await res.json();
+      setChatThreadId(thread.id);
+    } catch (err) {
+      console.error(err);
+      alert('Gagal membuka chat');
+    }
+  };
 
   return (
     <div className="cafe-content-padding cafe-theme">
       <div className="log-header">
-        <h1 className="page-title">Log Aktivitas</h1>
+        <h1 className="page-title">Log Aktivitas ({unacknowledged})</h1>
         <div className="log-header-actions">
-            <button className="btn-header-green delete">🗑 Hapus Semua</button>
-            <button className="btn-header-green confirm">✔ Konfirmasi Semua Pesan</button>
+            <button className="btn-header-green delete" onClick={() => setHideAcknowledged(!hideAcknowledged)}>
+              {hideAcknowledged ? '👁 Tampilkan Semua' : '🗑 Sembunyikan yang Dikonfirmasi'}
+            </button>
+            <button className="btn-header-green confirm" onClick={() => acknowledge(null)}>✔ Konfirmasi Semua Pesan</button>
         </div>
       </div>
       <div className="log-grid">
-        {logs.map((log, index) => <ActivityCard key={index} {...log} />)}
+        {logs.map((log) => (
+          <ActivityCard key={log.id} log={log} onConfirm={acknowledge} onHistory={showHistory} onChat={openChat} />
+        ))}
       </div>
+
+      {history && (
+        <div className="log-history">
+          <div className="log-header">
+            <h2>Riwayat {history.log.entity_type} #{history.log.entity_id}</h2>
+            <button className="btn-header-green" onClick={() => setHistory(null)}>Tutup</button>
+          </div>
+          {history.items.map((item) => (
+            <div key={item.id} className="log-history-item">
+              <strong>{formatDate(item.created_at)}</strong> — {item.title} ({item.actor_name || 'system'})
+              {item.before && <pre>Sebelum: {JSON.stringify(item.before, null, 2)}</pre>}
+              {item.after && <pre>Sesudah: {JSON.stringify(item.after, null, 2)}</pre>}
+            </div>
+          ))}
+        </div>
+      )}
+
+      {chatThreadId && <ChatPanel threadId={chatThreadId} onClose={() => setChatThreadId(null)} />}
     </div>
   );
 };
 
-export default LogAktivitasPage;
\ No newline at end of file
+export default LogAktivitasPage;
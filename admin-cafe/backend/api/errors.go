@@ -0,0 +1,135 @@
+// Package api menyeragamkan response JSON semua handler.
+//
+// Response sukses ditulis dengan JSON. Error ditulis dengan Fail, Failf
+// atau WriteError dalam satu bentuk:
+//
+//	{"error": "Email tidak valid", "code": "validation_failed",
+//	 "fields": [{"field": "email", "code": "email", "message": "..."}],
+//	 "request_id": "..."}
+//
+// "error" adalah pesan untuk ditampilkan, diterjemahkan ke bahasa dari
+// header Accept-Language (id atau en); "code" stabil untuk dicek
+// program. Pesan ditulis dalam Bahasa Indonesia di kode dan terjemahan
+// Inggrisnya ada di messages_en.go.
+package api
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Kode error yang bisa dicek client
+const (
+	CodeBadRequest         = "bad_request"
+	CodeInvalidBody        = "invalid_body"
+	CodeValidation         = "validation_failed"
+	CodeUnauthorized       = "unauthorized"
+	CodeInvalidCredentials = "invalid_credentials"
+	CodeSessionInvalid     = "session_invalid"
+	CodePasswordChange     = "password_change_required"
+	CodeForbidden          = "forbidden"
+	CodeAccountSuspended   = "account_suspended"
+	CodeEmailUnverified    = "email_unverified"
+	CodeNotFound           = "not_found"
+	CodeConflict           = "conflict"
+	CodeTooLarge           = "payload_too_large"
+	CodeUnsupportedMedia   = "unsupported_media_type"
+	CodeUnprocessable      = "unprocessable"
+	CodeInternal           = "internal"
+	CodeNotImplemented     = "not_implemented"
+	CodeBadGateway         = "bad_gateway"
+	CodeUnavailable        = "unavailable"
+)
+
+// codeForStatus adalah kode default untuk setiap status HTTP
+var codeForStatus = map[int]string{
+	http.StatusBadRequest:            CodeBadRequest,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusConflict:              CodeConflict,
+	http.StatusRequestEntityTooLarge: CodeTooLarge,
+	http.StatusUnsupportedMediaType:  CodeUnsupportedMedia,
+	http.StatusUnprocessableEntity:   CodeUnprocessable,
+	http.StatusInternalServerError:   CodeInternal,
+	http.StatusNotImplemented:        CodeNotImplemented,
+	http.StatusBadGateway:            CodeBadGateway,
+	http.StatusServiceUnavailable:    CodeUnavailable,
+}
+
+// Error adalah error yang dikirim ke client. Message adalah format pesan
+// Bahasa Indonesia (diterjemahkan saat ditulis); Err adalah penyebab
+// internal yang hanya ditulis ke log.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Args    []interface{}
+	Fields  []FieldError
+	Err     error
+}
+
+// FieldError adalah kesalahan input pada satu field request
+type FieldError struct {
+	Field   string        `json:"field"`
+	Code    string        `json:"code"`
+	Message string        `json:"message"`
+	Args    []interface{} `json:"-"`
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if len(e.Args) > 0 {
+		msg = fmt.Sprintf(e.Message, e.Args...)
+	}
+	if e.Err != nil {
+		return msg + ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New membuat error dengan kode default dari status
+func New(status int, message string) *Error {
+	return &Error{Status: status, Code: statusCode(status), Message: message}
+}
+
+// Newf seperti New dengan format pesan; format ikut diterjemahkan
+func Newf(status int, format string, args ...interface{}) *Error {
+	return &Error{Status: status, Code: statusCode(status), Message: format, Args: args}
+}
+
+// Validation membuat error 400 dengan daftar field yang salah. Pesan
+// utamanya pesan field pertama, supaya client yang hanya menampilkan
+// "error" tetap memberi petunjuk yang jelas.
+func Validation(fields ...FieldError) *Error {
+	e := &Error{
+		Status:  http.StatusBadRequest,
+		Code:    CodeValidation,
+		Message: "Data yang dikirim tidak valid",
+		Fields:  fields,
+	}
+	if len(fields) > 0 {
+		e.Message, e.Args = fields[0].Message, fields[0].Args
+	}
+	return e
+}
+
+// WithCode mengganti kode default dengan kode yang lebih spesifik
+func (e *Error) WithCode(code string) *Error {
+	e.Code = code
+	return e
+}
+
+func statusCode(status int) string {
+	if code, ok := codeForStatus[status]; ok {
+		return code
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Bahasa pesan error yang didukung
+const (
+	LangID = "id"
+	LangEN = "en"
+)
+
+// Language memilih bahasa dari header Accept-Language (mis.
+// "en-US,en;q=0.9,id;q=0.8"). Bahasa dengan q tertinggi yang didukung
+// dipakai; default Bahasa Indonesia.
+func Language(r *http.Request) string {
+	best, bestQ := LangID, -1.0
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if lang != LangID && lang != LangEN {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > bestQ {
+			best, bestQ = lang, q
+		}
+	}
+	return best
+}
@@ -0,0 +1,375 @@
+package api
+
+// english adalah terjemahan pesan error untuk Accept-Language: en, dengan
+// kunci pesan/format Bahasa Indonesia persis seperti di kode (termasuk
+// pesan error sentinel repository yang diteruskan ke client). Pesan yang
+// belum ada di sini tetap dikirim dalam Bahasa Indonesia.
+var english = map[string]string{
+	// Umum
+	"Body request tidak valid":        "Invalid request body",
+	"Data yang dikirim tidak valid":   "The submitted data is invalid",
+	"Terjadi kesalahan pada server":   "Something went wrong on the server",
+	"Akses ditolak":                   "Access denied",
+	"%s tidak valid":                  "%s is invalid",
+	"limit harus di antara 1 dan %d":  "limit must be between 1 and %d",
+	"offset tidak valid":              "offset is invalid",
+	"File error":                      "File error",
+	"File terlalu besar (maks 10 MB)": "File is too large (max 10 MB)",
+	"Gagal menyimpan file":            "Failed to save file",
+	"Gagal memproses permintaan":      "Failed to process the request",
+
+	// Validasi field (validate)
+	"%s wajib diisi":                                     "%s is required",
+	"%s minimal %s karakter":                             "%s must be at least %s characters",
+	"%s maksimal %s karakter":                            "%s must be at most %s characters",
+	"%s minimal %s item":                                 "%s must have at least %s items",
+	"%s maksimal %s item":                                "%s must have at most %s items",
+	"%s minimal %s":                                      "%s must be at least %s",
+	"%s maksimal %s":                                     "%s must be at most %s",
+	"%s harus lebih dari %s":                             "%s must be greater than %s",
+	"%s harus kurang dari %s":                            "%s must be less than %s",
+	"%s harus salah satu dari: %s":                       "%s must be one of: %s",
+	"%s harus berupa alamat email yang valid":            "%s must be a valid email address",
+	"%s 3-50 karakter (huruf, angka, titik, underscore)": "%s must be 3-50 characters (letters, digits, dot, underscore)",
+	"%s harus berupa nomor HP yang valid":                "%s must be a valid phone number",
+	"Format %s harus YYYY-MM-DD":                         "%s must be formatted YYYY-MM-DD",
+	"Format %s harus HH:MM":                              "%s must be formatted HH:MM",
+	"%s tidak boleh sebelum %s":                          "%s must not be before %s",
+	"%s tidak boleh lebih kecil dari %s":                 "%s must not be less than %s",
+	"%s tidak boleh kosong":                              "%s must not be empty",
+	"%s harus berbeda dari %s":                           "%s must differ from %s",
+
+	// Auth & session
+	"Silakan login terlebih dahulu":                            "Please log in first",
+	"Session tidak valid, silakan login ulang":                 "Session is invalid, please log in again",
+	"session tidak valid":                                      "session is invalid",
+	"Gagal memeriksa session":                                  "Failed to check session",
+	"Password wajib diganti sebelum melanjutkan":               "You must change your password before continuing",
+	"Username atau password salah":                             "Incorrect username or password",
+	"Akun kamu ditangguhkan":                                   "Your account has been suspended",
+	"Akun kamu ditangguhkan: %s":                               "Your account has been suspended: %s",
+	"Email belum diverifikasi, cek inbox email kamu":           "Email is not verified yet, check your inbox",
+	"Gagal login":                                              "Failed to log in",
+	"Gagal logout":                                             "Failed to log out",
+	"Gagal registrasi":                                         "Registration failed",
+	"Gagal verifikasi email":                                   "Failed to verify email",
+	"Gagal reset password":                                     "Failed to reset password",
+	"Gagal mengganti password":                                 "Failed to change password",
+	"Email sudah terdaftar":                                    "Email is already registered",
+	"Username sudah dipakai":                                   "Username is already taken",
+	"Format email tidak valid":                                 "Invalid email format",
+	"Format nomor HP tidak valid":                              "Invalid phone number format",
+	"Username 3-50 karakter (huruf, angka, titik, underscore)": "Username must be 3-50 characters (letters, digits, dot, underscore)",
+	"Password minimal 8 karakter":                              "Password must be at least 8 characters",
+	"Password baru minimal 8 karakter":                         "New password must be at least 8 characters",
+	"Password baru harus berbeda dari password lama":           "New password must differ from the old password",
+	"Password lama salah":                                      "Old password is incorrect",
+	"Password salah":                                           "Incorrect password",
+	"Token wajib diisi":                                        "Token is required",
+	"token tidak valid atau kedaluwarsa":                       "token is invalid or expired",
+	"Link reset password tidak valid atau sudah kedaluwarsa":   "Password reset link is invalid or has expired",
+	"Link verifikasi tidak valid atau sudah kedaluwarsa":       "Verification link is invalid or has expired",
+	"Izin usaha harus berupa JPG, PNG atau PDF":                "Business permit must be a JPG, PNG or PDF",
+	"Gagal menyimpan file izin usaha":                          "Failed to save business permit file",
+	"Gagal menyimpan izin usaha":                               "Failed to save business permit",
+
+	// Akun & profil
+	"Akun tidak ditemukan":                                    "Account not found",
+	"akun tidak ditemukan":                                    "account not found",
+	"ID akun tidak valid":                                     "Invalid account ID",
+	"Gagal mengambil daftar akun":                             "Failed to fetch accounts",
+	"Gagal memproses akun":                                    "Failed to process account",
+	"Gagal menghapus akun":                                    "Failed to delete account",
+	"Tidak bisa %s akun sendiri":                              "Cannot %s your own account",
+	"Alasan penangguhan wajib diisi":                          "Suspension reason is required",
+	"Alasan penangguhan maksimal 500 karakter":                "Suspension reason must be at most 500 characters",
+	"Email akun sudah dipakai akun lain dengan role tersebut": "The account email is already used by another account with that role",
+	"role harus admin, cafe atau customer":                    "role must be admin, cafe or customer",
+	"status harus active, unverified, suspended atau deleted": "status must be active, unverified, suspended or deleted",
+	"super admin aktif terakhir tidak boleh ditangguhkan, dihapus atau diturunkan": "the last active super admin cannot be suspended, deleted or demoted",
+	"akun sudah ditangguhkan":                       "account is already suspended",
+	"akun tidak sedang ditangguhkan":                "account is not suspended",
+	"akun sudah dihapus":                            "account is already deleted",
+	"akun tidak dalam status terhapus":              "account is not deleted",
+	"Password wajib diisi untuk menghapus akun":     "Password is required to delete the account",
+	"Nama tampilan 2-100 karakter":                  "Display name must be 2-100 characters",
+	"Avatar harus berupa gambar JPG, PNG atau WEBP": "Avatar must be a JPG, PNG or WEBP image",
+	"Gagal menyimpan avatar":                        "Failed to save avatar",
+	"Gagal mengambil profil":                        "Failed to fetch profile",
+	"Gagal menyimpan profil":                        "Failed to save profile",
+
+	// Cafe
+	"Cafe tidak ditemukan":                                          "Cafe not found",
+	"ID cafe tidak valid":                                           "Invalid cafe ID",
+	"cafe_id tidak valid":                                           "cafe_id is invalid",
+	"cafe_id wajib diisi":                                           "cafe_id is required",
+	"Parameter cafe_id wajib diisi":                                 "The cafe_id parameter is required",
+	"Gagal approve cafe":                                            "Failed to approve cafe",
+	"Gagal menolak cafe":                                            "Failed to reject cafe",
+	"Alasan penolakan wajib diisi":                                  "Rejection reason is required",
+	"Alasan penolakan maksimal 500 karakter":                        "Rejection reason must be at most 500 characters",
+	"Gagal mengambil data cafe":                                     "Failed to fetch cafe data",
+	"Profil cafe belum dibuat":                                      "Cafe profile has not been created yet",
+	"Gagal mengambil profil cafe":                                   "Failed to fetch cafe profile",
+	"Gagal menyimpan profil cafe":                                   "Failed to save cafe profile",
+	"Gagal menyimpan lokasi cafe":                                   "Failed to save cafe location",
+	"Nama cafe wajib diisi, maksimal 255 karakter":                  "Cafe name is required, at most 255 characters",
+	"Telepon maksimal 20 karakter":                                  "Phone must be at most 20 characters",
+	"Deskripsi maksimal 2000 karakter":                              "Description must be at most 2000 characters",
+	"Alamat wajib diisi minimal 10 karakter":                        "Address is required, at least 10 characters",
+	"Koordinat tidak valid":                                         "Invalid coordinates",
+	"latitude dan longitude wajib diisi":                            "latitude and longitude are required",
+	"latitude dan longitude harus diisi bersamaan":                  "latitude and longitude must be provided together",
+	"latitude harus di antara -90 dan 90":                           "latitude must be between -90 and 90",
+	"longitude harus di antara -180 dan 180":                        "longitude must be between -180 and 180",
+	"Perubahan koordinat wajib disertai alamat":                     "Coordinate changes must include an address",
+	"Parameter lat dan lng wajib diisi dengan koordinat yang valid": "The lat and lng parameters must be valid coordinates",
+	"radius harus di antara 0 dan %.0f km":                          "radius must be between 0 and %.0f km",
+	"Gagal mencari cafe terdekat":                                   "Failed to search nearby cafes",
+	"open_now harus true atau false":                                "open_now must be true or false",
+	"min_rating harus di antara 0 dan 5":                            "min_rating must be between 0 and 5",
+	"Cafe belum mengatur jam operasional":                           "The cafe has not set its opening hours",
+	"Gagal memeriksa jam operasional":                               "Failed to check opening hours",
+
+	// Permintaan perubahan & diskon
+	"ID permintaan tidak valid":                    "Invalid request ID",
+	"permintaan perubahan tidak ditemukan":         "change request not found",
+	"permintaan perubahan sudah diproses":          "change request has already been processed",
+	"Gagal mengajukan perubahan":                   "Failed to submit change",
+	"Gagal mengambil permintaan perubahan":         "Failed to fetch change requests",
+	"Gagal memproses permintaan perubahan":         "Failed to process change request",
+	"status harus pending, approved atau rejected": "status must be pending, approved or rejected",
+	"discount harus 0-100":                         "discount must be 0-100",
+	"Format start_date harus YYYY-MM-DD":           "start_date must be formatted YYYY-MM-DD",
+	"Format end_date harus YYYY-MM-DD":             "end_date must be formatted YYYY-MM-DD",
+	"end_date tidak boleh sebelum start_date":      "end_date must not be before start_date",
+	"Gagal menyimpan diskon":                       "Failed to save discount",
+
+	// Menu & pesanan
+	"Menu tidak ditemukan":                                    "Menu not found",
+	"Gagal mengambil menu":                                    "Failed to fetch menu",
+	"Gagal menyimpan menu":                                    "Failed to save menu",
+	"premium_only wajib diisi":                                "premium_only is required",
+	"Varian tidak ditemukan":                                  "Variant not found",
+	"ID varian tidak valid":                                   "Invalid variant ID",
+	"Nama varian 1-100 karakter":                              "Variant name must be 1-100 characters",
+	"Harga menu dengan varian tidak boleh negatif":            "Menu price with the variant must not be negative",
+	"price_delta tidak valid":                                 "price_delta is invalid",
+	"Gagal mengambil varian menu":                             "Failed to fetch menu variants",
+	"Gagal menyimpan varian menu":                             "Failed to save menu variant",
+	"Gagal menghapus varian menu":                             "Failed to delete menu variant",
+	"Pesanan tidak ditemukan":                                 "Order not found",
+	"pesanan tidak ditemukan":                                 "order not found",
+	"ID pesanan tidak valid":                                  "Invalid order ID",
+	"Keranjang masih kosong":                                  "The cart is empty",
+	"Maksimal %d item per pesanan":                            "At most %d items per order",
+	"Jumlah per item harus 1-%d":                              "Quantity per item must be 1-%d",
+	"Catatan item maksimal 200 karakter":                      "Item note must be at most 200 characters",
+	"Catatan maksimal 500 karakter":                           "Note must be at most 500 characters",
+	"Nomor meja wajib diisi (maks 20 karakter)":               "Table number is required (max 20 characters)",
+	"Nama pengambil wajib diisi (2-100 karakter)":             "Pickup name is required (2-100 characters)",
+	"order_type harus dine_in atau pickup":                    "order_type must be dine_in or pickup",
+	"menu_id %q tidak valid":                                  "menu_id %q is invalid",
+	"Menu %s tidak ditemukan di cafe ini":                     "Menu %s was not found at this cafe",
+	"Menu %s sedang tidak tersedia":                           "Menu %s is currently unavailable",
+	"Varian tidak ditemukan untuk menu %s":                    "Variant not found for menu %s",
+	"Varian %s untuk menu %s sedang tidak tersedia":           "Variant %s for menu %s is currently unavailable",
+	"Gagal menghitung harga pesanan":                          "Failed to price the order",
+	"Gagal membuat pesanan":                                   "Failed to create order",
+	"Gagal mengambil pesanan":                                 "Failed to fetch orders",
+	"Gagal mengubah status pesanan":                           "Failed to update order status",
+	"Pesanan yang sudah diproses tidak bisa dibatalkan":       "Orders that are already being processed cannot be cancelled",
+	"Alasan pembatalan wajib diisi":                           "Cancellation reason is required",
+	"status harus preparing, ready, completed atau cancelled": "status must be preparing, ready, completed or cancelled",
+	"status tidak dikenal: %s":                                "unknown status: %s",
+	"Status pesanan tidak bisa diubah dari %s ke %s":          "Order status cannot change from %s to %s",
+
+	// Pembayaran
+	"Pembayaran tidak ditemukan":                          "Payment not found",
+	"pembayaran tidak ditemukan":                          "payment not found",
+	"ID pembayaran tidak valid":                           "Invalid payment ID",
+	"method harus qris, ewallet atau bank_transfer":       "method must be qris, ewallet or bank_transfer",
+	"pesanan tidak bisa dibayar":                          "order cannot be paid",
+	"pesanan sudah dibayar":                               "order is already paid",
+	"pembayaran pesanan sedang dibuat, coba lagi":         "the order payment is being created, try again",
+	"langganan tidak sedang menunggu pembayaran":          "the subscription is not awaiting payment",
+	"hanya pembayaran berstatus paid yang bisa di-refund": "only payments with status paid can be refunded",
+	"signature webhook tidak valid":                       "invalid webhook signature",
+	"Webhook tidak valid":                                 "Invalid webhook",
+	"Provider tidak dikenal":                              "Unknown provider",
+	"Gagal membuat pembayaran":                            "Failed to create payment",
+	"Gagal mengambil pembayaran":                          "Failed to fetch payment",
+	"Gagal melakukan refund":                              "Failed to refund",
+	"Gagal memproses webhook":                             "Failed to process webhook",
+	"Refund hanya bisa dilakukan oleh cafe":               "Only the cafe can issue refunds",
+	"status harus paid, failed atau expired":              "status must be paid, failed or expired",
+	"Simulasi hanya tersedia untuk PAYMENT_PROVIDER=mock": "Simulation is only available with PAYMENT_PROVIDER=mock",
+
+	// Reservasi
+	"Reservasi tidak ditemukan":                                             "Reservation not found",
+	"reservasi tidak ditemukan":                                             "reservation not found",
+	"ID reservasi tidak valid":                                              "Invalid reservation ID",
+	"Meja tidak ditemukan":                                                  "Table not found",
+	"meja tidak ditemukan":                                                  "table not found",
+	"ID meja tidak valid":                                                   "Invalid table ID",
+	"Label meja wajib diisi (maks 50 karakter)":                             "Table label is required (max 50 characters)",
+	"Label meja sudah dipakai":                                              "Table label is already in use",
+	"Kapasitas meja harus 1-%d":                                             "Table capacity must be 1-%d",
+	"meja masih memiliki reservasi, nonaktifkan saja":                       "the table still has reservations, deactivate it instead",
+	"tidak ada meja tersedia untuk waktu tersebut":                          "no table is available at that time",
+	"status reservasi sudah berubah, muat ulang data":                       "the reservation status has changed, reload the data",
+	"Tanggal (YYYY-MM-DD) dan jam (HH:MM) wajib diisi":                      "Date (YYYY-MM-DD) and time (HH:MM) are required",
+	"Durasi reservasi %d-%d menit":                                          "Reservation duration must be %d-%d minutes",
+	"Jumlah orang harus 1-%d":                                               "Party size must be 1-%d",
+	"area harus indoor, outdoor atau smoking_area":                          "area must be indoor, outdoor or smoking_area",
+	"Waktu reservasi harus di masa depan":                                   "Reservation time must be in the future",
+	"Reservasi maksimal %d hari ke depan":                                   "Reservations can be made at most %d days ahead",
+	"Waktu reservasi di luar jam operasional cafe":                          "Reservation time is outside the cafe's opening hours",
+	"Reservasi berstatus %s tidak bisa dibatalkan":                          "Reservations with status %s cannot be cancelled",
+	"Reservasi yang sudah dimulai tidak bisa dibatalkan":                    "Reservations that have already started cannot be cancelled",
+	"Reservasi belum dimulai":                                               "The reservation has not started yet",
+	"Hanya reservasi pending yang bisa ditolak":                             "Only pending reservations can be rejected",
+	"Hanya reservasi pending yang belum kedaluwarsa yang bisa dikonfirmasi": "Only pending reservations that have not expired can be confirmed",
+	"Hanya reservasi confirmed yang bisa ditandai tidak datang":             "Only confirmed reservations can be marked as no-show",
+	"Format date harus YYYY-MM-DD":                                          "date must be formatted YYYY-MM-DD",
+	"Gagal memeriksa ketersediaan":                                          "Failed to check availability",
+	"Gagal membuat reservasi":                                               "Failed to create reservation",
+	"Gagal mengambil reservasi":                                             "Failed to fetch reservations",
+	"Gagal mengubah status reservasi":                                       "Failed to update reservation status",
+	"Gagal mengambil data meja":                                             "Failed to fetch tables",
+	"Gagal menyimpan meja":                                                  "Failed to save table",
+	"Gagal menghapus meja":                                                  "Failed to delete table",
+
+	// Langganan
+	"ID paket tidak valid":                                                     "Invalid plan ID",
+	"paket langganan tidak ditemukan":                                          "subscription plan not found",
+	"tidak ada langganan premium yang aktif":                                   "there is no active premium subscription",
+	"akun ini sudah berlangganan premium":                                      "this account is already subscribed to premium",
+	"hanya langganan yang dibatalkan dan belum berakhir yang bisa dilanjutkan": "only cancelled subscriptions that have not ended can be resumed",
+	"plan_code wajib diisi":                                                    "plan_code is required",
+	"Nama paket wajib diisi":                                                   "Plan name is required",
+	"Harga paket tidak boleh negatif":                                          "Plan price must not be negative",
+	"Kode paket sudah dipakai":                                                 "Plan code is already in use",
+	"code hanya boleh huruf kecil, angka dan _ (3-50 karakter)":                "code may only contain lowercase letters, digits and _ (3-50 characters)",
+	"billing_period harus monthly atau yearly":                                 "billing_period must be monthly or yearly",
+	"Gagal mengambil paket langganan":                                          "Failed to fetch subscription plans",
+	"Gagal menyimpan paket langganan":                                          "Failed to save subscription plan",
+	"Gagal mengambil langganan":                                                "Failed to fetch subscription",
+	"Gagal memulai langganan":                                                  "Failed to start subscription",
+	"Gagal membatalkan langganan":                                              "Failed to cancel subscription",
+	"Gagal melanjutkan langganan":                                              "Failed to resume subscription",
+
+	// Loyalty
+	"ID hadiah tidak valid":                                  "Invalid reward ID",
+	"hadiah tidak ditemukan":                                 "reward not found",
+	"poin tidak cukup":                                       "not enough points",
+	"stamp belum cukup":                                      "not enough stamps",
+	"cafe ini belum mengaktifkan program loyalty":            "this cafe has not enabled its loyalty program",
+	"cafe ini tidak memakai kartu stamp":                     "this cafe does not use a stamp card",
+	"reward_id wajib diisi":                                  "reward_id is required",
+	"Nama hadiah wajib diisi (maks 255 karakter)":            "Reward name is required (max 255 characters)",
+	"points_cost harus lebih dari 0":                         "points_cost must be greater than 0",
+	"points_per_unit harus 0-1000":                           "points_per_unit must be 0-1000",
+	"amount_unit minimal 1000":                               "amount_unit must be at least 1000",
+	"point_expiry_days harus 0-3650 (0 = tidak kedaluwarsa)": "point_expiry_days must be 0-3650 (0 = never expires)",
+	"stamps_required harus 1-50":                             "stamps_required must be 1-50",
+	"stamp_reward wajib diisi jika kartu stamp aktif":        "stamp_reward is required when the stamp card is enabled",
+	"stamp_reward maksimal 255 karakter":                     "stamp_reward must be at most 255 characters",
+	"Gagal mengambil pengaturan loyalty":                     "Failed to fetch loyalty settings",
+	"Gagal menyimpan pengaturan loyalty":                     "Failed to save loyalty settings",
+	"Gagal mengambil saldo poin":                             "Failed to fetch point balance",
+	"Gagal mengambil riwayat poin":                           "Failed to fetch point history",
+	"Gagal mengambil riwayat":                                "Failed to fetch history",
+	"Gagal mengambil hadiah":                                 "Failed to fetch rewards",
+	"Gagal menyimpan hadiah":                                 "Failed to save reward",
+	"Gagal menukar poin":                                     "Failed to redeem points",
+
+	// Notifikasi
+	"Notifikasi tidak ditemukan":            "Notification not found",
+	"ID notifikasi tidak valid":             "Invalid notification ID",
+	"unread harus true atau false":          "unread must be true or false",
+	"event_type %q tidak dikenal":           "unknown event_type %q",
+	"channel %q tidak dikenal":              "unknown channel %q",
+	"Gagal mengambil notifikasi":            "Failed to fetch notifications",
+	"Gagal menandai notifikasi":             "Failed to mark notification",
+	"Gagal mengambil pengaturan notifikasi": "Failed to fetch notification settings",
+	"Gagal menyimpan pengaturan notifikasi": "Failed to save notification settings",
+
+	// Chat
+	"Percakapan tidak ditemukan":                             "Conversation not found",
+	"percakapan tidak ditemukan":                             "conversation not found",
+	"ID percakapan tidak valid":                              "Invalid conversation ID",
+	"Akun cafe hanya bisa mengobrol dengan super admin":      "Cafe accounts can only chat with the super admin",
+	"Peserta chat harus akun admin atau cafe yang terdaftar": "Chat participants must be registered admin or cafe accounts",
+	"Subjek wajib diisi, maksimal 200 karakter":              "Subject is required, at most 200 characters",
+	"Pesan tidak boleh kosong":                               "Message must not be empty",
+	"Pesan maksimal %d karakter":                             "Message must be at most %d characters",
+	"Maksimal 20 peserta":                                    "At most 20 participants",
+	"message_id wajib diisi":                                 "message_id is required",
+	"Streaming tidak didukung, gunakan polling":              "Streaming is not supported, use polling",
+	"Gagal membuat percakapan":                               "Failed to create conversation",
+	"Gagal mengambil percakapan":                             "Failed to fetch conversations",
+	"Gagal mengambil pesan":                                  "Failed to fetch messages",
+	"Gagal mengirim pesan":                                   "Failed to send message",
+	"Gagal menandai pesan":                                   "Failed to mark messages",
+	"Gagal mengambil jumlah pesan":                           "Failed to fetch message count",
+
+	// Log aktivitas, laporan & pencarian
+	"ID log tidak valid":                              "Invalid log ID",
+	"actor_id tidak valid":                            "actor_id is invalid",
+	"user_id tidak valid":                             "user_id is invalid",
+	"entity_type tidak valid":                         "entity_type is invalid",
+	"entity_id maksimal 64 karakter":                  "entity_id must be at most 64 characters",
+	"entity_type dan entity_id harus diisi bersamaan": "entity_type and entity_id must be provided together",
+	"acknowledged harus true atau false":              "acknowledged must be true or false",
+	"Maksimal %d id per permintaan":                   "At most %d ids per request",
+	"Format from harus YYYY-MM-DD":                    "from must be formatted YYYY-MM-DD",
+	"Format to harus YYYY-MM-DD":                      "to must be formatted YYYY-MM-DD",
+	"Gagal mengambil log aktivitas":                   "Failed to fetch activity logs",
+	"Gagal mengonfirmasi log aktivitas":               "Failed to acknowledge activity logs",
+	"days harus di antara 1 dan %d":                   "days must be between 1 and %d",
+	"granularity harus day, week atau month":          "granularity must be day, week or month",
+	"Format start harus YYYY-MM-DD":                   "start must be formatted YYYY-MM-DD",
+	"Format end harus YYYY-MM-DD":                     "end must be formatted YYYY-MM-DD",
+	"Tanggal akhir tidak boleh sebelum tanggal mulai": "End date must not be before start date",
+	"Rentang laporan harian maksimal %d hari":         "Daily reports can span at most %d days",
+	"Rentang laporan maksimal %d hari":                "Reports can span at most %d days",
+	"format harus pdf atau xlsx":                      "format must be pdf or xlsx",
+	"Gagal mengambil statistik dashboard":             "Failed to fetch dashboard statistics",
+	"Gagal membuat laporan penjualan":                 "Failed to build sales report",
+	"Gagal membuat file laporan":                      "Failed to build report file",
+	"Parameter q wajib diisi":                         "The q parameter is required",
+	"Parameter q maksimal %d karakter":                "The q parameter must be at most %d characters",
+	"type harus cafe, menu atau all":                  "type must be cafe, menu or all",
+	"Gagal melakukan pencarian":                       "Search failed",
+
+	// Menu, ulasan, promo & detail profil cafe
+	"status harus Aktif atau Nonaktif":            "status must be Aktif or Nonaktif",
+	"Gagal menghapus menu":                        "Failed to delete menu",
+	"File harus berupa gambar JPG, PNG atau WEBP": "File must be a JPG, PNG or WEBP image",
+	"Gagal menyimpan gambar":                      "Failed to save image",
+	"Ulasan tidak ditemukan":                      "Review not found",
+	"status harus pending, approved atau hidden":  "status must be pending, approved or hidden",
+	"rating harus di antara 1 dan 5":              "rating must be between 1 and 5",
+	"Gagal mengambil ulasan":                      "Failed to fetch reviews",
+	"Gagal menyimpan ulasan":                      "Failed to save review",
+	"Gagal mengambil statistik ulasan":            "Failed to fetch review statistics",
+	"Promo tidak ditemukan":                       "Promo not found",
+	"Gagal mengambil promo":                       "Failed to fetch promos",
+	"Gagal mengambil statistik promo":             "Failed to fetch promo statistics",
+	"Maksimal %d media sosial":                    "At most %d social media accounts",
+	"Gagal mengambil media sosial":                "Failed to fetch social media",
+	"Gagal menyimpan media sosial":                "Failed to save social media",
+	"Gagal menghapus media sosial":                "Failed to delete social media",
+	"Hari %s dikirim lebih dari sekali":           "Day %s was sent more than once",
+	"Gagal mengambil jam operasional":             "Failed to fetch operating hours",
+	"Gagal menyimpan jam operasional":             "Failed to save operating hours",
+	"Gagal mengambil fasilitas":                   "Failed to fetch facilities",
+	"Gagal menyimpan fasilitas":                   "Failed to save facilities",
+	"Maksimal %d foto galeri":                     "At most %d gallery photos",
+	"Foto galeri tidak ditemukan":                 "Gallery photo not found",
+	"Gagal mengambil galeri":                      "Failed to fetch gallery",
+	"Gagal menyimpan foto galeri":                 "Failed to save gallery photo",
+	"Gagal menghapus foto galeri":                 "Failed to delete gallery photo",
+	"Gagal menyimpan foto utama":                  "Failed to save main photo",
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"backend/logging"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// JSON menulis v sebagai response JSON dengan status
+func JSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("JSON encode error", "err", err)
+	}
+}
+
+// Fail menulis error dengan status & pesan (Bahasa Indonesia)
+func Fail(w http.ResponseWriter, r *http.Request, status int, message string) {
+	WriteError(w, r, New(status, message))
+}
+
+// Failf seperti Fail dengan format pesan
+func Failf(w http.ResponseWriter, r *http.Request, status int, format string, args ...interface{}) {
+	WriteError(w, r, Newf(status, format, args...))
+}
+
+// InvalidBody menulis error 400 untuk body JSON/form yang tidak bisa dibaca
+func InvalidBody(w http.ResponseWriter, r *http.Request) {
+	WriteError(w, r, New(http.StatusBadRequest, "Body request tidak valid").WithCode(CodeInvalidBody))
+}
+
+// errorBody adalah bentuk JSON semua response error
+type errorBody struct {
+	Error     string       `json:"error"`
+	Code      string       `json:"code"`
+	Fields    []FieldError `json:"fields,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// WriteError menulis err sebagai response error. Error selain *Error
+// dianggap error internal: detailnya ditulis ke log, client hanya
+// menerima pesan umum.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	var e *Error
+	if !errors.As(err, &e) {
+		slog.ErrorContext(r.Context(), "Unhandled error", "err", err)
+		e = New(http.StatusInternalServerError, "Terjadi kesalahan pada server")
+	} else if e.Err != nil && e.Status >= 500 {
+		slog.ErrorContext(r.Context(), e.Message, "err", e.Err)
+	}
+
+	lang := Language(r)
+	body := errorBody{
+		Error:     translate(lang, e.Message, e.Args),
+		Code:      e.Code,
+		RequestID: logging.RequestID(r.Context()),
+	}
+	for _, f := range e.Fields {
+		f.Message = translate(lang, f.Message, f.Args)
+		body.Fields = append(body.Fields, f)
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(e.Status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// translate menerjemahkan format pesan lalu mengisi argumennya
+func translate(lang, message string, args []interface{}) string {
+	if lang == LangEN {
+		if en, ok := english[message]; ok {
+			message = en
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
@@ -0,0 +1,214 @@
+// Package audit mencatat setiap request yang mengubah data (POST, PUT,
+// PATCH, DELETE) ke log aktivitas super-admin.
+//
+// Middleware membuat collector per request. Handler yang tahu entitas
+// yang diubah memanggil Record dengan data sebelum/sesudah; request
+// berhasil tanpa Record tetap dicatat sebagai entri generik dari route-nya.
+// Request yang gagal (status >= 400) tidak dicatat.
+package audit
+
+import (
+	"backend/logging"
+	"backend/models"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Store menyimpan entri audit (diimplementasikan ActivityRepository)
+type Store interface {
+	Record(ctx context.Context, a models.Activity, now time.Time) error
+}
+
+// Entry adalah perubahan yang dicatat handler. Before/After di-encode
+// sebagai JSON; jangan isi dengan data rahasia seperti password.
+type Entry struct {
+	Action     string
+	EntityType string
+	EntityID   string
+	Title      string
+	Subject    string
+	Claim      string
+	Before     interface{}
+	After      interface{}
+}
+
+type contextKey string
+
+const collectorKey contextKey = "audit"
+
+type collector struct {
+	user    *models.SessionUser
+	entries []Entry
+	skip    bool
+}
+
+func fromContext(r *http.Request) *collector {
+	c, _ := r.Context().Value(collectorKey).(*collector)
+	return c
+}
+
+// SetActor mencatat user yang melakukan request. Dipanggil oleh
+// middleware Auth, dan oleh Login untuk user yang baru masuk.
+func SetActor(r *http.Request, user *models.SessionUser) {
+	if c := fromContext(r); c != nil {
+		c.user = user
+	}
+}
+
+// Record menambahkan entri audit untuk request ini
+func Record(r *http.Request, e Entry) {
+	if c := fromContext(r); c != nil {
+		c.entries = append(c.entries, e)
+	}
+}
+
+// Skip menandai request ini tidak perlu dicatat, mis. saat admin
+// mengonfirmasi log aktivitas itu sendiri
+func Skip(r *http.Request) {
+	if c := fromContext(r); c != nil {
+		c.skip = true
+	}
+}
+
+// statusWriter menyimpan status code yang ditulis handler
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush diteruskan supaya streaming response tetap jalan
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func mutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// Middleware mencatat request yang mengubah data setelah handler selesai
+func Middleware(store Store) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !mutating(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			c := &collector{}
+			sw := &statusWriter{ResponseWriter: w}
+			r = r.WithContext(context.WithValue(r.Context(), collectorKey, c))
+			next.ServeHTTP(sw, r)
+
+			if sw.status == 0 {
+				sw.status = http.StatusOK
+			}
+			if sw.status >= 400 || c.skip {
+				return
+			}
+
+			entries := c.entries
+			if len(entries) == 0 {
+				entries = []Entry{genericEntry(r)}
+			}
+			// Entri tetap dicatat walau client sudah memutus koneksi
+			ctx := context.WithoutCancel(r.Context())
+			now := time.Now()
+			for _, e := range entries {
+				if err := store.Record(ctx, activity(r, c.user, e), now); err != nil {
+					slog.ErrorContext(r.Context(), "Audit log error", "err", err)
+				}
+			}
+		})
+	}
+}
+
+// genericEntry membuat entri dari route, mis. "put /cafe/tables/{id}"
+func genericEntry(r *http.Request) Entry {
+	path := r.URL.Path
+	if route := mux.CurrentRoute(r); route != nil {
+		if tpl, err := route.GetPathTemplate(); err == nil {
+			path = tpl
+		}
+	}
+
+	entityType := strings.SplitN(strings.Trim(path, "/"), "/", 2)[0]
+	return Entry{
+		Action:     strings.ToLower(r.Method) + " " + path,
+		EntityType: entityType,
+		EntityID:   mux.Vars(r)["id"],
+		Title:      r.Method + " " + r.URL.Path,
+	}
+}
+
+func activity(r *http.Request, user *models.SessionUser, e Entry) models.Activity {
+	a := models.Activity{
+		Action:     e.Action,
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID,
+		Title:      e.Title,
+		Subject:    e.Subject,
+		Claim:      e.Claim,
+		Before:     encode(e.Before),
+		After:      encode(e.After),
+		IPAddress:  clientIP(r),
+		UserAgent:  r.UserAgent(),
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RequestID:  logging.RequestID(r.Context()),
+	}
+	if user != nil {
+		a.ActorUserID = &user.ID
+		a.ActorRole = user.Role
+		a.ActorName = user.Username
+	}
+	return a
+}
+
+func encode(v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		slog.Error("Audit encode error", "err", err)
+		return nil
+	}
+	return b
+}
+
+// clientIP mengambil IP dari RemoteAddr. Header X-Forwarded-For tidak
+// dipakai karena bisa dipalsukan client.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
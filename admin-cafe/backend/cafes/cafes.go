@@ -0,0 +1,369 @@
+// Package cafes mengelola detail profil cafe di luar data inti yang lewat
+// persetujuan admin: media sosial, jam operasional, fasilitas, galeri dan
+// foto utama. Data inti profil (nama, alamat, telepon, ...) tetap lewat
+// handler permintaan perubahan.
+package cafes
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Batas jumlah item per cafe, sama dengan batas di halaman profil
+const (
+	MaxSocialMedia = 10
+	MaxGallery     = 10
+)
+
+// Nama hari sesuai kolom cafe_operational_hours.hari
+var Days = []string{"minggu", "senin", "selasa", "rabu", "kamis", "jumat", "sabtu"}
+
+var (
+	ErrNotFound     = errors.New("data tidak ditemukan")
+	ErrLimitReached = errors.New("batas jumlah tercapai")
+)
+
+type SocialMedia struct {
+	ID       int    `json:"id"`
+	Platform string `json:"platform" validate:"trim,required,max=50"`
+	URL      string `json:"url" validate:"trim,required,max=500"`
+}
+
+// OperationalHour adalah jam buka satu hari. Jika tutup < buka, cafe buka
+// melewati tengah malam.
+type OperationalHour struct {
+	Hari  string `json:"hari" validate:"trim,required,oneof=minggu senin selasa rabu kamis jumat sabtu"`
+	Buka  string `json:"buka" validate:"clock"`
+	Tutup string `json:"tutup" validate:"clock,nefield=Buka"`
+}
+
+type Facility struct {
+	NamaFasilitas string `json:"nama_fasilitas" validate:"trim,required,max=100"`
+	Tersedia      bool   `json:"tersedia"`
+}
+
+type GalleryImage struct {
+	ID       int    `json:"id"`
+	ImageURL string `json:"image_url"`
+	Urutan   int    `json:"urutan"`
+}
+
+// OpenStatus adalah status buka cafe saat ini
+type OpenStatus struct {
+	Open     bool             `json:"open"`
+	Hari     string           `json:"hari"`
+	Time     string           `json:"time"`
+	Today    *OperationalHour `json:"today"`
+	HasHours bool             `json:"has_hours"`
+}
+
+// Store adalah penyimpanan detail profil cafe; diimplementasikan
+// Service (PostgreSQL)
+type Store interface {
+	SocialMedia(ctx context.Context, cafeID int) ([]SocialMedia, error)
+	AddSocialMedia(ctx context.Context, cafeID int, m SocialMedia) (*SocialMedia, error)
+	DeleteAllSocialMedia(ctx context.Context, cafeID int) (int64, error)
+	OperationalHours(ctx context.Context, cafeID int) ([]OperationalHour, error)
+	ReplaceOperationalHours(ctx context.Context, cafeID int, hours []OperationalHour) error
+	SetOperationalHour(ctx context.Context, cafeID int, h OperationalHour) error
+	Status(ctx context.Context, cafeID int, now time.Time) (*OpenStatus, error)
+	Facilities(ctx context.Context, cafeID int) ([]Facility, error)
+	ReplaceFacilities(ctx context.Context, cafeID int, facilities []Facility) error
+	Gallery(ctx context.Context, cafeID int) ([]GalleryImage, error)
+	AddGalleryImage(ctx context.Context, cafeID int, imageURL string, urutan int) (*GalleryImage, error)
+	DeleteGalleryImage(ctx context.Context, cafeID, id int) (string, error)
+	SetMainImage(ctx context.Context, cafeID int, imageURL string) (string, error)
+}
+
+var _ Store = (*Service)(nil)
+
+type Service struct {
+	db       *sql.DB
+	location *time.Location
+}
+
+func NewService(db *sql.DB, location *time.Location) *Service {
+	return &Service{db: db, location: location}
+}
+
+// =========================
+// Media sosial
+// =========================
+func (s *Service) SocialMedia(ctx context.Context, cafeID int) ([]SocialMedia, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id, platform, url FROM cafe_social_media WHERE cafe_profile_id=$1 ORDER BY id", cafeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []SocialMedia{}
+	for rows.Next() {
+		var m SocialMedia
+		if err := rows.Scan(&m.ID, &m.Platform, &m.URL); err != nil {
+			return nil, err
+		}
+		list = append(list, m)
+	}
+	return list, rows.Err()
+}
+
+// AddSocialMedia menambah satu akun media sosial; ErrLimitReached jika
+// cafe sudah punya MaxSocialMedia akun
+func (s *Service) AddSocialMedia(ctx context.Context, cafeID int, m SocialMedia) (*SocialMedia, error) {
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO cafe_social_media (cafe_profile_id, platform, url)
+		SELECT $1, $2, $3
+		WHERE (SELECT COUNT(*) FROM cafe_social_media WHERE cafe_profile_id=$1) < $4
+		RETURNING id`,
+		cafeID, m.Platform, m.URL, MaxSocialMedia,
+	).Scan(&m.ID)
+	if err == sql.ErrNoRows {
+		return nil, ErrLimitReached
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (s *Service) DeleteAllSocialMedia(ctx context.Context, cafeID int) (int64, error) {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM cafe_social_media WHERE cafe_profile_id=$1", cafeID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// =========================
+// Jam operasional
+// =========================
+func (s *Service) OperationalHours(ctx context.Context, cafeID int) ([]OperationalHour, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT LOWER(hari), to_char(buka, 'HH24:MI'), to_char(tutup, 'HH24:MI')
+		FROM cafe_operational_hours WHERE cafe_profile_id=$1
+		ORDER BY array_position($2::text[], LOWER(hari)::text)`,
+		cafeID, pq.Array(Days),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []OperationalHour{}
+	for rows.Next() {
+		var h OperationalHour
+		if err := rows.Scan(&h.Hari, &h.Buka, &h.Tutup); err != nil {
+			return nil, err
+		}
+		list = append(list, h)
+	}
+	return list, rows.Err()
+}
+
+// ReplaceOperationalHours mengganti seluruh jadwal; hari yang tidak ada
+// di daftar berarti tutup
+func (s *Service) ReplaceOperationalHours(ctx context.Context, cafeID int, hours []OperationalHour) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM cafe_operational_hours WHERE cafe_profile_id=$1", cafeID); err != nil {
+		return err
+	}
+	for _, h := range hours {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO cafe_operational_hours (cafe_profile_id, hari, buka, tutup) VALUES ($1, $2, $3, $4)",
+			cafeID, h.Hari, h.Buka, h.Tutup,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SetOperationalHour mengganti jadwal satu hari saja
+func (s *Service) SetOperationalHour(ctx context.Context, cafeID int, h OperationalHour) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM cafe_operational_hours WHERE cafe_profile_id=$1 AND LOWER(hari)=$2", cafeID, h.Hari); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO cafe_operational_hours (cafe_profile_id, hari, buka, tutup) VALUES ($1, $2, $3, $4)",
+		cafeID, h.Hari, h.Buka, h.Tutup,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// =========================
+// Status buka saat ini
+// =========================
+// Jadwal hari sebelumnya yang melewati tengah malam ikut dihitung, sama
+// seperti filter open_now di pencarian cafe terdekat.
+func (s *Service) Status(ctx context.Context, cafeID int, now time.Time) (*OpenStatus, error) {
+	hours, err := s.OperationalHours(ctx, cafeID)
+	if err != nil {
+		return nil, err
+	}
+	return StatusAt(hours, now.In(s.location)), nil
+}
+
+// StatusAt menghitung status buka dari jadwal hours pada waktu lokal now
+func StatusAt(hours []OperationalHour, now time.Time) *OpenStatus {
+	st := &OpenStatus{
+		Hari:     Days[now.Weekday()],
+		Time:     now.Format("15:04"),
+		HasHours: len(hours) > 0,
+	}
+	yesterday := Days[(now.Weekday()+6)%7]
+
+	for i := range hours {
+		h := hours[i]
+		switch h.Hari {
+		case st.Hari:
+			st.Today = &h
+			if h.Buka < h.Tutup {
+				st.Open = st.Open || (st.Time >= h.Buka && st.Time < h.Tutup)
+			} else {
+				st.Open = st.Open || st.Time >= h.Buka
+			}
+		case yesterday:
+			if h.Tutup < h.Buka && st.Time < h.Tutup {
+				st.Open = true
+			}
+		}
+	}
+	return st
+}
+
+// =========================
+// Fasilitas
+// =========================
+func (s *Service) Facilities(ctx context.Context, cafeID int) ([]Facility, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT nama_fasilitas, COALESCE(tersedia, false) FROM cafe_facilities WHERE cafe_profile_id=$1 ORDER BY id", cafeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Facility{}
+	for rows.Next() {
+		var f Facility
+		if err := rows.Scan(&f.NamaFasilitas, &f.Tersedia); err != nil {
+			return nil, err
+		}
+		list = append(list, f)
+	}
+	return list, rows.Err()
+}
+
+// ReplaceFacilities mengganti seluruh daftar fasilitas cafe
+func (s *Service) ReplaceFacilities(ctx context.Context, cafeID int, facilities []Facility) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM cafe_facilities WHERE cafe_profile_id=$1", cafeID); err != nil {
+		return err
+	}
+	for _, f := range facilities {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO cafe_facilities (cafe_profile_id, nama_fasilitas, tersedia) VALUES ($1, $2, $3)",
+			cafeID, f.NamaFasilitas, f.Tersedia,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// =========================
+// Galeri
+// =========================
+func (s *Service) Gallery(ctx context.Context, cafeID int) ([]GalleryImage, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id, image_url, COALESCE(urutan, 0) FROM cafe_gallery WHERE cafe_profile_id=$1 ORDER BY urutan, id", cafeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []GalleryImage{}
+	for rows.Next() {
+		var g GalleryImage
+		if err := rows.Scan(&g.ID, &g.ImageURL, &g.Urutan); err != nil {
+			return nil, err
+		}
+		list = append(list, g)
+	}
+	return list, rows.Err()
+}
+
+// AddGalleryImage menambah foto galeri; ErrLimitReached jika galeri sudah
+// berisi MaxGallery foto
+func (s *Service) AddGalleryImage(ctx context.Context, cafeID int, imageURL string, urutan int) (*GalleryImage, error) {
+	g := &GalleryImage{ImageURL: imageURL, Urutan: urutan}
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO cafe_gallery (cafe_profile_id, image_url, urutan)
+		SELECT $1, $2, $3
+		WHERE (SELECT COUNT(*) FROM cafe_gallery WHERE cafe_profile_id=$1) < $4
+		RETURNING id`,
+		cafeID, imageURL, urutan, MaxGallery,
+	).Scan(&g.ID)
+	if err == sql.ErrNoRows {
+		return nil, ErrLimitReached
+	}
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// DeleteGalleryImage menghapus foto galeri dan mengembalikan URL-nya
+// supaya file-nya bisa ikut dihapus
+func (s *Service) DeleteGalleryImage(ctx context.Context, cafeID, id int) (string, error) {
+	var url string
+	err := s.db.QueryRowContext(ctx,
+		"DELETE FROM cafe_gallery WHERE id=$1 AND cafe_profile_id=$2 RETURNING image_url", id, cafeID,
+	).Scan(&url)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	return url, err
+}
+
+// =========================
+// Foto utama
+// =========================
+// SetMainImage mengganti foto utama (kosong = hapus) dan mengembalikan URL
+// lama supaya file-nya bisa ikut dihapus
+func (s *Service) SetMainImage(ctx context.Context, cafeID int, imageURL string) (string, error) {
+	var old string
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE cafe_profiles p SET main_image=NULLIF($2, ''), updated_at=CURRENT_TIMESTAMP
+		FROM (SELECT id, COALESCE(main_image, '') AS main_image FROM cafe_profiles WHERE id=$1 FOR UPDATE) old
+		WHERE p.id=old.id
+		RETURNING old.main_image`,
+		cafeID, imageURL,
+	).Scan(&old)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	return old, err
+}
@@ -0,0 +1,75 @@
+// Package chat membagikan event chat ke koneksi stream (SSE) yang sedang
+// terbuka. Hub hanya hidup di memori satu proses server; client yang
+// tidak tersambung (atau server lain) tetap mendapat pesan lewat polling.
+package chat
+
+import (
+	"backend/models"
+	"sync"
+)
+
+// Ukuran buffer per koneksi. Koneksi yang terlalu lambat kehilangan
+// event dan harus menyusul lewat polling.
+const subscriberBuffer = 32
+
+type Hub struct {
+	mu   sync.Mutex
+	subs map[int]map[chan models.ChatEvent]struct{}
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: map[int]map[chan models.ChatEvent]struct{}{}, done: make(chan struct{})}
+}
+
+// Done selesai saat server shutdown; stream yang terbuka harus ditutup
+// supaya shutdown tidak menunggu koneksi SSE yang tidak pernah selesai
+func (h *Hub) Done() <-chan struct{} {
+	return h.done
+}
+
+// Close menandai hub berhenti. Client tersambung ulang (retry SSE) atau
+// menyusul lewat polling.
+func (h *Hub) Close() {
+	h.closeOnce.Do(func() { close(h.done) })
+}
+
+// Subscribe mendaftarkan satu koneksi milik user. Panggil fungsi yang
+// dikembalikan saat koneksi ditutup.
+func (h *Hub) Subscribe(userID int) (<-chan models.ChatEvent, func()) {
+	ch := make(chan models.ChatEvent, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.subs[userID] == nil {
+		h.subs[userID] = map[chan models.ChatEvent]struct{}{}
+	}
+	h.subs[userID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subs[userID], ch)
+		if len(h.subs[userID]) == 0 {
+			delete(h.subs, userID)
+		}
+		h.mu.Unlock()
+	}
+}
+
+// Publish mengirim event ke semua koneksi milik userIDs tanpa menunggu
+// koneksi yang lambat
+func (h *Hub) Publish(userIDs []int, e models.ChatEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, id := range userIDs {
+		for ch := range h.subs[id] {
+			select {
+			case ch <- e:
+			default:
+			}
+		}
+	}
+}
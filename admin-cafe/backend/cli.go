@@ -0,0 +1,67 @@
+package main
+
+import (
+	"backend/config"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// runCommand menjalankan subcommand CLI. Mengembalikan false jika args
+// bukan subcommand sehingga server dijalankan seperti biasa.
+func runCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	switch args[0] {
+	case "createsuperadmin":
+		createSuperAdminCommand(args[1:])
+		return true
+	default:
+		return false
+	}
+}
+
+// createSuperAdminCommand membuat akun super-admin tambahan:
+//
+//	go run . createsuperadmin -username budi -email budi@contoh.com
+//
+// Jika -password kosong, password acak dibuat dan ditampilkan sekali.
+// Akun baru wajib ganti password saat login pertama.
+func createSuperAdminCommand(args []string) {
+	fs := flag.NewFlagSet("createsuperadmin", flag.ExitOnError)
+	username := fs.String("username", "", "username admin baru (wajib)")
+	email := fs.String("email", "", "email admin baru")
+	password := fs.String("password", "", "password sementara (kosong = dibuat acak)")
+	fs.Parse(args)
+
+	if *username == "" {
+		fmt.Fprintln(os.Stderr, "Flag -username wajib diisi")
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	config.ConnectDB()
+
+	pw := *password
+	if pw == "" {
+		var err error
+		pw, err = config.GeneratePassword(16)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Gagal membuat password:", err)
+			os.Exit(1)
+		}
+	}
+
+	if err := config.CreateSuperAdmin(*username, *email, pw); err != nil {
+		fmt.Fprintln(os.Stderr, "Gagal membuat super-admin:", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Super-admin %q dibuat.\n", *username)
+	if *password == "" {
+		fmt.Println("Password sekali pakai:", pw)
+	}
+	fmt.Println("Password wajib diganti saat login pertama.")
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// FrontendURL adalah alamat aplikasi React, dipakai untuk link di email
+var FrontendURL = Getenv("FRONTEND_URL", "http://localhost:5173")
+
+// StatsCacheTTL adalah umur cache statistik dashboard super admin
+var StatsCacheTTL = getDuration("ADMIN_STATS_TTL", time.Minute)
+
+// ShutdownTimeout adalah batas waktu menunggu request & background job
+// yang sedang berjalan saat server dihentikan
+var ShutdownTimeout = getDuration("SHUTDOWN_TIMEOUT", 15*time.Second)
+
+// Location adalah zona waktu cafe (WIB). Jam operasional & kolom
+// TIMESTAMP disimpan dalam waktu lokal ini.
+var Location = loadLocation()
+
+func loadLocation() *time.Location {
+	loc, err := time.LoadLocation(Getenv("APP_TIMEZONE", "Asia/Jakarta"))
+	if err != nil {
+		return time.FixedZone("WIB", 7*60*60)
+	}
+	return loc
+}
+
+// Getenv membaca environment variable dengan nilai default
+func Getenv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// getDuration membaca durasi (mis. "30s", "2m") dari environment
+// variable; nilai kosong atau tidak valid memakai default
+func getDuration(key string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || d < 0 {
+		return fallback
+	}
+	return d
+}
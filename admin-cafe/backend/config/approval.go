@@ -0,0 +1,44 @@
+package config
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// ApprovalFields adalah field profil cafe yang perubahannya harus
+// disetujui super admin. Diatur lewat CAFE_APPROVAL_FIELDS, dipisah koma;
+// "none" mematikan antrean persetujuan.
+var ApprovalFields = loadApprovalFields()
+
+// ApprovalDiscountMin adalah persen diskon menu minimal yang butuh
+// persetujuan (CAFE_APPROVAL_DISCOUNT_MIN), jika field "discount" aktif
+var ApprovalDiscountMin = loadApprovalDiscountMin()
+
+func loadApprovalFields() map[string]bool {
+	fields := map[string]bool{}
+	raw := Getenv("CAFE_APPROVAL_FIELDS", "nama,alamat,izin_usaha,discount")
+	if strings.TrimSpace(raw) == "none" {
+		return fields
+	}
+	for _, f := range strings.Split(raw, ",") {
+		if f = strings.ToLower(strings.TrimSpace(f)); f != "" {
+			fields[f] = true
+		}
+	}
+	return fields
+}
+
+func loadApprovalDiscountMin() float64 {
+	v, err := strconv.ParseFloat(Getenv("CAFE_APPROVAL_DISCOUNT_MIN", "30"), 64)
+	if err != nil || v < 0 || v > 100 {
+		slog.Warn("CAFE_APPROVAL_DISCOUNT_MIN tidak valid, memakai 30")
+		return 30
+	}
+	return v
+}
+
+// RequiresApproval bernilai true jika field butuh persetujuan super admin
+func RequiresApproval(field string) bool {
+	return ApprovalFields[field]
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"backend/passwords"
+	"crypto/rand"
+	"math/big"
+)
+
+// Karakter password acak (tanpa karakter yang mirip seperti 0/O, 1/l)
+const passwordAlphabet = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789!@#$%&*"
+
+// GeneratePassword membuat password acak sepanjang n karakter
+func GeneratePassword(n int) (string, error) {
+	b := make([]byte, n)
+	max := big.NewInt(int64(len(passwordAlphabet)))
+	for i := range b {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = passwordAlphabet[idx.Int64()]
+	}
+	return string(b), nil
+}
+
+// CreateSuperAdmin membuat akun admin baru yang wajib ganti password saat
+// login pertama. Dipakai saat first boot & oleh subcommand createsuperadmin.
+func CreateSuperAdmin(username, email, password string) error {
+	hash, err := passwords.Hash(password)
+	if err != nil {
+		return err
+	}
+	_, err = DB.Exec(`
+		INSERT INTO users (username, password, email, role, verified, email_verified, must_change_password)
+		VALUES ($1, $2, NULLIF($3, ''), 'admin', true, $4, true)`,
+		username, hash, email, email != "",
+	)
+	return err
+}
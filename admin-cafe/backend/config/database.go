@@ -1,12 +1,14 @@
 package config
 
 import (
+    "backend/logging"
     "database/sql"
     "fmt"
     "log"
+    "log/slog"
     "os"
 
-    _ "github.com/lib/pq"
+    "github.com/lib/pq"
 )
 
 var DB *sql.DB
@@ -14,22 +16,35 @@ var DB *sql.DB
 func ConnectDB() {
     // DSN menggunakan database carispot_db
     dsn := "host=localhost port=5432 user=postgres password=111122 dbname=carispot_db sslmode=disable"
-    var err error
-    DB, err = sql.Open("postgres", dsn)
+    connector, err := pq.NewConnector(dsn)
     if err != nil {
         log.Fatal("DB connection error:", err)
     }
+    // Query dengan context request diberi komentar request ID
+    DB = sql.OpenDB(logging.Connector(connector))
 
     err = DB.Ping()
     if err != nil {
         log.Fatal("DB ping error:", err)
     }
 
-    fmt.Println("Database connected")
+    slog.Info("Database connected")
 
     // Pastikan folder uploads ada
     ensureUploadsFolder()
 
+    Migrate()
+
+    // Buat default admin jika belum ada
+    createDefaultAdmin()
+}
+
+// =========================
+// SCHEMA
+// =========================
+// Migrate membuat / melengkapi semua tabel di DB. Aman dijalankan
+// berulang; dipakai juga oleh harness test integrasi.
+func Migrate() {
     // Buat table users jika belum ada
     createTable := `
     CREATE TABLE IF NOT EXISTS users (
@@ -40,20 +55,711 @@ func ConnectDB() {
         role VARCHAR(20) NOT NULL,
         izin_usaha TEXT,
         verified BOOLEAN DEFAULT false,
+        display_name VARCHAR(100),
+        phone VARCHAR(20),
+        avatar TEXT,
+        email_verified BOOLEAN DEFAULT false,
+        must_change_password BOOLEAN DEFAULT false,
         created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
     );
+    ALTER TABLE users ADD COLUMN IF NOT EXISTS display_name VARCHAR(100);
+    ALTER TABLE users ADD COLUMN IF NOT EXISTS phone VARCHAR(20);
+    ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar TEXT;
+    ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified BOOLEAN DEFAULT false;
+    ALTER TABLE users ADD COLUMN IF NOT EXISTS must_change_password BOOLEAN DEFAULT false;
+    -- Penangguhan & hapus lunak oleh super admin
+    ALTER TABLE users ADD COLUMN IF NOT EXISTS suspended_at TIMESTAMP;
+    ALTER TABLE users ADD COLUMN IF NOT EXISTS suspended_reason TEXT;
+    ALTER TABLE users ADD COLUMN IF NOT EXISTS suspended_by INTEGER;
+    ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+    ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_by INTEGER;
+    CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_customer ON users(LOWER(email)) WHERE role='customer';
     `
-    _, err = DB.Exec(createTable)
+    _, err := DB.Exec(createTable)
     if err != nil {
         log.Fatal("Failed to create table:", err)
     }
-    fmt.Println("Table users ready")
+    slog.Info("Table users ready")
 
-    // Buat default admin jika belum ada
-    createDefaultAdmin()
+    // Buat table session login & token email
+    createAuthTables()
+
+    // Buat table profil cafe & ulasan
+    createCafeTables()
+    createUlasanTable()
+    createMenuTable()
+    createOrderTables()
+    createPaymentTables()
+    createReservationTables()
+    createActivityTables()
+    createSubscriptionTables()
+    createLoyaltyTables()
+    createChangeRequestTables()
+    createChatTables()
+    createNotificationTables()
+
+    // Index full-text search untuk cafe & menu
+    setupSearch()
+}
+
+// =========================
+// ORDERS TABLES
+// =========================
+func createOrderTables() {
+    tables := []string{
+        `CREATE TABLE IF NOT EXISTS orders (
+            id SERIAL PRIMARY KEY,
+            cafe_profile_id INTEGER NOT NULL REFERENCES cafe_profiles(id) ON DELETE CASCADE,
+            status VARCHAR(20) NOT NULL DEFAULT 'placed',
+            total DECIMAL(12,2) NOT NULL DEFAULT 0,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            completed_at TIMESTAMP
+        )`,
+        `CREATE INDEX IF NOT EXISTS idx_orders_cafe_completed ON orders(cafe_profile_id, completed_at) WHERE status='completed'`,
+        // Nama & harga menu disimpan (snapshot) supaya laporan tidak berubah saat menu diedit
+        `CREATE TABLE IF NOT EXISTS order_items (
+            id SERIAL PRIMARY KEY,
+            order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+            menu_id UUID REFERENCES menus(id) ON DELETE SET NULL,
+            menu_name VARCHAR(255) NOT NULL,
+            quantity INTEGER NOT NULL CHECK (quantity > 0),
+            unit_price DECIMAL(10,2) NOT NULL,
+            subtotal DECIMAL(12,2) NOT NULL
+        )`,
+        `CREATE INDEX IF NOT EXISTS idx_order_items_order ON order_items(order_id)`,
+
+        // Varian menu (ukuran, level gula, dll) dengan selisih harga
+        `CREATE TABLE IF NOT EXISTS menu_variants (
+            id SERIAL PRIMARY KEY,
+            menu_id UUID NOT NULL REFERENCES menus(id) ON DELETE CASCADE,
+            name VARCHAR(100) NOT NULL,
+            price_delta DECIMAL(10,2) NOT NULL DEFAULT 0,
+            available BOOLEAN NOT NULL DEFAULT true,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        )`,
+        `CREATE INDEX IF NOT EXISTS idx_menu_variants_menu ON menu_variants(menu_id)`,
+
+        // Lifecycle pesanan: placed -> preparing -> ready -> completed / cancelled
+        `ALTER TABLE orders ADD COLUMN IF NOT EXISTS customer_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL`,
+        `ALTER TABLE orders ADD COLUMN IF NOT EXISTS created_by INTEGER REFERENCES users(id) ON DELETE SET NULL`,
+        `ALTER TABLE orders ADD COLUMN IF NOT EXISTS order_type VARCHAR(20) NOT NULL DEFAULT 'dine_in'`,
+        `ALTER TABLE orders ADD COLUMN IF NOT EXISTS table_number VARCHAR(20)`,
+        `ALTER TABLE orders ADD COLUMN IF NOT EXISTS pickup_name VARCHAR(100)`,
+        `ALTER TABLE orders ADD COLUMN IF NOT EXISTS note TEXT`,
+        `ALTER TABLE orders ADD COLUMN IF NOT EXISTS subtotal DECIMAL(12,2) NOT NULL DEFAULT 0`,
+        `ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount_total DECIMAL(12,2) NOT NULL DEFAULT 0`,
+        `ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancel_reason TEXT`,
+        `ALTER TABLE orders ADD COLUMN IF NOT EXISTS preparing_at TIMESTAMP`,
+        `ALTER TABLE orders ADD COLUMN IF NOT EXISTS ready_at TIMESTAMP`,
+        `ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP`,
+        `ALTER TABLE orders ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP`,
+        `CREATE INDEX IF NOT EXISTS idx_orders_cafe_status ON orders(cafe_profile_id, status, created_at)`,
+        `CREATE INDEX IF NOT EXISTS idx_orders_customer ON orders(customer_user_id, created_at)`,
+
+        // Snapshot harga per baris: harga asli, diskon yang berlaku & varian
+        `ALTER TABLE order_items ADD COLUMN IF NOT EXISTS variant_id INTEGER REFERENCES menu_variants(id) ON DELETE SET NULL`,
+        `ALTER TABLE order_items ADD COLUMN IF NOT EXISTS variant_name VARCHAR(100)`,
+        `ALTER TABLE order_items ADD COLUMN IF NOT EXISTS original_price DECIMAL(10,2) NOT NULL DEFAULT 0`,
+        `ALTER TABLE order_items ADD COLUMN IF NOT EXISTS discount_percent DECIMAL(5,2) NOT NULL DEFAULT 0`,
+        `ALTER TABLE order_items ADD COLUMN IF NOT EXISTS discount_amount DECIMAL(12,2) NOT NULL DEFAULT 0`,
+        `ALTER TABLE order_items ADD COLUMN IF NOT EXISTS note TEXT`,
+    }
+
+    for _, table := range tables {
+        _, err := DB.Exec(table)
+        if err != nil {
+            log.Fatal("Failed to create order tables:", err)
+        }
+    }
+    slog.Info("Order tables ready")
+}
+
+// =========================
+// PAYMENTS
+// =========================
+func createPaymentTables() {
+    tables := []string{
+        `ALTER TABLE orders ADD COLUMN IF NOT EXISTS payment_status VARCHAR(20) NOT NULL DEFAULT 'unpaid'`,
+        `CREATE TABLE IF NOT EXISTS payments (
+            id SERIAL PRIMARY KEY,
+            reference_type VARCHAR(20) NOT NULL,
+            reference_id INTEGER NOT NULL,
+            provider VARCHAR(30) NOT NULL,
+            provider_ref VARCHAR(100),
+            method VARCHAR(20) NOT NULL,
+            amount DECIMAL(12,2) NOT NULL,
+            status VARCHAR(20) NOT NULL DEFAULT 'pending',
+            instructions JSONB,
+            failure_reason TEXT,
+            expires_at TIMESTAMP,
+            paid_at TIMESTAMP,
+            refunded_at TIMESTAMP,
+            flagged_at TIMESTAMP,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        )`,
+        `CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_provider_ref ON payments(provider, provider_ref)`,
+        // Hanya boleh ada satu tagihan pending per pesanan
+        `CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_one_pending ON payments(reference_type, reference_id) WHERE status='pending'`,
+        `CREATE INDEX IF NOT EXISTS idx_payments_pending ON payments(created_at) WHERE status='pending'`,
+        // Event webhook yang sudah diterima, untuk menolak pengiriman ulang
+        `CREATE TABLE IF NOT EXISTS payment_events (
+            id SERIAL PRIMARY KEY,
+            provider VARCHAR(30) NOT NULL,
+            event_id VARCHAR(100) NOT NULL,
+            provider_ref VARCHAR(100),
+            status VARCHAR(20),
+            payload JSONB,
+            received_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            UNIQUE (provider, event_id)
+        )`,
+    }
+
+    for _, table := range tables {
+        _, err := DB.Exec(table)
+        if err != nil {
+            log.Fatal("Failed to create payment tables:", err)
+        }
+    }
+    slog.Info("Payment tables ready")
+}
+
+// =========================
+// RESERVASI MEJA
+// =========================
+func createReservationTables() {
+    tables := []string{
+        // Area memakai kunci fasilitas cafe: indoor, outdoor, smoking_area
+        `CREATE TABLE IF NOT EXISTS cafe_tables (
+            id SERIAL PRIMARY KEY,
+            cafe_profile_id INTEGER NOT NULL REFERENCES cafe_profiles(id) ON DELETE CASCADE,
+            label VARCHAR(50) NOT NULL,
+            area VARCHAR(20) NOT NULL CHECK (area IN ('indoor', 'outdoor', 'smoking_area')),
+            capacity INTEGER NOT NULL CHECK (capacity > 0),
+            active BOOLEAN NOT NULL DEFAULT true,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            UNIQUE (cafe_profile_id, label)
+        )`,
+        `CREATE TABLE IF NOT EXISTS reservations (
+            id SERIAL PRIMARY KEY,
+            cafe_profile_id INTEGER NOT NULL REFERENCES cafe_profiles(id) ON DELETE CASCADE,
+            table_id INTEGER NOT NULL REFERENCES cafe_tables(id) ON DELETE RESTRICT,
+            customer_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+            party_size INTEGER NOT NULL CHECK (party_size > 0),
+            start_at TIMESTAMP NOT NULL,
+            end_at TIMESTAMP NOT NULL CHECK (end_at > start_at),
+            status VARCHAR(20) NOT NULL DEFAULT 'pending',
+            note TEXT,
+            decline_reason TEXT,
+            hold_expires_at TIMESTAMP,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        )`,
+        `CREATE INDEX IF NOT EXISTS idx_reservations_table_time ON reservations(table_id, start_at, end_at)`,
+        `CREATE INDEX IF NOT EXISTS idx_reservations_cafe_start ON reservations(cafe_profile_id, start_at)`,
+        `CREATE INDEX IF NOT EXISTS idx_reservations_customer ON reservations(customer_user_id, start_at)`,
+        `CREATE INDEX IF NOT EXISTS idx_reservations_hold ON reservations(hold_expires_at) WHERE status='pending'`,
+    }
+
+    for _, table := range tables {
+        _, err := DB.Exec(table)
+        if err != nil {
+            log.Fatal("Failed to create reservation tables:", err)
+        }
+    }
+    slog.Info("Reservation tables ready")
 }
 
-// Buat default admin
+// =========================
+// LOG AKTIVITAS
+// =========================
+func createActivityTables() {
+    tables := []string{
+        // Claim mengikuti kartu log aktivitas, mis. "Klaim : Anggota Premium"
+        `CREATE TABLE IF NOT EXISTS activity_logs (
+            id SERIAL PRIMARY KEY,
+            actor_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+            actor_role VARCHAR(20),
+            action VARCHAR(50) NOT NULL,
+            entity_type VARCHAR(30) NOT NULL,
+            entity_id VARCHAR(50) NOT NULL,
+            title TEXT NOT NULL,
+            subject TEXT,
+            claim TEXT,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        )`,
+        `CREATE INDEX IF NOT EXISTS idx_activity_logs_created ON activity_logs(created_at DESC)`,
+        `CREATE INDEX IF NOT EXISTS idx_activity_logs_entity ON activity_logs(entity_type, entity_id)`,
+        // Audit trail: data sebelum/sesudah, asal request & konfirmasi admin
+        `ALTER TABLE activity_logs ADD COLUMN IF NOT EXISTS actor_name VARCHAR(100)`,
+        `ALTER TABLE activity_logs ADD COLUMN IF NOT EXISTS before_data JSONB`,
+        `ALTER TABLE activity_logs ADD COLUMN IF NOT EXISTS after_data JSONB`,
+        `ALTER TABLE activity_logs ADD COLUMN IF NOT EXISTS ip_address VARCHAR(64)`,
+        `ALTER TABLE activity_logs ADD COLUMN IF NOT EXISTS user_agent TEXT`,
+        `ALTER TABLE activity_logs ADD COLUMN IF NOT EXISTS request_method VARCHAR(10)`,
+        `ALTER TABLE activity_logs ADD COLUMN IF NOT EXISTS request_path TEXT`,
+        `ALTER TABLE activity_logs ADD COLUMN IF NOT EXISTS request_id VARCHAR(64)`,
+        `ALTER TABLE activity_logs ADD COLUMN IF NOT EXISTS acknowledged_at TIMESTAMP`,
+        `ALTER TABLE activity_logs ADD COLUMN IF NOT EXISTS acknowledged_by INTEGER REFERENCES users(id) ON DELETE SET NULL`,
+        `CREATE INDEX IF NOT EXISTS idx_activity_logs_unack ON activity_logs(created_at DESC) WHERE acknowledged_at IS NULL`,
+        `CREATE INDEX IF NOT EXISTS idx_activity_logs_actor ON activity_logs(actor_user_id, created_at DESC)`,
+    }
+
+    for _, table := range tables {
+        _, err := DB.Exec(table)
+        if err != nil {
+            log.Fatal("Failed to create activity tables:", err)
+        }
+    }
+    slog.Info("Activity tables ready")
+}
+
+// =========================
+// LANGGANAN PREMIUM
+// =========================
+func createSubscriptionTables() {
+    tables := []string{
+        `CREATE TABLE IF NOT EXISTS subscription_plans (
+            id SERIAL PRIMARY KEY,
+            code VARCHAR(50) UNIQUE NOT NULL,
+            name VARCHAR(100) NOT NULL,
+            price DECIMAL(12,2) NOT NULL CHECK (price >= 0),
+            billing_period VARCHAR(10) NOT NULL CHECK (billing_period IN ('monthly', 'yearly')),
+            active BOOLEAN NOT NULL DEFAULT true,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        )`,
+        `INSERT INTO subscription_plans (code, name, price, billing_period) VALUES
+            ('premium_monthly', 'Premium Bulanan', 29000, 'monthly'),
+            ('premium_yearly', 'Premium Tahunan', 290000, 'yearly')
+        ON CONFLICT (code) DO NOTHING`,
+        // status: pending (menunggu pembayaran pertama), active, cancelled
+        // (tetap berlaku sampai akhir periode), past_due (tagihan
+        // perpanjangan belum dibayar), expired
+        `CREATE TABLE IF NOT EXISTS subscriptions (
+            id SERIAL PRIMARY KEY,
+            user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            plan_id INTEGER NOT NULL REFERENCES subscription_plans(id),
+            status VARCHAR(20) NOT NULL DEFAULT 'pending',
+            current_period_start TIMESTAMP NOT NULL,
+            current_period_end TIMESTAMP NOT NULL,
+            cancelled_at TIMESTAMP,
+            expired_at TIMESTAMP,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        )`,
+        // Satu user hanya boleh punya satu langganan yang belum berakhir
+        `CREATE UNIQUE INDEX IF NOT EXISTS idx_subscriptions_one_live ON subscriptions(user_id) WHERE status IN ('pending', 'active', 'cancelled', 'past_due')`,
+        `CREATE INDEX IF NOT EXISTS idx_subscriptions_due ON subscriptions(current_period_end) WHERE status IN ('active', 'cancelled')`,
+        // Riwayat tagihan per periode
+        `CREATE TABLE IF NOT EXISTS subscription_periods (
+            id SERIAL PRIMARY KEY,
+            subscription_id INTEGER NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+            payment_id INTEGER REFERENCES payments(id),
+            period_start TIMESTAMP NOT NULL,
+            period_end TIMESTAMP NOT NULL,
+            amount DECIMAL(12,2) NOT NULL,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        )`,
+        `CREATE INDEX IF NOT EXISTS idx_subscription_periods_sub ON subscription_periods(subscription_id, period_start)`,
+    }
+
+    for _, table := range tables {
+        _, err := DB.Exec(table)
+        if err != nil {
+            log.Fatal("Failed to create subscription tables:", err)
+        }
+    }
+    slog.Info("Subscription tables ready")
+}
+
+// =========================
+// LOYALTY: POIN & KARTU STAMP
+// =========================
+func createLoyaltyTables() {
+    tables := []string{
+        // Aturan per cafe: points_per_unit poin untuk setiap amount_unit
+        // rupiah, kedaluwarsa setelah point_expiry_days hari (0 = tidak pernah).
+        // Kartu stamp: 1 stamp per pesanan selesai, stamps_required stamp
+        // ditukar dengan stamp_reward (mis. beli 9 gratis ke-10).
+        `CREATE TABLE IF NOT EXISTS loyalty_settings (
+            cafe_profile_id INTEGER PRIMARY KEY REFERENCES cafe_profiles(id) ON DELETE CASCADE,
+            enabled BOOLEAN NOT NULL DEFAULT false,
+            points_per_unit INTEGER NOT NULL DEFAULT 1 CHECK (points_per_unit >= 0),
+            amount_unit DECIMAL(12,2) NOT NULL DEFAULT 10000 CHECK (amount_unit > 0),
+            point_expiry_days INTEGER NOT NULL DEFAULT 365 CHECK (point_expiry_days >= 0),
+            stamp_enabled BOOLEAN NOT NULL DEFAULT false,
+            stamps_required INTEGER NOT NULL DEFAULT 9 CHECK (stamps_required > 0),
+            stamp_reward VARCHAR(255) NOT NULL DEFAULT '1 minuman gratis',
+            updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        )`,
+        `CREATE TABLE IF NOT EXISTS loyalty_rewards (
+            id SERIAL PRIMARY KEY,
+            cafe_profile_id INTEGER NOT NULL REFERENCES cafe_profiles(id) ON DELETE CASCADE,
+            name VARCHAR(255) NOT NULL,
+            points_cost INTEGER NOT NULL CHECK (points_cost > 0),
+            active BOOLEAN NOT NULL DEFAULT true,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        )`,
+        // Ledger append-only: saldo = SUM(points) / SUM(stamps).
+        // type: earn, redeem, stamp_redeem, expire, reverse (poin pesanan
+        // yang di-refund ditarik kembali)
+        `CREATE TABLE IF NOT EXISTS loyalty_transactions (
+            id SERIAL PRIMARY KEY,
+            cafe_profile_id INTEGER NOT NULL REFERENCES cafe_profiles(id) ON DELETE CASCADE,
+            user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            type VARCHAR(20) NOT NULL,
+            points INTEGER NOT NULL DEFAULT 0,
+            stamps INTEGER NOT NULL DEFAULT 0,
+            order_id INTEGER REFERENCES orders(id) ON DELETE SET NULL,
+            reward_id INTEGER REFERENCES loyalty_rewards(id),
+            redeem_code VARCHAR(20),
+            description TEXT,
+            expires_at TIMESTAMP,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        )`,
+        // Satu pesanan hanya menghasilkan poin sekali
+        `CREATE UNIQUE INDEX IF NOT EXISTS idx_loyalty_earn_order ON loyalty_transactions(order_id) WHERE type='earn'`,
+        `CREATE UNIQUE INDEX IF NOT EXISTS idx_loyalty_reverse_order ON loyalty_transactions(order_id) WHERE type='reverse'`,
+        `CREATE INDEX IF NOT EXISTS idx_loyalty_user_cafe ON loyalty_transactions(user_id, cafe_profile_id, created_at)`,
+        `CREATE INDEX IF NOT EXISTS idx_loyalty_cafe_created ON loyalty_transactions(cafe_profile_id, created_at)`,
+        `CREATE INDEX IF NOT EXISTS idx_loyalty_expiring ON loyalty_transactions(expires_at) WHERE type='earn'`,
+        // Mencegah nilai transaksi diubah; koreksi dicatat sebagai transaksi
+        // baru. DELETE tetap boleh supaya cascade hapus akun/cafe berjalan.
+        `CREATE OR REPLACE FUNCTION loyalty_transactions_append_only() RETURNS trigger AS $$
+        BEGIN
+            RAISE EXCEPTION 'loyalty_transactions bersifat append-only';
+        END
+        $$ LANGUAGE plpgsql`,
+        `DROP TRIGGER IF EXISTS trg_loyalty_append_only ON loyalty_transactions`,
+        `CREATE TRIGGER trg_loyalty_append_only
+            BEFORE UPDATE OF type, points, stamps, user_id, cafe_profile_id ON loyalty_transactions
+            FOR EACH ROW EXECUTE FUNCTION loyalty_transactions_append_only()`,
+    }
+
+    for _, table := range tables {
+        _, err := DB.Exec(table)
+        if err != nil {
+            log.Fatal("Failed to create loyalty tables:", err)
+        }
+    }
+    slog.Info("Loyalty tables ready")
+}
+
+// =========================
+// CHANGE REQUEST TABLES
+// =========================
+// Perubahan profil / diskon sensitif disimpan di sini sampai disetujui
+// super admin; data live tidak berubah selama status masih pending.
+func createChangeRequestTables() {
+    tables := []string{
+        `CREATE TABLE IF NOT EXISTS cafe_change_requests (
+            id SERIAL PRIMARY KEY,
+            cafe_profile_id INTEGER NOT NULL REFERENCES cafe_profiles(id) ON DELETE CASCADE,
+            entity_type VARCHAR(30) NOT NULL,
+            entity_id VARCHAR(64) NOT NULL,
+            changes JSONB NOT NULL,
+            previous JSONB NOT NULL DEFAULT '{}',
+            status VARCHAR(20) NOT NULL DEFAULT 'pending',
+            reason TEXT,
+            requested_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+            reviewed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+            reviewed_at TIMESTAMP,
+            created_at TIMESTAMP NOT NULL,
+            updated_at TIMESTAMP NOT NULL
+        )`,
+        // Satu entitas hanya punya satu perubahan pending; pengajuan baru
+        // digabung ke yang lama
+        `CREATE UNIQUE INDEX IF NOT EXISTS idx_change_requests_pending
+            ON cafe_change_requests(entity_type, entity_id) WHERE status='pending'`,
+        `CREATE INDEX IF NOT EXISTS idx_change_requests_cafe ON cafe_change_requests(cafe_profile_id, created_at)`,
+        `CREATE INDEX IF NOT EXISTS idx_change_requests_status ON cafe_change_requests(status, created_at)`,
+    }
+
+    for _, table := range tables {
+        _, err := DB.Exec(table)
+        if err != nil {
+            log.Fatal("Failed to create change request tables:", err)
+        }
+    }
+    slog.Info("Change request tables ready")
+}
+
+// =========================
+// CHAT TABLES
+// =========================
+func createChatTables() {
+    tables := []string{
+        `CREATE TABLE IF NOT EXISTS chat_threads (
+            id SERIAL PRIMARY KEY,
+            subject VARCHAR(200) NOT NULL,
+            entity_type VARCHAR(30),
+            entity_id VARCHAR(64),
+            created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+            created_at TIMESTAMP NOT NULL,
+            last_message_at TIMESTAMP
+        )`,
+        `CREATE INDEX IF NOT EXISTS idx_chat_threads_entity ON chat_threads(entity_type, entity_id)`,
+        `CREATE INDEX IF NOT EXISTS idx_chat_threads_last_message ON chat_threads(last_message_at DESC NULLS LAST)`,
+        // last_read_message_id adalah read receipt per peserta
+        `CREATE TABLE IF NOT EXISTS chat_participants (
+            thread_id INTEGER NOT NULL REFERENCES chat_threads(id) ON DELETE CASCADE,
+            user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            last_read_message_id BIGINT NOT NULL DEFAULT 0,
+            joined_at TIMESTAMP NOT NULL,
+            PRIMARY KEY (thread_id, user_id)
+        )`,
+        `CREATE INDEX IF NOT EXISTS idx_chat_participants_user ON chat_participants(user_id)`,
+        `CREATE TABLE IF NOT EXISTS chat_messages (
+            id BIGSERIAL PRIMARY KEY,
+            thread_id INTEGER NOT NULL REFERENCES chat_threads(id) ON DELETE CASCADE,
+            sender_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+            body TEXT NOT NULL,
+            created_at TIMESTAMP NOT NULL
+        )`,
+        `CREATE INDEX IF NOT EXISTS idx_chat_messages_thread ON chat_messages(thread_id, id)`,
+    }
+
+    for _, table := range tables {
+        _, err := DB.Exec(table)
+        if err != nil {
+            log.Fatal("Failed to create chat tables:", err)
+        }
+    }
+    slog.Info("Chat tables ready")
+}
+
+// =========================
+// NOTIFICATION TABLES
+// =========================
+// Notifikasi ditulis dulu ke outbox (satu baris per channel), lalu
+// dikirim worker dengan retry. Channel in_app berakhir di tabel
+// notifications yang menjadi inbox user.
+func createNotificationTables() {
+    tables := []string{
+        `CREATE TABLE IF NOT EXISTS notification_outbox (
+            id BIGSERIAL PRIMARY KEY,
+            user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            event_type VARCHAR(40) NOT NULL,
+            channel VARCHAR(20) NOT NULL,
+            title VARCHAR(200) NOT NULL,
+            body TEXT NOT NULL,
+            link TEXT,
+            data JSONB NOT NULL DEFAULT '{}',
+            status VARCHAR(20) NOT NULL DEFAULT 'pending',
+            attempts INTEGER NOT NULL DEFAULT 0,
+            next_attempt_at TIMESTAMP NOT NULL,
+            last_error TEXT,
+            created_at TIMESTAMP NOT NULL,
+            sent_at TIMESTAMP
+        )`,
+        `CREATE INDEX IF NOT EXISTS idx_notification_outbox_due
+            ON notification_outbox(next_attempt_at) WHERE status='pending'`,
+        // outbox_id unik supaya retry setelah crash tidak menggandakan inbox
+        `CREATE TABLE IF NOT EXISTS notifications (
+            id BIGSERIAL PRIMARY KEY,
+            user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            outbox_id BIGINT UNIQUE REFERENCES notification_outbox(id) ON DELETE SET NULL,
+            event_type VARCHAR(40) NOT NULL,
+            title VARCHAR(200) NOT NULL,
+            body TEXT NOT NULL,
+            link TEXT,
+            data JSONB NOT NULL DEFAULT '{}',
+            read_at TIMESTAMP,
+            created_at TIMESTAMP NOT NULL
+        )`,
+        `CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id, id DESC)`,
+        `CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL`,
+        `CREATE TABLE IF NOT EXISTS notification_preferences (
+            user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            event_type VARCHAR(40) NOT NULL,
+            channel VARCHAR(20) NOT NULL,
+            enabled BOOLEAN NOT NULL,
+            PRIMARY KEY (user_id, event_type, channel)
+        )`,
+    }
+
+    for _, table := range tables {
+        _, err := DB.Exec(table)
+        if err != nil {
+            log.Fatal("Failed to create notification tables:", err)
+        }
+    }
+    slog.Info("Notification tables ready")
+}
+
+// =========================
+// SESSIONS & AUTH TOKENS
+// =========================
+func createAuthTables() {
+    tables := []string{
+        // Token disimpan dalam bentuk hash SHA-256
+        `CREATE TABLE IF NOT EXISTS sessions (
+            id SERIAL PRIMARY KEY,
+            user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            token_hash CHAR(64) UNIQUE NOT NULL,
+            expires_at TIMESTAMP NOT NULL,
+            revoked_at TIMESTAMP,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        )`,
+        `CREATE INDEX IF NOT EXISTS idx_sessions_user ON sessions(user_id)`,
+        `CREATE TABLE IF NOT EXISTS auth_tokens (
+            id SERIAL PRIMARY KEY,
+            user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            purpose VARCHAR(30) NOT NULL,
+            token_hash CHAR(64) UNIQUE NOT NULL,
+            expires_at TIMESTAMP NOT NULL,
+            used_at TIMESTAMP,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        )`,
+        `CREATE INDEX IF NOT EXISTS idx_auth_tokens_user ON auth_tokens(user_id, purpose)`,
+    }
+
+    for _, table := range tables {
+        _, err := DB.Exec(table)
+        if err != nil {
+            log.Fatal("Failed to create auth tables:", err)
+        }
+    }
+    slog.Info("Auth tables ready")
+}
+
+// =========================
+// CAFE TABLES
+// =========================
+func createCafeTables() {
+    tables := []string{
+        `CREATE TABLE IF NOT EXISTS cafe_profiles (
+            id SERIAL PRIMARY KEY,
+            user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+            nama VARCHAR(255) NOT NULL,
+            alamat TEXT NOT NULL,
+            telepon VARCHAR(20),
+            deskripsi TEXT,
+            main_image VARCHAR(500),
+            latitude DOUBLE PRECISION,
+            longitude DOUBLE PRECISION,
+            verified BOOLEAN DEFAULT false,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        )`,
+        // Tabel lama belum punya kolom user_id & koordinat
+        `ALTER TABLE cafe_profiles ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES users(id) ON DELETE CASCADE`,
+        `ALTER TABLE cafe_profiles ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION`,
+        `ALTER TABLE cafe_profiles ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION`,
+        `CREATE INDEX IF NOT EXISTS idx_cafe_profiles_lat_lng ON cafe_profiles(latitude, longitude)`,
+        `CREATE TABLE IF NOT EXISTS cafe_social_media (
+            id SERIAL PRIMARY KEY,
+            cafe_profile_id INTEGER REFERENCES cafe_profiles(id) ON DELETE CASCADE,
+            platform VARCHAR(50) NOT NULL,
+            url VARCHAR(500) NOT NULL,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        )`,
+        `CREATE TABLE IF NOT EXISTS cafe_operational_hours (
+            id SERIAL PRIMARY KEY,
+            cafe_profile_id INTEGER REFERENCES cafe_profiles(id) ON DELETE CASCADE,
+            hari VARCHAR(10) NOT NULL,
+            buka TIME NOT NULL,
+            tutup TIME NOT NULL,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        )`,
+        `CREATE TABLE IF NOT EXISTS cafe_facilities (
+            id SERIAL PRIMARY KEY,
+            cafe_profile_id INTEGER REFERENCES cafe_profiles(id) ON DELETE CASCADE,
+            nama_fasilitas VARCHAR(100) NOT NULL,
+            tersedia BOOLEAN DEFAULT true,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        )`,
+        `CREATE TABLE IF NOT EXISTS cafe_gallery (
+            id SERIAL PRIMARY KEY,
+            cafe_profile_id INTEGER REFERENCES cafe_profiles(id) ON DELETE CASCADE,
+            image_url VARCHAR(500) NOT NULL,
+            urutan INTEGER DEFAULT 0,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        )`,
+    }
+
+    for _, table := range tables {
+        _, err := DB.Exec(table)
+        if err != nil {
+            log.Fatal("Failed to create cafe tables:", err)
+        }
+    }
+    slog.Info("Cafe tables ready")
+}
+
+// =========================
+// ULASAN TABLE
+// =========================
+func createUlasanTable() {
+    createTable := `
+    CREATE TABLE IF NOT EXISTS ulasan (
+        id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+        cafe_profile_id INTEGER REFERENCES cafe_profiles(id) ON DELETE CASCADE,
+        nama VARCHAR(255) NOT NULL,
+        email VARCHAR(255),
+        rating INTEGER NOT NULL CHECK (rating >= 1 AND rating <= 5),
+        teks TEXT NOT NULL,
+        gambar TEXT,
+        avatar TEXT,
+        user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+        balasan TEXT,
+        status VARCHAR(50) DEFAULT 'pending',
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );
+    ALTER TABLE ulasan ADD COLUMN IF NOT EXISTS cafe_profile_id INTEGER REFERENCES cafe_profiles(id) ON DELETE CASCADE;
+    ALTER TABLE ulasan ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+    CREATE INDEX IF NOT EXISTS idx_ulasan_cafe ON ulasan(cafe_profile_id);
+    CREATE INDEX IF NOT EXISTS idx_ulasan_user ON ulasan(user_id);
+    CREATE INDEX IF NOT EXISTS idx_ulasan_status ON ulasan(status);
+    CREATE INDEX IF NOT EXISTS idx_ulasan_rating ON ulasan(rating);
+    `
+    _, err := DB.Exec(createTable)
+    if err != nil {
+        log.Fatal("Failed to create ulasan table:", err)
+    }
+    slog.Info("Table ulasan ready")
+}
+
+// =========================
+// MENUS TABLE
+// =========================
+func createMenuTable() {
+    createTable := `
+    CREATE TABLE IF NOT EXISTS menus (
+        id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+        cafe_profile_id INTEGER REFERENCES cafe_profiles(id) ON DELETE CASCADE,
+        name VARCHAR(255) NOT NULL,
+        price DECIMAL(10,2) NOT NULL,
+        discount DECIMAL(5,2) DEFAULT 0,
+        discounted_price DECIMAL(10,2) DEFAULT 0,
+        start_date DATE,
+        end_date DATE,
+        category VARCHAR(100) NOT NULL,
+        status VARCHAR(50) DEFAULT 'Aktif',
+        img TEXT,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );
+    ALTER TABLE menus ADD COLUMN IF NOT EXISTS cafe_profile_id INTEGER REFERENCES cafe_profiles(id) ON DELETE CASCADE;
+    -- Diskon menu hanya berlaku untuk anggota premium
+    ALTER TABLE menus ADD COLUMN IF NOT EXISTS premium_only BOOLEAN NOT NULL DEFAULT false;
+
+    CREATE INDEX IF NOT EXISTS idx_menu_cafe ON menus(cafe_profile_id);
+    CREATE INDEX IF NOT EXISTS idx_menu_category ON menus(category);
+    CREATE INDEX IF NOT EXISTS idx_menu_status ON menus(status);
+    `
+    _, err := DB.Exec(createTable)
+    if err != nil {
+        log.Fatal("Failed to create menus table:", err)
+    }
+    slog.Info("Table menus ready")
+}
+
+// Buat default admin saat first boot.
+// Password diambil dari ADMIN_INITIAL_PASSWORD atau dibuat acak, dan
+// wajib diganti saat login pertama.
 func createDefaultAdmin() {
     // Cek apakah admin sudah ada
     var exists bool
@@ -63,18 +769,44 @@ func createDefaultAdmin() {
         log.Fatal("Check admin error:", err)
     }
 
-    if !exists {
-        // Simpan password plain text
-        _, err := DB.Exec(
-            "INSERT INTO users (username, password, role, verified) VALUES ($1,$2,$3,true)",
-            "admin", "admin123", "admin",
+    if exists {
+        // Admin lama yang masih memakai password default (tersimpan plain
+        // text, sebelum password di-hash) wajib ganti password
+        res, err := DB.Exec(
+            "UPDATE users SET must_change_password=true WHERE username='admin' AND password IN ('admin123','1234') AND must_change_password=false",
         )
         if err != nil {
-            log.Fatal("Failed to create default admin:", err)
+            log.Fatal("Check default admin password error:", err)
+        }
+        if n, _ := res.RowsAffected(); n > 0 {
+            slog.Info("Default admin masih memakai password bawaan, wajib diganti saat login berikutnya")
+        } else {
+            slog.Info("Default admin already exists")
+        }
+        return
+    }
+
+    password := os.Getenv("ADMIN_INITIAL_PASSWORD")
+    generated := password == ""
+    if generated {
+        password, err = GeneratePassword(16)
+        if err != nil {
+            log.Fatal("Failed to generate admin password:", err)
         }
-        fmt.Println("Default admin created (username: admin, password: admin123)")
+    }
+
+    err = CreateSuperAdmin("admin", "", password)
+    if err != nil {
+        log.Fatal("Failed to create default admin:", err)
+    }
+
+    if generated {
+        // Password sekali pakai sengaja ditulis langsung ke terminal, bukan
+        // ke log, supaya tidak ikut tersimpan di agregator log
+        fmt.Println("Default admin created (username: admin). Password sekali pakai:", password)
+        fmt.Println("Password ini hanya ditampilkan sekali dan wajib diganti saat login pertama.")
     } else {
-        fmt.Println("Default admin already exists")
+        slog.Info("Default admin created (username: admin) dengan password dari ADMIN_INITIAL_PASSWORD, wajib diganti saat login pertama.")
     }
 }
 
@@ -82,6 +814,6 @@ func createDefaultAdmin() {
 func ensureUploadsFolder() {
     if _, err := os.Stat("./uploads"); os.IsNotExist(err) {
         os.Mkdir("./uploads", os.ModePerm)
-        fmt.Println("Folder uploads dibuat")
+        slog.Info("Folder uploads dibuat")
     }
 }
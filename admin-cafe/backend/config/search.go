@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"log/slog"
+)
+
+// SearchConfig adalah text search config PostgreSQL yang dipakai untuk
+// tsvector & query. "indonesian" (snowball, PostgreSQL 12+) dipakai jika
+// tersedia, selain itu "simple".
+var SearchConfig = "simple"
+
+// TrigramEnabled bernilai true jika extension pg_trgm bisa dipakai untuk
+// pencarian toleran typo.
+var TrigramEnabled bool
+
+// =========================
+// FULL-TEXT SEARCH
+// =========================
+func setupSearch() {
+	var hasIndonesian bool
+	err := DB.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_ts_config WHERE cfgname='indonesian')").Scan(&hasIndonesian)
+	if err != nil {
+		log.Fatal("Check text search config error:", err)
+	}
+	if hasIndonesian {
+		SearchConfig = "indonesian"
+	}
+
+	// pg_trgm butuh hak CREATE di database, jadi boleh gagal
+	if _, err := DB.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm"); err != nil {
+		slog.Warn("pg_trgm tidak tersedia, pencarian typo memakai ILIKE", "err", err)
+	} else {
+		TrigramEnabled = true
+	}
+
+	statements := []string{
+		`ALTER TABLE cafe_profiles ADD COLUMN IF NOT EXISTS search_vector tsvector`,
+		`ALTER TABLE menus ADD COLUMN IF NOT EXISTS search_vector tsvector`,
+
+		fmt.Sprintf(`CREATE OR REPLACE FUNCTION cafe_profiles_search_update() RETURNS trigger AS $$
+		BEGIN
+			NEW.search_vector :=
+				setweight(to_tsvector('%[1]s', COALESCE(NEW.nama, '')), 'A') ||
+				setweight(to_tsvector('%[1]s', COALESCE(NEW.deskripsi, '')), 'B') ||
+				setweight(to_tsvector('%[1]s', COALESCE(NEW.alamat, '')), 'C');
+			RETURN NEW;
+		END
+		$$ LANGUAGE plpgsql`, SearchConfig),
+		`DROP TRIGGER IF EXISTS trg_cafe_profiles_search ON cafe_profiles`,
+		`CREATE TRIGGER trg_cafe_profiles_search
+			BEFORE INSERT OR UPDATE OF nama, deskripsi, alamat ON cafe_profiles
+			FOR EACH ROW EXECUTE FUNCTION cafe_profiles_search_update()`,
+
+		fmt.Sprintf(`CREATE OR REPLACE FUNCTION menus_search_update() RETURNS trigger AS $$
+		BEGIN
+			NEW.search_vector :=
+				setweight(to_tsvector('%[1]s', COALESCE(NEW.name, '')), 'A') ||
+				setweight(to_tsvector('%[1]s', COALESCE(NEW.category, '')), 'B');
+			RETURN NEW;
+		END
+		$$ LANGUAGE plpgsql`, SearchConfig),
+		`DROP TRIGGER IF EXISTS trg_menus_search ON menus`,
+		`CREATE TRIGGER trg_menus_search
+			BEFORE INSERT OR UPDATE OF name, category ON menus
+			FOR EACH ROW EXECUTE FUNCTION menus_search_update()`,
+
+		// Isi search_vector untuk data lama
+		`UPDATE cafe_profiles SET nama = nama WHERE search_vector IS NULL`,
+		`UPDATE menus SET name = name WHERE search_vector IS NULL`,
+
+		`CREATE INDEX IF NOT EXISTS idx_cafe_profiles_search ON cafe_profiles USING GIN(search_vector)`,
+		`CREATE INDEX IF NOT EXISTS idx_menus_search ON menus USING GIN(search_vector)`,
+	}
+
+	if TrigramEnabled {
+		statements = append(statements,
+			`CREATE INDEX IF NOT EXISTS idx_cafe_profiles_nama_trgm ON cafe_profiles USING GIN(nama gin_trgm_ops)`,
+			`CREATE INDEX IF NOT EXISTS idx_menus_name_trgm ON menus USING GIN(name gin_trgm_ops)`,
+		)
+	}
+
+	for _, stmt := range statements {
+		if _, err := DB.Exec(stmt); err != nil {
+			log.Fatal("Failed to setup search index:", err)
+		}
+	}
+	slog.Info("Search index ready", "config", SearchConfig, "trigram", TrigramEnabled)
+}
@@ -0,0 +1,31 @@
+// Package dbtime mengubah waktu dari dan ke kolom TIMESTAMP (tanpa zona
+// waktu). Kolom seperti itu menyimpan jam dinding lokal cafe, jadi nilai
+// dikirim sebagai string waktu lokal dan dibaca ulang dengan zona cafe.
+package dbtime
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Format mengubah t menjadi parameter kolom TIMESTAMP. t harus sudah
+// berada di zona waktu cafe.
+func Format(t time.Time) string {
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// WallClock memasang zona loc pada nilai TIMESTAMP yang dibaca lib/pq
+// sebagai UTC; jam dinding-nya sebenarnya waktu lokal cafe.
+func WallClock(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// WallClockNull seperti WallClock untuk kolom yang boleh NULL
+func WallClockNull(t pq.NullTime, loc *time.Location) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	local := WallClock(t.Time, loc)
+	return &local
+}
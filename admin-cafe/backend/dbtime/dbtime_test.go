@@ -0,0 +1,28 @@
+package dbtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func TestRoundTrip(t *testing.T) {
+	loc := time.FixedZone("WIB", 7*60*60)
+	now := time.Date(2024, 3, 1, 23, 30, 15, 0, loc)
+
+	if got := Format(now); got != "2024-03-01 23:30:15" {
+		t.Fatalf("Format = %q", got)
+	}
+	// lib/pq membaca kolom TIMESTAMP sebagai UTC dengan jam yang sama
+	read := time.Date(2024, 3, 1, 23, 30, 15, 0, time.UTC)
+	if got := WallClock(read, loc); !got.Equal(now) {
+		t.Fatalf("WallClock = %v, mau %v", got, now)
+	}
+	if got := WallClockNull(pq.NullTime{}, loc); got != nil {
+		t.Fatalf("WallClockNull NULL = %v", got)
+	}
+	if got := WallClockNull(pq.NullTime{Time: read, Valid: true}, loc); got == nil || !got.Equal(now) {
+		t.Fatalf("WallClockNull = %v", got)
+	}
+}
@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"backend/api"
+	"backend/audit"
+	"backend/config"
+	"backend/middleware"
+	"backend/models"
+	"backend/repository"
+	"backend/validate"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Role yang bisa diberikan lewat kelola akun
+var accountRoles = map[string]bool{
+	models.RoleAdmin:    true,
+	models.RoleCafe:     true,
+	models.RoleCustomer: true,
+}
+
+var accountStatuses = map[string]bool{
+	models.AccountActive:     true,
+	models.AccountUnverified: true,
+	models.AccountSuspended:  true,
+	models.AccountDeleted:    true,
+}
+
+type AccountHandler struct {
+	accounts repository.AccountStore
+	sessions repository.SessionStore
+}
+
+func NewAccountHandler(accounts repository.AccountStore, sessions repository.SessionStore) *AccountHandler {
+	return &AccountHandler{accounts: accounts, sessions: sessions}
+}
+
+// ==============================
+// Daftar akun (super admin)
+// GET /admin/accounts?role=cafe&status=active|unverified|suspended|deleted&q=budi&from=2025-09-01&to=2025-09-30&limit=50&offset=0
+// ==============================
+func (h *AccountHandler) List(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	limit, offset, invalid := parsePaging(r)
+	if invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+	filter := models.AccountFilter{
+		Role:   params.Get("role"),
+		Status: params.Get("status"),
+		Search: strings.TrimSpace(params.Get("q")),
+		Limit:  limit,
+		Offset: offset,
+	}
+	if filter.Role != "" && !accountRoles[filter.Role] {
+		api.Fail(w, r, http.StatusBadRequest, "role harus admin, cafe atau customer")
+		return
+	}
+	if filter.Status != "" && !accountStatuses[filter.Status] {
+		api.Fail(w, r, http.StatusBadRequest, "status harus active, unverified, suspended atau deleted")
+		return
+	}
+	if v := params.Get("from"); v != "" {
+		d, err := time.ParseInLocation("2006-01-02", v, config.Location)
+		if err != nil {
+			api.Fail(w, r, http.StatusBadRequest, "Format from harus YYYY-MM-DD")
+			return
+		}
+		filter.From = &d
+	}
+	if v := params.Get("to"); v != "" {
+		d, err := time.ParseInLocation("2006-01-02", v, config.Location)
+		if err != nil {
+			api.Fail(w, r, http.StatusBadRequest, "Format to harus YYYY-MM-DD")
+			return
+		}
+		// to inklusif sampai akhir hari
+		d = d.AddDate(0, 0, 1)
+		filter.To = &d
+	}
+
+	list, total, err := h.accounts.List(r.Context(), filter)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in account List", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil daftar akun")
+		return
+	}
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"accounts": list,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+	})
+}
+
+// ==============================
+// Detail akun
+// GET /admin/accounts/{id}
+// ==============================
+func (h *AccountHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := accountID(w, r)
+	if !ok {
+		return
+	}
+	account, err := h.accounts.Get(r.Context(), id)
+	if !accountOK(w, r, err) {
+		return
+	}
+	api.JSON(w, http.StatusOK, account)
+}
+
+// ==============================
+// Tangguhkan / aktifkan kembali akun
+// POST /admin/accounts/{id}/suspend  {"reason": "Laporan penipuan"}
+// POST /admin/accounts/{id}/unsuspend
+// ==============================
+func (h *AccountHandler) Suspend(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.targetOther(w, r, "menangguhkan")
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Reason string `json:"reason" validate:"trim,required,max=500"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	err := h.accounts.Suspend(r.Context(), id, middleware.CurrentUser(r).ID, body.Reason, time.Now())
+	h.respond(w, r, id, err, "Akun ditangguhkan", audit.Entry{
+		Action:  "account.suspended",
+		Title:   "Akun %s ditangguhkan",
+		Subject: body.Reason,
+		Before:  map[string]bool{"suspended": false},
+		After:   map[string]interface{}{"suspended": true, "reason": body.Reason},
+	})
+}
+
+func (h *AccountHandler) Unsuspend(w http.ResponseWriter, r *http.Request) {
+	id, ok := accountID(w, r)
+	if !ok {
+		return
+	}
+	err := h.accounts.Unsuspend(r.Context(), id)
+	h.respond(w, r, id, err, "Penangguhan akun dicabut", audit.Entry{
+		Action: "account.unsuspended",
+		Title:  "Penangguhan akun %s dicabut",
+		Before: map[string]bool{"suspended": true},
+		After:  map[string]bool{"suspended": false},
+	})
+}
+
+// ==============================
+// Paksa logout dari semua perangkat
+// POST /admin/accounts/{id}/logout
+// ==============================
+func (h *AccountHandler) ForceLogout(w http.ResponseWriter, r *http.Request) {
+	id, ok := accountID(w, r)
+	if !ok {
+		return
+	}
+	if _, err := h.accounts.Get(r.Context(), id); !accountOK(w, r, err) {
+		return
+	}
+	err := h.sessions.RevokeAllForUser(r.Context(), id)
+	h.respond(w, r, id, err, "Semua session akun dicabut", audit.Entry{
+		Action: "account.sessions_revoked",
+		Title:  "Akun %s dikeluarkan dari semua perangkat",
+	})
+}
+
+// ==============================
+// Ganti role akun
+// PUT /admin/accounts/{id}/role  {"role": "admin"}
+// ==============================
+func (h *AccountHandler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.targetOther(w, r, "mengganti role")
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Role string `json:"role" validate:"required,oneof=admin cafe customer"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	before, err := h.accounts.Get(r.Context(), id)
+	if !accountOK(w, r, err) {
+		return
+	}
+	if before.Role == body.Role {
+		api.JSON(w, http.StatusOK, map[string]interface{}{"message": "Role tidak berubah", "account": before})
+		return
+	}
+
+	err = h.accounts.ChangeRole(r.Context(), id, body.Role)
+	if repository.IsUniqueViolation(err) {
+		api.Fail(w, r, http.StatusConflict, "Email akun sudah dipakai akun lain dengan role tersebut")
+		return
+	}
+	h.respond(w, r, id, err, "Role akun diganti", audit.Entry{
+		Action: "account.role_changed",
+		Title:  "Role akun %s diganti",
+		Before: map[string]string{"role": before.Role},
+		After:  map[string]string{"role": body.Role},
+	})
+}
+
+// ==============================
+// Hapus lunak & pulihkan akun
+// DELETE /admin/accounts/{id}
+// POST   /admin/accounts/{id}/restore
+// ==============================
+func (h *AccountHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.targetOther(w, r, "menghapus")
+	if !ok {
+		return
+	}
+	err := h.accounts.SoftDelete(r.Context(), id, middleware.CurrentUser(r).ID, time.Now())
+	h.respond(w, r, id, err, "Akun dihapus", audit.Entry{
+		Action: "account.deleted",
+		Title:  "Akun %s dihapus",
+		Before: map[string]bool{"deleted": false},
+		After:  map[string]bool{"deleted": true},
+	})
+}
+
+func (h *AccountHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	id, ok := accountID(w, r)
+	if !ok {
+		return
+	}
+	err := h.accounts.Restore(r.Context(), id)
+	h.respond(w, r, id, err, "Akun dipulihkan", audit.Entry{
+		Action: "account.restored",
+		Title:  "Akun %s dipulihkan",
+		Before: map[string]bool{"deleted": true},
+		After:  map[string]bool{"deleted": false},
+	})
+}
+
+// targetOther membaca id akun dan menolak aksi terhadap akun sendiri
+func (h *AccountHandler) targetOther(w http.ResponseWriter, r *http.Request, action string) (int, bool) {
+	id, ok := accountID(w, r)
+	if !ok {
+		return 0, false
+	}
+	if id == middleware.CurrentUser(r).ID {
+		api.Failf(w, r, http.StatusBadRequest, "Tidak bisa %s akun sendiri", action)
+		return 0, false
+	}
+	return id, true
+}
+
+// respond menulis hasil aksi: error, atau catat log aktivitas lalu kirim
+// data akun terbaru. Title entry berisi %s untuk username akun.
+func (h *AccountHandler) respond(w http.ResponseWriter, r *http.Request, id int, err error, message string, entry audit.Entry) {
+	if !accountOK(w, r, err) {
+		return
+	}
+	account, err := h.accounts.Get(r.Context(), id)
+	if !accountOK(w, r, err) {
+		return
+	}
+
+	entry.EntityType = "user"
+	entry.EntityID = strconv.Itoa(id)
+	entry.Title = fmt.Sprintf(entry.Title, account.Username)
+	audit.Record(r, entry)
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"message": message,
+		"account": account,
+	})
+}
+
+func accountID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.Fail(w, r, http.StatusBadRequest, "ID akun tidak valid")
+		return 0, false
+	}
+	return id, true
+}
+
+// accountOK menulis response error untuk aksi kelola akun
+func accountOK(w http.ResponseWriter, r *http.Request, err error) bool {
+	switch err {
+	case nil:
+		return true
+	case repository.ErrAccountNotFound:
+		api.Fail(w, r, http.StatusNotFound, err.Error())
+	case repository.ErrLastAdmin, repository.ErrAlreadySuspended, repository.ErrNotSuspended,
+		repository.ErrAccountDeleted, repository.ErrNotDeleted:
+		api.Fail(w, r, http.StatusConflict, err.Error())
+	default:
+		slog.ErrorContext(r.Context(), "DB error in account management", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal memproses akun")
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"backend/api"
+	"backend/audit"
+	"backend/config"
+	"backend/middleware"
+	"backend/models"
+	"backend/repository"
+	"backend/validate"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type ActivityHandler struct {
+	activities repository.ActivityStore
+}
+
+func NewActivityHandler(activities repository.ActivityStore) *ActivityHandler {
+	return &ActivityHandler{activities: activities}
+}
+
+// ==============================
+// Daftar log aktivitas (super admin)
+// GET /admin/activity?action=&entity_type=&entity_id=&actor_id=&role=&acknowledged=false&from=2025-09-01&to=2025-09-30&limit=50&offset=0
+// ==============================
+func (h *ActivityHandler) List(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	limit, offset, invalid := parsePaging(r)
+	if invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+	filter := models.ActivityFilter{
+		Action:     params.Get("action"),
+		EntityType: params.Get("entity_type"),
+		EntityID:   params.Get("entity_id"),
+		ActorRole:  params.Get("role"),
+		Limit:      limit,
+		Offset:     offset,
+	}
+
+	if v := params.Get("actor_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			api.Fail(w, r, http.StatusBadRequest, "actor_id tidak valid")
+			return
+		}
+		filter.ActorUserID = id
+	}
+	if v := params.Get("acknowledged"); v != "" {
+		ack, err := strconv.ParseBool(v)
+		if err != nil {
+			api.Fail(w, r, http.StatusBadRequest, "acknowledged harus true atau false")
+			return
+		}
+		filter.Acknowledged = &ack
+	}
+	if v := params.Get("from"); v != "" {
+		d, err := time.ParseInLocation("2006-01-02", v, config.Location)
+		if err != nil {
+			api.Fail(w, r, http.StatusBadRequest, "Format from harus YYYY-MM-DD")
+			return
+		}
+		filter.From = &d
+	}
+	if v := params.Get("to"); v != "" {
+		d, err := time.ParseInLocation("2006-01-02", v, config.Location)
+		if err != nil {
+			api.Fail(w, r, http.StatusBadRequest, "Format to harus YYYY-MM-DD")
+			return
+		}
+		// to inklusif sampai akhir hari
+		d = d.AddDate(0, 0, 1)
+		filter.To = &d
+	}
+
+	list, total, err := h.activities.List(r.Context(), filter)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in activity List", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil log aktivitas")
+		return
+	}
+	unacknowledged, err := h.activities.CountUnacknowledged(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in activity List", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil log aktivitas")
+		return
+	}
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"activities":     list,
+		"total":          total,
+		"unacknowledged": unacknowledged,
+		"limit":          limit,
+		"offset":         offset,
+	})
+}
+
+// ==============================
+// Riwayat satu entitas
+// GET /admin/activity/history/{entityType}/{entityId}
+// ==============================
+func (h *ActivityHandler) History(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	list, err := h.activities.History(r.Context(), vars["entityType"], vars["entityId"])
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in activity History", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil riwayat")
+		return
+	}
+	api.JSON(w, http.StatusOK, map[string]interface{}{"activities": list})
+}
+
+// ==============================
+// Konfirmasi log aktivitas
+// POST /admin/activity/{id}/acknowledge
+// POST /admin/activity/acknowledge  {"ids": [1, 2]}  (ids kosong = semua)
+// ==============================
+func (h *ActivityHandler) Acknowledge(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.Fail(w, r, http.StatusBadRequest, "ID log tidak valid")
+		return
+	}
+	h.acknowledge(w, r, []int{id})
+}
+
+func (h *ActivityHandler) AcknowledgeBulk(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		IDs []int `json:"ids" validate:"max=500"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+	h.acknowledge(w, r, body.IDs)
+}
+
+func (h *ActivityHandler) acknowledge(w http.ResponseWriter, r *http.Request, ids []int) {
+	// Konfirmasi tidak dicatat supaya tidak menambah log baru
+	audit.Skip(r)
+
+	n, err := h.activities.Acknowledge(r.Context(), ids, middleware.CurrentUser(r).ID, time.Now())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in activity Acknowledge", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengonfirmasi log aktivitas")
+		return
+	}
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"message":      "Log aktivitas dikonfirmasi",
+		"acknowledged": n,
+	})
+}
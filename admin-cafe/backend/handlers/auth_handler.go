@@ -1,125 +1,522 @@
 package handlers
 
 import (
+	"backend/api"
+	"backend/audit"
+	"backend/config"
+	"backend/logging"
+	"backend/mailer"
+	"backend/metrics"
+	"backend/middleware"
 	"backend/models"
+	"backend/passwords"
 	"backend/repository"
-	"encoding/json"
+	"backend/validate"
+	"context"
+	"database/sql"
 	"fmt"
-	"io"
+	"log/slog"
 	"net/http"
-	"os"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Masa berlaku session login & token email
+const (
+	sessionTTL       = 7 * 24 * time.Hour
+	verifyEmailTTL   = 24 * time.Hour
+	resetPasswordTTL = 1 * time.Hour
 )
 
 type AuthHandler struct {
-	repo *repository.UserRepository
+	repo     repository.UserStore
+	sessions repository.SessionStore
+	tokens   repository.TokenStore
+	mailer   mailer.Mailer
+}
+
+func NewAuthHandler(repo repository.UserStore, sessions repository.SessionStore, tokens repository.TokenStore, m mailer.Mailer) *AuthHandler {
+	return &AuthHandler{repo: repo, sessions: sessions, tokens: tokens, mailer: m}
 }
 
-func NewAuthHandler(repo *repository.UserRepository) *AuthHandler {
-	return &AuthHandler{repo: repo}
+// errInvalidCredentials dipakai untuk username tidak ada maupun password salah
+func errInvalidCredentials() *api.Error {
+	return api.New(http.StatusUnauthorized, "Username atau password salah").
+		WithCode(api.CodeInvalidCredentials)
 }
 
 // ==============================
-// LOGIN (Plain Text Password)
+// LOGIN
 // ==============================
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
-    var body struct {
-        Username string `json:"username"`
-        Password string `json:"password"`
-        Role     string `json:"role"`
-    }
-
-    // <- letakkan di sini
-    err := json.NewDecoder(r.Body).Decode(&body)
-    if err != nil {
-        fmt.Println("JSON decode error:", err)
-        http.Error(w, "Invalid request body", http.StatusBadRequest)
-        return
-    }
-    fmt.Println("Login attempt:", body.Username, "Role:", body.Role)
-
-    // query DB setelah ini
-    var dbPassword, role, username string
-    err = h.repo.DB.QueryRow(
-        "SELECT username, password, role FROM users WHERE username=$1 AND role=$2",
-        body.Username, body.Role,
-    ).Scan(&username, &dbPassword, &role)
-
-    if err != nil {
-        fmt.Println("DB query error:", err)
-        http.Error(w, "User not found", http.StatusUnauthorized)
-        return
-    }
-
-    if body.Password != dbPassword {
-        fmt.Println("Password mismatch")
-        http.Error(w, "Invalid password", http.StatusUnauthorized)
-        return
-    }
-
-    fmt.Println("Login success:", username, role)
-    json.NewEncoder(w).Encode(map[string]string{
-        "username": username,
-        "role":     role,
-    })
+	var body struct {
+		Username string `json:"username" validate:"trim,required,max=50"`
+		Password string `json:"password" validate:"required"`
+		Role     string `json:"role" validate:"required,oneof=admin cafe customer"`
+	}
+
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	user, err := h.repo.GetLoginUser(r.Context(), body.Username, body.Role)
+
+	// Login gagal dicatat tanpa username supaya log tidak menyimpan
+	// data akun orang lain yang salah ketik. Username tidak ada & password
+	// salah dijawab dengan error & waktu bcrypt yang sama supaya username
+	// yang terdaftar tidak bisa ditebak dari response.
+	if err == sql.ErrNoRows {
+		passwords.VerifyMissing(body.Password)
+		slog.InfoContext(r.Context(), "Login failed", "reason", "user_not_found", "role", body.Role)
+		metrics.LoginFailures.Inc("user_not_found")
+		api.WriteError(w, r, errInvalidCredentials())
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in Login", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal login")
+		return
+	}
+
+	if !passwords.Verify(user.Password, body.Password) {
+		slog.InfoContext(r.Context(), "Login failed", "reason", "password_mismatch", "role", body.Role)
+		metrics.LoginFailures.Inc("password_mismatch")
+		api.WriteError(w, r, errInvalidCredentials())
+		return
+	}
+
+	// Akun yang ditangguhkan super admin tidak bisa login
+	if user.Suspended {
+		metrics.LoginFailures.Inc("suspended")
+		e := api.New(http.StatusForbidden, "Akun kamu ditangguhkan")
+		if user.SuspendedReason != "" {
+			e = api.Newf(http.StatusForbidden, "Akun kamu ditangguhkan: %s", user.SuspendedReason)
+		}
+		api.WriteError(w, r, e.WithCode(api.CodeAccountSuspended))
+		return
+	}
+
+	// Customer wajib verifikasi email sebelum bisa login
+	if user.Role == models.RoleCustomer && !user.EmailVerified {
+		metrics.LoginFailures.Inc("email_unverified")
+		api.WriteError(w, r, api.New(http.StatusForbidden, "Email belum diverifikasi, cek inbox email kamu").
+			WithCode(api.CodeEmailUnverified))
+		return
+	}
+
+	// Akun lama yang masih menyimpan plain text di-hash saat berhasil login
+	if passwords.NeedsRehash(user.Password) {
+		if err := h.rehash(r.Context(), user.ID, body.Password); err != nil {
+			slog.ErrorContext(r.Context(), "Rehash password error", "err", err)
+		}
+	}
+
+	token, expiresAt, err := h.sessions.Create(r.Context(), user.ID, sessionTTL)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Create session error", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal login")
+		return
+	}
+
+	logging.SetUserID(r.Context(), user.ID)
+	slog.InfoContext(r.Context(), "Login success", "role", user.Role)
+	audit.SetActor(r, &models.SessionUser{ID: user.ID, Username: user.Username, Role: user.Role})
+	audit.Record(r, audit.Entry{
+		Action:     "auth.login",
+		EntityType: "user",
+		EntityID:   strconv.Itoa(user.ID),
+		Title:      fmt.Sprintf("USER %s login", user.Username),
+	})
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"id":         user.ID,
+		"username":   user.Username,
+		"role":       user.Role,
+		"token":      token,
+		"expires_at": expiresAt,
+
+		"must_change_password": user.MustChangePassword,
+	})
 }
 
+// rehash menyimpan password dalam bentuk hash bcrypt
+func (h *AuthHandler) rehash(ctx context.Context, userID int, password string) error {
+	hash, err := passwords.Hash(password)
+	if err != nil {
+		return err
+	}
+	return h.repo.UpdatePassword(ctx, userID, hash)
+}
 
 // ==============================
-// REGISTER CAFE (Plain Password)
+// LOGOUT (cabut session saat ini)
 // ==============================
-func (h *AuthHandler) RegisterCafe(w http.ResponseWriter, r *http.Request) {
-	// Parse multipart form
-	err := r.ParseMultipartForm(10 << 20) // 10 MB
-	if err != nil {
-		fmt.Println("Parse form error:", err)
-		http.Error(w, "Invalid form data", http.StatusBadRequest)
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if err := h.sessions.Revoke(r.Context(), middleware.BearerToken(r)); err != nil {
+		slog.ErrorContext(r.Context(), "Revoke session error", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal logout")
 		return
 	}
+	api.JSON(w, http.StatusOK, map[string]string{"message": "Logout berhasil"})
+}
 
-	username := r.FormValue("username")
-	password := r.FormValue("password")
-	email := r.FormValue("email")
+// ==============================
+// REGISTER CAFE
+// ==============================
+func (h *AuthHandler) RegisterCafe(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize+(1<<20))
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		slog.DebugContext(r.Context(), "Parse form error in RegisterCafe", "err", err)
+		api.Fail(w, r, http.StatusBadRequest, "File terlalu besar (maks 10 MB)")
+		return
+	}
 
-	// ===== Simpan file izin usaha =====
-	file, header, err := r.FormFile("izin_usaha")
-	if err != nil {
-		http.Error(w, "File error", http.StatusBadRequest)
+	var form struct {
+		Username string `form:"username" validate:"trim,required,username"`
+		Password string `form:"password" validate:"required,min=8,max=128"`
+		Email    string `form:"email" validate:"trim,required,email,max=255"`
+	}
+	if invalid := validate.Form(r, &form); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+	if _, _, err := r.FormFile("izin_usaha"); err != nil {
+		api.WriteError(w, r, api.Validation(api.FieldError{
+			Field: "izin_usaha", Code: "required", Message: "%s wajib diisi", Args: []interface{}{"izin_usaha"},
+		}))
 		return
 	}
-	defer file.Close()
 
-	filePath := "./uploads/" + header.Filename
-	out, err := os.Create(filePath)
+	hash, err := passwords.Hash(form.Password)
 	if err != nil {
-		http.Error(w, "Gagal menyimpan file", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Hash password error in RegisterCafe", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal registrasi")
 		return
 	}
-	defer out.Close()
 
-	_, err = io.Copy(out, file)
+	// ===== Simpan file izin usaha =====
+	// Nama file acak & isi dicek, sama seperti upload izin usaha dari profil
+	name, err := saveUpload(r, "izin_usaha", documentTypes)
+	if err == errInvalidFileType {
+		api.Fail(w, r, http.StatusBadRequest, "Izin usaha harus berupa JPG, PNG atau PDF")
+		return
+	}
 	if err != nil {
-		http.Error(w, "Gagal menyimpan file", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Upload error in RegisterCafe", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan file")
 		return
 	}
+	filePath := uploadDir + "/" + name
 
-	// PASSWORD DISIMPAN TANPA HASH
 	user := &models.User{
-		Username:  username,
-		Password:  password, // 🔥 plain text
-		Email:     email,
+		Username:  form.Username,
+		Password:  hash,
+		Email:     form.Email,
 		Role:      "cafe",
 		IzinUsaha: filePath,
 		Verified:  false,
 	}
 
-	err = h.repo.CreateCafe(user)
+	err = h.repo.CreateCafe(r.Context(), user)
 	if err != nil {
-		fmt.Println("CreateCafe error:", err)
-		http.Error(w, "Gagal registrasi", http.StatusInternalServerError)
+		removeUpload(filePath)
+		if repository.IsUniqueViolation(err) {
+			api.Fail(w, r, http.StatusConflict, "Username sudah dipakai")
+			return
+		}
+		slog.ErrorContext(r.Context(), "CreateCafe error", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal registrasi")
 		return
 	}
 
-	json.NewEncoder(w).Encode(map[string]string{
+	if err := h.sendVerificationEmail(r, user.ID, form.Username, form.Email); err != nil {
+		slog.ErrorContext(r.Context(), "Send verification email error", "err", err)
+	}
+
+	api.JSON(w, http.StatusOK, map[string]string{
 		"message": "Registrasi sukses! Tunggu approval admin.",
 	})
 }
+
+// ==============================
+// REGISTER CUSTOMER
+// ==============================
+func (h *AuthHandler) RegisterCustomer(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Username    string `json:"username" validate:"trim,required,username"`
+		Password    string `json:"password" validate:"required,min=8,max=128"`
+		Email       string `json:"email" validate:"trim,required,email,max=255"`
+		DisplayName string `json:"display_name" validate:"trim,min=2,max=100"`
+		Phone       string `json:"phone" validate:"trim,phone"`
+	}
+
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+	if body.DisplayName == "" {
+		body.DisplayName = body.Username
+	}
+
+	if exists, err := h.repo.Exists(r.Context(), body.Username); err != nil || exists {
+		if err != nil {
+			slog.ErrorContext(r.Context(), "DB error checking username", "err", err)
+			api.Fail(w, r, http.StatusInternalServerError, "Gagal registrasi")
+			return
+		}
+		api.Fail(w, r, http.StatusConflict, "Username sudah dipakai")
+		return
+	}
+	if exists, err := h.repo.CustomerEmailExists(r.Context(), body.Email); err != nil || exists {
+		if err != nil {
+			slog.ErrorContext(r.Context(), "DB error checking email", "err", err)
+			api.Fail(w, r, http.StatusInternalServerError, "Gagal registrasi")
+			return
+		}
+		api.Fail(w, r, http.StatusConflict, "Email sudah terdaftar")
+		return
+	}
+
+	hash, err := passwords.Hash(body.Password)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Hash password error in RegisterCustomer", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal registrasi")
+		return
+	}
+	user := &models.User{
+		Username: body.Username,
+		Password: hash,
+		Email:    body.Email,
+	}
+	id, err := h.repo.CreateCustomer(r.Context(), user, body.DisplayName, body.Phone)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "CreateCustomer error", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal registrasi")
+		return
+	}
+
+	if err := h.sendVerificationEmail(r, id, body.DisplayName, body.Email); err != nil {
+		slog.ErrorContext(r.Context(), "Send verification email error", "err", err)
+	}
+
+	api.JSON(w, http.StatusCreated, map[string]string{
+		"message": "Registrasi sukses! Cek email untuk verifikasi akun.",
+	})
+}
+
+// sendVerificationEmail membuat token verifikasi baru & mengirim link-nya
+func (h *AuthHandler) sendVerificationEmail(r *http.Request, userID int, name, email string) error {
+	token, err := h.tokens.Create(r.Context(), userID, repository.TokenPurposeVerifyEmail, verifyEmailTTL)
+	if err != nil {
+		return err
+	}
+	link := config.FrontendURL + "/verify-email?token=" + url.QueryEscape(token)
+	return h.sendEmail(r, mailer.TemplateVerifyEmail, email, name, link, verifyEmailTTL)
+}
+
+// sendEmail merender template sesuai bahasa request lalu mengirimnya
+func (h *AuthHandler) sendEmail(r *http.Request, template, to, name, link string, ttl time.Duration) error {
+	lang := mailer.LangFromHeader(r.Header.Get("Accept-Language"))
+	msg, err := mailer.Render(template, lang, to, mailer.TemplateData{
+		Name:      name,
+		Link:      link,
+		ExpiresIn: formatTTL(ttl, lang),
+	})
+	if err != nil {
+		return err
+	}
+	return h.mailer.Send(msg)
+}
+
+// formatTTL menulis durasi dalam jam, misal "24 jam" / "24 hours"
+func formatTTL(d time.Duration, lang string) string {
+	hours := int(d.Hours())
+	if lang == mailer.LangEN {
+		if hours == 1 {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", hours)
+	}
+	return fmt.Sprintf("%d jam", hours)
+}
+
+// ==============================
+// VERIFY EMAIL
+// ==============================
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Token string `json:"token" validate:"trim,required,max=128"`
+	}
+
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	userID, err := h.tokens.Consume(r.Context(), body.Token, repository.TokenPurposeVerifyEmail)
+	if err == repository.ErrTokenInvalid {
+		api.Fail(w, r, http.StatusBadRequest, "Link verifikasi tidak valid atau sudah kedaluwarsa")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error consuming token", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal verifikasi email")
+		return
+	}
+
+	if err := h.repo.MarkEmailVerified(r.Context(), userID); err != nil {
+		slog.ErrorContext(r.Context(), "DB error verifying email", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal verifikasi email")
+		return
+	}
+
+	api.JSON(w, http.StatusOK, map[string]string{"message": "Email berhasil diverifikasi"})
+}
+
+// ==============================
+// FORGOT PASSWORD
+// ==============================
+// Response selalu sama, supaya tidak bisa dipakai untuk menebak email
+// yang terdaftar.
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Email string `json:"email" validate:"trim,required,email"`
+	}
+
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	users, err := h.repo.FindByEmail(r.Context(), body.Email)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in ForgotPassword", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal memproses permintaan")
+		return
+	}
+
+	for _, u := range users {
+		token, err := h.tokens.Create(r.Context(), u.ID, repository.TokenPurposeResetPassword, resetPasswordTTL)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Create reset token error", "err", err)
+			continue
+		}
+		link := config.FrontendURL + "/reset-password?token=" + url.QueryEscape(token)
+		if err := h.sendEmail(r, mailer.TemplateResetPassword, u.Email, u.Username, link, resetPasswordTTL); err != nil {
+			slog.ErrorContext(r.Context(), "Send reset password email error", "err", err)
+		}
+	}
+
+	api.JSON(w, http.StatusOK, map[string]string{
+		"message": "Jika email terdaftar, link reset password sudah dikirim.",
+	})
+}
+
+// ==============================
+// RESET PASSWORD
+// ==============================
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Token    string `json:"token" validate:"trim,required,max=128"`
+		Password string `json:"password" validate:"required,min=8,max=128"`
+	}
+
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	// Hash dibuat dulu supaya token tidak terpakai jika hash gagal
+	hash, err := passwords.Hash(body.Password)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Hash password error in ResetPassword", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal reset password")
+		return
+	}
+
+	userID, err := h.tokens.Consume(r.Context(), body.Token, repository.TokenPurposeResetPassword)
+	if err == repository.ErrTokenInvalid {
+		api.Fail(w, r, http.StatusBadRequest, "Link reset password tidak valid atau sudah kedaluwarsa")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error consuming token", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal reset password")
+		return
+	}
+
+	if err := h.repo.UpdatePassword(r.Context(), userID, hash); err != nil {
+		slog.ErrorContext(r.Context(), "DB error updating password", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal reset password")
+		return
+	}
+
+	// Semua session lama dicabut, user harus login ulang
+	if err := h.sessions.RevokeAllForUser(r.Context(), userID); err != nil {
+		slog.ErrorContext(r.Context(), "Revoke sessions error", "err", err)
+	}
+
+	audit.Record(r, audit.Entry{
+		Action:     "user.password_reset",
+		EntityType: "user",
+		EntityID:   strconv.Itoa(userID),
+		Title:      "Password direset lewat email",
+	})
+
+	api.JSON(w, http.StatusOK, map[string]string{"message": "Password berhasil diganti, silakan login"})
+}
+
+// ==============================
+// CHANGE PASSWORD (user yang sedang login)
+// ==============================
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	user := middleware.CurrentUser(r)
+
+	var body struct {
+		OldPassword string `json:"old_password" validate:"required"`
+		NewPassword string `json:"new_password" validate:"required,min=8,max=128,nefield=OldPassword"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	account, err := h.repo.GetUserByID(r.Context(), user.ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in ChangePassword", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengganti password")
+		return
+	}
+	if !passwords.Verify(account.Password, body.OldPassword) {
+		api.Fail(w, r, http.StatusUnauthorized, "Password lama salah")
+		return
+	}
+
+	hash, err := passwords.Hash(body.NewPassword)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Hash password error in ChangePassword", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengganti password")
+		return
+	}
+	if err := h.repo.ChangePassword(r.Context(), user.ID, hash); err != nil {
+		slog.ErrorContext(r.Context(), "DB error in ChangePassword", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengganti password")
+		return
+	}
+
+	// Session lain (misal yang memakai password lama) dicabut
+	if err := h.sessions.RevokeOthers(r.Context(), user.ID, middleware.BearerToken(r)); err != nil {
+		slog.ErrorContext(r.Context(), "Revoke sessions error", "err", err)
+	}
+
+	// Password tidak ikut dicatat
+	audit.Record(r, audit.Entry{
+		Action:     "user.password_changed",
+		EntityType: "user",
+		EntityID:   strconv.Itoa(user.ID),
+		Title:      fmt.Sprintf("USER %s mengganti password", user.Username),
+	})
+
+	api.JSON(w, http.StatusOK, map[string]string{"message": "Password berhasil diganti"})
+}
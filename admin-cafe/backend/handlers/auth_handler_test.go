@@ -0,0 +1,145 @@
+package handlers_test
+
+import (
+	"backend/models"
+	"backend/passwords"
+	"backend/repository/memory"
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+func TestLogin(t *testing.T) {
+	e := newEnv(t)
+	cafeID := e.addUser("kopikita", models.RoleCafe, true)
+	e.users.Add(memory.User{
+		User:      models.User{Username: "nakal", Password: mustHash("rahasia123"), Role: models.RoleCafe, Verified: true},
+		Suspended: true, SuspendedReason: "spam",
+	})
+	e.users.Add(memory.User{
+		User: models.User{Username: "budi", Password: mustHash("rahasia123"), Email: "budi@mail.com", Role: models.RoleCustomer, Verified: true},
+	})
+
+	t.Run("berhasil", func(t *testing.T) {
+		var res struct {
+			ID    int    `json:"id"`
+			Role  string `json:"role"`
+			Token string `json:"token"`
+		}
+		body := map[string]string{"username": "kopikita", "password": "rahasia123", "role": models.RoleCafe}
+		expect(t, e.do(t, "POST", "/login", "", body), http.StatusOK, &res)
+		if res.ID != cafeID || res.Role != models.RoleCafe || res.Token == "" {
+			t.Fatalf("response = %+v", res)
+		}
+		// Token langsung bisa dipakai
+		expect(t, e.do(t, "GET", "/cafe/menus", res.Token, nil), http.StatusNotFound, nil)
+	})
+
+	cases := []struct {
+		name   string
+		body   map[string]string
+		status int
+		code   string
+	}{
+		{"password salah", map[string]string{"username": "kopikita", "password": "salah", "role": models.RoleCafe}, http.StatusUnauthorized, "invalid_credentials"},
+		{"username tidak ada", map[string]string{"username": "siapa", "password": "rahasia123", "role": models.RoleCafe}, http.StatusUnauthorized, "invalid_credentials"},
+		{"role lain", map[string]string{"username": "kopikita", "password": "rahasia123", "role": models.RoleAdmin}, http.StatusUnauthorized, "invalid_credentials"},
+		{"ditangguhkan", map[string]string{"username": "nakal", "password": "rahasia123", "role": models.RoleCafe}, http.StatusForbidden, "account_suspended"},
+		{"email belum diverifikasi", map[string]string{"username": "budi", "password": "rahasia123", "role": models.RoleCustomer}, http.StatusForbidden, "email_unverified"},
+		{"role tidak valid", map[string]string{"username": "kopikita", "password": "rahasia123", "role": "root"}, http.StatusBadRequest, "validation_failed"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var res errorResponse
+			expect(t, e.do(t, "POST", "/login", "", c.body), c.status, &res)
+			if res.Code != c.code {
+				t.Fatalf("code = %q, mau %q", res.Code, c.code)
+			}
+		})
+	}
+}
+
+func TestLogoutRevokesSession(t *testing.T) {
+	e := newEnv(t)
+	token := e.login(t, e.addUser("admin", models.RoleAdmin, true))
+
+	expect(t, e.do(t, "GET", "/all-cafes", token, nil), http.StatusOK, nil)
+	expect(t, e.do(t, "POST", "/logout", token, nil), http.StatusOK, nil)
+
+	var res errorResponse
+	expect(t, e.do(t, "GET", "/all-cafes", token, nil), http.StatusUnauthorized, &res)
+	if res.Code != "session_invalid" {
+		t.Fatalf("code = %q, mau session_invalid", res.Code)
+	}
+}
+
+func TestLoginRehashesPlainTextPassword(t *testing.T) {
+	e := newEnv(t)
+	id := e.users.Add(memory.User{User: models.User{Username: "lama", Password: "rahasia123", Role: models.RoleCafe, Verified: true}})
+
+	body := map[string]string{"username": "lama", "password": "rahasia123", "role": models.RoleCafe}
+	expect(t, e.do(t, "POST", "/login", "", body), http.StatusOK, nil)
+
+	u, _ := e.users.Get(id)
+	if passwords.NeedsRehash(u.Password) || !passwords.Verify(u.Password, "rahasia123") {
+		t.Fatalf("password setelah login = %q, mau hash bcrypt", u.Password)
+	}
+	expect(t, e.do(t, "POST", "/login", "", body), http.StatusOK, nil)
+}
+
+func TestRegisterCustomerHashesPassword(t *testing.T) {
+	e := newEnv(t)
+	body := map[string]string{"username": "budi", "password": "rahasia123", "email": "budi@mail.com"}
+	expect(t, e.do(t, "POST", "/register-customer", "", body), http.StatusCreated, nil)
+
+	u, err := e.users.GetUserByUsername(context.Background(), "budi")
+	if err != nil {
+		t.Fatalf("GetUserByUsername: %v", err)
+	}
+	if u.Password == "rahasia123" || !passwords.Verify(u.Password, "rahasia123") {
+		t.Fatalf("password tersimpan = %q, mau hash bcrypt", u.Password)
+	}
+}
+
+func TestChangePasswordStoresHash(t *testing.T) {
+	e := newEnv(t)
+	id := e.addUser("kopikita", models.RoleCafe, true)
+	token := e.login(t, id)
+
+	var res errorResponse
+	expect(t, e.do(t, "POST", "/auth/change-password", token, map[string]string{
+		"old_password": "salah12345", "new_password": "baru12345",
+	}), http.StatusUnauthorized, &res)
+
+	expect(t, e.do(t, "POST", "/auth/change-password", token, map[string]string{
+		"old_password": "rahasia123", "new_password": "baru12345",
+	}), http.StatusOK, nil)
+
+	u, _ := e.users.Get(id)
+	if u.Password == "baru12345" || !passwords.Verify(u.Password, "baru12345") {
+		t.Fatalf("password tersimpan = %q, mau hash bcrypt", u.Password)
+	}
+}
+
+func TestResetPasswordStoresHash(t *testing.T) {
+	e := newEnv(t)
+	id := e.addUser("budi", models.RoleCustomer, true)
+
+	expect(t, e.do(t, "POST", "/auth/forgot-password", "", map[string]string{"email": "budi@mail.com"}), http.StatusOK, nil)
+	sent := e.mailer.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("email terkirim = %d, mau 1", len(sent))
+	}
+	m := regexp.MustCompile(`token=([0-9a-f]+)`).FindStringSubmatch(sent[0].Text)
+	if m == nil {
+		t.Fatalf("link reset tidak ditemukan di email: %s", sent[0].Text)
+	}
+
+	expect(t, e.do(t, "POST", "/auth/reset-password", "", map[string]string{"token": m[1], "password": "baru12345"}), http.StatusOK, nil)
+
+	u, _ := e.users.Get(id)
+	if u.Password == "baru12345" || !passwords.Verify(u.Password, "baru12345") {
+		t.Fatalf("password tersimpan = %q, mau hash bcrypt", u.Password)
+	}
+}
@@ -0,0 +1,390 @@
+package handlers
+
+import (
+	"backend/api"
+	"backend/cafes"
+	"backend/repository"
+	"backend/validate"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// CafeDetailsHandler mengelola detail profil cafe yang langsung berlaku
+// tanpa persetujuan admin: media sosial, jam operasional, fasilitas,
+// galeri dan foto utama
+type CafeDetailsHandler struct {
+	details cafes.Store
+	cafes   repository.CafeProfileStore
+}
+
+func NewCafeDetailsHandler(details cafes.Store, cafeRepo repository.CafeProfileStore) *CafeDetailsHandler {
+	return &CafeDetailsHandler{details: details, cafes: cafeRepo}
+}
+
+// ==============================
+// Media sosial
+// GET /cafe/social-media
+// ==============================
+func (h *CafeDetailsHandler) ListSocialMedia(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	list, err := h.details.SocialMedia(r.Context(), cafeID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error listing social media", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil media sosial")
+		return
+	}
+	api.JSON(w, http.StatusOK, map[string]interface{}{"social_media": list})
+}
+
+// ==============================
+// Tambah media sosial
+// POST /cafe/social-media  {"platform": "instagram", "url": "https://instagram.com/..."}
+// ==============================
+func (h *CafeDetailsHandler) AddSocialMedia(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	var body cafes.SocialMedia
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	m, err := h.details.AddSocialMedia(r.Context(), cafeID, body)
+	if err == cafes.ErrLimitReached {
+		api.Failf(w, r, http.StatusConflict, "Maksimal %d media sosial", cafes.MaxSocialMedia)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error adding social media", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan media sosial")
+		return
+	}
+	api.JSON(w, http.StatusCreated, m)
+}
+
+// ==============================
+// Hapus semua media sosial
+// DELETE /cafe/social-media/all
+// ==============================
+// Halaman profil menyimpan media sosial dengan menghapus semua lalu
+// menambah ulang satu per satu.
+func (h *CafeDetailsHandler) DeleteAllSocialMedia(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	n, err := h.details.DeleteAllSocialMedia(r.Context(), cafeID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error deleting social media", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menghapus media sosial")
+		return
+	}
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Media sosial dihapus",
+		"deleted": n,
+	})
+}
+
+// ==============================
+// Jam operasional
+// GET /cafe/operational-hours
+// ==============================
+func (h *CafeDetailsHandler) ListOperationalHours(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+	h.writeOperationalHours(w, r, cafeID)
+}
+
+func (h *CafeDetailsHandler) writeOperationalHours(w http.ResponseWriter, r *http.Request, cafeID int) {
+	list, err := h.details.OperationalHours(r.Context(), cafeID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error listing operational hours", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil jam operasional")
+		return
+	}
+	api.JSON(w, http.StatusOK, map[string]interface{}{"operational_hours": list})
+}
+
+// ==============================
+// Ganti seluruh jam operasional
+// PUT /cafe/operational-hours  {"operational_hours": [{"hari": "senin", "buka": "08:00", "tutup": "22:00"}]}
+// ==============================
+// Hari yang tidak dikirim berarti tutup.
+func (h *CafeDetailsHandler) ReplaceOperationalHours(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		OperationalHours []cafes.OperationalHour `json:"operational_hours" validate:"max=7"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+	seen := map[string]bool{}
+	for _, oh := range body.OperationalHours {
+		if seen[oh.Hari] {
+			api.Failf(w, r, http.StatusBadRequest, "Hari %s dikirim lebih dari sekali", oh.Hari)
+			return
+		}
+		seen[oh.Hari] = true
+	}
+
+	if err := h.details.ReplaceOperationalHours(r.Context(), cafeID, body.OperationalHours); err != nil {
+		slog.ErrorContext(r.Context(), "DB error saving operational hours", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan jam operasional")
+		return
+	}
+	h.writeOperationalHours(w, r, cafeID)
+}
+
+// ==============================
+// Ubah jam operasional satu hari
+// PUT /cafe/operational-hours/{hari}  {"buka": "08:00", "tutup": "22:00"}
+// ==============================
+// Alias lama PUT /api/operational-hours/single mengirim hari di body.
+func (h *CafeDetailsHandler) SetOperationalHour(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	var body cafes.OperationalHour
+	if invalid := validate.Decode(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+	// Hari di path mengalahkan body; hanya alias lama yang tidak punya
+	// {hari} memakai hari dari body
+	if hari, ok := mux.Vars(r)["hari"]; ok {
+		body.Hari = hari
+	}
+	if invalid := validate.Struct(&body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	if err := h.details.SetOperationalHour(r.Context(), cafeID, body); err != nil {
+		slog.ErrorContext(r.Context(), "DB error saving operational hour", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan jam operasional")
+		return
+	}
+	h.writeOperationalHours(w, r, cafeID)
+}
+
+// ==============================
+// Status buka saat ini
+// GET /cafe/operational-hours/status
+// ==============================
+// Juga menjawab alias lama /api/operational-hours/today: jadwal hari ini
+// ada di field today.
+func (h *CafeDetailsHandler) OpenStatus(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	status, err := h.details.Status(r.Context(), cafeID, time.Now())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error reading open status", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil jam operasional")
+		return
+	}
+	api.JSON(w, http.StatusOK, status)
+}
+
+// ==============================
+// Fasilitas
+// GET /cafe/facilities
+// ==============================
+func (h *CafeDetailsHandler) ListFacilities(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+	h.writeFacilities(w, r, cafeID)
+}
+
+func (h *CafeDetailsHandler) writeFacilities(w http.ResponseWriter, r *http.Request, cafeID int) {
+	list, err := h.details.Facilities(r.Context(), cafeID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error listing facilities", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil fasilitas")
+		return
+	}
+	api.JSON(w, http.StatusOK, map[string]interface{}{"facilities": list})
+}
+
+// ==============================
+// Ganti daftar fasilitas
+// PUT /cafe/facilities  {"facilities": [{"nama_fasilitas": "WiFi", "tersedia": true}]}
+// ==============================
+func (h *CafeDetailsHandler) ReplaceFacilities(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Facilities []cafes.Facility `json:"facilities" validate:"max=50"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	if err := h.details.ReplaceFacilities(r.Context(), cafeID, body.Facilities); err != nil {
+		slog.ErrorContext(r.Context(), "DB error saving facilities", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan fasilitas")
+		return
+	}
+	h.writeFacilities(w, r, cafeID)
+}
+
+// ==============================
+// Galeri
+// GET /cafe/gallery
+// ==============================
+func (h *CafeDetailsHandler) ListGallery(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	list, err := h.details.Gallery(r.Context(), cafeID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error listing gallery", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil galeri")
+		return
+	}
+	api.JSON(w, http.StatusOK, map[string]interface{}{"gallery": list})
+}
+
+// ==============================
+// Tambah foto galeri
+// POST /cafe/gallery  (multipart: image, urutan)
+// ==============================
+func (h *CafeDetailsHandler) AddGalleryImage(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	url, ok := receiveImage(w, r, "image")
+	if !ok {
+		return
+	}
+	urutan, _ := strconv.Atoi(r.FormValue("urutan"))
+
+	image, err := h.details.AddGalleryImage(r.Context(), cafeID, url, urutan)
+	if err != nil {
+		removeUpload(url)
+	}
+	if err == cafes.ErrLimitReached {
+		api.Failf(w, r, http.StatusConflict, "Maksimal %d foto galeri", cafes.MaxGallery)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error adding gallery image", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan foto galeri")
+		return
+	}
+	api.JSON(w, http.StatusCreated, image)
+}
+
+// ==============================
+// Hapus foto galeri
+// DELETE /cafe/gallery/{id}
+// ==============================
+func (h *CafeDetailsHandler) DeleteGalleryImage(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	url, err := h.details.DeleteGalleryImage(r.Context(), cafeID, id)
+	if err == cafes.ErrNotFound {
+		api.Fail(w, r, http.StatusNotFound, "Foto galeri tidak ditemukan")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error deleting gallery image", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menghapus foto galeri")
+		return
+	}
+	removeUpload(url)
+	api.JSON(w, http.StatusOK, map[string]string{"message": "Foto galeri dihapus"})
+}
+
+// ==============================
+// Ganti foto utama cafe
+// POST /cafe/profile/image  (multipart: image)
+// ==============================
+func (h *CafeDetailsHandler) UploadMainImage(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	url, ok := receiveImage(w, r, "image")
+	if !ok {
+		return
+	}
+	old, err := h.details.SetMainImage(r.Context(), cafeID, url)
+	if err != nil {
+		removeUpload(url)
+		h.mainImageError(w, r, err)
+		return
+	}
+	removeUpload(old)
+
+	api.JSON(w, http.StatusOK, map[string]string{
+		"message":   "Foto utama diperbarui",
+		"image_url": url,
+	})
+}
+
+// ==============================
+// Hapus foto utama cafe
+// DELETE /cafe/profile/image
+// ==============================
+func (h *CafeDetailsHandler) DeleteMainImage(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	old, err := h.details.SetMainImage(r.Context(), cafeID, "")
+	if err != nil {
+		h.mainImageError(w, r, err)
+		return
+	}
+	removeUpload(old)
+	api.JSON(w, http.StatusOK, map[string]string{"message": "Foto utama dihapus"})
+}
+
+func (h *CafeDetailsHandler) mainImageError(w http.ResponseWriter, r *http.Request, err error) {
+	if err == cafes.ErrNotFound {
+		api.Fail(w, r, http.StatusNotFound, "Cafe tidak ditemukan")
+		return
+	}
+	slog.ErrorContext(r.Context(), "DB error saving main image", "err", err)
+	api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan foto utama")
+}
@@ -0,0 +1,47 @@
+package handlers_test
+
+import (
+	"backend/cafes"
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestSetOperationalHourDayFromPath(t *testing.T) {
+	e := newEnv(t)
+	userID, cafeID := e.addCafe("kopikita", true)
+	token := e.login(t, userID)
+
+	// hari di body diabaikan jika path sudah menyebut hari
+	expect(t, e.do(t, "PUT", "/cafe/operational-hours/senin", token, map[string]string{
+		"hari": "selasa", "buka": "08:00", "tutup": "22:00",
+	}), http.StatusOK, nil)
+
+	// Alias lama tanpa {hari} mengambil hari dari body
+	expect(t, e.do(t, "PUT", "/api/operational-hours/single", token, map[string]string{
+		"hari": "rabu", "buka": "09:00", "tutup": "17:00",
+	}), http.StatusOK, nil)
+
+	hours, err := e.details.OperationalHours(context.Background(), cafeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []cafes.OperationalHour{
+		{Hari: "senin", Buka: "08:00", Tutup: "22:00"},
+		{Hari: "rabu", Buka: "09:00", Tutup: "17:00"},
+	}
+	if len(hours) != len(want) || hours[0] != want[0] || hours[1] != want[1] {
+		t.Fatalf("jam operasional = %+v, mau %+v", hours, want)
+	}
+
+	var res errorResponse
+	expect(t, e.do(t, "PUT", "/cafe/operational-hours/libur", token, map[string]string{
+		"buka": "08:00", "tutup": "22:00",
+	}), http.StatusBadRequest, &res)
+	if len(res.Fields) != 1 || res.Fields[0].Field != "hari" {
+		t.Fatalf("error = %+v", res)
+	}
+	expect(t, e.do(t, "PUT", "/api/operational-hours/single", token, map[string]string{
+		"buka": "08:00", "tutup": "22:00",
+	}), http.StatusBadRequest, nil)
+}
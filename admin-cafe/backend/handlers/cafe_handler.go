@@ -1,18 +1,25 @@
 package handlers
 
 import (
+    "backend/api"
+    "backend/audit"
+    "backend/models"
+    "backend/notify"
     "backend/repository"
-    "encoding/json"
+    "backend/validate"
     "fmt"
+    "log/slog"
     "net/http"
+    "strconv"
 )
 
 type CafeHandler struct {
-    repo *repository.UserRepository
+    repo   repository.UserStore
+    notify notify.Notifier
 }
 
-func NewCafeHandler(repo *repository.UserRepository) *CafeHandler {
-    return &CafeHandler{repo: repo}
+func NewCafeHandler(repo repository.UserStore, n notify.Notifier) *CafeHandler {
+    return &CafeHandler{repo: repo, notify: n}
 }
 
 // ==============================
@@ -20,28 +27,49 @@ func NewCafeHandler(repo *repository.UserRepository) *CafeHandler {
 // ==============================
 func (h *CafeHandler) ApproveCafe(w http.ResponseWriter, r *http.Request) {
     var body struct {
-        CafeID int `json:"cafe_id"`
+        CafeID int `json:"cafe_id" validate:"required,gt=0"`
     }
 
-    // Decode JSON body
-    err := json.NewDecoder(r.Body).Decode(&body)
-    if err != nil {
-        fmt.Println("JSON decode error in ApproveCafe:", err)
-        http.Error(w, "Invalid request body", http.StatusBadRequest)
+    if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+        api.WriteError(w, r, invalid)
         return
     }
 
-    fmt.Println("Approve attempt for cafe ID:", body.CafeID)
+    slog.InfoContext(r.Context(), "Approve cafe", "cafe_id", body.CafeID)
+
+    // Data sebelum diubah untuk log aktivitas
+    before, _ := h.repo.GetUserByID(r.Context(), body.CafeID)
 
     // Panggil repository untuk verify cafe
-    err = h.repo.VerifyCafe(body.CafeID)
+    err := h.repo.VerifyCafe(r.Context(), body.CafeID)
     if err != nil {
-        fmt.Println("DB error approving cafe:", err)
-        http.Error(w, "Gagal approve cafe", http.StatusInternalServerError)
+        slog.ErrorContext(r.Context(), "DB error approving cafe", "err", err)
+        api.Fail(w, r, http.StatusInternalServerError, "Gagal approve cafe")
         return
     }
 
-    json.NewEncoder(w).Encode(map[string]string{"message": "Cafe approved!"})
+    entry := audit.Entry{
+        Action:     "cafe.approved",
+        EntityType: "cafe",
+        EntityID:   strconv.Itoa(body.CafeID),
+        Title:      fmt.Sprintf("Cafe #%d disetujui", body.CafeID),
+        After:      map[string]bool{"verified": true},
+    }
+    if before != nil {
+        entry.Title = fmt.Sprintf("Cafe %s disetujui", before.Username)
+        entry.Before = map[string]bool{"verified": before.Verified}
+    }
+    audit.Record(r, entry)
+
+    // Kabari pemilik cafe; hanya saat status benar-benar berubah
+    if before != nil && !before.Verified {
+        err = h.notify.Notify(r.Context(), body.CafeID, models.NotifyCafeApproved, notify.Data{"name": before.Username})
+        if err != nil {
+            slog.ErrorContext(r.Context(), "Notify cafe approved error", "err", err)
+        }
+    }
+
+    api.JSON(w, http.StatusOK, map[string]string{"message": "Cafe approved!"})
 }
 
 // ==============================
@@ -49,61 +77,54 @@ func (h *CafeHandler) ApproveCafe(w http.ResponseWriter, r *http.Request) {
 // ==============================
 func (h *CafeHandler) RejectCafe(w http.ResponseWriter, r *http.Request) {
     var body struct {
-        CafeID int `json:"cafe_id"`
+        CafeID int `json:"cafe_id" validate:"required,gt=0"`
     }
 
-    err := json.NewDecoder(r.Body).Decode(&body)
-    if err != nil {
-        fmt.Println("JSON decode error in RejectCafe:", err)
-        http.Error(w, "Invalid request body", http.StatusBadRequest)
+    if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+        api.WriteError(w, r, invalid)
         return
     }
 
-    fmt.Println("Reject attempt for cafe ID:", body.CafeID)
+    slog.InfoContext(r.Context(), "Reject cafe", "cafe_id", body.CafeID)
+
+    // Data sebelum dihapus untuk log aktivitas
+    before, _ := h.repo.GetUserByID(r.Context(), body.CafeID)
 
     // Hapus cafe dari DB (atau bisa update status)
-    _, err = h.repo.DB.Exec("DELETE FROM users WHERE id=$1 AND role='cafe' AND verified=false", body.CafeID)
+    rejected, err := h.repo.RejectCafe(r.Context(), body.CafeID)
     if err != nil {
-        fmt.Println("DB error rejecting cafe:", err)
-        http.Error(w, "Gagal menolak cafe", http.StatusInternalServerError)
+        slog.ErrorContext(r.Context(), "DB error rejecting cafe", "err", err)
+        api.Fail(w, r, http.StatusInternalServerError, "Gagal menolak cafe")
         return
     }
 
-    json.NewEncoder(w).Encode(map[string]string{"message": "Cafe ditolak!"})
+    if rejected && before != nil {
+        audit.Record(r, audit.Entry{
+            Action:     "cafe.rejected",
+            EntityType: "cafe",
+            EntityID:   strconv.Itoa(body.CafeID),
+            Title:      fmt.Sprintf("Cafe %s ditolak", before.Username),
+            Before: map[string]interface{}{
+                "username": before.Username,
+                "email":    before.Email,
+                "verified": before.Verified,
+            },
+        })
+    }
+
+    api.JSON(w, http.StatusOK, map[string]string{"message": "Cafe ditolak!"})
 }
 
 // ==============================
 // List semua cafe (pending, approved, rejected)
 // ==============================
 func (h *CafeHandler) ListAllCafes(w http.ResponseWriter, r *http.Request) {
-	type Cafe struct {
-		ID        int    `json:"id"`
-		Username  string `json:"username"`
-		Email     string `json:"email"`
-		IzinUsaha string `json:"izin_usaha"`
-		Verified  bool   `json:"verified"`
-		Rejected  bool   `json:"rejected"` // buat field ini kalau ada
-	}
-
-	rows, err := h.repo.DB.Query("SELECT id, username, email, izin_usaha, verified, rejected FROM users WHERE role='cafe'")
-	if err != nil {
-		fmt.Println("DB query error in ListAllCafes:", err)
-		http.Error(w, "Gagal mengambil data cafe", http.StatusInternalServerError)
-		return
-	}
-	defer rows.Close()
-
-	var cafes []Cafe
-	for rows.Next() {
-		var c Cafe
-		err := rows.Scan(&c.ID, &c.Username, &c.Email, &c.IzinUsaha, &c.Verified, &c.Rejected)
-		if err != nil {
-			fmt.Println("Row scan error:", err)
-			continue
-		}
-		cafes = append(cafes, c)
-	}
-
-	json.NewEncoder(w).Encode(cafes)
-}
+    cafes, err := h.repo.ListCafes(r.Context())
+    if err != nil {
+        slog.ErrorContext(r.Context(), "DB query error in ListAllCafes", "err", err)
+        api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil data cafe")
+        return
+    }
 
+    api.JSON(w, http.StatusOK, cafes)
+}
@@ -0,0 +1,74 @@
+package handlers_test
+
+import (
+	"backend/models"
+	"net/http"
+	"testing"
+)
+
+func TestApproveCafeNotifiesOnce(t *testing.T) {
+	e := newEnv(t)
+	admin := e.login(t, e.addUser("admin", models.RoleAdmin, true))
+	cafeID := e.addUser("kopikita", models.RoleCafe, false)
+
+	for i := 0; i < 2; i++ {
+		expect(t, e.do(t, "POST", "/approve-cafe", admin, map[string]int{"cafe_id": cafeID}), http.StatusOK, nil)
+	}
+
+	u, _ := e.users.Get(cafeID)
+	if !u.Verified {
+		t.Fatal("cafe belum terverifikasi")
+	}
+	sent := e.notifier.Sent()
+	if len(sent) != 1 || sent[0].UserID != cafeID || sent[0].Event != models.NotifyCafeApproved {
+		t.Fatalf("notifikasi = %+v, mau satu %s untuk user %d", sent, models.NotifyCafeApproved, cafeID)
+	}
+}
+
+func TestRejectCafeOnlyRemovesPending(t *testing.T) {
+	e := newEnv(t)
+	admin := e.login(t, e.addUser("admin", models.RoleAdmin, true))
+	pending := e.addUser("kopikita", models.RoleCafe, false)
+	approved := e.addUser("senja", models.RoleCafe, true)
+
+	expect(t, e.do(t, "POST", "/reject-cafe", admin, map[string]int{"cafe_id": pending}), http.StatusOK, nil)
+	expect(t, e.do(t, "POST", "/reject-cafe", admin, map[string]int{"cafe_id": approved}), http.StatusOK, nil)
+
+	if _, ok := e.users.Get(pending); ok {
+		t.Error("cafe pending masih ada setelah ditolak")
+	}
+	if _, ok := e.users.Get(approved); !ok {
+		t.Error("cafe terverifikasi ikut terhapus")
+	}
+}
+
+func TestListAllCafes(t *testing.T) {
+	e := newEnv(t)
+	admin := e.login(t, e.addUser("admin", models.RoleAdmin, true))
+	pending := e.addUser("kopikita", models.RoleCafe, false)
+	approved := e.addUser("senja", models.RoleCafe, true)
+
+	var cafes []models.CafeAccount
+	expect(t, e.do(t, "GET", "/all-cafes", admin, nil), http.StatusOK, &cafes)
+	if len(cafes) != 2 || cafes[0].ID != pending || cafes[0].Verified || cafes[1].ID != approved || !cafes[1].Verified {
+		t.Fatalf("cafes = %+v", cafes)
+	}
+
+	// Hanya super admin
+	cafe := e.login(t, approved)
+	expect(t, e.do(t, "GET", "/all-cafes", cafe, nil), http.StatusForbidden, nil)
+}
+
+func TestApproveCafeValidation(t *testing.T) {
+	e := newEnv(t)
+	admin := e.login(t, e.addUser("admin", models.RoleAdmin, true))
+
+	var res errorResponse
+	expect(t, e.do(t, "POST", "/approve-cafe", admin, map[string]int{}), http.StatusBadRequest, &res)
+	if res.Code != "validation_failed" || len(res.Fields) != 1 || res.Fields[0].Field != "cafe_id" {
+		t.Fatalf("response = %+v", res)
+	}
+	if len(e.notifier.Sent()) != 0 {
+		t.Fatal("notifikasi terkirim untuk request yang tidak valid")
+	}
+}
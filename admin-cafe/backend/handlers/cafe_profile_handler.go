@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"backend/api"
+	"backend/config"
+	"backend/models"
+	"backend/repository"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultNearbyRadiusKm = 5.0
+	maxNearbyRadiusKm     = 50.0
+	defaultNearbyLimit    = 20
+	maxNearbyLimit        = 100
+)
+
+type CafeProfileHandler struct {
+	repo repository.CafeProfileStore
+}
+
+func NewCafeProfileHandler(repo repository.CafeProfileStore) *CafeProfileHandler {
+	return &CafeProfileHandler{repo: repo}
+}
+
+// checkCoordinates menolak titik 0,0 yang biasanya berarti lokasi belum
+// terbaca; batas lintang & bujur sudah dicek lewat tag validate
+func checkCoordinates(lat, lng float64) *api.Error {
+	if lat == 0 && lng == 0 {
+		return badRequest("Koordinat tidak valid")
+	}
+	return nil
+}
+
+// ==============================
+// Cari cafe terdekat
+// GET /cafes/nearby?lat=&lng=&radius=&open_now=&facilities=&min_rating=&limit=
+// ==============================
+func (h *CafeProfileHandler) NearbyCafes(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	lat, errLat := strconv.ParseFloat(params.Get("lat"), 64)
+	lng, errLng := strconv.ParseFloat(params.Get("lng"), 64)
+	if errLat != nil || errLng != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		api.Fail(w, r, http.StatusBadRequest, "Parameter lat dan lng wajib diisi dengan koordinat yang valid")
+		return
+	}
+
+	q := models.NearbyQuery{
+		Latitude:  lat,
+		Longitude: lng,
+		RadiusKm:  defaultNearbyRadiusKm,
+		Limit:     defaultNearbyLimit,
+		Now:       time.Now().In(config.Location),
+	}
+
+	if v := params.Get("radius"); v != "" {
+		radius, err := strconv.ParseFloat(v, 64)
+		if err != nil || radius <= 0 || radius > maxNearbyRadiusKm {
+			api.Failf(w, r, http.StatusBadRequest, "radius harus di antara 0 dan %.0f km", maxNearbyRadiusKm)
+			return
+		}
+		q.RadiusKm = radius
+	}
+
+	if v := params.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxNearbyLimit {
+			api.Failf(w, r, http.StatusBadRequest, "limit harus di antara 1 dan %d", maxNearbyLimit)
+			return
+		}
+		q.Limit = limit
+	}
+
+	if v := params.Get("open_now"); v != "" {
+		openNow, err := strconv.ParseBool(v)
+		if err != nil {
+			api.Fail(w, r, http.StatusBadRequest, "open_now harus true atau false")
+			return
+		}
+		q.OpenNow = openNow
+	}
+
+	if v := params.Get("min_rating"); v != "" {
+		rating, err := strconv.ParseFloat(v, 64)
+		if err != nil || rating < 0 || rating > 5 {
+			api.Fail(w, r, http.StatusBadRequest, "min_rating harus di antara 0 dan 5")
+			return
+		}
+		q.MinRating = rating
+	}
+
+	// facilities=wifi,outdoor atau facilities=wifi&facilities=outdoor
+	seen := map[string]bool{}
+	for _, raw := range params["facilities"] {
+		for _, f := range strings.Split(raw, ",") {
+			f = strings.ToLower(strings.TrimSpace(f))
+			if f != "" && !seen[f] {
+				seen[f] = true
+				q.Facilities = append(q.Facilities, f)
+			}
+		}
+	}
+
+	cafes, err := h.repo.FindNearby(r.Context(), q)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB query error in NearbyCafes", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mencari cafe terdekat")
+		return
+	}
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"radius_km": q.RadiusKm,
+		"total":     len(cafes),
+		"cafes":     cafes,
+	})
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"backend/api"
+	"backend/middleware"
+	"backend/models"
+	"backend/repository"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// requireCafeID menentukan cafe yang diakses request. Akun cafe selalu
+// memakai profil miliknya sendiri, admin wajib mengirim ?cafe_id=.
+// Jika gagal, response error sudah ditulis dan ok bernilai false.
+func requireCafeID(w http.ResponseWriter, r *http.Request, cafes repository.CafeProfileStore) (int, bool) {
+	user := middleware.CurrentUser(r)
+	if user == nil {
+		api.Fail(w, r, http.StatusUnauthorized, "Silakan login terlebih dahulu")
+		return 0, false
+	}
+
+	if user.Role == models.RoleAdmin {
+		id, err := strconv.Atoi(r.URL.Query().Get("cafe_id"))
+		if err != nil || id <= 0 {
+			api.Fail(w, r, http.StatusBadRequest, "Parameter cafe_id wajib diisi")
+			return 0, false
+		}
+		return id, true
+	}
+
+	id, err := cafes.GetIDByUserID(r.Context(), user.ID)
+	if err == sql.ErrNoRows {
+		api.Fail(w, r, http.StatusNotFound, "Profil cafe belum dibuat")
+		return 0, false
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error resolving cafe profile", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil profil cafe")
+		return 0, false
+	}
+	return id, true
+}
+
+// requireOwnMenu memastikan menu di path {id} milik cafe user yang login
+// dan mengembalikan id menu beserta id cafe-nya
+func requireOwnMenu(w http.ResponseWriter, r *http.Request, cafes repository.CafeProfileStore, orders repository.OrderStore) (string, int, bool) {
+	cafeID, ok := requireCafeID(w, r, cafes)
+	if !ok {
+		return "", 0, false
+	}
+
+	menuID := strings.ToLower(mux.Vars(r)["id"])
+	if !checkMenuOwner(w, r, orders, menuID, cafeID) {
+		return "", 0, false
+	}
+	return menuID, cafeID, true
+}
+
+// checkMenuOwner memastikan menu milik cafeID; jika bukan, response 404
+// sudah ditulis
+func checkMenuOwner(w http.ResponseWriter, r *http.Request, orders repository.OrderStore, menuID string, cafeID int) bool {
+	menuCafeID, err := orders.MenuCafeID(r.Context(), menuID)
+	if err == sql.ErrNoRows || (err == nil && menuCafeID != cafeID) {
+		api.Fail(w, r, http.StatusNotFound, "Menu tidak ditemukan")
+		return false
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error resolving menu owner", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil menu")
+		return false
+	}
+	return true
+}
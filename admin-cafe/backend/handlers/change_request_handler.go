@@ -0,0 +1,594 @@
+package handlers
+
+import (
+	"backend/api"
+	"backend/audit"
+	"backend/cafes"
+	"backend/config"
+	"backend/middleware"
+	"backend/models"
+	"backend/notify"
+	"backend/repository"
+	"backend/validate"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Klaim yang tampil di log aktivitas untuk perubahan yang butuh persetujuan
+const approvalClaim = "Klaim : SUPER ADMIN"
+
+// Tipe file izin usaha: gambar atau PDF
+var documentTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".pdf":  "application/pdf",
+}
+
+// Label perubahan per field profil, untuk log aktivitas & email
+var changeLabels = map[string]string{
+	"nama":       "Nama",
+	"alamat":     "Lokasi",
+	"latitude":   "Lokasi",
+	"longitude":  "Lokasi",
+	"telepon":    "Kontak",
+	"deskripsi":  "Deskripsi",
+	"izin_usaha": "Izin Usaha",
+	"discount":   "Diskon",
+}
+
+type ChangeRequestHandler struct {
+	changes repository.ChangeRequestStore
+	cafes   repository.CafeProfileStore
+	details cafes.Store
+	orders  repository.OrderStore
+	notify  notify.Notifier
+}
+
+func NewChangeRequestHandler(changes repository.ChangeRequestStore, cafeRepo repository.CafeProfileStore, details cafes.Store, orders repository.OrderStore, n notify.Notifier) *ChangeRequestHandler {
+	return &ChangeRequestHandler{changes: changes, cafes: cafeRepo, details: details, orders: orders, notify: n}
+}
+
+// ==============================
+// Profil cafe milik sendiri + perubahan yang menunggu persetujuan
+// GET /cafe/profile
+// ==============================
+func (h *ChangeRequestHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	profile, err := h.cafes.GetByID(r.Context(), cafeID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in GetProfile", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil profil cafe")
+		return
+	}
+	pending, _, err := h.changes.List(r.Context(), cafeID, models.ChangePending, maxOrdersLimit, 0)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in GetProfile", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil profil cafe")
+		return
+	}
+
+	socialMedia, err := h.details.SocialMedia(r.Context(), cafeID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in GetProfile", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil profil cafe")
+		return
+	}
+	hours, err := h.details.OperationalHours(r.Context(), cafeID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in GetProfile", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil profil cafe")
+		return
+	}
+	facilities, err := h.details.Facilities(r.Context(), cafeID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in GetProfile", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil profil cafe")
+		return
+	}
+
+	// Field profil tetap di level atas supaya halaman profil lama tetap jalan
+	api.JSON(w, http.StatusOK, struct {
+		*models.CafeProfile
+		SocialMedia      []cafes.SocialMedia     `json:"social_media"`
+		OperationalHours []cafes.OperationalHour `json:"operational_hours"`
+		Facilities       []cafes.Facility        `json:"facilities"`
+		PendingChanges   []models.ChangeRequest  `json:"pending_changes"`
+		ApprovalFields   []string                `json:"approval_fields"`
+		DiscountMinPct   float64                 `json:"discount_min_pct"`
+	}{profile, socialMedia, hours, facilities, pending, approvalFields(), config.ApprovalDiscountMin})
+}
+
+// ==============================
+// Ubah profil cafe
+// PUT /cafe/profile  {"nama": "...", "alamat": "...", "telepon": "...", "deskripsi": "...", "latitude": -6.2, "longitude": 106.8}
+// ==============================
+// Field biasa langsung diterapkan. Field sensitif (CAFE_APPROVAL_FIELDS)
+// disimpan sebagai permintaan perubahan dan profil live tidak berubah
+// sampai super admin menyetujuinya.
+func (h *ChangeRequestHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	var body models.ProfileChanges
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+	// Izin usaha hanya bisa diganti lewat upload file
+	body.IzinUsaha = nil
+	if invalid := validateProfileChanges(&body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	// Form profil mengirim semua field; yang nilainya sama dengan data
+	// live tidak dianggap perubahan supaya tidak membuat antrean kosong
+	live, err := h.changes.ProfileSnapshot(r.Context(), cafeID)
+	if err == sql.ErrNoRows {
+		api.Fail(w, r, http.StatusNotFound, "Cafe tidak ditemukan")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in UpdateProfile", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan profil cafe")
+		return
+	}
+	dropUnchanged(&body, *live)
+
+	direct, queued := splitProfileChanges(body)
+	if !direct.Empty() {
+		if err := h.changes.UpdateProfile(r.Context(), cafeID, direct); err != nil {
+			slog.ErrorContext(r.Context(), "DB error in UpdateProfile", "err", err)
+			api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan profil cafe")
+			return
+		}
+		audit.Record(r, audit.Entry{
+			Action:     "cafe.profile_updated",
+			EntityType: "cafe",
+			EntityID:   strconv.Itoa(cafeID),
+			Title:      "Profil cafe diperbarui",
+			After:      direct,
+		})
+	}
+
+	h.respondProfile(w, r, cafeID, queued, "Profil cafe disimpan")
+}
+
+// ==============================
+// Ganti izin usaha (selalu lewat antrean jika izin_usaha sensitif)
+// POST /cafe/profile/izin-usaha  (multipart, field "izin_usaha")
+// ==============================
+func (h *ChangeRequestHandler) UploadIzinUsaha(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize+(1<<20))
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		api.Fail(w, r, http.StatusBadRequest, "File terlalu besar (maks 10 MB)")
+		return
+	}
+
+	name, err := saveUpload(r, "izin_usaha", documentTypes)
+	if err == errInvalidFileType {
+		api.Fail(w, r, http.StatusBadRequest, "Izin usaha harus berupa JPG, PNG atau PDF")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Upload error in UploadIzinUsaha", "err", err)
+		api.Fail(w, r, http.StatusBadRequest, "Gagal menyimpan file izin usaha")
+		return
+	}
+
+	// Path disimpan seperti saat registrasi cafe
+	path := uploadDir + "/" + name
+	changes := models.ProfileChanges{IzinUsaha: &path}
+
+	if !config.RequiresApproval(models.ChangeFieldIzinUsaha) {
+		if err := h.changes.UpdateProfile(r.Context(), cafeID, changes); err != nil {
+			slog.ErrorContext(r.Context(), "DB error in UploadIzinUsaha", "err", err)
+			api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan izin usaha")
+			return
+		}
+		changes = models.ProfileChanges{}
+	}
+	h.respondProfile(w, r, cafeID, changes, "Izin usaha disimpan")
+}
+
+// respondProfile mengajukan perubahan sensitif (jika ada) lalu menulis
+// profil live beserta permintaan perubahan yang dibuat
+func (h *ChangeRequestHandler) respondProfile(w http.ResponseWriter, r *http.Request, cafeID int, queued models.ProfileChanges, message string) {
+	var request *models.ChangeRequest
+	if !queued.Empty() {
+		snapshot, err := h.changes.ProfileSnapshot(r.Context(), cafeID)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "DB error reading cafe profile", "err", err)
+			api.Fail(w, r, http.StatusInternalServerError, "Gagal mengajukan perubahan")
+			return
+		}
+		request, err = h.changes.Submit(r.Context(), cafeID, models.ChangeEntityCafe, strconv.Itoa(cafeID),
+			queued, previousValues(queued, *snapshot), middleware.CurrentUser(r).ID, time.Now())
+		if err != nil {
+			slog.ErrorContext(r.Context(), "DB error submitting change request", "err", err)
+			api.Fail(w, r, http.StatusInternalServerError, "Gagal mengajukan perubahan")
+			return
+		}
+		h.recordSubmitted(r, request, "Perubahan "+changeLabel(request))
+		message += ", perubahan " + changeLabel(request) + " menunggu persetujuan admin"
+	}
+
+	profile, err := h.cafes.GetByID(r.Context(), cafeID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error reading cafe profile", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil profil cafe")
+		return
+	}
+
+	status := http.StatusOK
+	if request != nil {
+		status = http.StatusAccepted
+	}
+	api.JSON(w, status, map[string]interface{}{
+		"message":        message,
+		"profile":        profile,
+		"change_request": request,
+	})
+}
+
+// ==============================
+// Ubah diskon menu
+// PUT /cafe/menus/{id}/discount  {"discount": 20, "start_date": "2025-09-01", "end_date": "2025-09-30"}
+// ==============================
+// Diskon >= CAFE_APPROVAL_DISCOUNT_MIN persen menunggu persetujuan admin;
+// diskon live tetap seperti sebelumnya sampai disetujui.
+func (h *ChangeRequestHandler) UpdateDiscount(w http.ResponseWriter, r *http.Request) {
+	menuID, cafeID, ok := requireOwnMenu(w, r, h.cafes, h.orders)
+	if !ok {
+		return
+	}
+
+	var body models.DiscountChange
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+	h.setDiscount(w, r, menuID, cafeID, body)
+}
+
+// setDiscount langsung menerapkan diskon menu atau mengajukannya ke super
+// admin, lalu menulis response. Dipakai juga oleh endpoint promo.
+func (h *ChangeRequestHandler) setDiscount(w http.ResponseWriter, r *http.Request, menuID string, cafeID int, body models.DiscountChange) {
+	if !config.RequiresApproval(models.ChangeFieldDiscount) || body.Discount < config.ApprovalDiscountMin {
+		if err := h.changes.UpdateDiscount(r.Context(), menuID, body); err != nil {
+			slog.ErrorContext(r.Context(), "DB error in UpdateDiscount", "err", err)
+			api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan diskon")
+			return
+		}
+		api.JSON(w, http.StatusOK, map[string]interface{}{
+			"message":  "Diskon disimpan",
+			"menu_id":  menuID,
+			"discount": body,
+		})
+		return
+	}
+
+	previous, err := h.changes.DiscountSnapshot(r.Context(), menuID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in UpdateDiscount", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengajukan perubahan")
+		return
+	}
+	request, err := h.changes.Submit(r.Context(), cafeID, models.ChangeEntityMenu, menuID, body, previous,
+		middleware.CurrentUser(r).ID, time.Now())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error submitting change request", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengajukan perubahan")
+		return
+	}
+	h.recordSubmitted(r, request, discountSubject(body))
+
+	api.JSON(w, http.StatusAccepted, map[string]interface{}{
+		"message":        fmt.Sprintf("Diskon %.0f%% menunggu persetujuan admin", body.Discount),
+		"menu_id":        menuID,
+		"change_request": request,
+	})
+}
+
+// ==============================
+// Riwayat permintaan perubahan cafe sendiri
+// GET /cafe/change-requests?status=pending
+// ==============================
+func (h *ChangeRequestHandler) CafeList(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+	h.list(w, r, cafeID)
+}
+
+// ==============================
+// Antrean persetujuan (super admin)
+// GET /admin/change-requests?status=pending&cafe_id=
+// ==============================
+func (h *ChangeRequestHandler) AdminList(w http.ResponseWriter, r *http.Request) {
+	cafeID := 0
+	if v := r.URL.Query().Get("cafe_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			api.Fail(w, r, http.StatusBadRequest, "cafe_id tidak valid")
+			return
+		}
+		cafeID = id
+	}
+	h.list(w, r, cafeID)
+}
+
+func (h *ChangeRequestHandler) list(w http.ResponseWriter, r *http.Request, cafeID int) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.ChangePending, models.ChangeApproved, models.ChangeRejected:
+	default:
+		api.Fail(w, r, http.StatusBadRequest, "status harus pending, approved atau rejected")
+		return
+	}
+	limit, offset, invalid := parsePaging(r)
+	if invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	list, total, err := h.changes.List(r.Context(), cafeID, status, limit, offset)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error listing change requests", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil permintaan perubahan")
+		return
+	}
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"change_requests": list,
+		"total":           total,
+		"limit":           limit,
+		"offset":          offset,
+	})
+}
+
+// ==============================
+// Setujui / tolak perubahan (super admin)
+// POST /admin/change-requests/{id}/approve
+// POST /admin/change-requests/{id}/reject  {"reason": "Alamat tidak sesuai izin usaha"}
+// ==============================
+func (h *ChangeRequestHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.Fail(w, r, http.StatusBadRequest, "ID permintaan tidak valid")
+		return
+	}
+
+	request, err := h.changes.Approve(r.Context(), id, middleware.CurrentUser(r).ID, time.Now())
+	if !h.reviewOK(w, r, err) {
+		return
+	}
+
+	audit.Record(r, audit.Entry{
+		Action:     "change_request.approved",
+		EntityType: request.EntityType,
+		EntityID:   request.EntityID,
+		Title:      fmt.Sprintf("Perubahan %s kafe %s disetujui", changeLabel(request), request.CafeName),
+		Subject:    "Perubahan " + changeLabel(request),
+		Before:     request.Previous,
+		After:      request.Changes,
+	})
+	h.notifyOwner(r.Context(), request, models.NotifyChangeApproved)
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"message":        "Perubahan disetujui dan diterapkan",
+		"change_request": request,
+	})
+}
+
+func (h *ChangeRequestHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.Fail(w, r, http.StatusBadRequest, "ID permintaan tidak valid")
+		return
+	}
+
+	var body struct {
+		Reason string `json:"reason" validate:"trim,required,max=500"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	request, err := h.changes.Reject(r.Context(), id, middleware.CurrentUser(r).ID, body.Reason, time.Now())
+	if !h.reviewOK(w, r, err) {
+		return
+	}
+
+	audit.Record(r, audit.Entry{
+		Action:     "change_request.rejected",
+		EntityType: request.EntityType,
+		EntityID:   request.EntityID,
+		Title:      fmt.Sprintf("Perubahan %s kafe %s ditolak", changeLabel(request), request.CafeName),
+		Subject:    body.Reason,
+		Before:     request.Previous,
+		After:      request.Changes,
+	})
+	h.notifyOwner(r.Context(), request, models.NotifyChangeRejected)
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"message":        "Perubahan ditolak",
+		"change_request": request,
+	})
+}
+
+// reviewOK menulis response error untuk hasil approve/reject
+func (h *ChangeRequestHandler) reviewOK(w http.ResponseWriter, r *http.Request, err error) bool {
+	switch err {
+	case nil:
+		return true
+	case repository.ErrChangeNotFound:
+		api.Fail(w, r, http.StatusNotFound, err.Error())
+	case repository.ErrChangeNotPending:
+		api.Fail(w, r, http.StatusConflict, err.Error())
+	default:
+		slog.ErrorContext(r.Context(), "DB error reviewing change request", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal memproses permintaan perubahan")
+	}
+	return false
+}
+
+// notifyOwner mengabarkan hasil review ke pemilik cafe (inbox, email, push)
+func (h *ChangeRequestHandler) notifyOwner(ctx context.Context, request *models.ChangeRequest, event string) {
+	ownerID, err := h.changes.OwnerID(ctx, request.CafeID)
+	if err == nil {
+		err = h.notify.Notify(ctx, ownerID, event, notify.Data{
+			"name":   request.CafeName,
+			"change": changeLabel(request),
+			"reason": request.Reason,
+		})
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Notify change request review error", "err", err)
+	}
+}
+
+// recordSubmitted mencatat pengajuan perubahan ke log aktivitas super admin
+func (h *ChangeRequestHandler) recordSubmitted(r *http.Request, request *models.ChangeRequest, subject string) {
+	audit.Record(r, audit.Entry{
+		Action:     "change_request.submitted",
+		EntityType: request.EntityType,
+		EntityID:   request.EntityID,
+		Title:      fmt.Sprintf("Kafe %s mengirimkan perubahan %s", request.CafeName, changeLabel(request)),
+		Subject:    subject,
+		Claim:      approvalClaim,
+		Before:     request.Previous,
+		After:      request.Changes,
+	})
+}
+
+// splitProfileChanges memisahkan field yang langsung diterapkan dari yang
+// butuh persetujuan. Koordinat ikut alamat karena keduanya satu lokasi.
+func splitProfileChanges(c models.ProfileChanges) (direct, queued models.ProfileChanges) {
+	direct, queued = c, models.ProfileChanges{}
+	if config.RequiresApproval(models.ChangeFieldNama) {
+		queued.Nama, direct.Nama = c.Nama, nil
+	}
+	if config.RequiresApproval(models.ChangeFieldAlamat) {
+		queued.Alamat, direct.Alamat = c.Alamat, nil
+		queued.Latitude, direct.Latitude = c.Latitude, nil
+		queued.Longitude, direct.Longitude = c.Longitude, nil
+	}
+	return direct, queued
+}
+
+// dropUnchanged mengosongkan field yang nilainya sama dengan data live
+func dropUnchanged(c *models.ProfileChanges, live models.ProfileChanges) {
+	same := func(a, b *string) bool { return a != nil && b != nil && *a == *b }
+	if same(c.Nama, live.Nama) {
+		c.Nama = nil
+	}
+	if same(c.Telepon, live.Telepon) {
+		c.Telepon = nil
+	}
+	if same(c.Deskripsi, live.Deskripsi) {
+		c.Deskripsi = nil
+	}
+	sameCoords := c.Latitude == nil || (live.Latitude != nil &&
+		*c.Latitude == *live.Latitude && *c.Longitude == *live.Longitude)
+	if same(c.Alamat, live.Alamat) && sameCoords {
+		c.Alamat, c.Latitude, c.Longitude = nil, nil, nil
+	}
+}
+
+// previousValues mengambil nilai live hanya untuk field yang diubah
+func previousValues(changed, live models.ProfileChanges) models.ProfileChanges {
+	prev := models.ProfileChanges{}
+	if changed.Nama != nil {
+		prev.Nama = live.Nama
+	}
+	if changed.Alamat != nil {
+		prev.Alamat = live.Alamat
+	}
+	if changed.Latitude != nil {
+		prev.Latitude, prev.Longitude = live.Latitude, live.Longitude
+	}
+	if changed.IzinUsaha != nil {
+		prev.IzinUsaha = live.IzinUsaha
+	}
+	return prev
+}
+
+// changeLabel meringkas field yang diubah, misal "Nama, Lokasi"
+func changeLabel(request *models.ChangeRequest) string {
+	if request.EntityType == models.ChangeEntityMenu {
+		return changeLabels["discount"]
+	}
+
+	var fields map[string]json.RawMessage
+	json.Unmarshal(request.Changes, &fields)
+
+	seen := map[string]bool{}
+	labels := []string{}
+	for field := range fields {
+		label, ok := changeLabels[field]
+		if !ok || seen[label] {
+			continue
+		}
+		seen[label] = true
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return strings.Join(labels, ", ")
+}
+
+// discountSubject menulis perihal diskon untuk log, misal "Diskon 20% sd 2025-09-30"
+func discountSubject(d models.DiscountChange) string {
+	subject := fmt.Sprintf("Diskon %.0f%%", d.Discount)
+	if d.EndDate != "" {
+		subject += " sd " + d.EndDate
+	}
+	return subject
+}
+
+// approvalFields mengembalikan field yang butuh persetujuan, terurut
+func approvalFields() []string {
+	fields := []string{}
+	for f := range config.ApprovalFields {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// validateProfileChanges memeriksa aturan antar-field yang tidak bisa
+// ditulis sebagai tag di models.ProfileChanges.
+func validateProfileChanges(c *models.ProfileChanges) *api.Error {
+	switch {
+	case (c.Latitude == nil) != (c.Longitude == nil):
+		return badRequest("latitude dan longitude harus diisi bersamaan")
+	case c.Latitude != nil && c.Alamat == nil:
+		return badRequest("Perubahan koordinat wajib disertai alamat")
+	case c.Latitude != nil:
+		return checkCoordinates(*c.Latitude, *c.Longitude)
+	}
+	return nil
+}
@@ -0,0 +1,436 @@
+package handlers
+
+import (
+	"backend/api"
+	"backend/audit"
+	"backend/chat"
+	"backend/middleware"
+	"backend/models"
+	"backend/repository"
+	"backend/validate"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultChatMessages = 50
+	maxChatMessages     = 200
+	// Komentar kosong dikirim berkala supaya proxy tidak menutup stream
+	chatHeartbeat = 25 * time.Second
+)
+
+var chatEntityPattern = regexp.MustCompile(`^[a-z_]{1,30}$`)
+
+type ChatHandler struct {
+	chats repository.ChatStore
+	hub   *chat.Hub
+}
+
+func NewChatHandler(chats repository.ChatStore, hub *chat.Hub) *ChatHandler {
+	return &ChatHandler{chats: chats, hub: hub}
+}
+
+// ==============================
+// Daftar percakapan milik user
+// GET /chat/threads?limit=20&offset=0
+// ==============================
+func (h *ChatHandler) ListThreads(w http.ResponseWriter, r *http.Request) {
+	limit, offset, invalid := parsePaging(r)
+	if invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	threads, err := h.chats.ListThreads(r.Context(), middleware.CurrentUser(r).ID, limit, offset)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in chat ListThreads", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil percakapan")
+		return
+	}
+	api.JSON(w, http.StatusOK, map[string]interface{}{"threads": threads})
+}
+
+// ==============================
+// Buat / buka percakapan
+// POST /chat/threads  {"subject": "Perubahan Lokasi", "entity_type": "change_request", "entity_id": "12", "participant_ids": [5], "message": "Halo"}
+// ==============================
+// Jika entity diisi dan user sudah punya percakapan untuk entitas itu,
+// percakapan lama dikembalikan (200) alih-alih membuat baru (201).
+// Percakapan yang dibuat akun cafe otomatis melibatkan semua super admin.
+func (h *ChatHandler) CreateThread(w http.ResponseWriter, r *http.Request) {
+	user := middleware.CurrentUser(r)
+
+	var body struct {
+		Subject        string `json:"subject" validate:"trim,required,max=200"`
+		EntityType     string `json:"entity_type"`
+		EntityID       string `json:"entity_id" validate:"trim,max=64"`
+		ParticipantIDs []int  `json:"participant_ids" validate:"max=20"`
+		Message        string `json:"message" validate:"trim,max=4000"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	switch {
+	case (body.EntityType == "") != (body.EntityID == ""):
+		api.Fail(w, r, http.StatusBadRequest, "entity_type dan entity_id harus diisi bersamaan")
+		return
+	case body.EntityType != "" && !chatEntityPattern.MatchString(body.EntityType):
+		api.Fail(w, r, http.StatusBadRequest, "entity_type tidak valid")
+		return
+	}
+
+	if body.EntityType != "" {
+		id, err := h.chats.FindByEntity(r.Context(), body.EntityType, body.EntityID, user.ID)
+		if err == nil {
+			h.writeThread(w, r, id, http.StatusOK)
+			return
+		}
+		if err != repository.ErrThreadNotFound {
+			slog.ErrorContext(r.Context(), "DB error in chat CreateThread", "err", err)
+			api.Fail(w, r, http.StatusInternalServerError, "Gagal membuat percakapan")
+			return
+		}
+	}
+
+	participants, err := h.chats.ChatUsers(r.Context(), body.ParticipantIDs)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in chat CreateThread", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal membuat percakapan")
+		return
+	}
+	if len(participants) != len(uniqueInts(body.ParticipantIDs)) {
+		api.Fail(w, r, http.StatusBadRequest, "Peserta chat harus akun admin atau cafe yang terdaftar")
+		return
+	}
+	if user.Role != models.RoleAdmin {
+		// Cafe hanya bisa memulai percakapan dengan super admin
+		admins, err := h.chats.AdminIDs(r.Context())
+		if err != nil {
+			slog.ErrorContext(r.Context(), "DB error in chat CreateThread", "err", err)
+			api.Fail(w, r, http.StatusInternalServerError, "Gagal membuat percakapan")
+			return
+		}
+		if !subsetOf(participants, admins) {
+			api.Fail(w, r, http.StatusForbidden, "Akun cafe hanya bisa mengobrol dengan super admin")
+			return
+		}
+		if len(participants) == 0 {
+			participants = admins
+		}
+	}
+
+	now := time.Now()
+	id, err := h.chats.CreateThread(r.Context(), body.Subject, body.EntityType, body.EntityID, user.ID, participants, now)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in chat CreateThread", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal membuat percakapan")
+		return
+	}
+	audit.Record(r, audit.Entry{
+		Action:     "chat.thread_created",
+		EntityType: "chat_thread",
+		EntityID:   strconv.Itoa(id),
+		Title:      fmt.Sprintf("%s membuka percakapan %q", user.Username, body.Subject),
+		Subject:    body.Subject,
+	})
+
+	members := append([]int{user.ID}, participants...)
+	h.hub.Publish(members, models.ChatEvent{Type: models.ChatEventThread, ThreadID: id})
+	if body.Message != "" {
+		if _, err := h.post(r.Context(), id, user.ID, body.Message, now); err != nil {
+			slog.ErrorContext(r.Context(), "DB error posting first chat message", "err", err)
+		}
+	}
+
+	h.writeThread(w, r, id, http.StatusCreated)
+}
+
+// ==============================
+// Detail percakapan + peserta & read receipt
+// GET /chat/threads/{id}
+// ==============================
+func (h *ChatHandler) GetThread(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.requireThread(w, r)
+	if !ok {
+		return
+	}
+	h.writeThread(w, r, id, http.StatusOK)
+}
+
+func (h *ChatHandler) writeThread(w http.ResponseWriter, r *http.Request, id, status int) {
+	thread, err := h.chats.GetThread(r.Context(), id, middleware.CurrentUser(r).ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in chat GetThread", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil percakapan")
+		return
+	}
+	api.JSON(w, status, thread)
+}
+
+// ==============================
+// Pesan dalam percakapan (juga dipakai untuk polling)
+// GET /chat/threads/{id}/messages?after_id=120   -> pesan baru setelah id 120
+// GET /chat/threads/{id}/messages?before_id=80   -> pesan lebih lama
+// ==============================
+func (h *ChatHandler) Messages(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.requireThread(w, r)
+	if !ok {
+		return
+	}
+
+	params := r.URL.Query()
+	var afterID, beforeID int64
+	for name, dst := range map[string]*int64{"after_id": &afterID, "before_id": &beforeID} {
+		if v := params.Get(name); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n < 0 {
+				api.Failf(w, r, http.StatusBadRequest, "%s tidak valid", name)
+				return
+			}
+			*dst = n
+		}
+	}
+	limit := defaultChatMessages
+	if v := params.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxChatMessages {
+			api.Failf(w, r, http.StatusBadRequest, "limit harus di antara 1 dan %d", maxChatMessages)
+			return
+		}
+		limit = n
+	}
+
+	messages, err := h.chats.Messages(r.Context(), id, afterID, beforeID, limit)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in chat Messages", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil pesan")
+		return
+	}
+	api.JSON(w, http.StatusOK, map[string]interface{}{"messages": messages})
+}
+
+// ==============================
+// Kirim pesan
+// POST /chat/threads/{id}/messages  {"body": "Halo, lokasi sudah kami cek"}
+// ==============================
+func (h *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
+	// Isi chat tidak dicatat ke log aktivitas
+	audit.Skip(r)
+
+	id, ok := h.requireThread(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Body string `json:"body" validate:"trim,required,max=4000"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	message, err := h.post(r.Context(), id, middleware.CurrentUser(r).ID, body.Body, time.Now())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in chat SendMessage", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengirim pesan")
+		return
+	}
+
+	api.JSON(w, http.StatusCreated, message)
+}
+
+// post menyimpan pesan lalu mengirimnya ke semua peserta yang tersambung
+func (h *ChatHandler) post(ctx context.Context, threadID, senderID int, text string, now time.Time) (*models.ChatMessage, error) {
+	message, err := h.chats.PostMessage(ctx, threadID, senderID, text, now)
+	if err != nil {
+		return nil, err
+	}
+	h.publish(ctx, threadID, models.ChatEvent{Type: models.ChatEventMessage, ThreadID: threadID, Message: message})
+	return message, nil
+}
+
+// ==============================
+// Tandai sudah dibaca (read receipt)
+// POST /chat/threads/{id}/read  {"message_id": 120}
+// ==============================
+func (h *ChatHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	audit.Skip(r)
+
+	id, ok := h.requireThread(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		MessageID int64 `json:"message_id" validate:"required,gt=0"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	user := middleware.CurrentUser(r)
+	lastRead, err := h.chats.MarkRead(r.Context(), id, user.ID, body.MessageID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in chat MarkRead", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menandai pesan")
+		return
+	}
+	h.publish(r.Context(), id, models.ChatEvent{Type: models.ChatEventRead, ThreadID: id, UserID: user.ID, MessageID: lastRead})
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{"thread_id": id, "last_read_message_id": lastRead})
+}
+
+func (h *ChatHandler) publish(ctx context.Context, threadID int, e models.ChatEvent) {
+	members, err := h.chats.ParticipantIDs(ctx, threadID)
+	if err != nil {
+		slog.ErrorContext(ctx, "DB error loading chat participants", "err", err)
+		return
+	}
+	h.hub.Publish(members, e)
+}
+
+// ==============================
+// Jumlah pesan belum dibaca
+// GET /chat/unread
+// ==============================
+func (h *ChatHandler) Unread(w http.ResponseWriter, r *http.Request) {
+	counts, err := h.chats.UnreadCounts(r.Context(), middleware.CurrentUser(r).ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in chat Unread", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil jumlah pesan")
+		return
+	}
+
+	total := 0
+	threads := map[string]int{}
+	for id, n := range counts {
+		total += n
+		threads[strconv.Itoa(id)] = n
+	}
+	api.JSON(w, http.StatusOK, map[string]interface{}{"total": total, "threads": threads})
+}
+
+// ==============================
+// Stream event chat (Server-Sent Events)
+// GET /chat/stream
+// ==============================
+// Setiap event berisi JSON models.ChatEvent. Client yang tidak bisa
+// memakai stream cukup polling /chat/unread dan
+// /chat/threads/{id}/messages?after_id=.
+func (h *ChatHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		api.Fail(w, r, http.StatusNotImplemented, "Streaming tidak didukung, gunakan polling")
+		return
+	}
+
+	events, unsubscribe := h.hub.Subscribe(middleware.CurrentUser(r).ID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Client menyusul lewat polling jika koneksi putus
+	fmt.Fprint(w, "retry: 5000\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(chatHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-h.hub.Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case e := <-events:
+			data, err := json.Marshal(e)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "Chat event encode error", "err", err)
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+		}
+		flusher.Flush()
+	}
+}
+
+// requireThread memastikan user boleh membuka thread di path {id}.
+// Super admin boleh membuka semua percakapan dan otomatis ikut sebagai
+// peserta supaya read receipt & jumlah belum dibaca tercatat.
+func (h *ChatHandler) requireThread(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.Fail(w, r, http.StatusBadRequest, "ID percakapan tidak valid")
+		return 0, false
+	}
+
+	user := middleware.CurrentUser(r)
+	member, err := h.chats.IsParticipant(r.Context(), id, user.ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error checking chat participant", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil percakapan")
+		return 0, false
+	}
+	if member {
+		return id, true
+	}
+
+	if user.Role == models.RoleAdmin {
+		if _, err := h.chats.GetThread(r.Context(), id, user.ID); err == nil {
+			if err := h.chats.AddParticipant(r.Context(), id, user.ID, time.Now()); err != nil {
+				slog.ErrorContext(r.Context(), "DB error joining chat thread", "err", err)
+				api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil percakapan")
+				return 0, false
+			}
+			return id, true
+		} else if err != repository.ErrThreadNotFound {
+			slog.ErrorContext(r.Context(), "DB error loading chat thread", "err", err)
+			api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil percakapan")
+			return 0, false
+		}
+	}
+
+	api.Fail(w, r, http.StatusNotFound, "Percakapan tidak ditemukan")
+	return 0, false
+}
+
+func uniqueInts(ids []int) []int {
+	seen := map[int]bool{}
+	out := []int{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// subsetOf bernilai true jika semua ids ada di set
+func subsetOf(ids, set []int) bool {
+	in := map[int]bool{}
+	for _, id := range set {
+		in[id] = true
+	}
+	for _, id := range ids {
+		if !in[id] {
+			return false
+		}
+	}
+	return true
+}
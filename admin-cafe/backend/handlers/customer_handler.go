@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"backend/api"
+	"backend/middleware"
+	"backend/passwords"
+	"backend/repository"
+	"backend/validate"
+	"database/sql"
+	"log/slog"
+	"net/http"
+)
+
+type CustomerHandler struct {
+	repo repository.UserStore
+}
+
+func NewCustomerHandler(repo repository.UserStore) *CustomerHandler {
+	return &CustomerHandler{repo: repo}
+}
+
+// ==============================
+// Profil customer yang sedang login
+// ==============================
+func (h *CustomerHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	user := middleware.CurrentUser(r)
+
+	profile, err := h.repo.GetCustomerProfile(r.Context(), user.ID)
+	if err == sql.ErrNoRows {
+		api.Fail(w, r, http.StatusNotFound, "Akun tidak ditemukan")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in GetProfile", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil profil")
+		return
+	}
+
+	api.JSON(w, http.StatusOK, profile)
+}
+
+// ==============================
+// Update nama tampilan & nomor HP
+// ==============================
+func (h *CustomerHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	user := middleware.CurrentUser(r)
+
+	var body struct {
+		DisplayName string `json:"display_name" validate:"trim,required,min=2,max=100"`
+		Phone       string `json:"phone" validate:"trim,phone"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	if err := h.repo.UpdateCustomerProfile(r.Context(), user.ID, body.DisplayName, body.Phone); err != nil {
+		slog.ErrorContext(r.Context(), "DB error in UpdateProfile", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan profil")
+		return
+	}
+
+	h.GetProfile(w, r)
+}
+
+// ==============================
+// Upload avatar customer
+// ==============================
+func (h *CustomerHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	user := middleware.CurrentUser(r)
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize+(1<<20))
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		api.Fail(w, r, http.StatusBadRequest, "File terlalu besar (maks 10 MB)")
+		return
+	}
+
+	name, err := saveUpload(r, "avatar", imageTypes)
+	if err == errInvalidFileType {
+		api.Fail(w, r, http.StatusBadRequest, "Avatar harus berupa gambar JPG, PNG atau WEBP")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Upload avatar error", "err", err)
+		api.Fail(w, r, http.StatusBadRequest, "Gagal menyimpan avatar")
+		return
+	}
+
+	avatar := "/uploads/" + name
+	old, err := h.repo.UpdateAvatar(r.Context(), user.ID, avatar)
+	if err != nil {
+		removeUpload(avatar)
+		slog.ErrorContext(r.Context(), "DB error in UploadAvatar", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan avatar")
+		return
+	}
+	removeUpload(old)
+
+	api.JSON(w, http.StatusOK, map[string]string{
+		"message": "Avatar diperbarui",
+		"avatar":  avatar,
+	})
+}
+
+// ==============================
+// Hapus akun customer
+// ==============================
+func (h *CustomerHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	user := middleware.CurrentUser(r)
+
+	var body struct {
+		Password string `json:"password" validate:"required"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	account, err := h.repo.GetUserByUsername(r.Context(), user.Username)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in DeleteAccount", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menghapus akun")
+		return
+	}
+	if !passwords.Verify(account.Password, body.Password) {
+		api.Fail(w, r, http.StatusUnauthorized, "Password salah")
+		return
+	}
+
+	profile, err := h.repo.GetCustomerProfile(r.Context(), user.ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in DeleteAccount", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menghapus akun")
+		return
+	}
+
+	if err := h.repo.DeleteCustomer(r.Context(), user.ID); err != nil {
+		slog.ErrorContext(r.Context(), "DB error in DeleteAccount", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menghapus akun")
+		return
+	}
+	removeUpload(profile.Avatar)
+
+	api.JSON(w, http.StatusOK, map[string]string{"message": "Akun berhasil dihapus"})
+}
@@ -0,0 +1,141 @@
+package handlers_test
+
+import (
+	"backend/api"
+	"backend/handlers"
+	"backend/models"
+	"backend/passwords"
+	"backend/repository/memory"
+	"backend/routes"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var testLocation = time.FixedZone("WIB", 7*60*60)
+
+func init() {
+	passwords.Cost = bcrypt.MinCost
+}
+
+func testNow() time.Time { return time.Now().In(testLocation) }
+
+func itoa(n int) string { return strconv.Itoa(n) }
+
+// env adalah router lengkap di atas fake in-memory
+type env struct {
+	users    *memory.Users
+	sessions *memory.Sessions
+	cafes    *memory.CafeProfiles
+	details  *memory.CafeDetails
+	menus    *memory.Menus
+	reviews  *memory.Reviews
+	tokens   *memory.Tokens
+	notifier *memory.Notifier
+	mailer   *memory.Mailer
+	router   http.Handler
+}
+
+func newEnv(t *testing.T) *env {
+	t.Helper()
+	users := memory.NewUsers()
+	e := &env{
+		users:    users,
+		sessions: memory.NewSessions(users),
+		cafes:    memory.NewCafeProfiles(),
+		details:  memory.NewCafeDetails(testLocation),
+		menus:    memory.NewMenus(),
+		reviews:  memory.NewReviews(testLocation),
+		tokens:   memory.NewTokens(),
+		notifier: &memory.Notifier{},
+		mailer:   &memory.Mailer{},
+	}
+	e.router = routes.SetupRoutes(routes.Handlers{
+		Auth:        handlers.NewAuthHandler(e.users, e.sessions, e.tokens, e.mailer),
+		Cafe:        handlers.NewCafeHandler(e.users, e.notifier),
+		CafeDetails: handlers.NewCafeDetailsHandler(e.details, e.cafes),
+		Menu:        handlers.NewMenuHandler(e.menus, e.cafes),
+		Review:      handlers.NewReviewHandler(e.reviews, e.cafes, e.users, e.notifier),
+	}, e.sessions)
+	return e
+}
+
+// addUser menyimpan akun berpassword "rahasia123" dan mengembalikan ID-nya
+func (e *env) addUser(username, role string, verified bool) int {
+	return e.users.Add(memory.User{
+		User:          models.User{Username: username, Password: mustHash("rahasia123"), Email: username + "@mail.com", Role: role, Verified: verified},
+		EmailVerified: true,
+	})
+}
+
+func mustHash(password string) string {
+	hash, err := passwords.Hash(password)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+}
+
+// addCafe membuat akun cafe beserta profilnya dan mengembalikan ID akun &
+// ID profil
+func (e *env) addCafe(username string, verified bool) (userID, cafeID int) {
+	userID = e.addUser(username, models.RoleCafe, verified)
+	cafeID = e.cafes.Add(models.CafeProfile{UserID: &userID, Nama: username, Alamat: "Jl. Test", Verified: verified})
+	return userID, cafeID
+}
+
+// login membuat session untuk user dan mengembalikan token-nya
+func (e *env) login(t *testing.T, userID int) string {
+	t.Helper()
+	token, _, err := e.sessions.Create(context.Background(), userID, time.Hour)
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	return token
+}
+
+// do mengirim request JSON ke router. token boleh kosong.
+func (e *env) do(t *testing.T, method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatalf("encode body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	e.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// expect memastikan status response lalu men-decode body ke v (jika ada)
+func expect(t *testing.T, rec *httptest.ResponseRecorder, status int, v interface{}) {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status = %d, mau %d; body: %s", rec.Code, status, rec.Body)
+	}
+	if v != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("decode response: %v; body: %s", err, rec.Body)
+		}
+	}
+}
+
+// errorResponse adalah body error dari package api
+type errorResponse struct {
+	Error  string           `json:"error"`
+	Code   string           `json:"code"`
+	Fields []api.FieldError `json:"fields"`
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"backend/api"
+	"backend/metrics"
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Batas waktu ping database untuk readiness check
+const readyTimeout = 2 * time.Second
+
+type HealthHandler struct {
+	db *sql.DB
+}
+
+func NewHealthHandler(db *sql.DB) *HealthHandler {
+	return &HealthHandler{db: db}
+}
+
+// ==============================
+// Liveness: proses masih melayani request
+// GET /healthz
+// ==============================
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	api.JSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// ==============================
+// Readiness: database, folder uploads & heartbeat background job
+// GET /readyz
+// ==============================
+// Detail error hanya ditulis ke log, response cukup status per cek.
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	type jobCheck struct {
+		Name     string    `json:"name"`
+		Status   string    `json:"status"`
+		LastBeat time.Time `json:"last_beat"`
+	}
+	checks := map[string]string{"database": "ok", "uploads": "ok", "jobs": "ok"}
+	ready := true
+
+	ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+	defer cancel()
+	if err := h.db.PingContext(ctx); err != nil {
+		slog.ErrorContext(r.Context(), "Readiness: database ping failed", "err", err)
+		checks["database"] = "fail"
+		ready = false
+	}
+
+	if err := checkWritable(uploadDir); err != nil {
+		slog.ErrorContext(r.Context(), "Readiness: uploads not writable", "err", err)
+		checks["uploads"] = "fail"
+		ready = false
+	}
+
+	now := time.Now()
+	jobs := []jobCheck{}
+	for _, job := range metrics.Jobs() {
+		status := "ok"
+		if job.Stale(now) {
+			slog.ErrorContext(r.Context(), "Readiness: background job stale", "job", job.Name,
+				"last_beat", job.LastBeat, "last_error", job.LastErr)
+			status = "stale"
+			checks["jobs"] = "fail"
+			ready = false
+		}
+		jobs = append(jobs, jobCheck{Name: job.Name, Status: status, LastBeat: job.LastBeat})
+	}
+
+	status, code := "ok", http.StatusOK
+	if !ready {
+		status, code = "unavailable", http.StatusServiceUnavailable
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	api.JSON(w, code, map[string]interface{}{
+		"status": status,
+		"checks": checks,
+		"jobs":   jobs,
+	})
+}
+
+// checkWritable memastikan file baru bisa dibuat di dir
+func checkWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".readyz-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
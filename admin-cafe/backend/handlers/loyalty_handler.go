@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"backend/api"
+	"backend/audit"
+	"backend/middleware"
+	"backend/models"
+	"backend/repository"
+	"backend/validate"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type LoyaltyHandler struct {
+	loyalty repository.LoyaltyStore
+	cafes   repository.CafeProfileStore
+}
+
+func NewLoyaltyHandler(loyalty repository.LoyaltyStore, cafes repository.CafeProfileStore) *LoyaltyHandler {
+	return &LoyaltyHandler{loyalty: loyalty, cafes: cafes}
+}
+
+// ==============================
+// Saldo poin & stamp customer di semua cafe
+// GET /customer/loyalty
+// ==============================
+func (h *LoyaltyHandler) Balances(w http.ResponseWriter, r *http.Request) {
+	user := middleware.CurrentUser(r)
+
+	balances, err := h.loyalty.Balances(r.Context(), user.ID, time.Now())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in loyalty Balances", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil saldo poin")
+		return
+	}
+	api.JSON(w, http.StatusOK, map[string]interface{}{"balances": balances})
+}
+
+// ==============================
+// Detail loyalty customer di satu cafe
+// GET /customer/loyalty/{cafeId}?limit=20&offset=0
+// ==============================
+func (h *LoyaltyHandler) CafeBalance(w http.ResponseWriter, r *http.Request) {
+	user := middleware.CurrentUser(r)
+
+	cafeID, ok := h.cafeFromPath(w, r)
+	if !ok {
+		return
+	}
+	limit, offset, invalid := parsePaging(r)
+	if invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	balance, err := h.loyalty.Balance(r.Context(), user.ID, cafeID, time.Now())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in CafeBalance", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil saldo poin")
+		return
+	}
+	settings, err := h.loyalty.GetSettings(r.Context(), cafeID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in CafeBalance", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil saldo poin")
+		return
+	}
+	rewards, err := h.loyalty.ListRewards(r.Context(), cafeID, true)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in CafeBalance", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil saldo poin")
+		return
+	}
+	history, err := h.loyalty.History(r.Context(), cafeID, user.ID, limit, offset)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in CafeBalance", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil riwayat poin")
+		return
+	}
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"balance":  balance,
+		"settings": settings,
+		"rewards":  rewards,
+		"history":  history,
+	})
+}
+
+// ==============================
+// Tukar poin dengan hadiah
+// POST /customer/loyalty/{cafeId}/redeem  {"reward_id": 3}
+// ==============================
+func (h *LoyaltyHandler) RedeemReward(w http.ResponseWriter, r *http.Request) {
+	user := middleware.CurrentUser(r)
+
+	cafeID, ok := h.cafeFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		RewardID int `json:"reward_id" validate:"required,gt=0"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	t, err := h.loyalty.RedeemReward(r.Context(), user.ID, cafeID, body.RewardID, time.Now())
+	h.writeRedemption(w, r, t, err)
+}
+
+// ==============================
+// Tukar kartu stamp yang sudah penuh
+// POST /customer/loyalty/{cafeId}/stamps/redeem
+// ==============================
+func (h *LoyaltyHandler) RedeemStamps(w http.ResponseWriter, r *http.Request) {
+	user := middleware.CurrentUser(r)
+
+	cafeID, ok := h.cafeFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	t, err := h.loyalty.RedeemStamps(r.Context(), user.ID, cafeID, time.Now())
+	h.writeRedemption(w, r, t, err)
+}
+
+func (h *LoyaltyHandler) writeRedemption(w http.ResponseWriter, r *http.Request, t *models.LoyaltyTransaction, err error) {
+	switch err {
+	case nil:
+	case repository.ErrRewardNotFound:
+		api.Fail(w, r, http.StatusNotFound, err.Error())
+		return
+	case repository.ErrInsufficientPoints, repository.ErrInsufficientStamps,
+		repository.ErrLoyaltyDisabled, repository.ErrStampCardDisabled:
+		api.Fail(w, r, http.StatusConflict, err.Error())
+		return
+	default:
+		slog.ErrorContext(r.Context(), "DB error in loyalty redeem", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menukar poin")
+		return
+	}
+
+	api.JSON(w, http.StatusCreated, map[string]interface{}{
+		"message":     "Penukaran berhasil, tunjukkan kode ke kasir",
+		"transaction": t,
+	})
+}
+
+// cafeFromPath membaca {cafeId} dan memastikan cafe-nya ada
+func (h *LoyaltyHandler) cafeFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	cafeID, err := strconv.Atoi(mux.Vars(r)["cafeId"])
+	if err != nil {
+		api.Fail(w, r, http.StatusBadRequest, "ID cafe tidak valid")
+		return 0, false
+	}
+	if _, err := h.cafes.GetByID(r.Context(), cafeID); err == sql.ErrNoRows {
+		api.Fail(w, r, http.StatusNotFound, "Cafe tidak ditemukan")
+		return 0, false
+	} else if err != nil {
+		slog.ErrorContext(r.Context(), "DB error resolving cafe", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil data cafe")
+		return 0, false
+	}
+	return cafeID, true
+}
+
+// ==============================
+// Aturan loyalty cafe
+// GET /cafe/loyalty/settings
+// PUT /cafe/loyalty/settings
+// {"enabled": true, "points_per_unit": 1, "amount_unit": 10000, "point_expiry_days": 365, "stamp_enabled": true, "stamps_required": 9, "stamp_reward": "1 kopi gratis"}
+// ==============================
+func (h *LoyaltyHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	settings, err := h.loyalty.GetSettings(r.Context(), cafeID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in loyalty GetSettings", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil pengaturan loyalty")
+		return
+	}
+	api.JSON(w, http.StatusOK, settings)
+}
+
+func (h *LoyaltyHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	var settings models.LoyaltySettings
+	if invalid := validate.DecodeJSON(r, &settings); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+	settings.CafeID = cafeID
+
+	// Aturan yang bergantung pada field lain tidak bisa ditulis di tag
+	if settings.StampEnabled && settings.StampReward == "" {
+		api.Fail(w, r, http.StatusBadRequest, "stamp_reward wajib diisi jika kartu stamp aktif")
+		return
+	}
+	if settings.StampReward == "" {
+		settings.StampReward = "1 minuman gratis"
+	}
+
+	before, err := h.loyalty.GetSettings(r.Context(), cafeID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in loyalty UpdateSettings", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan pengaturan loyalty")
+		return
+	}
+	if err := h.loyalty.SaveSettings(r.Context(), &settings); err != nil {
+		slog.ErrorContext(r.Context(), "DB error in loyalty UpdateSettings", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan pengaturan loyalty")
+		return
+	}
+
+	audit.Record(r, audit.Entry{
+		Action:     "loyalty.settings_updated",
+		EntityType: "cafe",
+		EntityID:   strconv.Itoa(cafeID),
+		Title:      "Pengaturan loyalty diperbarui",
+		Before:     before,
+		After:      settings,
+	})
+	api.JSON(w, http.StatusOK, settings)
+}
+
+// ==============================
+// Katalog hadiah cafe
+// GET /cafe/loyalty/rewards
+// POST /cafe/loyalty/rewards, PUT /cafe/loyalty/rewards/{id}
+// {"name": "Croissant gratis", "points_cost": 50, "active": true}
+// ==============================
+func (h *LoyaltyHandler) ListRewards(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	rewards, err := h.loyalty.ListRewards(r.Context(), cafeID, false)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in ListRewards", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil hadiah")
+		return
+	}
+	api.JSON(w, http.StatusOK, map[string]interface{}{"rewards": rewards})
+}
+
+func (h *LoyaltyHandler) SaveReward(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Name       string `json:"name" validate:"trim,required,max=255"`
+		PointsCost int    `json:"points_cost" validate:"gt=0"`
+		Active     *bool  `json:"active"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	reward := &models.LoyaltyReward{
+		CafeID:     cafeID,
+		Name:       body.Name,
+		PointsCost: body.PointsCost,
+		Active:     body.Active == nil || *body.Active,
+	}
+
+	status := http.StatusOK
+	if idParam, isUpdate := mux.Vars(r)["id"]; isUpdate {
+		id, err := strconv.Atoi(idParam)
+		if err != nil {
+			api.Fail(w, r, http.StatusBadRequest, "ID hadiah tidak valid")
+			return
+		}
+		reward.ID = id
+	} else {
+		status = http.StatusCreated
+	}
+
+	err := h.loyalty.SaveReward(r.Context(), reward)
+	if err == repository.ErrRewardNotFound {
+		api.Fail(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in SaveReward", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan hadiah")
+		return
+	}
+
+	api.JSON(w, status, reward)
+}
+
+// ==============================
+// Riwayat transaksi loyalty cafe
+// GET /cafe/loyalty/transactions?user_id=&limit=50&offset=0
+// ==============================
+func (h *LoyaltyHandler) CafeTransactions(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+	limit, offset, invalid := parsePaging(r)
+	if invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+	userID := 0
+	if v := r.URL.Query().Get("user_id"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			api.Fail(w, r, http.StatusBadRequest, "user_id tidak valid")
+			return
+		}
+		userID = n
+	}
+
+	history, err := h.loyalty.History(r.Context(), cafeID, userID, limit, offset)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in CafeTransactions", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil riwayat poin")
+		return
+	}
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"transactions": history,
+		"limit":        limit,
+		"offset":       offset,
+	})
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"backend/api"
+	"backend/menus"
+	"backend/repository"
+	"backend/validate"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+type MenuHandler struct {
+	menus menus.Store
+	cafes repository.CafeProfileStore
+}
+
+func NewMenuHandler(menuService menus.Store, cafes repository.CafeProfileStore) *MenuHandler {
+	return &MenuHandler{menus: menuService, cafes: cafes}
+}
+
+// ==============================
+// Daftar menu cafe sendiri
+// GET /cafe/menus?category=&status=Aktif&q=
+// ==============================
+func (h *MenuHandler) List(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	f := menus.Filter{
+		Category: strings.TrimSpace(q.Get("category")),
+		Status:   q.Get("status"),
+		Search:   strings.TrimSpace(q.Get("q")),
+	}
+	switch f.Status {
+	case "", menus.StatusActive, menus.StatusInactive:
+	default:
+		api.Fail(w, r, http.StatusBadRequest, "status harus Aktif atau Nonaktif")
+		return
+	}
+
+	list, err := h.menus.List(r.Context(), cafeID, f)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error listing menus", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil menu")
+		return
+	}
+	api.JSON(w, http.StatusOK, map[string]interface{}{"menus": list})
+}
+
+// ==============================
+// Detail menu
+// GET /cafe/menus/{id}
+// ==============================
+func (h *MenuHandler) Get(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	m, err := h.menus.Get(r.Context(), cafeID, menuID(r))
+	if err != nil {
+		menuError(w, r, err, "Gagal mengambil menu")
+		return
+	}
+	api.JSON(w, http.StatusOK, m)
+}
+
+// ==============================
+// Tambah menu
+// POST /cafe/menus  {"name": "Kopi Susu", "price": 18000, "category": "Minuman", "status": "Aktif", "img": "/uploads/..."}
+// ==============================
+// Diskon diatur lewat PUT /cafe/menus/{id}/discount.
+func (h *MenuHandler) Create(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	var body menus.NewMenu
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	m, err := h.menus.Create(r.Context(), cafeID, body)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error creating menu", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan menu")
+		return
+	}
+	api.JSON(w, http.StatusCreated, m)
+}
+
+// ==============================
+// Ubah menu
+// PUT /cafe/menus/{id}  {"status": "Nonaktif"}
+// ==============================
+// Hanya field yang dikirim yang diubah. Gambar lama dihapus jika diganti.
+func (h *MenuHandler) Update(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	var body menus.Changes
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	id := menuID(r)
+	before, err := h.menus.Get(r.Context(), cafeID, id)
+	if err != nil {
+		menuError(w, r, err, "Gagal menyimpan menu")
+		return
+	}
+	m, err := h.menus.Update(r.Context(), cafeID, id, body)
+	if err != nil {
+		menuError(w, r, err, "Gagal menyimpan menu")
+		return
+	}
+	if before.Img != m.Img {
+		removeUpload(before.Img)
+	}
+	api.JSON(w, http.StatusOK, m)
+}
+
+// ==============================
+// Hapus menu
+// DELETE /cafe/menus/{id}
+// ==============================
+func (h *MenuHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	m, err := h.menus.Delete(r.Context(), cafeID, menuID(r))
+	if err != nil {
+		menuError(w, r, err, "Gagal menghapus menu")
+		return
+	}
+	removeUpload(m.Img)
+	api.JSON(w, http.StatusOK, map[string]string{"message": "Menu dihapus"})
+}
+
+// ==============================
+// Upload gambar menu
+// POST /cafe/upload  (multipart: image)
+// ==============================
+// URL hasil upload lalu dikirim sebagai img saat membuat/mengubah menu.
+func (h *MenuHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireCafeID(w, r, h.cafes); !ok {
+		return
+	}
+
+	url, ok := receiveImage(w, r, "image")
+	if !ok {
+		return
+	}
+	api.JSON(w, http.StatusCreated, map[string]string{"url": url})
+}
+
+func menuID(r *http.Request) string {
+	return strings.ToLower(mux.Vars(r)["id"])
+}
+
+func menuError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	if err == menus.ErrNotFound {
+		api.Fail(w, r, http.StatusNotFound, "Menu tidak ditemukan")
+		return
+	}
+	slog.ErrorContext(r.Context(), "DB error in menu handler", "err", err)
+	api.Fail(w, r, http.StatusInternalServerError, message)
+}
@@ -0,0 +1,85 @@
+package handlers_test
+
+import (
+	"backend/menus"
+	"backend/models"
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestMenuCRUD(t *testing.T) {
+	e := newEnv(t)
+	userID, cafeID := e.addCafe("kopikita", true)
+	token := e.login(t, userID)
+
+	var created menus.Menu
+	expect(t, e.do(t, "POST", "/cafe/menus", token, map[string]interface{}{
+		"name": "  Kopi Susu ", "price": 18000, "category": "Minuman",
+	}), http.StatusCreated, &created)
+	if created.CafeID != cafeID || created.Name != "Kopi Susu" || created.Status != menus.StatusActive {
+		t.Fatalf("menu baru = %+v", created)
+	}
+
+	var updated menus.Menu
+	expect(t, e.do(t, "PUT", "/cafe/menus/"+created.ID, token, map[string]string{"status": menus.StatusInactive}),
+		http.StatusOK, &updated)
+	if updated.Status != menus.StatusInactive || updated.Name != "Kopi Susu" || updated.Price != 18000 {
+		t.Fatalf("menu diubah = %+v", updated)
+	}
+
+	var list struct {
+		Menus []menus.Menu `json:"menus"`
+	}
+	expect(t, e.do(t, "GET", "/cafe/menus?status=Nonaktif", token, nil), http.StatusOK, &list)
+	if len(list.Menus) != 1 || list.Menus[0].ID != created.ID {
+		t.Fatalf("daftar menu = %+v", list.Menus)
+	}
+
+	expect(t, e.do(t, "DELETE", "/cafe/menus/"+created.ID, token, nil), http.StatusOK, nil)
+	expect(t, e.do(t, "GET", "/cafe/menus/"+created.ID, token, nil), http.StatusNotFound, nil)
+}
+
+func TestMenuScopedToOwnCafe(t *testing.T) {
+	e := newEnv(t)
+	_, cafeID := e.addCafe("kopikita", true)
+	otherUserID, _ := e.addCafe("senja", true)
+	id := e.menus.Add(menus.Menu{CafeID: cafeID, Name: "Latte", Price: 25000, Category: "Kopi", Status: menus.StatusActive})
+
+	other := e.login(t, otherUserID)
+	expect(t, e.do(t, "GET", "/cafe/menus/"+id, other, nil), http.StatusNotFound, nil)
+	expect(t, e.do(t, "PUT", "/cafe/menus/"+id, other, map[string]string{"name": "Punyaku"}), http.StatusNotFound, nil)
+	expect(t, e.do(t, "DELETE", "/cafe/menus/"+id, other, nil), http.StatusNotFound, nil)
+	if m, err := e.menus.Get(context.Background(), cafeID, id); err != nil || m.Name != "Latte" {
+		t.Fatalf("menu cafe asal = %+v, %v", m, err)
+	}
+
+	// Super admin memilih cafe lewat ?cafe_id=
+	admin := e.login(t, e.addUser("admin", models.RoleAdmin, true))
+	expect(t, e.do(t, "GET", "/cafe/menus/"+id, admin, nil), http.StatusBadRequest, nil)
+	expect(t, e.do(t, "GET", "/cafe/menus/"+id+"?cafe_id="+itoa(cafeID), admin, nil), http.StatusOK, nil)
+
+	// Customer tidak boleh mengelola menu
+	customer := e.login(t, e.addUser("budi", models.RoleCustomer, true))
+	expect(t, e.do(t, "GET", "/cafe/menus", customer, nil), http.StatusForbidden, nil)
+}
+
+func TestMenuValidation(t *testing.T) {
+	e := newEnv(t)
+	userID, _ := e.addCafe("kopikita", true)
+	token := e.login(t, userID)
+
+	var res errorResponse
+	expect(t, e.do(t, "POST", "/cafe/menus", token, map[string]interface{}{
+		"name": " ", "price": -1, "category": "Minuman", "status": "Habis",
+	}), http.StatusBadRequest, &res)
+	fields := map[string]bool{}
+	for _, f := range res.Fields {
+		fields[f.Field] = true
+	}
+	if res.Code != "validation_failed" || !fields["name"] || !fields["price"] || !fields["status"] || fields["category"] {
+		t.Fatalf("response = %+v", res)
+	}
+
+	expect(t, e.do(t, "GET", "/cafe/menus?status=Habis", token, nil), http.StatusBadRequest, nil)
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"backend/api"
+	"backend/audit"
+	"backend/middleware"
+	"backend/models"
+	"backend/notify"
+	"backend/repository"
+	"backend/validate"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type NotificationHandler struct {
+	notifications repository.NotificationStore
+}
+
+func NewNotificationHandler(notifications repository.NotificationStore) *NotificationHandler {
+	return &NotificationHandler{notifications: notifications}
+}
+
+// ==============================
+// Inbox notifikasi user yang login
+// GET /notifications?unread=true&limit=50&offset=0
+// ==============================
+func (h *NotificationHandler) List(w http.ResponseWriter, r *http.Request) {
+	limit, offset, invalid := parsePaging(r)
+	if invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+	unreadOnly := false
+	if v := r.URL.Query().Get("unread"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			api.Fail(w, r, http.StatusBadRequest, "unread harus true atau false")
+			return
+		}
+		unreadOnly = b
+	}
+
+	userID := middleware.CurrentUser(r).ID
+	list, total, err := h.notifications.List(r.Context(), userID, unreadOnly, limit, offset)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in notification List", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil notifikasi")
+		return
+	}
+	unread, err := h.notifications.UnreadCount(r.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in notification List", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil notifikasi")
+		return
+	}
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"notifications": list,
+		"total":         total,
+		"unread":        unread,
+		"limit":         limit,
+		"offset":        offset,
+	})
+}
+
+// ==============================
+// Tandai dibaca
+// POST /notifications/{id}/read
+// POST /notifications/read-all
+// ==============================
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	audit.Skip(r)
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		api.Fail(w, r, http.StatusBadRequest, "ID notifikasi tidak valid")
+		return
+	}
+
+	ok, err := h.notifications.MarkRead(r.Context(), middleware.CurrentUser(r).ID, id, time.Now())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in notification MarkRead", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menandai notifikasi")
+		return
+	}
+	if !ok {
+		api.Fail(w, r, http.StatusNotFound, "Notifikasi tidak ditemukan")
+		return
+	}
+	api.JSON(w, http.StatusOK, map[string]string{"message": "Notifikasi ditandai dibaca"})
+}
+
+func (h *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	audit.Skip(r)
+
+	n, err := h.notifications.MarkAllRead(r.Context(), middleware.CurrentUser(r).ID, time.Now())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in notification MarkAllRead", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menandai notifikasi")
+		return
+	}
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Semua notifikasi ditandai dibaca",
+		"updated": n,
+	})
+}
+
+// ==============================
+// Pengaturan channel notifikasi
+// GET /notifications/preferences
+// PUT /notifications/preferences  {"preferences": [{"event_type": "change_rejected", "channel": "email", "enabled": false}]}
+// ==============================
+func (h *NotificationHandler) Preferences(w http.ResponseWriter, r *http.Request) {
+	h.writePreferences(w, r, middleware.CurrentUser(r).ID)
+}
+
+func (h *NotificationHandler) SavePreferences(w http.ResponseWriter, r *http.Request) {
+	audit.Skip(r)
+
+	var body struct {
+		Preferences []models.NotificationPreference `json:"preferences"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+	for _, p := range body.Preferences {
+		if !notify.KnownEvent(p.EventType) {
+			api.Failf(w, r, http.StatusBadRequest, "event_type %q tidak dikenal", p.EventType)
+			return
+		}
+		if !validChannel(p.Channel) {
+			api.Failf(w, r, http.StatusBadRequest, "channel %q tidak dikenal", p.Channel)
+			return
+		}
+	}
+
+	userID := middleware.CurrentUser(r).ID
+	if err := h.notifications.SavePreferences(r.Context(), userID, body.Preferences); err != nil {
+		slog.ErrorContext(r.Context(), "DB error in SavePreferences", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan pengaturan notifikasi")
+		return
+	}
+	h.writePreferences(w, r, userID)
+}
+
+// writePreferences mengirim pengaturan lengkap untuk setiap event &
+// channel, termasuk yang belum pernah diubah (aktif)
+func (h *NotificationHandler) writePreferences(w http.ResponseWriter, r *http.Request, userID int) {
+	saved, err := h.notifications.Preferences(r.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in notification Preferences", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil pengaturan notifikasi")
+		return
+	}
+	disabled := map[[2]string]bool{}
+	for _, p := range saved {
+		disabled[[2]string{p.EventType, p.Channel}] = !p.Enabled
+	}
+
+	prefs := []models.NotificationPreference{}
+	for _, event := range notify.EventTypes() {
+		for _, channel := range models.NotificationChannels {
+			prefs = append(prefs, models.NotificationPreference{
+				EventType: event,
+				Channel:   channel,
+				Enabled:   !disabled[[2]string{event, channel}],
+			})
+		}
+	}
+	api.JSON(w, http.StatusOK, map[string]interface{}{"preferences": prefs})
+}
+
+func validChannel(channel string) bool {
+	for _, c := range models.NotificationChannels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,562 @@
+package handlers
+
+import (
+	"backend/api"
+	"backend/audit"
+	"backend/config"
+	"backend/middleware"
+	"backend/models"
+	"backend/payments"
+	"backend/repository"
+	"backend/validate"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultOrdersLimit = 50
+	maxOrdersLimit     = 200
+)
+
+type OrderHandler struct {
+	orders        repository.OrderStore
+	cafes         repository.CafeProfileStore
+	subscriptions repository.SubscriptionStore
+	loyalty       repository.LoyaltyStore
+	payments      payments.Store
+}
+
+func NewOrderHandler(orders repository.OrderStore, cafes repository.CafeProfileStore, subscriptions repository.SubscriptionStore, loyalty repository.LoyaltyStore, payments payments.Store) *OrderHandler {
+	return &OrderHandler{orders: orders, cafes: cafes, subscriptions: subscriptions, loyalty: loyalty, payments: payments}
+}
+
+// ==============================
+// Hitung harga keranjang (tanpa membuat pesanan)
+// POST /orders/quote
+// ==============================
+func (h *OrderHandler) Quote(w http.ResponseWriter, r *http.Request) {
+	var cart models.Cart
+	if invalid := validate.DecodeJSON(r, &cart); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+	if cart.CafeID <= 0 {
+		api.Fail(w, r, http.StatusBadRequest, "cafe_id wajib diisi")
+		return
+	}
+
+	// Login bersifat opsional; customer premium melihat harga promo premium
+	premium, err := h.isPremium(r.Context(), middleware.CurrentUser(r))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error checking premium in Quote", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menghitung harga pesanan")
+		return
+	}
+	cart.Premium = premium
+
+	items, err := h.orders.Quote(r.Context(), cart, time.Now())
+	var itemErr *repository.OrderItemError
+	if errors.As(err, &itemErr) {
+		api.Failf(w, r, http.StatusUnprocessableEntity, itemErr.Format, itemErr.Args...)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in Quote", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menghitung harga pesanan")
+		return
+	}
+
+	var subtotal, discount, total float64
+	for _, item := range items {
+		subtotal += item.OriginalPrice * float64(item.Quantity)
+		discount += item.DiscountAmount
+		total += item.Subtotal
+	}
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"cafe_id":        cart.CafeID,
+		"items":          items,
+		"subtotal":       math.Round(subtotal*100) / 100,
+		"discount_total": math.Round(discount*100) / 100,
+		"total":          math.Round(total*100) / 100,
+	})
+}
+
+// ==============================
+// Buat pesanan
+// POST /orders
+// ==============================
+// Customer memesan di cafe pilihannya (cafe_id wajib). Akun cafe memakai
+// endpoint yang sama sebagai kasir (POS) untuk cafe miliknya sendiri.
+func (h *OrderHandler) PlaceOrder(w http.ResponseWriter, r *http.Request) {
+	user := middleware.CurrentUser(r)
+
+	var cart models.Cart
+	if invalid := validate.DecodeJSON(r, &cart); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	var customerID *int
+	if user.Role == models.RoleCafe {
+		cafeID, ok := requireCafeID(w, r, h.cafes)
+		if !ok {
+			return
+		}
+		cart.CafeID = cafeID
+	} else {
+		customerID = &user.ID
+		if cart.CafeID <= 0 {
+			api.Fail(w, r, http.StatusBadRequest, "cafe_id wajib diisi")
+			return
+		}
+		premium, err := h.isPremium(r.Context(), user)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "DB error checking premium in PlaceOrder", "err", err)
+			api.Fail(w, r, http.StatusInternalServerError, "Gagal membuat pesanan")
+			return
+		}
+		cart.Premium = premium
+		if _, err := h.cafes.GetByID(r.Context(), cart.CafeID); err == sql.ErrNoRows {
+			api.Fail(w, r, http.StatusNotFound, "Cafe tidak ditemukan")
+			return
+		} else if err != nil {
+			slog.ErrorContext(r.Context(), "DB error in PlaceOrder", "err", err)
+			api.Fail(w, r, http.StatusInternalServerError, "Gagal membuat pesanan")
+			return
+		}
+	}
+
+	if invalid := normalizeCart(&cart); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	order, err := h.orders.Create(r.Context(), cart, customerID, user.ID, time.Now())
+	var itemErr *repository.OrderItemError
+	if errors.As(err, &itemErr) {
+		api.Failf(w, r, http.StatusUnprocessableEntity, itemErr.Format, itemErr.Args...)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in PlaceOrder", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal membuat pesanan")
+		return
+	}
+
+	api.JSON(w, http.StatusCreated, map[string]interface{}{
+		"message": "Pesanan berhasil dibuat",
+		"order":   order,
+	})
+}
+
+// normalizeCart melengkapi validasi tag models.Cart: field yang wajib
+// tergantung order_type
+func normalizeCart(cart *models.Cart) *api.Error {
+	if cart.OrderType == "" {
+		cart.OrderType = models.OrderDineIn
+	}
+
+	switch cart.OrderType {
+	case models.OrderDineIn:
+		if cart.TableNumber == "" {
+			return badRequest("Nomor meja wajib diisi (maks 20 karakter)")
+		}
+		cart.PickupName = ""
+	case models.OrderPickup:
+		if utf8.RuneCountInString(cart.PickupName) < 2 {
+			return badRequest("Nama pengambil wajib diisi (2-100 karakter)")
+		}
+		cart.TableNumber = ""
+	}
+	return nil
+}
+
+// ==============================
+// Detail pesanan
+// GET /orders/{id}
+// ==============================
+func (h *OrderHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	order, ok := h.loadOrder(w, r)
+	if !ok {
+		return
+	}
+	api.JSON(w, http.StatusOK, order)
+}
+
+// loadOrder mengambil pesanan dari path {id} dan memastikan user boleh
+// melihatnya: customer pemesan, akun cafe pemilik, atau admin.
+func (h *OrderHandler) loadOrder(w http.ResponseWriter, r *http.Request) (*models.Order, bool) {
+	user := middleware.CurrentUser(r)
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.Fail(w, r, http.StatusBadRequest, "ID pesanan tidak valid")
+		return nil, false
+	}
+
+	order, err := h.orders.GetByID(r.Context(), id)
+	if err == repository.ErrOrderNotFound {
+		api.Fail(w, r, http.StatusNotFound, "Pesanan tidak ditemukan")
+		return nil, false
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in loadOrder", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil pesanan")
+		return nil, false
+	}
+
+	if !canAccessOrder(r.Context(), user, order, h.cafes) {
+		api.Fail(w, r, http.StatusNotFound, "Pesanan tidak ditemukan")
+		return nil, false
+	}
+	return order, true
+}
+
+// canAccessOrder: customer pemesan, akun cafe pemilik, atau admin
+func canAccessOrder(ctx context.Context, user *models.SessionUser, order *models.Order, cafes repository.CafeProfileStore) bool {
+	switch user.Role {
+	case models.RoleAdmin:
+		return true
+	case models.RoleCustomer:
+		return order.CustomerUserID != nil && *order.CustomerUserID == user.ID
+	case models.RoleCafe:
+		cafeID, err := cafes.GetIDByUserID(ctx, user.ID)
+		return err == nil && cafeID == order.CafeID
+	}
+	return false
+}
+
+// ==============================
+// Riwayat pesanan customer
+// GET /customer/orders?limit=&offset=
+// ==============================
+func (h *OrderHandler) CustomerOrders(w http.ResponseWriter, r *http.Request) {
+	user := middleware.CurrentUser(r)
+
+	limit, offset, invalid := parsePaging(r)
+	if invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	orders, err := h.orders.ListByCustomer(r.Context(), user.ID, limit, offset)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in CustomerOrders", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil pesanan")
+		return
+	}
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{"orders": orders})
+}
+
+// ==============================
+// Customer membatalkan pesanan yang belum diproses
+// POST /customer/orders/{id}/cancel
+// ==============================
+func (h *OrderHandler) CancelOwnOrder(w http.ResponseWriter, r *http.Request) {
+	order, ok := h.loadOrder(w, r)
+	if !ok {
+		return
+	}
+	if order.Status != models.OrderPlaced {
+		api.Fail(w, r, http.StatusConflict, "Pesanan yang sudah diproses tidak bisa dibatalkan")
+		return
+	}
+
+	// Status dicek ulang di baris yang terkunci: cafe bisa saja mulai
+	// memproses pesanan di antara loadOrder dan update ini
+	h.updateStatus(w, r, order.ID, order.CafeID, models.OrderPlaced, models.OrderCancelled, "Dibatalkan oleh customer")
+}
+
+// ==============================
+// Daftar pesanan cafe (antrian dapur / kasir)
+// GET /cafe/orders?status=placed,preparing&limit=&offset=
+// ==============================
+func (h *OrderHandler) CafeOrders(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	var statuses []string
+	for _, raw := range r.URL.Query()["status"] {
+		for _, s := range strings.Split(raw, ",") {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+			if !validOrderStatus(s) {
+				api.Failf(w, r, http.StatusBadRequest, "status tidak dikenal: %s", s)
+				return
+			}
+			statuses = append(statuses, s)
+		}
+	}
+
+	limit, offset, invalid := parsePaging(r)
+	if invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	orders, err := h.orders.ListByCafe(r.Context(), cafeID, statuses, limit, offset)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in CafeOrders", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil pesanan")
+		return
+	}
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{"orders": orders})
+}
+
+// ==============================
+// Ubah status pesanan oleh cafe
+// PUT /cafe/orders/{id}/status  {"status": "preparing", "reason": ""}
+// ==============================
+func (h *OrderHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.Fail(w, r, http.StatusBadRequest, "ID pesanan tidak valid")
+		return
+	}
+
+	var body struct {
+		Status string `json:"status" validate:"required,oneof=preparing ready completed cancelled"`
+		Reason string `json:"reason" validate:"trim,max=500"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+	if body.Status == models.OrderCancelled && body.Reason == "" {
+		api.Fail(w, r, http.StatusBadRequest, "Alasan pembatalan wajib diisi")
+		return
+	}
+
+	h.updateStatus(w, r, id, cafeID, "", body.Status, body.Reason)
+}
+
+func (h *OrderHandler) updateStatus(w http.ResponseWriter, r *http.Request, id, cafeID int, from, status, reason string) {
+	order, err := h.orders.UpdateStatus(r.Context(), id, cafeID, from, status, reason, time.Now().In(config.Location))
+	var transitionErr *repository.OrderTransitionError
+	if errors.As(err, &transitionErr) {
+		api.Failf(w, r, http.StatusConflict, "Status pesanan tidak bisa diubah dari %s ke %s", transitionErr.From, transitionErr.To)
+		return
+	}
+	if err == repository.ErrOrderNotFound {
+		api.Fail(w, r, http.StatusNotFound, "Pesanan tidak ditemukan")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error updating order status", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengubah status pesanan")
+		return
+	}
+
+	audit.Record(r, audit.Entry{
+		Action:     "order." + order.Status,
+		EntityType: "order",
+		EntityID:   strconv.Itoa(order.ID),
+		Title:      fmt.Sprintf("Pesanan #%d menjadi %s", order.ID, order.Status),
+		After:      map[string]string{"status": order.Status, "reason": reason},
+	})
+
+	// Poin loyalty untuk pesanan selesai; jika gagal, scheduler loyalty
+	// akan mencatatnya belakangan
+	if order.Status == models.OrderCompleted {
+		if _, err := h.loyalty.EarnForOrder(r.Context(), order.ID, time.Now()); err != nil {
+			slog.ErrorContext(r.Context(), "Loyalty earn error", "order_id", order.ID, "err", err)
+		}
+	}
+
+	// Pesanan batal: tagihan pending dibatalkan dan pembayaran lunas
+	// di-refund. Pembayaran yang gagal diproses ditandai untuk admin.
+	if order.Status == models.OrderCancelled {
+		if err := h.payments.CancelForOrder(context.WithoutCancel(r.Context()), order.ID); err != nil {
+			slog.ErrorContext(r.Context(), "Payment cancel error", "order_id", order.ID, "err", err)
+		}
+	}
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Status pesanan diperbarui",
+		"order":   order,
+	})
+}
+
+func validOrderStatus(s string) bool {
+	switch s {
+	case models.OrderPlaced, models.OrderPreparing, models.OrderReady, models.OrderCompleted, models.OrderCancelled:
+		return true
+	}
+	return false
+}
+
+// badRequest membuat error 400 dengan format pesan (Bahasa Indonesia)
+func badRequest(format string, args ...interface{}) *api.Error {
+	return api.Newf(http.StatusBadRequest, format, args...)
+}
+
+// parsePaging membaca limit & offset dari query string
+func parsePaging(r *http.Request) (limit, offset int, invalid *api.Error) {
+	limit = defaultOrdersLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxOrdersLimit {
+			return 0, 0, badRequest("limit harus di antara 1 dan %d", maxOrdersLimit)
+		}
+		limit = n
+	}
+	if v := r.URL.Query().Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, 0, badRequest("offset tidak valid")
+		}
+		offset = n
+	}
+	return limit, offset, nil
+}
+
+// ==============================
+// Varian menu
+// GET /menus/{id}/variants
+// ==============================
+func (h *OrderHandler) ListVariants(w http.ResponseWriter, r *http.Request) {
+	menuID := mux.Vars(r)["id"]
+	if _, err := h.orders.MenuCafeID(r.Context(), menuID); err == sql.ErrNoRows {
+		api.Fail(w, r, http.StatusNotFound, "Menu tidak ditemukan")
+		return
+	} else if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in ListVariants", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil varian menu")
+		return
+	}
+
+	variants, err := h.orders.ListVariants(r.Context(), menuID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in ListVariants", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil varian menu")
+		return
+	}
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{"variants": variants})
+}
+
+// ==============================
+// Tambah varian menu
+// POST /cafe/menus/{id}/variants  {"name": "Large", "price_delta": 5000}
+// ==============================
+func (h *OrderHandler) CreateVariant(w http.ResponseWriter, r *http.Request) {
+	menuID, _, ok := requireOwnMenu(w, r, h.cafes, h.orders)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Name       string  `json:"name" validate:"trim,required,max=100"`
+		PriceDelta float64 `json:"price_delta" validate:"min=-100000000,max=100000000"`
+		Available  *bool   `json:"available"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	variant := &models.MenuVariant{MenuID: menuID, Name: body.Name, PriceDelta: body.PriceDelta, Available: true}
+	if body.Available != nil {
+		variant.Available = *body.Available
+	}
+	err := h.orders.CreateVariant(r.Context(), variant)
+	if err == repository.ErrVariantPriceNegative {
+		api.Fail(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in CreateVariant", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan varian menu")
+		return
+	}
+
+	api.JSON(w, http.StatusCreated, variant)
+}
+
+// ==============================
+// Hapus varian menu
+// DELETE /cafe/menus/{id}/variants/{variantId}
+// ==============================
+func (h *OrderHandler) DeleteVariant(w http.ResponseWriter, r *http.Request) {
+	menuID, _, ok := requireOwnMenu(w, r, h.cafes, h.orders)
+	if !ok {
+		return
+	}
+
+	variantID, err := strconv.Atoi(mux.Vars(r)["variantId"])
+	if err != nil {
+		api.Fail(w, r, http.StatusBadRequest, "ID varian tidak valid")
+		return
+	}
+
+	err = h.orders.DeleteVariant(r.Context(), menuID, variantID)
+	if err == sql.ErrNoRows {
+		api.Fail(w, r, http.StatusNotFound, "Varian tidak ditemukan")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in DeleteVariant", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menghapus varian menu")
+		return
+	}
+
+	api.JSON(w, http.StatusOK, map[string]string{"message": "Varian dihapus"})
+}
+
+// ==============================
+// Tandai diskon menu khusus anggota premium
+// PUT /cafe/menus/{id}/premium  {"premium_only": true}
+// ==============================
+func (h *OrderHandler) SetPremiumOnly(w http.ResponseWriter, r *http.Request) {
+	menuID, _, ok := requireOwnMenu(w, r, h.cafes, h.orders)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		PremiumOnly *bool `json:"premium_only" validate:"required"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	if err := h.orders.SetMenuPremiumOnly(r.Context(), menuID, *body.PremiumOnly); err != nil {
+		slog.ErrorContext(r.Context(), "DB error in SetPremiumOnly", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan menu")
+		return
+	}
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{"menu_id": menuID, "premium_only": *body.PremiumOnly})
+}
+
+// isPremium memeriksa apakah pemesan adalah customer anggota premium
+func (h *OrderHandler) isPremium(ctx context.Context, user *models.SessionUser) (bool, error) {
+	if user == nil || user.Role != models.RoleCustomer {
+		return false, nil
+	}
+	return h.subscriptions.IsPremium(ctx, user.ID, time.Now())
+}
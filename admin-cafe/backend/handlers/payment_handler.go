@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"backend/api"
+	"backend/middleware"
+	"backend/models"
+	"backend/payments"
+	"backend/repository"
+	"backend/validate"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Batas ukuran body webhook
+const maxWebhookBody = 1 << 20
+
+type PaymentHandler struct {
+	payments      payments.Store
+	orders        repository.OrderStore
+	subscriptions repository.SubscriptionStore
+	cafes         repository.CafeProfileStore
+	loyalty       repository.LoyaltyStore
+}
+
+func NewPaymentHandler(payments payments.Store, orders repository.OrderStore, subscriptions repository.SubscriptionStore, cafes repository.CafeProfileStore, loyalty repository.LoyaltyStore) *PaymentHandler {
+	return &PaymentHandler{payments: payments, orders: orders, subscriptions: subscriptions, cafes: cafes, loyalty: loyalty}
+}
+
+// ==============================
+// Bayar pesanan
+// POST /orders/{id}/payments  {"method": "qris|ewallet|bank_transfer"}
+// ==============================
+func (h *PaymentHandler) PayOrder(w http.ResponseWriter, r *http.Request) {
+	user := middleware.CurrentUser(r)
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.Fail(w, r, http.StatusBadRequest, "ID pesanan tidak valid")
+		return
+	}
+
+	var body struct {
+		Method string `json:"method" validate:"required"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+	method, err := payments.ParseMethod(body.Method)
+	if err != nil {
+		api.Fail(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	order, err := h.orders.GetByID(r.Context(), id)
+	if err == repository.ErrOrderNotFound || (err == nil && !canAccessOrder(r.Context(), user, order, h.cafes)) {
+		api.Fail(w, r, http.StatusNotFound, "Pesanan tidak ditemukan")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in PayOrder", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal membuat pembayaran")
+		return
+	}
+
+	payment, err := h.payments.CreateForOrder(r.Context(), order.ID, method)
+	switch err {
+	case nil:
+	case payments.ErrNotPayable, payments.ErrAlreadyPaid, payments.ErrInProgress:
+		api.Fail(w, r, http.StatusConflict, err.Error())
+		return
+	default:
+		slog.ErrorContext(r.Context(), "Payment error in PayOrder", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal membuat pembayaran")
+		return
+	}
+
+	api.JSON(w, http.StatusCreated, payment)
+}
+
+// ==============================
+// Detail pembayaran (untuk polling status)
+// GET /payments/{id}
+// ==============================
+func (h *PaymentHandler) GetPayment(w http.ResponseWriter, r *http.Request) {
+	payment, ok := h.loadPayment(w, r)
+	if !ok {
+		return
+	}
+	api.JSON(w, http.StatusOK, payment)
+}
+
+// ==============================
+// Refund pembayaran (cafe pemilik / admin)
+// POST /payments/{id}/refund
+// ==============================
+func (h *PaymentHandler) Refund(w http.ResponseWriter, r *http.Request) {
+	if middleware.CurrentUser(r).Role == models.RoleCustomer {
+		api.Fail(w, r, http.StatusForbidden, "Refund hanya bisa dilakukan oleh cafe")
+		return
+	}
+
+	payment, ok := h.loadPayment(w, r)
+	if !ok {
+		return
+	}
+
+	payment, err := h.payments.Refund(r.Context(), payment.ID)
+	if err == payments.ErrNotRefundable {
+		api.Fail(w, r, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Payment error in Refund", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal melakukan refund")
+		return
+	}
+
+	// Poin loyalty pesanan ditarik; jika gagal, scheduler loyalty akan
+	// menariknya belakangan
+	if payment.ReferenceType == payments.ReferenceOrder {
+		if _, err := h.loyalty.ReverseForOrder(r.Context(), payment.ReferenceID, time.Now()); err != nil {
+			slog.ErrorContext(r.Context(), "Loyalty reverse error", "order_id", payment.ReferenceID, "err", err)
+		}
+	}
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Refund berhasil",
+		"payment": payment,
+	})
+}
+
+// loadPayment mengambil pembayaran dari path {id} dan memastikan user
+// boleh mengakses pesanan atau langganan yang dibayar. Pembayaran
+// langganan hanya terlihat oleh pemiliknya dan super admin.
+func (h *PaymentHandler) loadPayment(w http.ResponseWriter, r *http.Request) (*payments.Payment, bool) {
+	user := middleware.CurrentUser(r)
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.Fail(w, r, http.StatusBadRequest, "ID pembayaran tidak valid")
+		return nil, false
+	}
+
+	payment, err := h.payments.GetByID(r.Context(), id)
+	if err == payments.ErrPaymentNotFound {
+		api.Fail(w, r, http.StatusNotFound, "Pembayaran tidak ditemukan")
+		return nil, false
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in loadPayment", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil pembayaran")
+		return nil, false
+	}
+
+	if payment.ReferenceType == payments.ReferenceSubscription {
+		sub, err := h.subscriptions.GetByID(r.Context(), payment.ReferenceID)
+		if err == repository.ErrSubscriptionNotFound || (err == nil && user.Role != models.RoleAdmin && sub.UserID != user.ID) {
+			api.Fail(w, r, http.StatusNotFound, "Pembayaran tidak ditemukan")
+			return nil, false
+		}
+		if err != nil {
+			slog.ErrorContext(r.Context(), "DB error in loadPayment", "err", err)
+			api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil pembayaran")
+			return nil, false
+		}
+		return payment, true
+	}
+
+	order, err := h.orders.GetByID(r.Context(), payment.ReferenceID)
+	if err == repository.ErrOrderNotFound || (err == nil && !canAccessOrder(r.Context(), user, order, h.cafes)) {
+		api.Fail(w, r, http.StatusNotFound, "Pembayaran tidak ditemukan")
+		return nil, false
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in loadPayment", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil pembayaran")
+		return nil, false
+	}
+	return payment, true
+}
+
+// ==============================
+// Webhook dari gateway pembayaran
+// POST /payments/webhook/{provider}
+// ==============================
+// Selalu dibalas 200 untuk event duplikat supaya gateway berhenti
+// mengirim ulang.
+func (h *PaymentHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	provider := h.payments.Provider()
+	if mux.Vars(r)["provider"] != provider.Name() {
+		api.Fail(w, r, http.StatusNotFound, "Provider tidak dikenal")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody))
+	if err != nil {
+		api.InvalidBody(w, r)
+		return
+	}
+
+	event, err := provider.VerifyWebhook(r.Header, body)
+	if err == payments.ErrInvalidSignature {
+		api.Fail(w, r, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if err != nil {
+		api.Fail(w, r, http.StatusBadRequest, "Webhook tidak valid")
+		return
+	}
+
+	duplicate, err := h.payments.HandleWebhook(r.Context(), event)
+	if err == payments.ErrPaymentNotFound {
+		api.Fail(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Payment webhook error", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal memproses webhook")
+		return
+	}
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{"received": true, "duplicate": duplicate})
+}
+
+// ==============================
+// Simulasi pembayaran (hanya gateway mock)
+// POST /payments/mock/simulate  {"provider_ref": "...", "status": "paid|failed|expired"}
+// Hanya super admin: simulasi bisa menandai pesanan siapa pun lunas
+// ==============================
+func (h *PaymentHandler) MockSimulate(w http.ResponseWriter, r *http.Request) {
+	mock, ok := h.payments.Provider().(*payments.MockProvider)
+	if !ok {
+		api.Fail(w, r, http.StatusNotFound, "Simulasi hanya tersedia untuk PAYMENT_PROVIDER=mock")
+		return
+	}
+
+	var body struct {
+		ProviderRef string          `json:"provider_ref" validate:"trim,required"`
+		Status      payments.Status `json:"status" validate:"required,oneof=paid failed expired"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	if err := mock.Simulate(body.ProviderRef, body.Status); err != nil {
+		api.Fail(w, r, http.StatusConflict, err.Error())
+		return
+	}
+
+	api.JSON(w, http.StatusOK, map[string]string{"message": "Webhook simulasi dikirim"})
+}
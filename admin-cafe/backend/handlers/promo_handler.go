@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"backend/api"
+	"backend/models"
+	"backend/promos"
+	"backend/repository"
+	"backend/validate"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PromoHandler menyajikan promo (menu berdiskon). Perubahan diskon
+// diteruskan ke ChangeRequestHandler supaya tetap lewat antrean
+// persetujuan yang sama dengan PUT /cafe/menus/{id}/discount.
+type PromoHandler struct {
+	promos    promos.Store
+	cafes     repository.CafeProfileStore
+	orders    repository.OrderStore
+	discounts *ChangeRequestHandler
+}
+
+func NewPromoHandler(promoService promos.Store, cafes repository.CafeProfileStore, orders repository.OrderStore, discounts *ChangeRequestHandler) *PromoHandler {
+	return &PromoHandler{promos: promoService, cafes: cafes, orders: orders, discounts: discounts}
+}
+
+// ==============================
+// Daftar promo cafe sendiri
+// GET /cafe/promos?include_ended=true
+// ==============================
+func (h *PromoHandler) List(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	includeEnded := r.URL.Query().Get("include_ended") == "true"
+	list, err := h.promos.List(r.Context(), cafeID, includeEnded, time.Now())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error listing promos", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil promo")
+		return
+	}
+	api.JSON(w, http.StatusOK, map[string]interface{}{"promos": list})
+}
+
+// ==============================
+// Statistik promo
+// GET /cafe/promos/stats
+// ==============================
+func (h *PromoHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	stats, err := h.promos.Stats(r.Context(), cafeID, time.Now())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error reading promo stats", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil statistik promo")
+		return
+	}
+	api.JSON(w, http.StatusOK, stats)
+}
+
+// ==============================
+// Detail promo
+// GET /cafe/promos/{id}
+// ==============================
+// {id} adalah ID menu.
+func (h *PromoHandler) Get(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	promo, err := h.promos.Get(r.Context(), cafeID, menuID(r), time.Now())
+	if err == promos.ErrNotFound {
+		api.Fail(w, r, http.StatusNotFound, "Promo tidak ditemukan")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error reading promo", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil promo")
+		return
+	}
+	api.JSON(w, http.StatusOK, promo)
+}
+
+// ==============================
+// Buat promo untuk satu menu
+// POST /cafe/promos  {"menu_id": "...", "discount": 20, "start_date": "2025-09-01", "end_date": "2025-09-30"}
+// ==============================
+// Mengubah promo memakai PUT /cafe/promos/{id}, sama dengan
+// PUT /cafe/menus/{id}/discount.
+func (h *PromoHandler) Create(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		MenuID    string  `json:"menu_id" validate:"trim,required"`
+		Discount  float64 `json:"discount" validate:"gt=0,max=100"`
+		StartDate string  `json:"start_date" validate:"date"`
+		EndDate   string  `json:"end_date" validate:"date,gtefield=StartDate"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	id := strings.ToLower(body.MenuID)
+	if !checkMenuOwner(w, r, h.orders, id, cafeID) {
+		return
+	}
+	h.discounts.setDiscount(w, r, id, cafeID, models.DiscountChange{
+		Discount:  body.Discount,
+		StartDate: body.StartDate,
+		EndDate:   body.EndDate,
+	})
+}
+
+// ==============================
+// Hapus promo
+// DELETE /cafe/promos/{id}
+// ==============================
+// Diskon menu dikembalikan ke 0 tanpa batas tanggal.
+func (h *PromoHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, cafeID, ok := requireOwnMenu(w, r, h.cafes, h.orders)
+	if !ok {
+		return
+	}
+	h.discounts.setDiscount(w, r, id, cafeID, models.DiscountChange{})
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"backend/api"
+	"backend/config"
+	"backend/reports"
+	"backend/repository"
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultReportDays  = 30
+	maxDailyReportDays = 366
+	maxReportDays      = 3 * 366
+)
+
+type ReportHandler struct {
+	reports   reports.Store
+	dashboard *reports.DashboardCache
+	cafes     repository.CafeProfileStore
+}
+
+func NewReportHandler(service reports.Store, cafes repository.CafeProfileStore) *ReportHandler {
+	return &ReportHandler{
+		reports:   service,
+		dashboard: reports.NewDashboardCache(service, config.StatsCacheTTL),
+		cafes:     cafes,
+	}
+}
+
+// ==============================
+// Statistik dashboard super admin (di-cache sebentar)
+// GET /admin/stats?days=30
+// ==============================
+func (h *ReportHandler) AdminStats(w http.ResponseWriter, r *http.Request) {
+	days := defaultReportDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > reports.MaxDashboardDays {
+			api.Failf(w, r, http.StatusBadRequest, "days harus di antara 1 dan %d", reports.MaxDashboardDays)
+			return
+		}
+		days = n
+	}
+
+	stats, err := h.dashboard.Get(r.Context(), days, time.Now())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in AdminStats", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil statistik dashboard")
+		return
+	}
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(config.StatsCacheTTL.Seconds())))
+	api.JSON(w, http.StatusOK, stats)
+}
+
+// ==============================
+// Laporan penjualan
+// GET /reports/sales?start=2025-09-01&end=2025-09-10&granularity=day|week|month
+// ==============================
+func (h *ReportHandler) Sales(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	start, end, g, invalid := parseReportRange(r)
+	if invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	report, err := h.reports.Sales(r.Context(), cafeID, start, end, g)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in Sales report", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal membuat laporan penjualan")
+		return
+	}
+
+	api.JSON(w, http.StatusOK, report)
+}
+
+// parseReportRange membaca start, end & granularity dari query string.
+// Tanpa start/end, dipakai 30 hari terakhir.
+func parseReportRange(r *http.Request) (start, end time.Time, g reports.Granularity, invalid *api.Error) {
+	params := r.URL.Query()
+
+	g, err := reports.ParseGranularity(params.Get("granularity"))
+	if err != nil {
+		return start, end, g, api.New(http.StatusBadRequest, err.Error())
+	}
+
+	today := time.Now().In(config.Location)
+	end = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, config.Location)
+	start = end.AddDate(0, 0, -(defaultReportDays - 1))
+
+	if v := params.Get("start"); v != "" {
+		if start, err = time.ParseInLocation("2006-01-02", v, config.Location); err != nil {
+			return start, end, g, badRequest("Format start harus YYYY-MM-DD")
+		}
+	}
+	if v := params.Get("end"); v != "" {
+		if end, err = time.ParseInLocation("2006-01-02", v, config.Location); err != nil {
+			return start, end, g, badRequest("Format end harus YYYY-MM-DD")
+		}
+	}
+
+	if end.Before(start) {
+		return start, end, g, badRequest("Tanggal akhir tidak boleh sebelum tanggal mulai")
+	}
+
+	days := int(end.Sub(start).Hours()/24) + 1
+	if g == reports.Day && days > maxDailyReportDays {
+		return start, end, g, badRequest("Rentang laporan harian maksimal %d hari", maxDailyReportDays)
+	}
+	if days > maxReportDays {
+		return start, end, g, badRequest("Rentang laporan maksimal %d hari", maxReportDays)
+	}
+	return start, end, g, nil
+}
+
+// ==============================
+// Export laporan penjualan (unduhan)
+// GET /reports/sales/export?format=pdf|xlsx&start=&end=&granularity=
+// ==============================
+func (h *ReportHandler) ExportSales(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "pdf" && format != "xlsx" {
+		api.Fail(w, r, http.StatusBadRequest, "format harus pdf atau xlsx")
+		return
+	}
+
+	start, end, g, invalid := parseReportRange(r)
+	if invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	report, err := h.reports.Sales(r.Context(), cafeID, start, end, g)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in ExportSales", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal membuat laporan penjualan")
+		return
+	}
+
+	cafe, err := h.cafes.GetByID(r.Context(), cafeID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in ExportSales", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil profil cafe")
+		return
+	}
+
+	// Dokumen disusun di memori dulu supaya error tidak menghasilkan file rusak
+	var buf bytes.Buffer
+	contentType := "application/pdf"
+	if format == "xlsx" {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		err = reports.WriteXLSX(&buf, report, cafe.Nama)
+	} else {
+		err = reports.WritePDF(&buf, report, cafe.Nama)
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Export error in ExportSales", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal membuat file laporan")
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, reports.ExportFilename(report, format)))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	buf.WriteTo(w)
+}
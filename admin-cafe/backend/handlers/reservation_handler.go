@@ -0,0 +1,546 @@
+package handlers
+
+import (
+	"backend/api"
+	"backend/audit"
+	"backend/config"
+	"backend/middleware"
+	"backend/models"
+	"backend/repository"
+	"backend/validate"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultReservationMinutes = 90
+	minReservationMinutes     = 30
+	maxReservationMinutes     = 240
+	maxPartySize              = 50
+	maxReservationAdvanceDays = 60
+	// Reservasi pending menahan meja selama ini sebelum dilepas otomatis
+	reservationHold = 30 * time.Minute
+)
+
+type ReservationHandler struct {
+	reservations repository.ReservationStore
+	cafes        repository.CafeProfileStore
+}
+
+func NewReservationHandler(reservations repository.ReservationStore, cafes repository.CafeProfileStore) *ReservationHandler {
+	return &ReservationHandler{reservations: reservations, cafes: cafes}
+}
+
+// ==============================
+// Daftar meja cafe
+// GET /cafe/tables
+// ==============================
+func (h *ReservationHandler) ListTables(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	tables, err := h.reservations.ListTables(r.Context(), cafeID, false)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in ListTables", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil data meja")
+		return
+	}
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{"tables": tables})
+}
+
+// ==============================
+// Tambah / ubah meja
+// POST /cafe/tables, PUT /cafe/tables/{id}
+// {"label": "A1", "area": "indoor", "capacity": 4, "active": true}
+// ==============================
+func (h *ReservationHandler) SaveTable(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Label    string `json:"label" validate:"trim,required,max=50"`
+		Area     string `json:"area"`
+		Capacity int    `json:"capacity" validate:"min=1,max=50"`
+		Active   *bool  `json:"active"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	table := &models.CafeTable{
+		CafeID:   cafeID,
+		Label:    body.Label,
+		Area:     strings.ToLower(strings.TrimSpace(body.Area)),
+		Capacity: body.Capacity,
+		Active:   body.Active == nil || *body.Active,
+	}
+	if !models.ValidTableArea(table.Area) {
+		api.Fail(w, r, http.StatusBadRequest, "area harus indoor, outdoor atau smoking_area")
+		return
+	}
+
+	var err error
+	status := http.StatusOK
+	if idParam, isUpdate := mux.Vars(r)["id"]; isUpdate {
+		if table.ID, err = strconv.Atoi(idParam); err != nil {
+			api.Fail(w, r, http.StatusBadRequest, "ID meja tidak valid")
+			return
+		}
+		err = h.reservations.UpdateTable(r.Context(), table)
+	} else {
+		status = http.StatusCreated
+		err = h.reservations.CreateTable(r.Context(), table)
+	}
+
+	if err == repository.ErrTableNotFound {
+		api.Fail(w, r, http.StatusNotFound, "Meja tidak ditemukan")
+		return
+	}
+	if repository.IsUniqueViolation(err) {
+		api.Fail(w, r, http.StatusConflict, "Label meja sudah dipakai")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in SaveTable", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan meja")
+		return
+	}
+
+	api.JSON(w, status, table)
+}
+
+// ==============================
+// Hapus meja
+// DELETE /cafe/tables/{id}
+// ==============================
+func (h *ReservationHandler) DeleteTable(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.Fail(w, r, http.StatusBadRequest, "ID meja tidak valid")
+		return
+	}
+
+	err = h.reservations.DeleteTable(r.Context(), id, cafeID)
+	switch err {
+	case nil:
+	case repository.ErrTableNotFound:
+		api.Fail(w, r, http.StatusNotFound, "Meja tidak ditemukan")
+		return
+	case repository.ErrTableInUse:
+		api.Fail(w, r, http.StatusConflict, err.Error())
+		return
+	default:
+		slog.ErrorContext(r.Context(), "DB error in DeleteTable", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menghapus meja")
+		return
+	}
+
+	api.JSON(w, http.StatusOK, map[string]string{"message": "Meja dihapus"})
+}
+
+// reservationSlot adalah waktu & ukuran rombongan yang diminta customer
+type reservationSlot struct {
+	start, end time.Time
+	partySize  int
+	area       string
+}
+
+// parseSlot membaca tanggal (YYYY-MM-DD), jam (HH:MM), durasi (menit),
+// jumlah orang & area, lalu memastikan slot ada di masa depan.
+func parseSlot(date, clock string, duration, partySize int, area string, now time.Time) (reservationSlot, *api.Error) {
+	var slot reservationSlot
+
+	start, err := time.ParseInLocation("2006-01-02 15:04", date+" "+clock, config.Location)
+	if err != nil {
+		return slot, badRequest("Tanggal (YYYY-MM-DD) dan jam (HH:MM) wajib diisi")
+	}
+	if duration == 0 {
+		duration = defaultReservationMinutes
+	}
+	if duration < minReservationMinutes || duration > maxReservationMinutes {
+		return slot, badRequest("Durasi reservasi %d-%d menit", minReservationMinutes, maxReservationMinutes)
+	}
+	if partySize < 1 || partySize > maxPartySize {
+		return slot, badRequest("Jumlah orang harus 1-%d", maxPartySize)
+	}
+	area = strings.ToLower(strings.TrimSpace(area))
+	if area != "" && !models.ValidTableArea(area) {
+		return slot, badRequest("area harus indoor, outdoor atau smoking_area")
+	}
+	if !start.After(now) {
+		return slot, badRequest("Waktu reservasi harus di masa depan")
+	}
+	if start.After(now.AddDate(0, 0, maxReservationAdvanceDays)) {
+		return slot, badRequest("Reservasi maksimal %d hari ke depan", maxReservationAdvanceDays)
+	}
+
+	slot = reservationSlot{
+		start:     start,
+		end:       start.Add(time.Duration(duration) * time.Minute),
+		partySize: partySize,
+		area:      area,
+	}
+	return slot, nil
+}
+
+// checkOpen memastikan slot ada dalam jam operasional cafe. Jika tidak,
+// response error sudah ditulis dan hasilnya false.
+func (h *ReservationHandler) checkOpen(w http.ResponseWriter, r *http.Request, cafeID int, slot reservationSlot) bool {
+	open, hasHours, err := h.reservations.WithinOperationalHours(r.Context(), cafeID, slot.start, slot.end)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error checking operational hours", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal memeriksa jam operasional")
+		return false
+	}
+	if !hasHours {
+		api.Fail(w, r, http.StatusUnprocessableEntity, "Cafe belum mengatur jam operasional")
+		return false
+	}
+	if !open {
+		api.Fail(w, r, http.StatusUnprocessableEntity, "Waktu reservasi di luar jam operasional cafe")
+		return false
+	}
+	return true
+}
+
+// ==============================
+// Cek meja tersedia
+// GET /cafes/{id}/availability?date=2025-09-01&time=19:00&duration=90&party_size=2&area=outdoor
+// ==============================
+func (h *ReservationHandler) Availability(w http.ResponseWriter, r *http.Request) {
+	cafeID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.Fail(w, r, http.StatusBadRequest, "ID cafe tidak valid")
+		return
+	}
+	if _, err := h.cafes.GetByID(r.Context(), cafeID); err == sql.ErrNoRows {
+		api.Fail(w, r, http.StatusNotFound, "Cafe tidak ditemukan")
+		return
+	} else if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in Availability", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal memeriksa ketersediaan")
+		return
+	}
+
+	params := r.URL.Query()
+	duration, _ := strconv.Atoi(params.Get("duration"))
+	partySize, err := strconv.Atoi(params.Get("party_size"))
+	if err != nil {
+		partySize = 1
+	}
+	now := time.Now().In(config.Location)
+	slot, invalid := parseSlot(params.Get("date"), params.Get("time"), duration, partySize, params.Get("area"), now)
+	if invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+	if !h.checkOpen(w, r, cafeID, slot) {
+		return
+	}
+
+	tables, err := h.reservations.AvailableTables(r.Context(), cafeID, slot.start, slot.end, slot.partySize, slot.area, now)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in Availability", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal memeriksa ketersediaan")
+		return
+	}
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"start_at":  slot.start,
+		"end_at":    slot.end,
+		"available": len(tables) > 0,
+		"tables":    tables,
+	})
+}
+
+// ==============================
+// Customer membuat reservasi
+// POST /customer/reservations
+// {"cafe_id": 1, "date": "2025-09-01", "time": "19:00", "duration": 90, "party_size": 4, "area": "outdoor"}
+// ==============================
+func (h *ReservationHandler) CreateReservation(w http.ResponseWriter, r *http.Request) {
+	user := middleware.CurrentUser(r)
+
+	var body struct {
+		CafeID    int    `json:"cafe_id" validate:"required,gt=0"`
+		Date      string `json:"date" validate:"required,date"`
+		Time      string `json:"time" validate:"required,clock"`
+		Duration  int    `json:"duration"`
+		PartySize int    `json:"party_size"`
+		Area      string `json:"area"`
+		TableID   *int   `json:"table_id"`
+		Note      string `json:"note" validate:"trim,max=500"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	now := time.Now().In(config.Location)
+	slot, invalid := parseSlot(body.Date, body.Time, body.Duration, body.PartySize, body.Area, now)
+	if invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+	if !h.checkOpen(w, r, body.CafeID, slot) {
+		return
+	}
+
+	// Hold tidak boleh melewati waktu mulai reservasi
+	holdUntil := now.Add(reservationHold)
+	if holdUntil.After(slot.start) {
+		holdUntil = slot.start
+	}
+
+	reservation, err := h.reservations.Create(r.Context(), models.ReservationRequest{
+		CafeID:     body.CafeID,
+		CustomerID: user.ID,
+		TableID:    body.TableID,
+		Area:       slot.area,
+		PartySize:  slot.partySize,
+		Start:      slot.start,
+		End:        slot.end,
+		Note:       body.Note,
+		HoldUntil:  holdUntil,
+	}, now)
+	if err == repository.ErrNoTableAvailable {
+		api.Fail(w, r, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in CreateReservation", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal membuat reservasi")
+		return
+	}
+
+	api.JSON(w, http.StatusCreated, map[string]interface{}{
+		"message":     "Reservasi dikirim, menunggu konfirmasi cafe",
+		"reservation": reservation,
+	})
+}
+
+// ==============================
+// Reservasi milik customer
+// GET /customer/reservations
+// ==============================
+func (h *ReservationHandler) CustomerReservations(w http.ResponseWriter, r *http.Request) {
+	user := middleware.CurrentUser(r)
+
+	list, err := h.reservations.ListByCustomer(r.Context(), user.ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in CustomerReservations", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil reservasi")
+		return
+	}
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{"reservations": list})
+}
+
+// ==============================
+// Customer membatalkan reservasi
+// POST /customer/reservations/{id}/cancel
+// ==============================
+func (h *ReservationHandler) CancelReservation(w http.ResponseWriter, r *http.Request) {
+	user := middleware.CurrentUser(r)
+
+	res, ok := h.loadReservation(w, r)
+	if !ok {
+		return
+	}
+	if res.CustomerID != user.ID {
+		api.Fail(w, r, http.StatusNotFound, "Reservasi tidak ditemukan")
+		return
+	}
+
+	now := time.Now().In(config.Location)
+	if res.Status != models.ReservationPending && res.Status != models.ReservationConfirmed {
+		api.Failf(w, r, http.StatusConflict, "Reservasi berstatus %s tidak bisa dibatalkan", res.Status)
+		return
+	}
+	if !res.StartAt.After(now) {
+		api.Fail(w, r, http.StatusConflict, "Reservasi yang sudah dimulai tidak bisa dibatalkan")
+		return
+	}
+
+	h.updateStatus(w, r, res, models.ReservationCancelled, "Dibatalkan oleh customer", now)
+}
+
+// ==============================
+// Daftar reservasi cafe
+// GET /cafe/reservations?date=2025-09-01&status=pending,confirmed
+// ==============================
+func (h *ReservationHandler) CafeReservations(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	params := r.URL.Query()
+	var date *time.Time
+	if v := params.Get("date"); v != "" {
+		d, err := time.ParseInLocation("2006-01-02", v, config.Location)
+		if err != nil {
+			api.Fail(w, r, http.StatusBadRequest, "Format date harus YYYY-MM-DD")
+			return
+		}
+		date = &d
+	}
+
+	var statuses []string
+	for _, s := range strings.Split(params.Get("status"), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			statuses = append(statuses, s)
+		}
+	}
+
+	list, err := h.reservations.ListByCafe(r.Context(), cafeID, date, statuses)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in CafeReservations", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil reservasi")
+		return
+	}
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{"reservations": list})
+}
+
+// ==============================
+// Cafe mengubah status reservasi
+// POST /cafe/reservations/{id}/confirm
+// POST /cafe/reservations/{id}/decline  {"reason": "..."}
+// POST /cafe/reservations/{id}/no-show
+// ==============================
+func (h *ReservationHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	res, ok := h.loadCafeReservation(w, r)
+	if !ok {
+		return
+	}
+	now := time.Now().In(config.Location)
+	if res.Status != models.ReservationPending || (res.HoldExpiresAt != nil && !res.HoldExpiresAt.After(now)) {
+		api.Fail(w, r, http.StatusConflict, "Hanya reservasi pending yang belum kedaluwarsa yang bisa dikonfirmasi")
+		return
+	}
+	h.updateStatus(w, r, res, models.ReservationConfirmed, "", now)
+}
+
+func (h *ReservationHandler) Decline(w http.ResponseWriter, r *http.Request) {
+	res, ok := h.loadCafeReservation(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Reason string `json:"reason" validate:"trim,required,max=500"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	if res.Status != models.ReservationPending {
+		api.Fail(w, r, http.StatusConflict, "Hanya reservasi pending yang bisa ditolak")
+		return
+	}
+	h.updateStatus(w, r, res, models.ReservationDeclined, body.Reason, time.Now().In(config.Location))
+}
+
+func (h *ReservationHandler) NoShow(w http.ResponseWriter, r *http.Request) {
+	res, ok := h.loadCafeReservation(w, r)
+	if !ok {
+		return
+	}
+	now := time.Now().In(config.Location)
+	if res.Status != models.ReservationConfirmed {
+		api.Fail(w, r, http.StatusConflict, "Hanya reservasi confirmed yang bisa ditandai tidak datang")
+		return
+	}
+	if res.StartAt.After(now) {
+		api.Fail(w, r, http.StatusConflict, "Reservasi belum dimulai")
+		return
+	}
+	h.updateStatus(w, r, res, models.ReservationNoShow, "", now)
+}
+
+func (h *ReservationHandler) updateStatus(w http.ResponseWriter, r *http.Request, res *models.Reservation, status, reason string, now time.Time) {
+	updated, err := h.reservations.UpdateStatus(r.Context(), res.ID, res.Status, status, reason, now)
+	if err == repository.ErrReservationChanged {
+		api.Fail(w, r, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error updating reservation status", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengubah status reservasi")
+		return
+	}
+
+	audit.Record(r, audit.Entry{
+		Action:     "reservation." + status,
+		EntityType: "reservation",
+		EntityID:   strconv.Itoa(res.ID),
+		Title:      fmt.Sprintf("Reservasi #%d %s", res.ID, status),
+		Before:     res,
+		After:      updated,
+	})
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"message":     "Status reservasi diperbarui",
+		"reservation": updated,
+	})
+}
+
+func (h *ReservationHandler) loadReservation(w http.ResponseWriter, r *http.Request) (*models.Reservation, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.Fail(w, r, http.StatusBadRequest, "ID reservasi tidak valid")
+		return nil, false
+	}
+
+	res, err := h.reservations.GetByID(r.Context(), id)
+	if err == repository.ErrReservationNotFound {
+		api.Fail(w, r, http.StatusNotFound, "Reservasi tidak ditemukan")
+		return nil, false
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error loading reservation", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil reservasi")
+		return nil, false
+	}
+	return res, true
+}
+
+// loadCafeReservation memastikan reservasi milik cafe user yang login
+func (h *ReservationHandler) loadCafeReservation(w http.ResponseWriter, r *http.Request) (*models.Reservation, bool) {
+	user := middleware.CurrentUser(r)
+
+	res, ok := h.loadReservation(w, r)
+	if !ok {
+		return nil, false
+	}
+	if user.Role == models.RoleAdmin {
+		return res, true
+	}
+
+	cafeID, err := h.cafes.GetIDByUserID(r.Context(), user.ID)
+	if err != nil || cafeID != res.CafeID {
+		api.Fail(w, r, http.StatusNotFound, "Reservasi tidak ditemukan")
+		return nil, false
+	}
+	return res, true
+}
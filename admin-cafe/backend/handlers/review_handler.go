@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"backend/api"
+	"backend/middleware"
+	"backend/models"
+	"backend/notify"
+	"backend/repository"
+	"backend/reviews"
+	"backend/validate"
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type ReviewHandler struct {
+	reviews reviews.Store
+	cafes   repository.CafeProfileStore
+	users   repository.UserStore
+	notify  notify.Notifier
+}
+
+func NewReviewHandler(reviewService reviews.Store, cafes repository.CafeProfileStore, users repository.UserStore, n notify.Notifier) *ReviewHandler {
+	return &ReviewHandler{reviews: reviewService, cafes: cafes, users: users, notify: n}
+}
+
+// ==============================
+// Ulasan publik satu cafe
+// GET /cafes/{id}/reviews?rating=&limit=&offset=
+// ==============================
+// Hanya ulasan yang sudah disetujui cafe.
+func (h *ReviewHandler) PublicList(w http.ResponseWriter, r *http.Request) {
+	cafeID, _ := strconv.Atoi(mux.Vars(r)["id"])
+	f, invalid := reviewFilter(r)
+	if invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+	f.Status = reviews.StatusApproved
+	h.list(w, r, cafeID, f)
+}
+
+// ==============================
+// Ulasan cafe sendiri (semua status)
+// GET /cafe/reviews?status=pending&rating=&limit=&offset=
+// ==============================
+func (h *ReviewHandler) CafeList(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	f, invalid := reviewFilter(r)
+	if invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+	switch f.Status = r.URL.Query().Get("status"); f.Status {
+	case "", reviews.StatusPending, reviews.StatusApproved, reviews.StatusHidden:
+	default:
+		api.Fail(w, r, http.StatusBadRequest, "status harus pending, approved atau hidden")
+		return
+	}
+	h.list(w, r, cafeID, f)
+}
+
+func (h *ReviewHandler) list(w http.ResponseWriter, r *http.Request, cafeID int, f reviews.Filter) {
+	list, total, err := h.reviews.List(r.Context(), cafeID, f)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error listing reviews", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil ulasan")
+		return
+	}
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"reviews": list,
+		"total":   total,
+		"limit":   f.Limit,
+		"offset":  f.Offset,
+	})
+}
+
+// reviewFilter membaca rating & paging dari query string
+func reviewFilter(r *http.Request) (reviews.Filter, *api.Error) {
+	var f reviews.Filter
+	if v := r.URL.Query().Get("rating"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 5 {
+			return f, badRequest("rating harus di antara 1 dan 5")
+		}
+		f.Rating = n
+	}
+	limit, offset, invalid := parsePaging(r)
+	f.Limit, f.Offset = limit, offset
+	return f, invalid
+}
+
+// ==============================
+// Statistik ulasan cafe sendiri
+// GET /cafe/reviews/stats
+// ==============================
+func (h *ReviewHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	stats, err := h.reviews.Stats(r.Context(), cafeID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error reading review stats", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil statistik ulasan")
+		return
+	}
+	api.JSON(w, http.StatusOK, stats)
+}
+
+// ==============================
+// Kirim ulasan (customer)
+// POST /cafes/{id}/reviews  {"rating": 5, "text": "...", "image": "/uploads/..."}
+// ==============================
+// Nama, email & avatar diambil dari akun customer. Ulasan tampil publik
+// setelah disetujui atau dibalas cafe. Alias lama POST /ulasan memakai
+// ?cafe_id=.
+func (h *ReviewHandler) Create(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		id = r.URL.Query().Get("cafe_id")
+	}
+	cafeID, err := strconv.Atoi(id)
+	if err != nil || cafeID <= 0 {
+		api.Fail(w, r, http.StatusBadRequest, "Parameter cafe_id wajib diisi")
+		return
+	}
+
+	var body reviews.NewReview
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	cafe, err := h.cafes.GetByID(r.Context(), cafeID)
+	if err == sql.ErrNoRows || (err == nil && !cafe.Verified) {
+		api.Fail(w, r, http.StatusNotFound, "Cafe tidak ditemukan")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error reading cafe", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan ulasan")
+		return
+	}
+
+	profile, err := h.users.GetCustomerProfile(r.Context(), middleware.CurrentUser(r).ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error reading customer profile", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan ulasan")
+		return
+	}
+	body.UserID = profile.ID
+	body.Name = profile.DisplayName
+	if body.Name == "" {
+		body.Name = profile.Username
+	}
+	body.Email = profile.Email
+	body.Avatar = profile.Avatar
+
+	review, err := h.reviews.Create(r.Context(), cafeID, body, time.Now())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error creating review", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan ulasan")
+		return
+	}
+	api.JSON(w, http.StatusCreated, review)
+}
+
+// ==============================
+// Upload foto ulasan (customer)
+// POST /customer/reviews/image  (multipart: image)
+// ==============================
+func (h *ReviewHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
+	url, ok := receiveImage(w, r, "image")
+	if !ok {
+		return
+	}
+	api.JSON(w, http.StatusCreated, map[string]string{"url": url})
+}
+
+// ==============================
+// Balas ulasan
+// PUT /cafe/reviews/{id}/reply  {"reply": "Terima kasih!"}
+// ==============================
+// Ulasan pending yang dibalas otomatis disetujui dan penulisnya dikabari.
+func (h *ReviewHandler) Reply(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Reply string `json:"reply" validate:"trim,required,max=2000"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	review, err := h.reviews.SetReply(r.Context(), cafeID, reviewID(r), body.Reply, time.Now())
+	if err == nil && review.UserID != nil {
+		h.notifyReplied(r.Context(), cafeID, review)
+	}
+	h.writeReview(w, r, review, err)
+}
+
+// notifyReplied mengabarkan balasan cafe ke penulis ulasan
+func (h *ReviewHandler) notifyReplied(ctx context.Context, cafeID int, review *reviews.Review) {
+	cafe, err := h.cafes.GetByID(ctx, cafeID)
+	if err == nil {
+		err = h.notify.Notify(ctx, *review.UserID, models.NotifyReviewReplied, notify.Data{
+			"cafe":  cafe.Nama,
+			"reply": review.Reply,
+		})
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Notify review reply error", "err", err)
+	}
+}
+
+// ==============================
+// Hapus balasan
+// DELETE /cafe/reviews/{id}/reply
+// ==============================
+func (h *ReviewHandler) DeleteReply(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	review, err := h.reviews.ClearReply(r.Context(), cafeID, reviewID(r), time.Now())
+	h.writeReview(w, r, review, err)
+}
+
+// ==============================
+// Ubah status ulasan
+// PUT /cafe/reviews/{id}/status  {"status": "hidden"}
+// ==============================
+func (h *ReviewHandler) SetStatus(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Status string `json:"status" validate:"required,oneof=pending approved hidden"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	review, err := h.reviews.SetStatus(r.Context(), cafeID, reviewID(r), body.Status, time.Now())
+	h.writeReview(w, r, review, err)
+}
+
+// ==============================
+// Hapus ulasan
+// DELETE /cafe/reviews/{id}
+// ==============================
+func (h *ReviewHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	cafeID, ok := requireCafeID(w, r, h.cafes)
+	if !ok {
+		return
+	}
+
+	review, err := h.reviews.Delete(r.Context(), cafeID, reviewID(r))
+	if err != nil {
+		h.writeReview(w, r, nil, err)
+		return
+	}
+	removeUpload(review.Image)
+	api.JSON(w, http.StatusOK, map[string]string{"message": "Ulasan dihapus"})
+}
+
+func (h *ReviewHandler) writeReview(w http.ResponseWriter, r *http.Request, review *reviews.Review, err error) {
+	if err == reviews.ErrNotFound {
+		api.Fail(w, r, http.StatusNotFound, "Ulasan tidak ditemukan")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error updating review", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan ulasan")
+		return
+	}
+	api.JSON(w, http.StatusOK, review)
+}
+
+func reviewID(r *http.Request) string {
+	return strings.ToLower(mux.Vars(r)["id"])
+}
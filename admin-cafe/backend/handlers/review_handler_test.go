@@ -0,0 +1,105 @@
+package handlers_test
+
+import (
+	"backend/models"
+	"backend/repository/memory"
+	"backend/reviews"
+	"context"
+	"net/http"
+	"testing"
+)
+
+type reviewList struct {
+	Reviews []reviews.Review `json:"reviews"`
+	Total   int              `json:"total"`
+}
+
+func TestReviewModeration(t *testing.T) {
+	e := newEnv(t)
+	cafeUserID, cafeID := e.addCafe("kopikita", true)
+	customerID := e.users.Add(memory.User{
+		User:          models.User{Username: "budi", Password: "rahasia123", Email: "budi@mail.com", Role: models.RoleCustomer, Verified: true},
+		DisplayName:   "Budi S.",
+		EmailVerified: true,
+	})
+	customer := e.login(t, customerID)
+	cafe := e.login(t, cafeUserID)
+
+	var created reviews.Review
+	expect(t, e.do(t, "POST", "/cafes/"+itoa(cafeID)+"/reviews", customer, map[string]interface{}{
+		"rating": 5, "text": "Kopinya enak",
+	}), http.StatusCreated, &created)
+	if created.Status != reviews.StatusPending || created.Name != "Budi S." || created.Email != "budi@mail.com" ||
+		created.UserID == nil || *created.UserID != customerID {
+		t.Fatalf("ulasan baru = %+v", created)
+	}
+
+	// Belum tampil publik sebelum disetujui
+	var public reviewList
+	expect(t, e.do(t, "GET", "/cafes/"+itoa(cafeID)+"/reviews", "", nil), http.StatusOK, &public)
+	if public.Total != 0 || len(public.Reviews) != 0 {
+		t.Fatalf("ulasan publik = %+v", public)
+	}
+
+	var pending reviewList
+	expect(t, e.do(t, "GET", "/cafe/reviews?status=pending", cafe, nil), http.StatusOK, &pending)
+	if pending.Total != 1 || pending.Reviews[0].ID != created.ID {
+		t.Fatalf("ulasan pending = %+v", pending)
+	}
+
+	// Membalas sekaligus menyetujui
+	var replied reviews.Review
+	expect(t, e.do(t, "PUT", "/cafe/reviews/"+created.ID+"/reply", cafe, map[string]string{"reply": "Terima kasih!"}),
+		http.StatusOK, &replied)
+	if replied.Status != reviews.StatusApproved || replied.Reply != "Terima kasih!" {
+		t.Fatalf("ulasan dibalas = %+v", replied)
+	}
+	sent := e.notifier.Sent()
+	if len(sent) != 1 || sent[0].UserID != customerID || sent[0].Event != models.NotifyReviewReplied ||
+		sent[0].Data["reply"] != "Terima kasih!" {
+		t.Fatalf("notifikasi = %+v", sent)
+	}
+
+	expect(t, e.do(t, "GET", "/cafes/"+itoa(cafeID)+"/reviews", "", nil), http.StatusOK, &public)
+	if public.Total != 1 || public.Reviews[0].Reply != "Terima kasih!" {
+		t.Fatalf("ulasan publik = %+v", public)
+	}
+}
+
+func TestReviewRequiresVerifiedCafe(t *testing.T) {
+	e := newEnv(t)
+	_, pendingCafe := e.addCafe("kopikita", false)
+	customer := e.login(t, e.addUser("budi", models.RoleCustomer, true))
+
+	body := map[string]interface{}{"rating": 4, "text": "Enak"}
+	expect(t, e.do(t, "POST", "/cafes/"+itoa(pendingCafe)+"/reviews", customer, body), http.StatusNotFound, nil)
+	expect(t, e.do(t, "POST", "/cafes/999/reviews", customer, body), http.StatusNotFound, nil)
+
+	var res errorResponse
+	expect(t, e.do(t, "POST", "/cafes/"+itoa(pendingCafe)+"/reviews", customer, map[string]interface{}{"rating": 6, "text": ""}),
+		http.StatusBadRequest, &res)
+	if res.Code != "validation_failed" || len(res.Fields) != 2 {
+		t.Fatalf("response = %+v", res)
+	}
+}
+
+func TestReviewReplyScopedToOwnCafe(t *testing.T) {
+	ctx := context.Background()
+
+	e := newEnv(t)
+	_, cafeID := e.addCafe("kopikita", true)
+	otherUserID, _ := e.addCafe("senja", true)
+	customerID := e.addUser("budi", models.RoleCustomer, true)
+
+	rv, err := e.reviews.Create(ctx, cafeID, reviews.NewReview{Rating: 5, Text: "Mantap", UserID: customerID, Name: "budi"}, testNow())
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	other := e.login(t, otherUserID)
+	expect(t, e.do(t, "PUT", "/cafe/reviews/"+rv.ID+"/reply", other, map[string]string{"reply": "Hai"}), http.StatusNotFound, nil)
+	expect(t, e.do(t, "DELETE", "/cafe/reviews/"+rv.ID, other, nil), http.StatusNotFound, nil)
+	if got, err := e.reviews.Get(ctx, cafeID, rv.ID); err != nil || got.Reply != "" || got.Status != reviews.StatusPending {
+		t.Fatalf("ulasan = %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"backend/api"
+	"backend/models"
+	"backend/repository"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+	maxSearchLength    = 100
+)
+
+type SearchHandler struct {
+	repo repository.SearchStore
+}
+
+func NewSearchHandler(repo repository.SearchStore) *SearchHandler {
+	return &SearchHandler{repo: repo}
+}
+
+// ==============================
+// Pencarian gabungan cafe & menu
+// GET /search?q=&type=cafe,menu&limit=
+// ==============================
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	text := strings.TrimSpace(params.Get("q"))
+	if text == "" {
+		api.Fail(w, r, http.StatusBadRequest, "Parameter q wajib diisi")
+		return
+	}
+	if utf8.RuneCountInString(text) > maxSearchLength {
+		api.Failf(w, r, http.StatusBadRequest, "Parameter q maksimal %d karakter", maxSearchLength)
+		return
+	}
+
+	q := models.SearchQuery{
+		Text:  text,
+		Types: []string{models.SearchTypeCafe, models.SearchTypeMenu},
+		Limit: defaultSearchLimit,
+	}
+
+	if v := params.Get("type"); v != "" && v != "all" {
+		q.Types = nil
+		for _, t := range strings.Split(v, ",") {
+			t = strings.ToLower(strings.TrimSpace(t))
+			if t != models.SearchTypeCafe && t != models.SearchTypeMenu {
+				api.Fail(w, r, http.StatusBadRequest, "type harus cafe, menu atau all")
+				return
+			}
+			q.Types = append(q.Types, t)
+		}
+	}
+
+	if v := params.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxSearchLimit {
+			api.Failf(w, r, http.StatusBadRequest, "limit harus di antara 1 dan %d", maxSearchLimit)
+			return
+		}
+		q.Limit = limit
+	}
+
+	hits, fuzzy, err := h.repo.Search(r.Context(), q)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB query error in Search", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal melakukan pencarian")
+		return
+	}
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"query": text,
+		"fuzzy": fuzzy,
+		"total": len(hits),
+		"hits":  hits,
+	})
+}
@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"backend/api"
+	"backend/audit"
+	"backend/middleware"
+	"backend/models"
+	"backend/payments"
+	"backend/repository"
+	"backend/validate"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var planCodePattern = regexp.MustCompile(`^[a-z0-9_]{3,50}$`)
+
+type SubscriptionHandler struct {
+	subscriptions repository.SubscriptionStore
+	payments      payments.Store
+}
+
+func NewSubscriptionHandler(subscriptions repository.SubscriptionStore, payments payments.Store) *SubscriptionHandler {
+	return &SubscriptionHandler{subscriptions: subscriptions, payments: payments}
+}
+
+// ==============================
+// Daftar paket premium
+// GET /subscription-plans
+// ==============================
+func (h *SubscriptionHandler) ListPlans(w http.ResponseWriter, r *http.Request) {
+	plans, err := h.subscriptions.ListPlans(r.Context(), true)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in ListPlans", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil paket langganan")
+		return
+	}
+	api.JSON(w, http.StatusOK, map[string]interface{}{"plans": plans})
+}
+
+// ==============================
+// Status langganan customer
+// GET /customer/subscription
+// ==============================
+func (h *SubscriptionHandler) GetSubscription(w http.ResponseWriter, r *http.Request) {
+	user := middleware.CurrentUser(r)
+
+	sub, err := h.subscriptions.Current(r.Context(), user.ID)
+	if err == repository.ErrSubscriptionNotFound {
+		api.JSON(w, http.StatusOK, map[string]interface{}{
+			"premium":      false,
+			"subscription": nil,
+			"periods":      []models.SubscriptionPeriod{},
+		})
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in GetSubscription", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil langganan")
+		return
+	}
+
+	periods, err := h.subscriptions.Periods(r.Context(), sub.ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in GetSubscription", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil langganan")
+		return
+	}
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"premium":      sub.Entitled(time.Now()),
+		"subscription": sub,
+		"periods":      periods,
+	})
+}
+
+// ==============================
+// Mulai berlangganan
+// POST /customer/subscription  {"plan_code": "premium_monthly", "method": "qris|ewallet|bank_transfer"}
+// ==============================
+// Paket berbayar baru aktif setelah tagihan yang dikembalikan lunas.
+// Memanggil ulang endpoint ini dengan langganan pending atau past_due
+// mengembalikan tagihannya (atau membuat tagihan baru).
+func (h *SubscriptionHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	user := middleware.CurrentUser(r)
+
+	var body struct {
+		PlanCode string `json:"plan_code" validate:"trim,required,max=50"`
+		Method   string `json:"method" validate:"required"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+	method, err := payments.ParseMethod(body.Method)
+	if err != nil {
+		api.Fail(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sub, err := h.subscriptions.Start(r.Context(), user.ID, body.PlanCode, time.Now())
+	switch err {
+	case nil:
+	case repository.ErrPlanNotFound:
+		api.Fail(w, r, http.StatusNotFound, err.Error())
+		return
+	case repository.ErrAlreadySubscribed:
+		api.Fail(w, r, http.StatusConflict, err.Error())
+		return
+	default:
+		slog.ErrorContext(r.Context(), "DB error in Subscribe", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal memulai langganan")
+		return
+	}
+
+	if sub.Status == models.SubscriptionActive {
+		audit.Record(r, audit.Entry{
+			Action:     "subscription.started",
+			EntityType: "subscription",
+			EntityID:   strconv.Itoa(sub.ID),
+			Title:      fmt.Sprintf("USER %s berlangganan PREMIUM", user.Username),
+			Subject:    "Berlangganan " + sub.Plan.Name,
+			Claim:      repository.PremiumClaim,
+			After:      sub,
+		})
+		api.JSON(w, http.StatusCreated, map[string]interface{}{
+			"message":      "Selamat, kamu sekarang anggota premium",
+			"subscription": sub,
+		})
+		return
+	}
+
+	payment, err := h.payments.CreateForSubscription(r.Context(), sub.ID, method)
+	switch err {
+	case nil:
+	case payments.ErrSubscriptionNotPayable, payments.ErrInProgress:
+		api.Fail(w, r, http.StatusConflict, err.Error())
+		return
+	default:
+		slog.ErrorContext(r.Context(), "Payment error in Subscribe", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal membuat pembayaran")
+		return
+	}
+
+	audit.Record(r, audit.Entry{
+		Action:     "subscription.checkout",
+		EntityType: "subscription",
+		EntityID:   strconv.Itoa(sub.ID),
+		Title:      fmt.Sprintf("USER %s membuat tagihan langganan PREMIUM", user.Username),
+		Subject:    "Tagihan " + sub.Plan.Name,
+		Claim:      repository.PremiumClaim,
+		After:      sub,
+	})
+
+	api.JSON(w, http.StatusCreated, map[string]interface{}{
+		"message":      "Selesaikan pembayaran untuk mengaktifkan premium",
+		"subscription": sub,
+		"payment":      payment,
+	})
+}
+
+// ==============================
+// Batalkan / lanjutkan langganan
+// POST /customer/subscription/cancel
+// POST /customer/subscription/resume
+// ==============================
+func (h *SubscriptionHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	user := middleware.CurrentUser(r)
+
+	sub, err := h.subscriptions.Cancel(r.Context(), user.ID, time.Now())
+	if err == repository.ErrSubscriptionNotFound {
+		api.Fail(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in Cancel subscription", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal membatalkan langganan")
+		return
+	}
+
+	audit.Record(r, audit.Entry{
+		Action:     "subscription.cancelled",
+		EntityType: "subscription",
+		EntityID:   strconv.Itoa(sub.ID),
+		Title:      fmt.Sprintf("USER %s membatalkan PREMIUM", user.Username),
+		Subject:    "Berhenti Berlangganan",
+		Claim:      repository.PremiumClaim,
+		After:      sub,
+	})
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"message":      "Langganan dibatalkan, akses premium berlaku sampai akhir periode",
+		"subscription": sub,
+	})
+}
+
+func (h *SubscriptionHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	user := middleware.CurrentUser(r)
+
+	sub, err := h.subscriptions.Resume(r.Context(), user.ID, time.Now())
+	if err == repository.ErrNotResumable {
+		api.Fail(w, r, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in Resume subscription", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal melanjutkan langganan")
+		return
+	}
+
+	audit.Record(r, audit.Entry{
+		Action:     "subscription.resumed",
+		EntityType: "subscription",
+		EntityID:   strconv.Itoa(sub.ID),
+		Title:      fmt.Sprintf("USER %s melanjutkan PREMIUM", user.Username),
+		Subject:    "Lanjut Berlangganan",
+		Claim:      repository.PremiumClaim,
+		After:      sub,
+	})
+
+	api.JSON(w, http.StatusOK, map[string]interface{}{
+		"message":      "Langganan akan diperpanjang otomatis",
+		"subscription": sub,
+	})
+}
+
+// ==============================
+// Kelola paket (super admin)
+// GET /admin/subscription-plans
+// POST /admin/subscription-plans
+// PUT /admin/subscription-plans/{id}
+// {"code": "premium_monthly", "name": "Premium Bulanan", "price": 29000, "billing_period": "monthly", "active": true}
+// ==============================
+func (h *SubscriptionHandler) AdminListPlans(w http.ResponseWriter, r *http.Request) {
+	plans, err := h.subscriptions.ListPlans(r.Context(), false)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in AdminListPlans", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal mengambil paket langganan")
+		return
+	}
+	api.JSON(w, http.StatusOK, map[string]interface{}{"plans": plans})
+}
+
+func (h *SubscriptionHandler) SavePlan(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Code          string  `json:"code" validate:"trim"`
+		Name          string  `json:"name" validate:"trim,required,max=100"`
+		Price         float64 `json:"price" validate:"min=0"`
+		BillingPeriod string  `json:"billing_period" validate:"required,oneof=monthly yearly"`
+		Active        *bool   `json:"active"`
+	}
+	if invalid := validate.DecodeJSON(r, &body); invalid != nil {
+		api.WriteError(w, r, invalid)
+		return
+	}
+
+	plan := &models.SubscriptionPlan{
+		Code:          strings.ToLower(body.Code),
+		Name:          body.Name,
+		Price:         body.Price,
+		BillingPeriod: body.BillingPeriod,
+		Active:        body.Active == nil || *body.Active,
+	}
+
+	status := http.StatusOK
+	if idParam, isUpdate := mux.Vars(r)["id"]; isUpdate {
+		id, err := strconv.Atoi(idParam)
+		if err != nil {
+			api.Fail(w, r, http.StatusBadRequest, "ID paket tidak valid")
+			return
+		}
+		plan.ID = id
+	} else {
+		status = http.StatusCreated
+		if !planCodePattern.MatchString(plan.Code) {
+			api.Fail(w, r, http.StatusBadRequest, "code hanya boleh huruf kecil, angka dan _ (3-50 karakter)")
+			return
+		}
+	}
+
+	err := h.subscriptions.SavePlan(r.Context(), plan)
+	if err == repository.ErrPlanNotFound {
+		api.Fail(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	if repository.IsUniqueViolation(err) {
+		api.Fail(w, r, http.StatusConflict, "Kode paket sudah dipakai")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DB error in SavePlan", "err", err)
+		api.Fail(w, r, http.StatusInternalServerError, "Gagal menyimpan paket langganan")
+		return
+	}
+
+	audit.Record(r, audit.Entry{
+		Action:     "subscription_plan.saved",
+		EntityType: "subscription_plan",
+		EntityID:   strconv.Itoa(plan.ID),
+		Title:      "Paket langganan " + plan.Code + " disimpan",
+		After:      plan,
+	})
+
+	api.JSON(w, status, plan)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"backend/api"
+	"backend/metrics"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	uploadDir     = "./uploads"
+	maxUploadSize = 10 << 20 // 10 MB
+)
+
+// Tipe file gambar yang boleh diupload (ekstensi -> MIME hasil sniffing)
+var imageTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".webp": "image/webp",
+}
+
+var errInvalidFileType = errors.New("tipe file tidak didukung")
+
+// saveUpload menyimpan file dari form field ke folder uploads dengan nama
+// acak (nama asli dari client tidak dipakai) dan mengembalikan nama
+// file-nya. Isi file dicek dengan http.DetectContentType, bukan hanya
+// dari ekstensi.
+func saveUpload(r *http.Request, field string, allowed map[string]string) (string, error) {
+	file, header, err := r.FormFile(field)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	ext := strings.ToLower(filepath.Ext(header.Filename))
+	mime, ok := allowed[ext]
+	if !ok {
+		return "", errInvalidFileType
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	if http.DetectContentType(head[:n]) != mime {
+		return "", errInvalidFileType
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	name := hex.EncodeToString(b) + ext
+
+	out, err := os.Create(filepath.Join(uploadDir, name))
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	if _, err := out.Write(head[:n]); err != nil {
+		return "", err
+	}
+	size, err := io.Copy(out, io.LimitReader(file, maxUploadSize))
+	if err != nil {
+		return "", err
+	}
+	metrics.UploadBytes.Observe(float64(int64(n)+size), field)
+	return name, nil
+}
+
+// removeUpload menghapus file upload lama berdasarkan URL "/uploads/<nama>"
+func removeUpload(url string) {
+	name := strings.TrimPrefix(url, "/uploads/")
+	if name == "" || name == url || strings.ContainsAny(name, `/\`) {
+		return
+	}
+	os.Remove(filepath.Join(uploadDir, name))
+}
+
+// receiveImage menyimpan gambar dari form field dan mengembalikan URL
+// "/uploads/<nama>". Jika gagal, response error sudah ditulis dan ok
+// bernilai false.
+func receiveImage(w http.ResponseWriter, r *http.Request, field string) (string, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize+(1<<20))
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		api.Fail(w, r, http.StatusBadRequest, "File terlalu besar (maks 10 MB)")
+		return "", false
+	}
+
+	name, err := saveUpload(r, field, imageTypes)
+	if err == errInvalidFileType {
+		api.Fail(w, r, http.StatusBadRequest, "File harus berupa gambar JPG, PNG atau WEBP")
+		return "", false
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Upload image error", "field", field, "err", err)
+		api.Fail(w, r, http.StatusBadRequest, "Gagal menyimpan gambar")
+		return "", false
+	}
+	return "/uploads/" + name, true
+}
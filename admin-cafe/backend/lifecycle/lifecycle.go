@@ -0,0 +1,51 @@
+// Package lifecycle mengatur siklus hidup server: menangkap sinyal
+// berhenti, menguras request HTTP yang sedang berjalan dan mengawasi
+// background worker sampai semuanya selesai.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// SignalContext mengembalikan context yang selesai saat proses menerima
+// SIGINT (Ctrl+C) atau SIGTERM (deploy/orchestrator)
+func SignalContext(parent context.Context) (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+}
+
+// Serve menjalankan srv sampai ctx selesai, lalu berhenti menerima
+// koneksi baru dan menunggu request yang sedang berjalan paling lama
+// drain. Error dikembalikan jika server gagal start (mis. port dipakai)
+// atau request belum selesai saat drain habis.
+func Serve(ctx context.Context, srv *http.Server, drain time.Duration) error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down HTTP server", "drain_timeout", drain.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), drain)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		// Putus paksa koneksi yang belum selesai
+		srv.Close()
+		return err
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
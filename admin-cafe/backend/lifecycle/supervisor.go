@@ -0,0 +1,123 @@
+package lifecycle
+
+import (
+	"backend/metrics"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Backoff restart worker: mulai 1 detik, berlipat sampai 1 menit. Worker
+// yang sempat berjalan normal selama resetAfter dianggap sehat lagi.
+const (
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+	resetAfter = time.Minute
+)
+
+// ErrStopTimeout dikembalikan Stop jika ada worker yang belum selesai
+var ErrStopTimeout = errors.New("background worker belum berhenti sampai batas waktu")
+
+// Supervisor menjalankan background worker dengan context bersama.
+// Worker yang panic atau berhenti sebelum Stop dijalankan ulang dengan
+// backoff; Stop membatalkan context lalu menunggu putaran yang sedang
+// berjalan selesai.
+type Supervisor struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewSupervisor() *Supervisor {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Supervisor{ctx: ctx, cancel: cancel}
+}
+
+// Go menjalankan run di goroutine sampai Stop. run harus berhenti saat
+// ctx selesai.
+func (s *Supervisor) Go(name string, run func(ctx context.Context) error) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		backoff := minBackoff
+		for {
+			started := time.Now()
+			err := safeRun(s.ctx, name, run)
+			if s.ctx.Err() != nil {
+				return
+			}
+			if time.Since(started) >= resetAfter {
+				backoff = minBackoff
+			}
+			if err == nil {
+				err = errors.New("worker berhenti tanpa diminta")
+			}
+			slog.Error("Background worker stopped, restarting", "worker", name,
+				"err", err, "backoff", backoff.String())
+
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, maxBackoff)
+		}
+	}()
+}
+
+// Every menjalankan job setiap interval. Setiap putaran dicatat sebagai
+// heartbeat & durasi job di metrics; putaran yang gagal hanya dicatat ke
+// log dan job tetap berjalan di putaran berikutnya.
+func (s *Supervisor) Every(name string, interval time.Duration, job func(ctx context.Context) error) {
+	metrics.ExpectJob(name, interval)
+	s.Go(name, func(ctx context.Context) error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+				start := time.Now()
+				err := job(ctx)
+				metrics.JobDone(name, start, err)
+				if err != nil && ctx.Err() == nil {
+					slog.Error("Background job error", "job", name, "err", err)
+				}
+			}
+		}
+	})
+}
+
+// Stop menghentikan semua worker dan menunggu paling lama timeout
+func (s *Supervisor) Stop(timeout time.Duration) error {
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		return ErrStopTimeout
+	}
+}
+
+// safeRun mengubah panic di worker menjadi error supaya bisa di-restart.
+// Stack trace hanya ditulis ke log.
+func safeRun(ctx context.Context, name string, run func(ctx context.Context) error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			slog.Error("Background worker panic", "worker", name, "panic", fmt.Sprint(p), "stack", string(debug.Stack()))
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return run(ctx)
+}
@@ -0,0 +1,95 @@
+// Package logging menyiapkan structured logging (log/slog) untuk backend.
+//
+// Setup memasang handler JSON (atau teks) sebagai logger default, dengan
+// redaksi atribut rahasia. Middleware memberi setiap request sebuah
+// request ID yang dikirim balik di header X-Request-ID, ikut tercatat di
+// setiap log yang ditulis dengan context request (slog.InfoContext dkk.)
+// dan di komentar query database lewat Connector.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Nilai pengganti atribut rahasia
+const redacted = "[REDACTED]"
+
+// Potongan nama atribut yang nilainya tidak boleh masuk log
+var secretKeys = []string{"password", "passwd", "secret", "token", "authorization", "cookie", "api_key", "apikey"}
+
+// Setup memasang logger default. level: debug, info, warn atau error;
+// format: json atau text. Nilai tidak dikenal memakai info & json.
+func Setup(w io.Writer, level, format string) {
+	opts := &slog.HandlerOptions{
+		Level:       parseLevel(level),
+		ReplaceAttr: redact,
+	}
+
+	var h slog.Handler
+	if strings.EqualFold(format, "text") {
+		h = slog.NewTextHandler(w, opts)
+	} else {
+		h = slog.NewJSONHandler(w, opts)
+	}
+	slog.SetDefault(slog.New(contextHandler{h}))
+	// Sisa pemanggilan package log hanya log.Fatal saat startup
+	slog.SetLogLoggerLevel(slog.LevelError)
+}
+
+func parseLevel(s string) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// redact mengganti nilai atribut yang namanya mirip data rahasia
+func redact(groups []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() == slog.KindGroup {
+		return a
+	}
+	if IsSecretKey(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+	return a
+}
+
+// IsSecretKey melaporkan apakah nama field/atribut berisi data rahasia
+// seperti password, token atau header Authorization
+func IsSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range secretKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// contextHandler menambahkan request_id & user_id dari context request
+// ke setiap record log
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, rec slog.Record) error {
+	if s := stateFrom(ctx); s != nil {
+		rec.AddAttrs(slog.String("request_id", s.id))
+		if id := s.userID.Load(); id != 0 {
+			rec.AddAttrs(slog.Int64("user_id", id))
+		}
+	}
+	return h.Handler.Handle(ctx, rec)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
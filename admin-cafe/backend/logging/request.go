@@ -0,0 +1,122 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sync/atomic"
+	"time"
+)
+
+// HeaderRequestID adalah header request ID di request & response
+const HeaderRequestID = "X-Request-ID"
+
+// Request ID dari client dipakai ulang hanya jika aman ditulis ke log &
+// komentar SQL
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+type contextKey string
+
+const stateKey contextKey = "logging"
+
+// requestState adalah data log milik satu request. user_id diisi belakangan
+// oleh middleware Auth, jadi disimpan sebagai pointer di context.
+type requestState struct {
+	id     string
+	userID atomic.Int64
+}
+
+func stateFrom(ctx context.Context) *requestState {
+	s, _ := ctx.Value(stateKey).(*requestState)
+	return s
+}
+
+// RequestID mengembalikan request ID dari context, atau "" di luar request
+func RequestID(ctx context.Context) string {
+	if s := stateFrom(ctx); s != nil {
+		return s.id
+	}
+	return ""
+}
+
+// SetUserID mencatat user yang login untuk log request ini. Dipanggil oleh
+// middleware Auth.
+func SetUserID(ctx context.Context, id int) {
+	if s := stateFrom(ctx); s != nil {
+		s.userID.Store(int64(id))
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// statusWriter menyimpan status code & jumlah byte yang ditulis handler
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// Flush diteruskan supaya streaming response (SSE) tetap jalan
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Middleware memberi request ID (memakai X-Request-ID dari client jika
+// valid), menuliskannya di header response, lalu mencatat satu access log
+// per request. Query string tidak dicatat karena bisa berisi token.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		id := r.Header.Get(HeaderRequestID)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+		s := &requestState{id: id}
+		ctx := context.WithValue(r.Context(), stateKey, s)
+		w.Header().Set(HeaderRequestID, id)
+
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r.WithContext(ctx))
+
+		if sw.status == 0 {
+			sw.status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if sw.status >= 500 {
+			level = slog.LevelError
+		}
+		slog.Default().LogAttrs(ctx, level, "request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", sw.status),
+			slog.Int("bytes", sw.bytes),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+		)
+	})
+}
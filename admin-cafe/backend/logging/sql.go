@@ -0,0 +1,121 @@
+package logging
+
+import (
+	"context"
+	"database/sql/driver"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Connector membungkus connector driver database. Query yang dijalankan
+// dengan context request (QueryContext, ExecContext, ...) diberi komentar
+// /* request_id=... */ sehingga terlihat di pg_stat_activity & log
+// PostgreSQL, dan dicatat di level debug beserta durasinya.
+func Connector(c driver.Connector) driver.Connector {
+	return tracedConnector{c}
+}
+
+type tracedConnector struct {
+	driver.Connector
+}
+
+func (c tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tracedConn{conn}, nil
+}
+
+// tag menambahkan komentar request ID di depan query. Request ID sudah
+// divalidasi Middleware sehingga tidak bisa menutup komentar.
+func tag(ctx context.Context, query string) string {
+	if id := RequestID(ctx); id != "" {
+		return "/* request_id=" + id + " */ " + query
+	}
+	return query
+}
+
+// logQuery mencatat query di level debug. Argumen query tidak dicatat
+// karena bisa berisi password atau token.
+func logQuery(ctx context.Context, query string, start time.Time, err error) {
+	if !slog.Default().Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) > 200 {
+		query = query[:200] + "..."
+	}
+	attrs := []slog.Attr{
+		slog.String("query", query),
+		slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("err", err.Error()))
+	}
+	slog.Default().LogAttrs(ctx, slog.LevelDebug, "db query", attrs...)
+}
+
+// tracedConn meneruskan semua interface opsional driver ke koneksi asli
+type tracedConn struct {
+	driver.Conn
+}
+
+func (c tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := q.QueryContext(ctx, tag(ctx, query), args)
+	logQuery(ctx, query, start, err)
+	return rows, err
+}
+
+func (c tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	res, err := e.ExecContext(ctx, tag(ctx, query), args)
+	logQuery(ctx, query, start, err)
+	return res, err
+}
+
+func (c tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, tag(ctx, query))
+	}
+	return c.Conn.Prepare(tag(ctx, query))
+}
+
+func (c tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	// Driver lama tanpa BeginTx
+	return c.Conn.Begin()
+}
+
+func (c tracedConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c tracedConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c tracedConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileMailer menyimpan email sebagai file .eml di Dir. Jika Dir kosong,
+// email ditulis ke stdout. Dipakai untuk development lokal.
+type FileMailer struct {
+	Dir  string
+	From string
+
+	mu sync.Mutex
+}
+
+func (m *FileMailer) Send(msg Message) error {
+	raw := buildMIME(m.From, msg)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Dir == "" {
+		fmt.Printf("===== EMAIL =====\n%s\n===== END EMAIL =====\n", raw)
+		return nil
+	}
+
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+	to := strings.NewReplacer("@", "_at_", "/", "_", "\\", "_").Replace(msg.To)
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102-150405.000000000"), to)
+	return os.WriteFile(filepath.Join(m.Dir, name), raw, 0o644)
+}
@@ -0,0 +1,54 @@
+// Package mailer mengirim email transaksional (verifikasi email, reset
+// password, dll). Implementasi dipilih lewat environment variable MAILER:
+// "smtp" untuk server SMTP sungguhan, "file" untuk menyimpan email sebagai
+// file .eml, dan "stdout" (default) untuk development lokal.
+package mailer
+
+import (
+	"backend/config"
+	"fmt"
+	"strconv"
+)
+
+// Message adalah satu email yang siap dikirim
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer mengirim email
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// FromEnv membuat Mailer sesuai konfigurasi environment
+func FromEnv() (Mailer, error) {
+	from := config.Getenv("MAIL_FROM", "CariSpot <no-reply@carispot.local>")
+
+	switch driver := config.Getenv("MAILER", "stdout"); driver {
+	case "smtp":
+		port, err := strconv.Atoi(config.Getenv("SMTP_PORT", "587"))
+		if err != nil {
+			return nil, fmt.Errorf("SMTP_PORT tidak valid: %w", err)
+		}
+		host := config.Getenv("SMTP_HOST", "")
+		if host == "" {
+			return nil, fmt.Errorf("SMTP_HOST wajib diisi untuk MAILER=smtp")
+		}
+		return &SMTPMailer{
+			Host:     host,
+			Port:     port,
+			Username: config.Getenv("SMTP_USER", ""),
+			Password: config.Getenv("SMTP_PASSWORD", ""),
+			From:     from,
+		}, nil
+	case "file":
+		return &FileMailer{Dir: config.Getenv("MAIL_DIR", "./mails"), From: from}, nil
+	case "stdout":
+		return &FileMailer{From: from}, nil
+	default:
+		return nil, fmt.Errorf("MAILER tidak dikenal: %q", driver)
+	}
+}
@@ -0,0 +1,223 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Nama template email
+const (
+	TemplateVerifyEmail    = "verify_email"
+	TemplateResetPassword  = "reset_password"
+	TemplateChangeRejected = "change_rejected"
+	TemplateNotification   = "notification"
+)
+
+// Bahasa email yang didukung, default Indonesia
+const (
+	LangID = "id"
+	LangEN = "en"
+)
+
+// TemplateData adalah data yang bisa dipakai di template email
+type TemplateData struct {
+	Name      string
+	Link      string
+	ExpiresIn string
+
+	// Perubahan yang ditolak & alasannya (template change_rejected)
+	Change string
+	Reason string
+
+	// Isi notifikasi umum (template notification)
+	Title string
+	Body  string
+}
+
+type emailTemplate struct {
+	subject string
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+const layoutHTML = `<!DOCTYPE html>
+<html><body style="font-family:Arial,sans-serif;color:#333;max-width:560px;margin:auto">
+<h2 style="color:#6b4226">CariSpot</h2>
+{{template "content" .}}
+</body></html>`
+
+var templates = map[string]map[string]emailTemplate{
+	TemplateVerifyEmail: {
+		LangID: newTemplate(
+			"Verifikasi email akun CariSpot kamu",
+			`Halo {{.Name}},
+
+Terima kasih sudah mendaftar di CariSpot. Klik link berikut untuk memverifikasi email kamu:
+
+{{.Link}}
+
+Link ini berlaku selama {{.ExpiresIn}}. Abaikan email ini jika kamu tidak merasa mendaftar.`,
+			`<p>Halo {{.Name}},</p>
+<p>Terima kasih sudah mendaftar di CariSpot. Klik tombol berikut untuk memverifikasi email kamu:</p>
+<p><a href="{{.Link}}" style="background:#6b4226;color:#fff;padding:10px 18px;border-radius:6px;text-decoration:none">Verifikasi Email</a></p>
+<p>Link ini berlaku selama {{.ExpiresIn}}. Abaikan email ini jika kamu tidak merasa mendaftar.</p>`,
+		),
+		LangEN: newTemplate(
+			"Verify your CariSpot account email",
+			`Hi {{.Name}},
+
+Thanks for signing up to CariSpot. Open the link below to verify your email:
+
+{{.Link}}
+
+This link is valid for {{.ExpiresIn}}. Ignore this email if you did not sign up.`,
+			`<p>Hi {{.Name}},</p>
+<p>Thanks for signing up to CariSpot. Click the button below to verify your email:</p>
+<p><a href="{{.Link}}" style="background:#6b4226;color:#fff;padding:10px 18px;border-radius:6px;text-decoration:none">Verify Email</a></p>
+<p>This link is valid for {{.ExpiresIn}}. Ignore this email if you did not sign up.</p>`,
+		),
+	},
+	TemplateResetPassword: {
+		LangID: newTemplate(
+			"Reset password akun CariSpot",
+			`Halo {{.Name}},
+
+Kami menerima permintaan reset password untuk akun kamu. Klik link berikut untuk membuat password baru:
+
+{{.Link}}
+
+Link ini hanya bisa dipakai sekali dan berlaku selama {{.ExpiresIn}}. Jika kamu tidak meminta reset password, abaikan email ini.`,
+			`<p>Halo {{.Name}},</p>
+<p>Kami menerima permintaan reset password untuk akun kamu. Klik tombol berikut untuk membuat password baru:</p>
+<p><a href="{{.Link}}" style="background:#6b4226;color:#fff;padding:10px 18px;border-radius:6px;text-decoration:none">Reset Password</a></p>
+<p>Link ini hanya bisa dipakai sekali dan berlaku selama {{.ExpiresIn}}. Jika kamu tidak meminta reset password, abaikan email ini.</p>`,
+		),
+		LangEN: newTemplate(
+			"Reset your CariSpot password",
+			`Hi {{.Name}},
+
+We received a request to reset the password of your account. Open the link below to choose a new password:
+
+{{.Link}}
+
+This link can only be used once and is valid for {{.ExpiresIn}}. If you did not request a reset, ignore this email.`,
+			`<p>Hi {{.Name}},</p>
+<p>We received a request to reset the password of your account. Click the button below to choose a new password:</p>
+<p><a href="{{.Link}}" style="background:#6b4226;color:#fff;padding:10px 18px;border-radius:6px;text-decoration:none">Reset Password</a></p>
+<p>This link can only be used once and is valid for {{.ExpiresIn}}. If you did not request a reset, ignore this email.</p>`,
+		),
+	},
+	TemplateChangeRejected: {
+		LangID: newTemplate(
+			"Perubahan cafe kamu ditolak",
+			`Halo {{.Name}},
+
+Perubahan {{.Change}} yang kamu ajukan ditolak oleh admin CariSpot dengan alasan:
+
+{{.Reason}}
+
+Data cafe kamu tidak berubah. Kamu bisa mengajukan perubahan baru dari dashboard cafe:
+
+{{.Link}}`,
+			`<p>Halo {{.Name}},</p>
+<p>Perubahan <b>{{.Change}}</b> yang kamu ajukan ditolak oleh admin CariSpot dengan alasan:</p>
+<blockquote style="border-left:3px solid #6b4226;margin:0;padding-left:12px">{{.Reason}}</blockquote>
+<p>Data cafe kamu tidak berubah. Kamu bisa mengajukan perubahan baru dari <a href="{{.Link}}">dashboard cafe</a>.</p>`,
+		),
+		LangEN: newTemplate(
+			"Your cafe change was rejected",
+			`Hi {{.Name}},
+
+The {{.Change}} change you submitted was rejected by a CariSpot admin for the following reason:
+
+{{.Reason}}
+
+Your cafe data has not changed. You can submit a new change from the cafe dashboard:
+
+{{.Link}}`,
+			`<p>Hi {{.Name}},</p>
+<p>The <b>{{.Change}}</b> change you submitted was rejected by a CariSpot admin for the following reason:</p>
+<blockquote style="border-left:3px solid #6b4226;margin:0;padding-left:12px">{{.Reason}}</blockquote>
+<p>Your cafe data has not changed. You can submit a new change from the <a href="{{.Link}}">cafe dashboard</a>.</p>`,
+		),
+	},
+	// Subject template ini diganti dengan judul notifikasi oleh pengirim
+	TemplateNotification: {
+		LangID: newTemplate(
+			"Notifikasi CariSpot",
+			`Halo {{.Name}},
+
+{{.Body}}
+{{if .Link}}
+{{.Link}}
+{{end}}`,
+			`<p>Halo {{.Name}},</p>
+<p><b>{{.Title}}</b></p>
+<p>{{.Body}}</p>
+{{if .Link}}<p><a href="{{.Link}}" style="background:#6b4226;color:#fff;padding:10px 18px;border-radius:6px;text-decoration:none">Buka CariSpot</a></p>{{end}}`,
+		),
+		LangEN: newTemplate(
+			"CariSpot notification",
+			`Hi {{.Name}},
+
+{{.Body}}
+{{if .Link}}
+{{.Link}}
+{{end}}`,
+			`<p>Hi {{.Name}},</p>
+<p><b>{{.Title}}</b></p>
+<p>{{.Body}}</p>
+{{if .Link}}<p><a href="{{.Link}}" style="background:#6b4226;color:#fff;padding:10px 18px;border-radius:6px;text-decoration:none">Open CariSpot</a></p>{{end}}`,
+		),
+	},
+}
+
+func newTemplate(subject, text, html string) emailTemplate {
+	h := htmltemplate.Must(htmltemplate.New("layout").Parse(layoutHTML))
+	htmltemplate.Must(h.New("content").Parse(html))
+	return emailTemplate{
+		subject: subject,
+		text:    texttemplate.Must(texttemplate.New("text").Parse(text)),
+		html:    h,
+	}
+}
+
+// Render menyusun email dari template. Bahasa yang tidak dikenal
+// memakai bahasa Indonesia.
+func Render(name, lang, to string, data TemplateData) (Message, error) {
+	byLang, ok := templates[name]
+	if !ok {
+		return Message{}, fmt.Errorf("template email tidak dikenal: %s", name)
+	}
+	tpl, ok := byLang[lang]
+	if !ok {
+		tpl = byLang[LangID]
+	}
+
+	var text, html bytes.Buffer
+	if err := tpl.text.Execute(&text, data); err != nil {
+		return Message{}, err
+	}
+	if err := tpl.html.ExecuteTemplate(&html, "layout", data); err != nil {
+		return Message{}, err
+	}
+
+	return Message{To: to, Subject: tpl.subject, Text: text.String(), HTML: html.String()}, nil
+}
+
+// LangFromHeader memilih bahasa email dari header Accept-Language
+func LangFromHeader(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag := strings.ToLower(strings.TrimSpace(strings.SplitN(part, ";", 2)[0]))
+		switch {
+		case strings.HasPrefix(tag, LangID):
+			return LangID
+		case strings.HasPrefix(tag, LangEN):
+			return LangEN
+		}
+	}
+	return LangID
+}
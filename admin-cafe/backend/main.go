@@ -1,32 +1,149 @@
 package main
 
 import (
+    "backend/audit"
+    "backend/cafes"
+    "backend/chat"
     "backend/config"
     "backend/handlers"
+    "backend/lifecycle"
+    "backend/logging"
+    "backend/mailer"
+    "backend/menus"
+    "backend/metrics"
+    "backend/notify"
+    "backend/payments"
+    "backend/promos"
+    "backend/reports"
     "backend/repository"
+    "backend/reviews"
     "backend/routes"
-    "fmt"
+    "context"
     "log"
+    "log/slog"
     "net/http"
     "os"
+    "time"
 
     "github.com/rs/cors"
 )
 
 func main() {
+    // Subcommand CLI, misal: go run . createsuperadmin -username budi
+    if runCommand(os.Args[1:]) {
+        return
+    }
+
+    // Log terstruktur (JSON) ke stdout
+    logging.Setup(os.Stdout, config.Getenv("LOG_LEVEL", "info"), config.Getenv("LOG_FORMAT", "json"))
+
     // 1️⃣ Koneksi ke database
     config.ConnectDB()
 
     // 2️⃣ Buat repository & handler
-    userRepo := repository.NewUserRepository()
-    authHandler := handlers.NewAuthHandler(userRepo)
-    cafeHandler := handlers.NewCafeHandler(userRepo)
+    userRepo := repository.NewUserRepository(config.DB)
+    sessionRepo := repository.NewSessionRepository(config.DB)
+    tokenRepo := repository.NewTokenRepository(config.DB)
+    mail, err := mailer.FromEnv()
+    if err != nil {
+        log.Fatal("Mailer config error:", err)
+    }
+    // Notifikasi in-app, email & push lewat outbox
+    notifier := notify.NewService(config.DB, config.Location,
+        notify.NewInAppDriver(config.DB),
+        notify.NewEmailDriver(mail),
+        notify.NewPushDriver(config.Getenv("PUSH_SINK", "")),
+    )
+    notificationRepo := repository.NewNotificationRepository(config.DB, config.Location)
+    notificationHandler := handlers.NewNotificationHandler(notificationRepo)
+    authHandler := handlers.NewAuthHandler(userRepo, sessionRepo, tokenRepo, mail)
+    cafeHandler := handlers.NewCafeHandler(userRepo, notifier)
+    cafeProfileRepo := repository.NewCafeProfileRepository(config.DB)
+    cafeProfileHandler := handlers.NewCafeProfileHandler(cafeProfileRepo)
+    searchRepo := repository.NewSearchRepository(config.DB, config.SearchConfig, config.TrigramEnabled)
+    searchHandler := handlers.NewSearchHandler(searchRepo)
+    customerHandler := handlers.NewCustomerHandler(userRepo)
+    reportService := reports.NewService(config.DB, config.Location)
+    reportHandler := handlers.NewReportHandler(reportService, cafeProfileRepo)
+    orderRepo := repository.NewOrderRepository(config.DB, config.Location)
+    paymentProvider, err := payments.FromEnv()
+    if err != nil {
+        log.Fatal("Payment config error:", err)
+    }
+    paymentService := payments.NewService(config.DB, paymentProvider, config.Location)
+    subscriptionRepo := repository.NewSubscriptionRepository(config.DB, config.Location, paymentService)
+    subscriptionHandler := handlers.NewSubscriptionHandler(subscriptionRepo, paymentService)
+    loyaltyRepo := repository.NewLoyaltyRepository(config.DB, config.Location)
+    loyaltyHandler := handlers.NewLoyaltyHandler(loyaltyRepo, cafeProfileRepo)
+    orderHandler := handlers.NewOrderHandler(orderRepo, cafeProfileRepo, subscriptionRepo, loyaltyRepo, paymentService)
+    paymentHandler := handlers.NewPaymentHandler(paymentService, orderRepo, subscriptionRepo, cafeProfileRepo, loyaltyRepo)
+    reservationRepo := repository.NewReservationRepository(config.DB, config.Location)
+    reservationHandler := handlers.NewReservationHandler(reservationRepo, cafeProfileRepo)
+    activityRepo := repository.NewActivityRepository(config.DB, config.Location)
+    activityHandler := handlers.NewActivityHandler(activityRepo)
+    changeRequestRepo := repository.NewChangeRequestRepository(config.DB, config.Location)
+    cafeDetails := cafes.NewService(config.DB, config.Location)
+    cafeDetailsHandler := handlers.NewCafeDetailsHandler(cafeDetails, cafeProfileRepo)
+    changeRequestHandler := handlers.NewChangeRequestHandler(changeRequestRepo, cafeProfileRepo, cafeDetails, orderRepo, notifier)
+    menuHandler := handlers.NewMenuHandler(menus.NewService(config.DB), cafeProfileRepo)
+    reviewHandler := handlers.NewReviewHandler(reviews.NewService(config.DB, config.Location), cafeProfileRepo, userRepo, notifier)
+    promoHandler := handlers.NewPromoHandler(promos.NewService(config.DB, config.Location), cafeProfileRepo, orderRepo, changeRequestHandler)
+    chatRepo := repository.NewChatRepository(config.DB, config.Location)
+    chatHub := chat.NewHub()
+    chatHandler := handlers.NewChatHandler(chatRepo, chatHub)
+    accountRepo := repository.NewAccountRepository(config.DB, config.Location)
+    accountHandler := handlers.NewAccountHandler(accountRepo, sessionRepo)
+    healthHandler := handlers.NewHealthHandler(config.DB)
+    metrics.RegisterDBStats(config.DB)
+
+    // Background job diawasi supervisor: dijalankan ulang jika panic dan
+    // dihentikan saat shutdown
+    workers := lifecycle.NewSupervisor()
+    // Cek berkala pembayaran yang macet di status pending
+    workers.Every(metrics.JobPaymentReconcile, payments.ReconcileInterval, func(ctx context.Context) error {
+        return paymentService.RunReconcile(ctx, payments.StuckAfter)
+    })
+    // Lepas reservasi pending yang tidak dikonfirmasi sampai hold habis
+    workers.Every(metrics.JobReservationHolds, time.Minute, reservationRepo.RunHoldRelease)
+    // Tagih perpanjangan / akhiri langganan premium yang periodenya habis
+    workers.Every(metrics.JobSubscriptionRenewal, 15*time.Minute, subscriptionRepo.RunScheduler)
+    // Catat poin kedaluwarsa & poin pesanan yang terlewat
+    workers.Every(metrics.JobLoyaltyPoints, time.Hour, loyaltyRepo.RunScheduler)
+    // Kirim notifikasi yang antre di outbox
+    workers.Every(metrics.JobNotificationOutbox, notify.WorkerInterval, notifier.RunOutbox)
 
     // 3️⃣ Pastikan folder uploads ada
     ensureUploadsFolder()
 
     // 4️⃣ Setup router & routes
-    router := routes.SetupRoutes(authHandler, cafeHandler)
+    router := routes.SetupRoutes(routes.Handlers{
+        Auth:          authHandler,
+        Cafe:          cafeHandler,
+        CafeProfile:   cafeProfileHandler,
+        Search:        searchHandler,
+        Customer:      customerHandler,
+        Report:        reportHandler,
+        Order:         orderHandler,
+        Payment:       paymentHandler,
+        Reservation:   reservationHandler,
+        Subscription:  subscriptionHandler,
+        Loyalty:       loyaltyHandler,
+        Activity:      activityHandler,
+        ChangeRequest: changeRequestHandler,
+        CafeDetails:   cafeDetailsHandler,
+        Menu:          menuHandler,
+        Review:        reviewHandler,
+        Promo:         promoHandler,
+        Chat:          chatHandler,
+        Notification:  notificationHandler,
+        Account:       accountHandler,
+        Health:        healthHandler,
+    }, sessionRepo)
+
+    // Durasi request per route untuk /metrics
+    router.Use(metrics.Middleware)
+    // Catat setiap perubahan data ke log aktivitas super admin
+    router.Use(audit.Middleware(activityRepo))
 
     // 5️⃣ Serve file uploads
     router.PathPrefix("/uploads/").Handler(http.StripPrefix("/uploads/", http.FileServer(http.Dir("./uploads"))))
@@ -36,13 +153,41 @@ func main() {
         AllowedOrigins:   []string{"http://localhost:5173"}, // React dev server
         AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
         AllowedHeaders:   []string{"*"},
+        ExposedHeaders:   []string{"Content-Disposition", logging.HeaderRequestID},
         AllowCredentials: true,
     })
-    handler := c.Handler(router)
+    // Request ID & access log membungkus semua request, termasuk preflight CORS
+    handler := logging.Middleware(c.Handler(router))
 
-    // 7️⃣ Jalankan server
-    fmt.Println("Server running at :8080")
-    log.Fatal(http.ListenAndServe(":8080", handler))
+    // 7️⃣ Jalankan server sampai SIGINT/SIGTERM
+    ctx, stop := lifecycle.SignalContext(context.Background())
+    defer stop()
+    srv := &http.Server{
+        Addr:              ":8080",
+        Handler:           handler,
+        ReadHeaderTimeout: 10 * time.Second,
+    }
+    // Stream chat (SSE) tidak pernah selesai sendiri, tutup saat shutdown
+    srv.RegisterOnShutdown(chatHub.Close)
+
+    slog.Info("Server running", "addr", srv.Addr)
+    serveErr := lifecycle.Serve(ctx, srv, config.ShutdownTimeout)
+    if serveErr != nil {
+        slog.Error("HTTP server error", "err", serveErr)
+    }
+
+    // 8️⃣ Berhenti berurutan: request HTTP sudah selesai, lalu background
+    // job, terakhir pool database
+    if err := workers.Stop(config.ShutdownTimeout); err != nil {
+        slog.Error("Background worker shutdown error", "err", err)
+    }
+    if err := config.DB.Close(); err != nil {
+        slog.Error("DB close error", "err", err)
+    }
+    slog.Info("Server stopped")
+    if serveErr != nil {
+        os.Exit(1)
+    }
 }
 
 // ensureUploadsFolder memastikan folder uploads ada
@@ -52,6 +197,6 @@ func ensureUploadsFolder() {
         if err != nil {
             log.Fatal("Gagal membuat folder uploads:", err)
         }
-        fmt.Println("Folder uploads dibuat")
+        slog.Info("Folder uploads dibuat")
     }
 }
@@ -0,0 +1,221 @@
+// Package menus mengelola menu milik cafe (dulu di backend gin terpisah).
+// Diskon tidak diubah di sini: perubahan diskon lewat antrean persetujuan
+// di handler permintaan perubahan, lalu berlaku otomatis sesuai
+// start_date/end_date saat harga dihitung.
+package menus
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Status menu
+const (
+	StatusActive   = "Aktif"
+	StatusInactive = "Nonaktif"
+)
+
+var ErrNotFound = errors.New("menu tidak ditemukan")
+
+// Menu adalah satu item menu. JSON memakai camelCase karena sudah dipakai
+// halaman Menu & Promo di frontend.
+type Menu struct {
+	ID              string  `json:"id"`
+	CafeID          int     `json:"cafeId"`
+	Name            string  `json:"name"`
+	Price           float64 `json:"price"`
+	Discount        float64 `json:"discount"`
+	DiscountedPrice float64 `json:"discountedPrice"`
+	StartDate       string  `json:"startDate"`
+	EndDate         string  `json:"endDate"`
+	Category        string  `json:"category"`
+	Status          string  `json:"status"`
+	Img             string  `json:"img"`
+	PremiumOnly     bool    `json:"premiumOnly"`
+}
+
+// NewMenu adalah body untuk membuat menu
+type NewMenu struct {
+	Name     string  `json:"name" validate:"trim,required,max=255"`
+	Price    float64 `json:"price" validate:"gt=0,max=100000000"`
+	Category string  `json:"category" validate:"trim,required,max=100"`
+	Status   string  `json:"status" validate:"trim,oneof=Aktif Nonaktif"`
+	Img      string  `json:"img" validate:"trim,max=500"`
+}
+
+// Changes adalah field menu yang diubah; nil berarti tidak diubah.
+// Halaman Menu mengirim {"status": "Nonaktif"} saja untuk toggle status.
+type Changes struct {
+	Name     *string  `json:"name" validate:"trim,notblank,max=255"`
+	Price    *float64 `json:"price" validate:"gt=0,max=100000000"`
+	Category *string  `json:"category" validate:"trim,notblank,max=100"`
+	Status   *string  `json:"status" validate:"oneof=Aktif Nonaktif"`
+	Img      *string  `json:"img" validate:"trim,max=500"`
+}
+
+// Filter untuk daftar menu
+type Filter struct {
+	Category string
+	Status   string
+	Search   string
+}
+
+// Store adalah penyimpanan menu; diimplementasikan Service (PostgreSQL)
+type Store interface {
+	List(ctx context.Context, cafeID int, f Filter) ([]Menu, error)
+	Get(ctx context.Context, cafeID int, id string) (*Menu, error)
+	Create(ctx context.Context, cafeID int, in NewMenu) (*Menu, error)
+	Update(ctx context.Context, cafeID int, id string, c Changes) (*Menu, error)
+	Delete(ctx context.Context, cafeID int, id string) (*Menu, error)
+}
+
+var _ Store = (*Service)(nil)
+
+type Service struct {
+	db *sql.DB
+}
+
+func NewService(db *sql.DB) *Service {
+	return &Service{db: db}
+}
+
+const menuColumns = `id::text, cafe_profile_id, name, price::float8,
+	COALESCE(discount, 0)::float8, COALESCE(discounted_price, 0)::float8,
+	COALESCE(to_char(start_date, 'YYYY-MM-DD'), ''), COALESCE(to_char(end_date, 'YYYY-MM-DD'), ''),
+	category, COALESCE(status, ''), COALESCE(img, ''), premium_only`
+
+func scanMenu(row interface{ Scan(...interface{}) error }) (*Menu, error) {
+	m := &Menu{}
+	var cafeID sql.NullInt64
+	err := row.Scan(&m.ID, &cafeID, &m.Name, &m.Price, &m.Discount, &m.DiscountedPrice,
+		&m.StartDate, &m.EndDate, &m.Category, &m.Status, &m.Img, &m.PremiumOnly)
+	if err != nil {
+		return nil, err
+	}
+	m.CafeID = int(cafeID.Int64)
+	if m.Discount <= 0 {
+		m.DiscountedPrice = m.Price
+	}
+	return m, nil
+}
+
+// =========================
+// Daftar menu satu cafe
+// =========================
+func (s *Service) List(ctx context.Context, cafeID int, f Filter) ([]Menu, error) {
+	where := []string{"cafe_profile_id=$1"}
+	args := []interface{}{cafeID}
+	add := func(cond string, v interface{}) {
+		args = append(args, v)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if f.Category != "" {
+		add("LOWER(category)=LOWER($%d)", f.Category)
+	}
+	if f.Status != "" {
+		add("status=$%d", f.Status)
+	}
+	if f.Search != "" {
+		add("(name ILIKE '%%' || $%[1]d || '%%' OR category ILIKE '%%' || $%[1]d || '%%')", f.Search)
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+menuColumns+" FROM menus WHERE "+strings.Join(where, " AND ")+
+		" ORDER BY category, name", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Menu{}
+	for rows.Next() {
+		m, err := scanMenu(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *m)
+	}
+	return list, rows.Err()
+}
+
+// =========================
+// Detail menu milik cafe
+// =========================
+func (s *Service) Get(ctx context.Context, cafeID int, id string) (*Menu, error) {
+	m, err := scanMenu(s.db.QueryRowContext(ctx, "SELECT "+menuColumns+" FROM menus WHERE id=$1 AND cafe_profile_id=$2", id, cafeID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return m, err
+}
+
+// =========================
+// Tambah menu
+// =========================
+func (s *Service) Create(ctx context.Context, cafeID int, in NewMenu) (*Menu, error) {
+	if in.Status == "" {
+		in.Status = StatusActive
+	}
+	return scanMenu(s.db.QueryRowContext(ctx, `
+		INSERT INTO menus (cafe_profile_id, name, price, discounted_price, category, status, img)
+		VALUES ($1, $2, $3, $3, $4, $5, NULLIF($6, ''))
+		RETURNING `+menuColumns,
+		cafeID, in.Name, in.Price, in.Category, in.Status, in.Img,
+	))
+}
+
+// =========================
+// Ubah menu
+// =========================
+// Harga setelah diskon ikut dihitung ulang jika harga berubah.
+func (s *Service) Update(ctx context.Context, cafeID int, id string, c Changes) (*Menu, error) {
+	sets := []string{}
+	args := []interface{}{id, cafeID}
+	add := func(column string, v interface{}) {
+		args = append(args, v)
+		sets = append(sets, fmt.Sprintf("%s=$%d", column, len(args)))
+	}
+	if c.Name != nil {
+		add("name", *c.Name)
+	}
+	if c.Price != nil {
+		add("price", *c.Price)
+		sets = append(sets, fmt.Sprintf("discounted_price=ROUND($%d::numeric * (1 - COALESCE(discount, 0) / 100), 2)", len(args)))
+	}
+	if c.Category != nil {
+		add("category", *c.Category)
+	}
+	if c.Status != nil {
+		add("status", *c.Status)
+	}
+	if c.Img != nil {
+		add("img", *c.Img)
+	}
+	if len(sets) == 0 {
+		return s.Get(ctx, cafeID, id)
+	}
+
+	m, err := scanMenu(s.db.QueryRowContext(ctx,
+		"UPDATE menus SET "+strings.Join(sets, ", ")+", updated_at=CURRENT_TIMESTAMP WHERE id=$1 AND cafe_profile_id=$2 RETURNING "+menuColumns,
+		args...,
+	))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return m, err
+}
+
+// =========================
+// Hapus menu
+// =========================
+// Baris pesanan lama tetap ada (menu_id jadi NULL) karena menyimpan
+// snapshot nama & harga.
+func (s *Service) Delete(ctx context.Context, cafeID int, id string) (*Menu, error) {
+	m, err := scanMenu(s.db.QueryRowContext(ctx, "DELETE FROM menus WHERE id=$1 AND cafe_profile_id=$2 RETURNING "+menuColumns, id, cafeID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return m, err
+}
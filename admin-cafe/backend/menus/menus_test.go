@@ -0,0 +1,18 @@
+package menus_test
+
+import (
+	"backend/menus"
+	"backend/pgtest"
+	"backend/repository/storetest"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) { os.Exit(pgtest.Main(m)) }
+
+func TestService(t *testing.T) {
+	db := pgtest.Open(t)
+	cafeID := pgtest.CreateCafe(t, db, "kopikita")
+	otherCafeID := pgtest.CreateCafe(t, db, "senja")
+	storetest.Menus(t, menus.NewService(db), cafeID, otherCafeID)
+}
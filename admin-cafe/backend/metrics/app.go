@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Metrik aplikasi
+var (
+	HTTPDuration = NewHistogram("carispot_http_request_duration_seconds",
+		"Durasi request HTTP per route.", DefBuckets, "method", "route", "status")
+
+	LoginFailures = NewCounter("carispot_login_failures_total",
+		"Login yang gagal per alasan.", "reason")
+
+	UploadBytes = NewHistogram("carispot_upload_size_bytes",
+		"Ukuran file yang diupload per field form.",
+		[]float64{16 << 10, 64 << 10, 256 << 10, 1 << 20, 2 << 20, 5 << 20, 10 << 20}, "field")
+
+	JobDuration = NewHistogram("carispot_job_duration_seconds",
+		"Durasi satu putaran background job.", DefBuckets, "job", "result")
+)
+
+// =========================
+// Durasi request per route
+// =========================
+
+// statusWriter menyimpan status code yang ditulis handler
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush diteruskan supaya streaming response tetap jalan
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Middleware mencatat durasi request dengan label template route (mis.
+// "/orders/{id:[0-9]+}"), bukan path asli, supaya jumlah series tetap
+// kecil. Dipasang dengan router.Use sehingga hanya route yang cocok yang
+// dicatat.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r)
+
+		route := "unknown"
+		if current := mux.CurrentRoute(r); current != nil {
+			if tpl, err := current.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+		if sw.status == 0 {
+			sw.status = http.StatusOK
+		}
+		HTTPDuration.Observe(time.Since(start).Seconds(), r.Method, route, strconv.Itoa(sw.status))
+	})
+}
+
+// =========================
+// Statistik pool database
+// =========================
+
+// RegisterDBStats menyajikan sql.DB.Stats() sebagai metrik
+func RegisterDBStats(db *sql.DB) {
+	gauge := func(name, help string, fn func(sql.DBStats) int) {
+		NewGaugeFunc(name, help, func() float64 { return float64(fn(db.Stats())) })
+	}
+	counter := func(name, help string, fn func(sql.DBStats) int64) {
+		NewCounterFunc(name, help, func() float64 { return float64(fn(db.Stats())) })
+	}
+
+	gauge("carispot_db_max_open_connections", "Batas koneksi database terbuka.",
+		func(s sql.DBStats) int { return s.MaxOpenConnections })
+	gauge("carispot_db_open_connections", "Koneksi database yang terbuka.",
+		func(s sql.DBStats) int { return s.OpenConnections })
+	gauge("carispot_db_in_use_connections", "Koneksi database yang sedang dipakai.",
+		func(s sql.DBStats) int { return s.InUse })
+	gauge("carispot_db_idle_connections", "Koneksi database yang menganggur.",
+		func(s sql.DBStats) int { return s.Idle })
+	counter("carispot_db_wait_count_total", "Jumlah request yang menunggu koneksi database.",
+		func(s sql.DBStats) int64 { return s.WaitCount })
+	NewCounterFunc("carispot_db_wait_duration_seconds_total", "Total waktu menunggu koneksi database.",
+		func() float64 { return db.Stats().WaitDuration.Seconds() })
+	counter("carispot_db_max_idle_closed_total", "Koneksi ditutup karena SetMaxIdleConns.",
+		func(s sql.DBStats) int64 { return s.MaxIdleClosed })
+	counter("carispot_db_max_idle_time_closed_total", "Koneksi ditutup karena SetConnMaxIdleTime.",
+		func(s sql.DBStats) int64 { return s.MaxIdleTimeClosed })
+	counter("carispot_db_max_lifetime_closed_total", "Koneksi ditutup karena SetConnMaxLifetime.",
+		func(s sql.DBStats) int64 { return s.MaxLifetimeClosed })
+}
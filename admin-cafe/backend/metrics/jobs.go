@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Nama background job
+const (
+	JobPaymentReconcile    = "payment_reconcile"
+	JobReservationHolds    = "reservation_hold_release"
+	JobSubscriptionRenewal = "subscription_renewal"
+	JobLoyaltyPoints       = "loyalty_points"
+	JobNotificationOutbox  = "notification_outbox"
+)
+
+// JobStatus adalah heartbeat terakhir satu background job
+type JobStatus struct {
+	Name     string        `json:"name"`
+	Interval time.Duration `json:"-"`
+	LastBeat time.Time     `json:"last_beat"`
+	LastErr  string        `json:"last_error,omitempty"`
+}
+
+// Stale melaporkan apakah job sudah terlalu lama tidak berdetak: lebih
+// dari dua interval ditambah toleransi 30 detik untuk putaran yang lama
+func (s JobStatus) Stale(now time.Time) bool {
+	return now.Sub(s.LastBeat) > 2*s.Interval+30*time.Second
+}
+
+var jobs struct {
+	mu     sync.Mutex
+	status map[string]*JobStatus
+}
+
+// ExpectJob mendaftarkan job yang berjalan setiap interval. Dipanggil
+// saat worker dimulai; waktu mulai dihitung sebagai heartbeat pertama.
+func ExpectJob(name string, interval time.Duration) {
+	jobs.mu.Lock()
+	defer jobs.mu.Unlock()
+	if jobs.status == nil {
+		jobs.status = map[string]*JobStatus{}
+	}
+	jobs.status[name] = &JobStatus{Name: name, Interval: interval, LastBeat: time.Now()}
+}
+
+// JobDone mencatat heartbeat & durasi satu putaran job yang dimulai pada
+// start. Putaran yang gagal tetap dihitung sebagai heartbeat karena
+// worker-nya masih hidup.
+func JobDone(name string, start time.Time, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	now := time.Now()
+	JobDuration.Observe(now.Sub(start).Seconds(), name, result)
+
+	jobs.mu.Lock()
+	defer jobs.mu.Unlock()
+	if s, ok := jobs.status[name]; ok {
+		s.LastBeat = now
+		s.LastErr = ""
+		if err != nil {
+			s.LastErr = err.Error()
+		}
+	}
+}
+
+// Jobs mengembalikan status semua job yang terdaftar, urut nama
+func Jobs() []JobStatus {
+	jobs.mu.Lock()
+	defer jobs.mu.Unlock()
+	list := make([]JobStatus, 0, len(jobs.status))
+	for _, s := range jobs.status {
+		list = append(list, *s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
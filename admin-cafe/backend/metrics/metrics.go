@@ -0,0 +1,252 @@
+// Package metrics mengumpulkan metrik aplikasi dan menyajikannya di
+// /metrics dalam format teks Prometheus (exposition format 0.0.4).
+//
+// Hanya tipe yang dipakai backend ini yang diimplementasikan: counter,
+// histogram dan gauge/counter yang dibaca saat scrape (mis. statistik pool
+// database), supaya tidak perlu menambah dependency client Prometheus.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Bucket default histogram durasi (detik), sama dengan client Prometheus
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// collector menulis satu atau beberapa metrik lengkap dengan HELP & TYPE
+type collector interface {
+	write(w *bufio.Writer)
+}
+
+var registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+func register(c collector) {
+	registry.mu.Lock()
+	registry.collectors = append(registry.collectors, c)
+	registry.mu.Unlock()
+}
+
+// Handler menyajikan semua metrik yang terdaftar
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		bw := bufio.NewWriter(w)
+		registry.mu.Lock()
+		collectors := registry.collectors
+		registry.mu.Unlock()
+		for _, c := range collectors {
+			c.write(bw)
+		}
+		bw.Flush()
+	})
+}
+
+// =========================
+// Counter
+// =========================
+
+// Counter adalah nilai yang hanya bertambah, per kombinasi label
+type Counter struct {
+	name, help string
+	labels     []string
+
+	mu     sync.Mutex
+	series map[string]*counterSeries
+}
+
+type counterSeries struct {
+	values []string
+	value  float64
+}
+
+// NewCounter mendaftarkan counter baru. Nilai label diberikan saat Inc/Add
+// dengan urutan yang sama dengan labels.
+func NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{name: name, help: help, labels: labels, series: map[string]*counterSeries{}}
+	register(c)
+	return c
+}
+
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+func (c *Counter) Add(v float64, values ...string) {
+	key := strings.Join(values, "\xff")
+	c.mu.Lock()
+	s, ok := c.series[key]
+	if !ok {
+		s = &counterSeries{values: values}
+		c.series[key] = s
+	}
+	s.value += v
+	c.mu.Unlock()
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	writeHeader(w, c.name, c.help, "counter")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range sortedKeys(c.series) {
+		s := c.series[key]
+		writeSample(w, c.name, labelPairs(c.labels, s.values), s.value)
+	}
+}
+
+// =========================
+// Histogram
+// =========================
+
+// Histogram menghitung sebaran nilai (durasi, ukuran) ke dalam bucket
+type Histogram struct {
+	name, help string
+	labels     []string
+	buckets    []float64
+
+	mu     sync.Mutex
+	series map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	values []string
+	counts []uint64 // per bucket, tidak kumulatif
+	sum    float64
+	count  uint64
+}
+
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{name: name, help: help, labels: labels, buckets: buckets, series: map[string]*histogramSeries{}}
+	register(h)
+	return h
+}
+
+func (h *Histogram) Observe(v float64, values ...string) {
+	key := strings.Join(values, "\xff")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{values: values, counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	for i, upper := range h.buckets {
+		if v <= upper {
+			s.counts[i]++
+			break
+		}
+	}
+	s.sum += v
+	s.count++
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	writeHeader(w, h.name, h.help, "histogram")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		labels := labelPairs(h.labels, s.values)
+		var cumulative uint64
+		for i, upper := range h.buckets {
+			cumulative += s.counts[i]
+			writeSample(w, h.name+"_bucket", append(labels, [2]string{"le", formatFloat(upper)}), float64(cumulative))
+		}
+		writeSample(w, h.name+"_bucket", append(labels, [2]string{"le", "+Inf"}), float64(s.count))
+		writeSample(w, h.name+"_sum", labels, s.sum)
+		writeSample(w, h.name+"_count", labels, float64(s.count))
+	}
+}
+
+// =========================
+// Nilai yang dibaca saat scrape
+// =========================
+
+// funcMetric adalah gauge/counter tanpa label yang nilainya diambil dari
+// fungsi setiap kali /metrics diminta
+type funcMetric struct {
+	name, help, kind string
+	fn               func() float64
+}
+
+func (m funcMetric) write(w *bufio.Writer) {
+	writeHeader(w, m.name, m.help, m.kind)
+	writeSample(w, m.name, nil, m.fn())
+}
+
+// NewGaugeFunc mendaftarkan gauge yang nilainya dibaca dari fn
+func NewGaugeFunc(name, help string, fn func() float64) {
+	register(funcMetric{name: name, help: help, kind: "gauge", fn: fn})
+}
+
+// NewCounterFunc mendaftarkan counter yang nilainya dibaca dari fn
+func NewCounterFunc(name, help string, fn func() float64) {
+	register(funcMetric{name: name, help: help, kind: "counter", fn: fn})
+}
+
+// =========================
+// Format teks Prometheus
+// =========================
+
+func writeHeader(w *bufio.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func writeSample(w *bufio.Writer, name string, labels [][2]string, v float64) {
+	w.WriteString(name)
+	if len(labels) > 0 {
+		w.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(l[0])
+			w.WriteString(`="`)
+			w.WriteString(labelEscaper.Replace(l[1]))
+			w.WriteByte('"')
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(v))
+	w.WriteByte('\n')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func labelPairs(names, values []string) [][2]string {
+	pairs := make([][2]string, 0, len(names)+1)
+	for i, name := range names {
+		v := ""
+		if i < len(values) {
+			v = values[i]
+		}
+		pairs = append(pairs, [2]string{name, v})
+	}
+	return pairs
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"backend/api"
+	"backend/audit"
+	"backend/logging"
+	"backend/models"
+	"backend/repository"
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+type contextKey string
+
+const userKey contextKey = "user"
+
+// Endpoint yang tetap boleh diakses selama password belum diganti
+var passwordChangePaths = map[string]bool{
+	"/auth/change-password": true,
+	"/logout":               true,
+}
+
+// Auth memvalidasi header "Authorization: Bearer <token>" dan menyimpan
+// user yang login di context request.
+func Auth(sessions repository.SessionStore) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := BearerToken(r)
+			if token == "" {
+				api.Fail(w, r, http.StatusUnauthorized, "Silakan login terlebih dahulu")
+				return
+			}
+
+			user, err := sessions.Validate(r.Context(), token)
+			if err == repository.ErrSessionInvalid {
+				api.WriteError(w, r, api.New(http.StatusUnauthorized, "Session tidak valid, silakan login ulang").
+					WithCode(api.CodeSessionInvalid))
+				return
+			}
+			if err != nil {
+				slog.ErrorContext(r.Context(), "DB error validating session", "err", err)
+				api.Fail(w, r, http.StatusInternalServerError, "Gagal memeriksa session")
+				return
+			}
+
+			// Akun dengan password sementara hanya boleh ganti password
+			if user.MustChangePassword && !passwordChangePaths[r.URL.Path] {
+				api.WriteError(w, r, api.New(http.StatusForbidden, "Password wajib diganti sebelum melanjutkan").
+					WithCode(api.CodePasswordChange))
+				return
+			}
+
+			audit.SetActor(r, user)
+			logging.SetUserID(r.Context(), user.ID)
+			ctx := context.WithValue(r.Context(), userKey, user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// OptionalAuth menyimpan user yang login di context jika request membawa
+// token yang valid, tanpa menolak request anonim.
+func OptionalAuth(sessions repository.SessionStore) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token := BearerToken(r); token != "" {
+				user, err := sessions.Validate(r.Context(), token)
+				if err != nil && err != repository.ErrSessionInvalid {
+					slog.ErrorContext(r.Context(), "DB error validating session", "err", err)
+				}
+				if err == nil && !user.MustChangePassword {
+					audit.SetActor(r, user)
+					logging.SetUserID(r.Context(), user.ID)
+					r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireRole menolak request dari user dengan role lain. Harus dipasang
+// setelah Auth.
+func RequireRole(roles ...string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := CurrentUser(r)
+			if user == nil {
+				api.Fail(w, r, http.StatusUnauthorized, "Silakan login terlebih dahulu")
+				return
+			}
+			for _, role := range roles {
+				if user.Role == role {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			api.Fail(w, r, http.StatusForbidden, "Akses ditolak")
+		})
+	}
+}
+
+// CurrentUser mengembalikan user yang login, atau nil
+func CurrentUser(r *http.Request) *models.SessionUser {
+	user, _ := r.Context().Value(userKey).(*models.SessionUser)
+	return user
+}
+
+// BearerToken mengambil token dari header Authorization
+func BearerToken(r *http.Request) string {
+	h := r.Header.Get("Authorization")
+	if len(h) > 7 && strings.EqualFold(h[:7], "Bearer ") {
+		return strings.TrimSpace(h[7:])
+	}
+	return ""
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Activity adalah satu entri audit trail / log aktivitas super-admin
+type Activity struct {
+	ID             int             `json:"id"`
+	ActorUserID    *int            `json:"actor_user_id"`
+	ActorName      string          `json:"actor_name,omitempty"`
+	ActorRole      string          `json:"actor_role,omitempty"`
+	Action         string          `json:"action"`
+	EntityType     string          `json:"entity_type"`
+	EntityID       string          `json:"entity_id"`
+	Title          string          `json:"title"`
+	Subject        string          `json:"subject,omitempty"`
+	Claim          string          `json:"claim,omitempty"`
+	Before         json.RawMessage `json:"before,omitempty"`
+	After          json.RawMessage `json:"after,omitempty"`
+	IPAddress      string          `json:"ip_address,omitempty"`
+	UserAgent      string          `json:"user_agent,omitempty"`
+	Method         string          `json:"method,omitempty"`
+	Path           string          `json:"path,omitempty"`
+	RequestID      string          `json:"request_id,omitempty"`
+	AcknowledgedAt *time.Time      `json:"acknowledged_at"`
+	AcknowledgedBy *int            `json:"acknowledged_by"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// ActivityFilter adalah filter daftar log aktivitas. Nilai kosong berarti
+// tidak difilter.
+type ActivityFilter struct {
+	Action       string
+	EntityType   string
+	EntityID     string
+	ActorUserID  int
+	ActorRole    string
+	Acknowledged *bool
+	From         *time.Time
+	To           *time.Time
+	Limit        int
+	Offset       int
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// CafeProfile adalah profil publik sebuah cafe
+type CafeProfile struct {
+	ID        int      `json:"id"`
+	UserID    *int     `json:"user_id,omitempty"`
+	Nama      string   `json:"nama"`
+	Alamat    string   `json:"alamat"`
+	Telepon   string   `json:"telepon"`
+	Deskripsi string   `json:"deskripsi"`
+	MainImage string   `json:"main_image"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	Verified  bool     `json:"verified"`
+}
+
+// NearbyCafe adalah hasil pencarian cafe terdekat
+type NearbyCafe struct {
+	ID          int     `json:"id"`
+	Nama        string  `json:"nama"`
+	Alamat      string  `json:"alamat"`
+	MainImage   string  `json:"main_image"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	DistanceKm  float64 `json:"distance_km"`
+	Rating      float64 `json:"rating"`
+	TotalUlasan int     `json:"total_ulasan"`
+	OpenNow     bool    `json:"open_now"`
+}
+
+// NearbyQuery berisi filter untuk pencarian cafe terdekat
+type NearbyQuery struct {
+	Latitude   float64
+	Longitude  float64
+	RadiusKm   float64
+	OpenNow    bool
+	Facilities []string // huruf kecil, tanpa duplikat
+	MinRating  float64
+	Limit      int
+
+	// Waktu acuan untuk filter open_now
+	Now time.Time
+}
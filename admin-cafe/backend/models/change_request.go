@@ -0,0 +1,73 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Status permintaan perubahan
+const (
+	ChangePending  = "pending"
+	ChangeApproved = "approved"
+	ChangeRejected = "rejected"
+)
+
+// Entitas yang perubahannya bisa butuh persetujuan super admin
+const (
+	ChangeEntityCafe = "cafe_profile"
+	ChangeEntityMenu = "menu"
+)
+
+// Field sensitif yang bisa dikonfigurasi lewat CAFE_APPROVAL_FIELDS
+const (
+	ChangeFieldNama      = "nama"
+	ChangeFieldAlamat    = "alamat"
+	ChangeFieldIzinUsaha = "izin_usaha"
+	ChangeFieldDiscount  = "discount"
+)
+
+// ChangeRequest adalah perubahan profil / diskon cafe yang menunggu
+// persetujuan super admin. Changes berisi nilai baru per field, Previous
+// nilai live saat perubahan diajukan.
+type ChangeRequest struct {
+	ID          int             `json:"id"`
+	CafeID      int             `json:"cafe_id"`
+	CafeName    string          `json:"cafe_name"`
+	EntityType  string          `json:"entity_type"`
+	EntityID    string          `json:"entity_id"`
+	Changes     json.RawMessage `json:"changes"`
+	Previous    json.RawMessage `json:"previous"`
+	Status      string          `json:"status"`
+	Reason      string          `json:"reason,omitempty"`
+	RequestedBy *int            `json:"requested_by"`
+	ReviewedBy  *int            `json:"reviewed_by"`
+	ReviewedAt  *time.Time      `json:"reviewed_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// ProfileChanges adalah field profil cafe yang diubah. nil berarti
+// field tidak diubah.
+type ProfileChanges struct {
+	Nama      *string  `json:"nama,omitempty" validate:"trim,notblank,max=255"`
+	Alamat    *string  `json:"alamat,omitempty" validate:"trim,min=10,max=500"`
+	Telepon   *string  `json:"telepon,omitempty" validate:"trim,max=20"`
+	Deskripsi *string  `json:"deskripsi,omitempty" validate:"trim,max=2000"`
+	Latitude  *float64 `json:"latitude,omitempty" validate:"min=-90,max=90"`
+	Longitude *float64 `json:"longitude,omitempty" validate:"min=-180,max=180"`
+	IzinUsaha *string  `json:"izin_usaha,omitempty"`
+}
+
+// Empty bernilai true jika tidak ada field yang diubah
+func (c ProfileChanges) Empty() bool {
+	return c.Nama == nil && c.Alamat == nil && c.Telepon == nil && c.Deskripsi == nil &&
+		c.Latitude == nil && c.Longitude == nil && c.IzinUsaha == nil
+}
+
+// DiscountChange adalah perubahan diskon satu menu. Tanggal memakai
+// format YYYY-MM-DD, kosong berarti tanpa batas.
+type DiscountChange struct {
+	Discount  float64 `json:"discount" validate:"min=0,max=100"`
+	StartDate string  `json:"start_date" validate:"date"`
+	EndDate   string  `json:"end_date" validate:"date,gtefield=StartDate"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Jenis event chat yang dikirim lewat stream
+const (
+	ChatEventMessage = "message"
+	ChatEventRead    = "read"
+	ChatEventThread  = "thread"
+)
+
+// ChatThread adalah satu percakapan, opsional terhubung ke entitas
+// (mis. pendaftaran cafe, permintaan perubahan, pengajuan event)
+type ChatThread struct {
+	ID            int               `json:"id"`
+	Subject       string            `json:"subject"`
+	EntityType    string            `json:"entity_type,omitempty"`
+	EntityID      string            `json:"entity_id,omitempty"`
+	CreatedBy     *int              `json:"created_by"`
+	CreatedAt     time.Time         `json:"created_at"`
+	LastMessageAt *time.Time        `json:"last_message_at"`
+	Unread        int               `json:"unread"`
+	LastMessage   *ChatMessage      `json:"last_message,omitempty"`
+	Participants  []ChatParticipant `json:"participants,omitempty"`
+}
+
+// ChatParticipant adalah anggota thread. LastReadMessageID adalah read
+// receipt: semua pesan dengan id <= nilai ini sudah dibaca user tersebut.
+type ChatParticipant struct {
+	UserID            int    `json:"user_id"`
+	Username          string `json:"username"`
+	Role              string `json:"role"`
+	LastReadMessageID int64  `json:"last_read_message_id"`
+}
+
+// ChatMessage adalah satu pesan dalam thread
+type ChatMessage struct {
+	ID         int64     `json:"id"`
+	ThreadID   int       `json:"thread_id"`
+	SenderID   *int      `json:"sender_id"`
+	SenderName string    `json:"sender_name"`
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ChatEvent dikirim ke peserta thread secara live
+type ChatEvent struct {
+	Type      string       `json:"type"`
+	ThreadID  int          `json:"thread_id"`
+	Message   *ChatMessage `json:"message,omitempty"`
+	UserID    int          `json:"user_id,omitempty"`
+	MessageID int64        `json:"message_id,omitempty"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// Jenis transaksi loyalty
+const (
+	LoyaltyEarn        = "earn"
+	LoyaltyRedeem      = "redeem"
+	LoyaltyStampRedeem = "stamp_redeem"
+	LoyaltyExpire      = "expire"
+	LoyaltyReverse     = "reverse"
+)
+
+// LoyaltySettings adalah aturan poin & kartu stamp sebuah cafe
+type LoyaltySettings struct {
+	CafeID          int     `json:"cafe_id"`
+	Enabled         bool    `json:"enabled"`
+	PointsPerUnit   int     `json:"points_per_unit" validate:"min=0,max=1000"`
+	AmountUnit      float64 `json:"amount_unit" validate:"min=1000"`
+	PointExpiryDays int     `json:"point_expiry_days" validate:"min=0,max=3650"`
+	StampEnabled    bool    `json:"stamp_enabled"`
+	StampsRequired  int     `json:"stamps_required" validate:"min=1,max=50"`
+	StampReward     string  `json:"stamp_reward" validate:"trim,max=255"`
+}
+
+// PointsFor menghitung poin untuk total belanja
+func (s LoyaltySettings) PointsFor(total float64) int {
+	if !s.Enabled || s.AmountUnit <= 0 {
+		return 0
+	}
+	return int(total/s.AmountUnit) * s.PointsPerUnit
+}
+
+// LoyaltyReward adalah hadiah yang bisa ditukar dengan poin
+type LoyaltyReward struct {
+	ID         int    `json:"id"`
+	CafeID     int    `json:"cafe_id"`
+	Name       string `json:"name"`
+	PointsCost int    `json:"points_cost"`
+	Active     bool   `json:"active"`
+}
+
+// LoyaltyTransaction adalah satu baris ledger poin/stamp
+type LoyaltyTransaction struct {
+	ID           int        `json:"id"`
+	CafeID       int        `json:"cafe_id"`
+	UserID       int        `json:"user_id"`
+	CustomerName string     `json:"customer_name,omitempty"`
+	Type         string     `json:"type"`
+	Points       int        `json:"points"`
+	Stamps       int        `json:"stamps"`
+	OrderID      *int       `json:"order_id"`
+	RewardID     *int       `json:"reward_id"`
+	RedeemCode   string     `json:"redeem_code,omitempty"`
+	Description  string     `json:"description"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// LoyaltyBalance adalah saldo poin & stamp customer di satu cafe
+type LoyaltyBalance struct {
+	CafeID         int    `json:"cafe_id"`
+	CafeNama       string `json:"cafe_nama"`
+	Points         int    `json:"points"`
+	Stamps         int    `json:"stamps"`
+	StampsRequired int    `json:"stamps_required"`
+	StampReward    string `json:"stamp_reward,omitempty"`
+	// Poin yang akan kedaluwarsa dalam 30 hari
+	ExpiringPoints int `json:"expiring_points"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Jenis event notifikasi
+const (
+	NotifyCafeApproved       = "cafe_approved"
+	NotifyChangeApproved     = "change_approved"
+	NotifyChangeRejected     = "change_rejected"
+	NotifyReviewReplied      = "review_replied"
+	NotifyEventRequestStatus = "event_request_status"
+)
+
+// Channel pengiriman notifikasi
+const (
+	ChannelInApp = "in_app"
+	ChannelEmail = "email"
+	ChannelPush  = "push"
+)
+
+// NotificationChannels adalah semua channel yang dikenal, sesuai urutan
+// tampil di pengaturan
+var NotificationChannels = []string{ChannelInApp, ChannelEmail, ChannelPush}
+
+// Notification adalah satu pesan di kotak masuk (inbox) user
+type Notification struct {
+	ID        int64           `json:"id"`
+	EventType string          `json:"event_type"`
+	Title     string          `json:"title"`
+	Body      string          `json:"body"`
+	Link      string          `json:"link,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	ReadAt    *time.Time      `json:"read_at"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// NotificationPreference menyatakan apakah satu event dikirim lewat
+// satu channel. Kombinasi yang tidak disimpan dianggap aktif.
+type NotificationPreference struct {
+	EventType string `json:"event_type" validate:"required"`
+	Channel   string `json:"channel" validate:"required"`
+	Enabled   bool   `json:"enabled"`
+}
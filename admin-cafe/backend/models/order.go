@@ -0,0 +1,104 @@
+package models
+
+import "time"
+
+// Status pesanan
+const (
+	OrderPlaced    = "placed"
+	OrderPreparing = "preparing"
+	OrderReady     = "ready"
+	OrderCompleted = "completed"
+	OrderCancelled = "cancelled"
+)
+
+// Jenis pesanan
+const (
+	OrderDineIn = "dine_in"
+	OrderPickup = "pickup"
+)
+
+// orderTransitions adalah perpindahan status yang diizinkan
+var orderTransitions = map[string][]string{
+	OrderPlaced:    {OrderPreparing, OrderCancelled},
+	OrderPreparing: {OrderReady, OrderCancelled},
+	OrderReady:     {OrderCompleted, OrderCancelled},
+}
+
+// CanTransition memeriksa apakah status pesanan boleh berubah dari from ke to
+func CanTransition(from, to string) bool {
+	for _, s := range orderTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// MenuVariant adalah pilihan varian menu (mis. Large, Less Sugar)
+type MenuVariant struct {
+	ID         int     `json:"id"`
+	MenuID     string  `json:"menu_id"`
+	Name       string  `json:"name"`
+	PriceDelta float64 `json:"price_delta"`
+	Available  bool    `json:"available"`
+}
+
+// CartItem adalah satu baris keranjang yang dikirim client
+type CartItem struct {
+	MenuID    string `json:"menu_id" validate:"trim,required"`
+	VariantID *int   `json:"variant_id"`
+	Quantity  int    `json:"quantity" validate:"min=1,max=99"`
+	Note      string `json:"note" validate:"trim,max=200"`
+}
+
+// Cart adalah isi keranjang untuk satu cafe
+type Cart struct {
+	CafeID      int        `json:"cafe_id"`
+	OrderType   string     `json:"order_type" validate:"trim,oneof=dine_in pickup"`
+	TableNumber string     `json:"table_number" validate:"trim,max=20"`
+	PickupName  string     `json:"pickup_name" validate:"trim,max=100"`
+	Note        string     `json:"note" validate:"trim,max=500"`
+	Items       []CartItem `json:"items" validate:"required,max=50"`
+	// Premium diisi server: pemesan anggota premium berhak atas diskon
+	// menu khusus premium
+	Premium bool `json:"-"`
+}
+
+// OrderItem adalah baris pesanan dengan snapshot harga saat dipesan
+type OrderItem struct {
+	ID              int     `json:"id,omitempty"`
+	MenuID          *string `json:"menu_id"`
+	MenuName        string  `json:"menu_name"`
+	VariantID       *int    `json:"variant_id"`
+	VariantName     string  `json:"variant_name,omitempty"`
+	Quantity        int     `json:"quantity"`
+	OriginalPrice   float64 `json:"original_price"`
+	DiscountPercent float64 `json:"discount_percent"`
+	UnitPrice       float64 `json:"unit_price"`
+	DiscountAmount  float64 `json:"discount_amount"`
+	Subtotal        float64 `json:"subtotal"`
+	Note            string  `json:"note,omitempty"`
+}
+
+// Order adalah pesanan beserta barisnya
+type Order struct {
+	ID             int         `json:"id"`
+	CafeID         int         `json:"cafe_id"`
+	CustomerUserID *int        `json:"customer_user_id"`
+	Status         string      `json:"status"`
+	PaymentStatus  string      `json:"payment_status"`
+	OrderType      string      `json:"order_type"`
+	TableNumber    string      `json:"table_number,omitempty"`
+	PickupName     string      `json:"pickup_name,omitempty"`
+	Note           string      `json:"note,omitempty"`
+	Subtotal       float64     `json:"subtotal"`
+	DiscountTotal  float64     `json:"discount_total"`
+	Total          float64     `json:"total"`
+	CancelReason   string      `json:"cancel_reason,omitempty"`
+	CreatedAt      time.Time   `json:"created_at"`
+	PreparingAt    *time.Time  `json:"preparing_at"`
+	ReadyAt        *time.Time  `json:"ready_at"`
+	CompletedAt    *time.Time  `json:"completed_at"`
+	CancelledAt    *time.Time  `json:"cancelled_at"`
+	Items          []OrderItem `json:"items"`
+}
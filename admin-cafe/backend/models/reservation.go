@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// Area meja, sama dengan kunci fasilitas cafe (cafe_facilities.nama_fasilitas)
+var TableAreas = []string{"indoor", "outdoor", "smoking_area"}
+
+// ValidTableArea memeriksa apakah area dikenal
+func ValidTableArea(area string) bool {
+	for _, a := range TableAreas {
+		if a == area {
+			return true
+		}
+	}
+	return false
+}
+
+// Status reservasi
+const (
+	ReservationPending   = "pending"
+	ReservationConfirmed = "confirmed"
+	ReservationDeclined  = "declined"
+	ReservationCancelled = "cancelled"
+	ReservationNoShow    = "no_show"
+	ReservationExpired   = "expired"
+)
+
+// CafeTable adalah meja/area yang bisa direservasi
+type CafeTable struct {
+	ID       int    `json:"id"`
+	CafeID   int    `json:"cafe_id"`
+	Label    string `json:"label"`
+	Area     string `json:"area"`
+	Capacity int    `json:"capacity"`
+	Active   bool   `json:"active"`
+}
+
+// ReservationRequest adalah permintaan reservasi dari customer
+type ReservationRequest struct {
+	CafeID     int
+	CustomerID int
+	TableID    *int
+	Area       string
+	PartySize  int
+	Start      time.Time
+	End        time.Time
+	Note       string
+	HoldUntil  time.Time
+}
+
+// Reservation adalah reservasi meja
+type Reservation struct {
+	ID            int        `json:"id"`
+	CafeID        int        `json:"cafe_id"`
+	CafeNama      string     `json:"cafe_nama,omitempty"`
+	TableID       int        `json:"table_id"`
+	TableLabel    string     `json:"table_label"`
+	Area          string     `json:"area"`
+	CustomerID    int        `json:"customer_user_id"`
+	CustomerName  string     `json:"customer_name,omitempty"`
+	PartySize     int        `json:"party_size"`
+	StartAt       time.Time  `json:"start_at"`
+	EndAt         time.Time  `json:"end_at"`
+	Status        string     `json:"status"`
+	Note          string     `json:"note,omitempty"`
+	DeclineReason string     `json:"decline_reason,omitempty"`
+	HoldExpiresAt *time.Time `json:"hold_expires_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
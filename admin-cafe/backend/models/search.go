@@ -0,0 +1,28 @@
+package models
+
+// Jenis hasil pencarian
+const (
+	SearchTypeCafe = "cafe"
+	SearchTypeMenu = "menu"
+)
+
+// SearchHit adalah satu hasil pencarian gabungan cafe & menu.
+// Highlight & Snippet adalah HTML: teks asli sudah di-escape dan hanya
+// berisi tag <mark> di sekitar kata yang cocok.
+type SearchHit struct {
+	Type      string  `json:"type"`
+	ID        string  `json:"id"`
+	CafeID    *int    `json:"cafe_id"`
+	Title     string  `json:"title"`
+	Subtitle  string  `json:"subtitle"`
+	Highlight string  `json:"highlight"`
+	Snippet   string  `json:"snippet"`
+	Rank      float64 `json:"rank"`
+}
+
+// SearchQuery berisi parameter pencarian
+type SearchQuery struct {
+	Text  string
+	Types []string
+	Limit int
+}
@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// Periode tagihan paket langganan
+const (
+	BillingMonthly = "monthly"
+	BillingYearly  = "yearly"
+)
+
+// Status langganan
+const (
+	// Menunggu pembayaran pertama, belum memberi akses premium
+	SubscriptionPending = "pending"
+	SubscriptionActive  = "active"
+	// Periode habis dan tagihan perpanjangan belum dibayar
+	SubscriptionPastDue = "past_due"
+	// Dibatalkan user, tetap berlaku sampai akhir periode berjalan
+	SubscriptionCancelled = "cancelled"
+	SubscriptionExpired   = "expired"
+)
+
+// SubscriptionPlan adalah paket langganan premium
+type SubscriptionPlan struct {
+	ID            int     `json:"id"`
+	Code          string  `json:"code"`
+	Name          string  `json:"name"`
+	Price         float64 `json:"price"`
+	BillingPeriod string  `json:"billing_period"`
+	Active        bool    `json:"active"`
+}
+
+// PeriodEnd menghitung akhir periode tagihan yang dimulai pada start
+func (p SubscriptionPlan) PeriodEnd(start time.Time) time.Time {
+	if p.BillingPeriod == BillingYearly {
+		return start.AddDate(1, 0, 0)
+	}
+	return start.AddDate(0, 1, 0)
+}
+
+// Subscription adalah langganan premium milik user
+type Subscription struct {
+	ID                 int              `json:"id"`
+	UserID             int              `json:"user_id"`
+	Plan               SubscriptionPlan `json:"plan"`
+	Status             string           `json:"status"`
+	CurrentPeriodStart time.Time        `json:"current_period_start"`
+	CurrentPeriodEnd   time.Time        `json:"current_period_end"`
+	CancelledAt        *time.Time       `json:"cancelled_at"`
+	ExpiredAt          *time.Time       `json:"expired_at"`
+	CreatedAt          time.Time        `json:"created_at"`
+}
+
+// Entitled bernilai true jika langganan masih memberi akses premium
+func (s *Subscription) Entitled(now time.Time) bool {
+	return (s.Status == SubscriptionActive || s.Status == SubscriptionCancelled) && now.Before(s.CurrentPeriodEnd)
+}
+
+// SubscriptionPeriod adalah satu periode yang ditagihkan
+type SubscriptionPeriod struct {
+	ID          int       `json:"id"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	Amount      float64   `json:"amount"`
+	PaymentID   *int      `json:"payment_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -1,5 +1,14 @@
 package models
 
+import "time"
+
+// Role akun
+const (
+    RoleAdmin    = "admin"
+    RoleCafe     = "cafe"
+    RoleCustomer = "customer"
+)
+
 type User struct {
     ID        int    `json:"id"`
     Username  string `json:"username"`
@@ -9,3 +18,83 @@ type User struct {
     IzinUsaha string `json:"izin_usaha"`
     Verified  bool   `json:"verified"`
 }
+
+// LoginUser adalah data akun yang diperiksa saat login
+type LoginUser struct {
+    ID                 int
+    Username           string
+    Password           string
+    Role               string
+    EmailVerified      bool
+    MustChangePassword bool
+    Suspended          bool
+    SuspendedReason    string
+}
+
+// CafeAccount adalah akun cafe di halaman persetujuan super admin.
+// Cafe yang ditolak langsung dihapus, jadi Rejected selalu false; field
+// tetap dikirim karena dibaca frontend.
+type CafeAccount struct {
+    ID        int    `json:"id"`
+    Username  string `json:"username"`
+    Email     string `json:"email"`
+    IzinUsaha string `json:"izin_usaha"`
+    Verified  bool   `json:"verified"`
+    Rejected  bool   `json:"rejected"`
+}
+
+// CustomerProfile adalah data akun customer yang boleh dikirim ke client
+type CustomerProfile struct {
+    ID            int       `json:"id"`
+    Username      string    `json:"username"`
+    Email         string    `json:"email"`
+    DisplayName   string    `json:"display_name"`
+    Phone         string    `json:"phone"`
+    Avatar        string    `json:"avatar"`
+    EmailVerified bool      `json:"email_verified"`
+    CreatedAt     time.Time `json:"created_at"`
+}
+
+// SessionUser adalah user yang sedang login (hasil validasi token session)
+type SessionUser struct {
+    ID                 int    `json:"id"`
+    Username           string `json:"username"`
+    Role               string `json:"role"`
+    MustChangePassword bool   `json:"must_change_password"`
+}
+
+// Status akun untuk filter kelola akun super admin
+const (
+    AccountActive     = "active"
+    AccountUnverified = "unverified"
+    AccountSuspended  = "suspended"
+    AccountDeleted    = "deleted"
+)
+
+// Account adalah data akun yang tampil di halaman kelola akun
+type Account struct {
+    ID              int        `json:"id"`
+    Username        string     `json:"username"`
+    Email           string     `json:"email"`
+    Role            string     `json:"role"`
+    DisplayName     string     `json:"display_name,omitempty"`
+    Status          string     `json:"status"`
+    Verified        bool       `json:"verified"`
+    EmailVerified   bool       `json:"email_verified"`
+    SuspendedAt     *time.Time `json:"suspended_at"`
+    SuspendedReason string     `json:"suspended_reason,omitempty"`
+    DeletedAt       *time.Time `json:"deleted_at"`
+    ActiveSessions  int        `json:"active_sessions"`
+    CreatedAt       time.Time  `json:"created_at"`
+}
+
+// AccountFilter adalah filter daftar akun
+type AccountFilter struct {
+    Role   string
+    Status string
+    Search string
+    From   *time.Time
+    To     *time.Time
+    Limit  int
+    Offset int
+}
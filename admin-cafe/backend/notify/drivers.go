@@ -0,0 +1,166 @@
+package notify
+
+import (
+	"backend/config"
+	"backend/dbtime"
+	"backend/mailer"
+	"backend/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrUndeliverable menandai notifikasi yang tidak mungkin terkirim lewat
+// channel tersebut (mis. user tanpa email); tidak dicoba ulang.
+var ErrUndeliverable = errors.New("notifikasi tidak bisa dikirim lewat channel ini")
+
+// Delivery adalah satu baris outbox yang siap dikirim
+type Delivery struct {
+	OutboxID  int64
+	Channel   string
+	Attempt   int
+	UserID    int
+	Username  string
+	Email     string
+	EventType string
+	Title     string
+	Body      string
+	Link      string
+	Data      Data
+	CreatedAt time.Time
+}
+
+// Driver mengirim notifikasi lewat satu channel
+type Driver interface {
+	Channel() string
+	Deliver(ctx context.Context, d Delivery) error
+}
+
+// ==============================
+// In-app: tulis ke inbox user
+// ==============================
+type InAppDriver struct {
+	db *sql.DB
+}
+
+func NewInAppDriver(db *sql.DB) *InAppDriver {
+	return &InAppDriver{db: db}
+}
+
+func (d *InAppDriver) Channel() string { return models.ChannelInApp }
+
+func (d *InAppDriver) Deliver(ctx context.Context, n Delivery) error {
+	data, err := json.Marshal(n.Data)
+	if err != nil {
+		return err
+	}
+	_, err = d.db.ExecContext(ctx, `
+		INSERT INTO notifications (user_id, outbox_id, event_type, title, body, link, data, created_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8)
+		ON CONFLICT (outbox_id) DO NOTHING`,
+		n.UserID, n.OutboxID, n.EventType, n.Title, n.Body, n.Link, data, dbtime.Format(n.CreatedAt),
+	)
+	return err
+}
+
+// ==============================
+// Email lewat mailer
+// ==============================
+type EmailDriver struct {
+	mailer mailer.Mailer
+}
+
+func NewEmailDriver(m mailer.Mailer) *EmailDriver {
+	return &EmailDriver{mailer: m}
+}
+
+func (d *EmailDriver) Channel() string { return models.ChannelEmail }
+
+func (d *EmailDriver) Deliver(ctx context.Context, n Delivery) error {
+	if n.Email == "" {
+		return fmt.Errorf("%w: user #%d belum punya email", ErrUndeliverable, n.UserID)
+	}
+
+	name := n.Data["name"]
+	if name == "" {
+		name = n.Username
+	}
+	link := ""
+	if n.Link != "" {
+		link = config.FrontendURL + n.Link
+	}
+	data := mailer.TemplateData{
+		Name:   name,
+		Link:   link,
+		Change: n.Data["change"],
+		Reason: n.Data["reason"],
+		Title:  n.Title,
+		Body:   n.Body,
+	}
+
+	template := mailer.TemplateNotification
+	if tpl := templates[n.EventType]; tpl.email != "" {
+		template = tpl.email
+	}
+	msg, err := mailer.Render(template, mailer.LangID, n.Email, data)
+	if err != nil {
+		return err
+	}
+	if template == mailer.TemplateNotification {
+		msg.Subject = n.Title
+	}
+	return d.mailer.Send(msg)
+}
+
+// ==============================
+// Push (pengganti sementara): tulis ke sink lokal
+// ==============================
+
+// PushDriver belum terhubung ke layanan push sungguhan. Setiap push
+// ditulis sebagai satu baris JSON ke file Path, atau ke stdout jika Path
+// kosong.
+type PushDriver struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func NewPushDriver(path string) *PushDriver {
+	return &PushDriver{Path: path}
+}
+
+func (d *PushDriver) Channel() string { return models.ChannelPush }
+
+func (d *PushDriver) Deliver(ctx context.Context, n Delivery) error {
+	line, err := json.Marshal(map[string]interface{}{
+		"user_id": n.UserID,
+		"event":   n.EventType,
+		"title":   n.Title,
+		"body":    n.Body,
+		"link":    n.Link,
+		"data":    n.Data,
+	})
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Path == "" {
+		fmt.Printf("===== PUSH =====\n%s\n", line)
+		return nil
+	}
+	f, err := os.OpenFile(d.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
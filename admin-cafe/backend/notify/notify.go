@@ -0,0 +1,247 @@
+// Package notify mengirim notifikasi ke user lewat beberapa channel
+// (in-app, email, push). Notify hanya menulis ke tabel outbox; RunOutbox,
+// yang dijadwalkan supervisor di main, mengirimnya dengan retry, sehingga
+// notifikasi tidak hilang saat mailer / channel lain sedang gagal.
+package notify
+
+import (
+	"backend/dbtime"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Jadwal worker outbox
+const (
+	WorkerInterval = 5 * time.Second
+	batchSize      = 50
+	maxAttempts    = 6
+	// Baris yang sedang dikirim dianggap gagal jika worker tidak
+	// melapor dalam waktu ini (mis. proses mati di tengah jalan)
+	leaseDuration = 2 * time.Minute
+)
+
+// Status baris outbox
+const (
+	outboxPending = "pending"
+	outboxSent    = "sent"
+	outboxFailed  = "failed"
+	outboxSkipped = "skipped"
+)
+
+// Notifier mengantrekan notifikasi untuk user; diimplementasikan Service.
+// Handler cukup bergantung pada interface ini.
+type Notifier interface {
+	Notify(ctx context.Context, userID int, event string, data Data) error
+}
+
+var _ Notifier = (*Service)(nil)
+
+type Service struct {
+	db       *sql.DB
+	location *time.Location
+	drivers  map[string]Driver
+}
+
+func NewService(db *sql.DB, loc *time.Location, drivers ...Driver) *Service {
+	s := &Service{db: db, location: loc, drivers: map[string]Driver{}}
+	for _, d := range drivers {
+		s.drivers[d.Channel()] = d
+	}
+	return s
+}
+
+// Notify mengantrekan notifikasi event untuk user di setiap channel yang
+// tidak dimatikan user di pengaturan notifikasinya
+func (s *Service) Notify(ctx context.Context, userID int, event string, data Data) error {
+	title, body, link, err := render(event, data)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		data = Data{}
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	disabled, err := s.disabledChannels(ctx, userID, event)
+	if err != nil {
+		return err
+	}
+
+	var channels []string
+	for channel := range s.drivers {
+		if !disabled[channel] {
+			channels = append(channels, channel)
+		}
+	}
+	if len(channels) == 0 {
+		return nil
+	}
+
+	now := dbtime.Format(time.Now().In(s.location))
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO notification_outbox
+			(user_id, event_type, channel, title, body, link, data, next_attempt_at, created_at)
+		SELECT $1, $2, channel, $3, $4, NULLIF($5, ''), $6, $7, $7
+		FROM unnest($8::text[]) AS channel`,
+		userID, event, title, body, link, payload, now, pq.Array(channels),
+	)
+	return err
+}
+
+func (s *Service) disabledChannels(ctx context.Context, userID int, event string) (map[string]bool, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT channel FROM notification_preferences
+		WHERE user_id=$1 AND event_type=$2 AND enabled=false`, userID, event)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	disabled := map[string]bool{}
+	for rows.Next() {
+		var channel string
+		if err := rows.Scan(&channel); err != nil {
+			return nil, err
+		}
+		disabled[channel] = true
+	}
+	return disabled, rows.Err()
+}
+
+// ==============================
+// Worker outbox
+// ==============================
+
+// RunOutbox mengirim satu batch outbox dan mencatat pengiriman yang gagal.
+// Dijadwalkan setiap WorkerInterval oleh supervisor di main.
+func (s *Service) RunOutbox(ctx context.Context) error {
+	sent, failed, err := s.ProcessOutbox(ctx)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		slog.Warn("Notification outbox", "sent", sent, "failed", failed)
+	}
+	return nil
+}
+
+// ProcessOutbox mengirim satu batch notifikasi yang sudah jatuh tempo
+func (s *Service) ProcessOutbox(ctx context.Context) (sent, failed int, err error) {
+	now := time.Now().In(s.location)
+	deliveries, err := s.claim(ctx, now)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, d := range deliveries {
+		driver, ok := s.drivers[d.Channel]
+		var sendErr error
+		if !ok {
+			sendErr = fmt.Errorf("%w: channel %s tidak aktif", ErrUndeliverable, d.Channel)
+		} else {
+			sendErr = driver.Deliver(ctx, d)
+		}
+
+		if err := s.finish(ctx, d, sendErr); err != nil {
+			return sent, failed, err
+		}
+		if sendErr != nil {
+			failed++
+			slog.Warn("Kirim notifikasi gagal", "outbox_id", d.OutboxID, "channel", d.Channel, "attempt", d.Attempt, "err", sendErr)
+		} else {
+			sent++
+		}
+	}
+	return sent, failed, nil
+}
+
+// claim mengambil baris outbox yang jatuh tempo dan menggeser
+// next_attempt_at sejauh leaseDuration supaya worker lain tidak
+// mengambil baris yang sama
+func (s *Service) claim(ctx context.Context, now time.Time) ([]Delivery, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		UPDATE notification_outbox o
+		SET next_attempt_at=$2, attempts=o.attempts + 1
+		FROM users u
+		WHERE u.id = o.user_id AND o.id IN (
+			SELECT id FROM notification_outbox
+			WHERE status='pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING o.id, o.user_id, u.username, COALESCE(u.email, ''), o.event_type, o.channel,
+			o.title, o.body, COALESCE(o.link, ''), o.data, o.attempts, o.created_at`,
+		dbtime.Format(now), dbtime.Format(now.Add(leaseDuration)), batchSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []Delivery
+	for rows.Next() {
+		var (
+			d    Delivery
+			data []byte
+		)
+		err := rows.Scan(&d.OutboxID, &d.UserID, &d.Username, &d.Email, &d.EventType, &d.Channel,
+			&d.Title, &d.Body, &d.Link, &data, &d.Attempt, &d.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &d.Data); err != nil {
+			return nil, err
+		}
+		d.CreatedAt = dbtime.WallClock(d.CreatedAt, s.location)
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// finish mencatat hasil pengiriman. Gagal biasa dicoba lagi dengan jeda
+// yang makin panjang sampai maxAttempts.
+func (s *Service) finish(ctx context.Context, d Delivery, sendErr error) error {
+	now := time.Now().In(s.location)
+
+	if sendErr == nil {
+		_, err := s.db.ExecContext(ctx, `
+			UPDATE notification_outbox SET status=$2, sent_at=$3, last_error=NULL WHERE id=$1`,
+			d.OutboxID, outboxSent, dbtime.Format(now))
+		return err
+	}
+
+	status := outboxPending
+	switch {
+	case errors.Is(sendErr, ErrUndeliverable):
+		status = outboxSkipped
+	case d.Attempt >= maxAttempts:
+		status = outboxFailed
+	}
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE notification_outbox SET status=$2, next_attempt_at=$3, last_error=$4 WHERE id=$1`,
+		d.OutboxID, status, dbtime.Format(now.Add(backoff(d.Attempt))), sendErr.Error())
+	return err
+}
+
+// backoff: 30 detik, 1 menit, 2 menit, ... maksimal 1 jam
+func backoff(attempt int) time.Duration {
+	d := 30 * time.Second
+	for i := 1; i < attempt && d < time.Hour; i++ {
+		d *= 2
+	}
+	if d > time.Hour {
+		d = time.Hour
+	}
+	return d
+}
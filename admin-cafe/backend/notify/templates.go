@@ -0,0 +1,98 @@
+package notify
+
+import (
+	"backend/mailer"
+	"backend/models"
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// Data adalah variabel yang dipakai template notifikasi, mis. "name",
+// "change", "reason". Disimpan apa adanya di outbox & inbox.
+type Data map[string]string
+
+type eventTemplate struct {
+	title *template.Template
+	body  *template.Template
+	// Path halaman frontend yang dibuka dari notifikasi
+	link string
+	// Template email khusus; kosong berarti mailer.TemplateNotification
+	email string
+}
+
+var templates = map[string]eventTemplate{
+	models.NotifyCafeApproved: newTemplate(
+		"Cafe {{.name}} sudah disetujui",
+		"Pendaftaran cafe {{.name}} sudah disetujui admin CariSpot. Sekarang kamu bisa melengkapi profil dan menu cafe.",
+		"/login",
+		"",
+	),
+	models.NotifyChangeApproved: newTemplate(
+		"Perubahan {{.change}} disetujui",
+		"Perubahan {{.change}} untuk cafe {{.name}} sudah disetujui dan kini tampil di profil cafe.",
+		"/cafe/profile",
+		"",
+	),
+	models.NotifyChangeRejected: newTemplate(
+		"Perubahan {{.change}} ditolak",
+		"Perubahan {{.change}} untuk cafe {{.name}} ditolak dengan alasan: {{.reason}}",
+		"/cafe/profile",
+		mailer.TemplateChangeRejected,
+	),
+	models.NotifyReviewReplied: newTemplate(
+		"Ulasan kamu dibalas {{.cafe}}",
+		`{{.cafe}} membalas ulasan kamu: "{{.reply}}"`,
+		"/",
+		"",
+	),
+	models.NotifyEventRequestStatus: newTemplate(
+		"Pengajuan event {{.event}}: {{.status}}",
+		"Status pengajuan event {{.event}} sekarang {{.status}}.{{if .note}} Catatan: {{.note}}{{end}}",
+		"/",
+		"",
+	),
+}
+
+// EventTypes adalah semua jenis event yang punya template
+func EventTypes() []string {
+	return []string{
+		models.NotifyCafeApproved,
+		models.NotifyChangeApproved,
+		models.NotifyChangeRejected,
+		models.NotifyReviewReplied,
+		models.NotifyEventRequestStatus,
+	}
+}
+
+// KnownEvent mengecek apakah event punya template
+func KnownEvent(event string) bool {
+	_, ok := templates[event]
+	return ok
+}
+
+func newTemplate(title, body, link, email string) eventTemplate {
+	return eventTemplate{
+		title: template.Must(template.New("title").Option("missingkey=zero").Parse(title)),
+		body:  template.Must(template.New("body").Option("missingkey=zero").Parse(body)),
+		link:  link,
+		email: email,
+	}
+}
+
+// render menyusun judul & isi notifikasi dari template event
+func render(event string, data Data) (title, body, link string, err error) {
+	tpl, ok := templates[event]
+	if !ok {
+		return "", "", "", fmt.Errorf("template notifikasi tidak dikenal: %s", event)
+	}
+
+	var t, b bytes.Buffer
+	if err := tpl.title.Execute(&t, data); err != nil {
+		return "", "", "", err
+	}
+	if err := tpl.body.Execute(&b, data); err != nil {
+		return "", "", "", err
+	}
+	return t.String(), b.String(), tpl.link, nil
+}
@@ -0,0 +1,64 @@
+// Package passwords meng-hash dan memeriksa password akun dengan bcrypt.
+//
+// Akun lama masih menyimpan password plain text. Verify tetap menerima
+// password seperti itu supaya user bisa login, lalu pemanggil menyimpan
+// ulang hasil Hash jika NeedsRehash bernilai true.
+package passwords
+
+import (
+	"crypto/subtle"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Cost adalah biaya bcrypt untuk hash baru. Test boleh menurunkannya ke
+// bcrypt.MinCost supaya cepat.
+var Cost = bcrypt.DefaultCost
+
+// Hash membuat hash bcrypt dari password
+func Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Verify bernilai true jika password cocok dengan nilai kolom
+// users.password (hash bcrypt, atau plain text untuk akun lama)
+func Verify(stored, password string) bool {
+	if isHash(stored) {
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) == nil
+	}
+	return stored != "" && subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+}
+
+var (
+	dummyOnce sync.Once
+	dummyHash []byte
+)
+
+// VerifyMissing menjalankan perbandingan bcrypt terhadap hash palsu untuk
+// akun yang tidak ditemukan, supaya waktu respons login tidak membedakan
+// username terdaftar dan tidak
+func VerifyMissing(password string) {
+	dummyOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("password-palsu"), Cost)
+	})
+	bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+}
+
+// NeedsRehash bernilai true jika nilai tersimpan masih plain text
+func NeedsRehash(stored string) bool {
+	return !isHash(stored)
+}
+
+func isHash(stored string) bool {
+	if !strings.HasPrefix(stored, "$2") {
+		return false
+	}
+	_, err := bcrypt.Cost([]byte(stored))
+	return err == nil
+}
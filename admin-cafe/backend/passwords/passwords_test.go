@@ -0,0 +1,40 @@
+package passwords
+
+import (
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestVerify(t *testing.T) {
+	Cost = bcrypt.MinCost
+	hash, err := Hash("rahasia123")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	cases := []struct {
+		name     string
+		stored   string
+		password string
+		want     bool
+		rehash   bool
+	}{
+		{"hash cocok", hash, "rahasia123", true, false},
+		{"hash salah", hash, "rahasia124", false, false},
+		{"plain text lama cocok", "rahasia123", "rahasia123", true, true},
+		{"plain text lama salah", "rahasia123", "rahasia", false, true},
+		{"kosong", "", "", false, true},
+		{"mirip hash tapi bukan", "$2y$bukan-hash", "$2y$bukan-hash", true, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Verify(c.stored, c.password); got != c.want {
+				t.Errorf("Verify = %v, mau %v", got, c.want)
+			}
+			if got := NeedsRehash(c.stored); got != c.rehash {
+				t.Errorf("NeedsRehash = %v, mau %v", got, c.rehash)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// MockSignatureHeader berisi HMAC-SHA256 (hex) dari body webhook
+const MockSignatureHeader = "X-Mock-Signature"
+
+// MockProvider adalah gateway pembayaran lokal untuk development.
+// Charge disimpan di memori. Jika Delay > 0, charge otomatis dibayar
+// setelah Delay, kecuali nominal berakhiran 13 (mis. 25013) yang
+// disimulasikan gagal. Dengan Delay 0, status hanya berubah lewat
+// Simulate atau saat kedaluwarsa. Setiap perubahan status dikirim sebagai
+// webhook bertanda tangan ke WebhookURL.
+type MockProvider struct {
+	Secret     string
+	WebhookURL string
+	Delay      time.Duration
+
+	client  *http.Client
+	mu      sync.Mutex
+	charges map[string]*mockCharge
+}
+
+type mockCharge struct {
+	reference string
+	amount    float64
+	status    Status
+}
+
+func NewMockProvider(secret, webhookURL string, delay time.Duration) *MockProvider {
+	return &MockProvider{
+		Secret:     secret,
+		WebhookURL: webhookURL,
+		Delay:      delay,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		charges:    map[string]*mockCharge{},
+	}
+}
+
+func (p *MockProvider) Name() string {
+	return "mock"
+}
+
+// =========================
+// Buat tagihan
+// =========================
+func (p *MockProvider) CreateCharge(ctx context.Context, req ChargeRequest) (*Charge, error) {
+	ref, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	ref = "mock_" + ref
+
+	charge := &Charge{ProviderRef: ref, Status: StatusPending, ExpiresAt: req.ExpiresAt}
+	amount := fmt.Sprintf("%.0f", req.Amount)
+	switch req.Method {
+	case MethodQRIS:
+		// Payload meniru format QRIS (EMVCo) tapi tidak bisa dipindai bank
+		charge.Instructions.QRString = fmt.Sprintf("00020101021226570011ID.MOCKPAY0118%s5204581253033605405%s5802ID5907CARISPOT6304MOCK", ref, amount)
+	case MethodEWallet:
+		charge.Instructions.CheckoutURL = "https://mockpay.local/checkout/" + ref
+		charge.Instructions.DeeplinkURL = "mockpay://pay?ref=" + ref
+	case MethodBankTransfer:
+		va, err := randomDigits(10)
+		if err != nil {
+			return nil, err
+		}
+		charge.Instructions.BankCode = "MOCKBANK"
+		charge.Instructions.VANumber = "8808" + va
+	default:
+		return nil, fmt.Errorf("metode pembayaran tidak didukung: %s", req.Method)
+	}
+
+	p.mu.Lock()
+	p.charges[ref] = &mockCharge{reference: req.Reference, amount: req.Amount, status: StatusPending}
+	p.mu.Unlock()
+
+	if p.Delay > 0 {
+		outcome := StatusPaid
+		if int64(math.Round(req.Amount))%100 == 13 {
+			outcome = StatusFailed
+		}
+		time.AfterFunc(p.Delay, func() { p.Simulate(ref, outcome) })
+	}
+	if !req.ExpiresAt.IsZero() {
+		time.AfterFunc(time.Until(req.ExpiresAt), func() { p.Simulate(ref, StatusExpired) })
+	}
+	return charge, nil
+}
+
+func (p *MockProvider) GetStatus(ctx context.Context, providerRef string) (Status, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c, ok := p.charges[providerRef]
+	if !ok {
+		return "", ErrChargeNotFound
+	}
+	return c.status, nil
+}
+
+func (p *MockProvider) Refund(ctx context.Context, providerRef string, amount float64) error {
+	p.mu.Lock()
+	c, ok := p.charges[providerRef]
+	if !ok {
+		p.mu.Unlock()
+		return ErrChargeNotFound
+	}
+	if c.status != StatusPaid {
+		p.mu.Unlock()
+		return fmt.Errorf("charge %s berstatus %s, tidak bisa di-refund", providerRef, c.status)
+	}
+	c.status = StatusRefunded
+	event := p.event(providerRef, c)
+	p.mu.Unlock()
+
+	go p.sendWebhook(event)
+	return nil
+}
+
+// Cancel membatalkan charge yang masih pending. Tidak ada webhook karena
+// pemanggil sudah mencatat pembatalannya sendiri.
+func (p *MockProvider) Cancel(ctx context.Context, providerRef string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c, ok := p.charges[providerRef]
+	if !ok {
+		return ErrChargeNotFound
+	}
+	if c.status != StatusPending {
+		return fmt.Errorf("charge %s sudah berstatus %s", providerRef, c.status)
+	}
+	c.status = StatusExpired
+	return nil
+}
+
+// =========================
+// Simulasi perubahan status
+// =========================
+// Simulate mengubah status charge yang masih pending lalu mengirim
+// webhook, seperti saat customer membayar di aplikasi bank/e-wallet.
+func (p *MockProvider) Simulate(providerRef string, status Status) error {
+	p.mu.Lock()
+	c, ok := p.charges[providerRef]
+	if !ok {
+		p.mu.Unlock()
+		return ErrChargeNotFound
+	}
+	if c.status != StatusPending {
+		p.mu.Unlock()
+		return fmt.Errorf("charge %s sudah berstatus %s", providerRef, c.status)
+	}
+	c.status = status
+	event := p.event(providerRef, c)
+	p.mu.Unlock()
+
+	go p.sendWebhook(event)
+	return nil
+}
+
+func (p *MockProvider) event(providerRef string, c *mockCharge) WebhookEvent {
+	id, _ := randomHex(8)
+	return WebhookEvent{
+		EventID:     "evt_" + id,
+		ProviderRef: providerRef,
+		Reference:   c.reference,
+		Status:      c.status,
+		Amount:      c.amount,
+	}
+}
+
+// sendWebhook mengirim webhook dengan retry (1s, 2s, 4s) seperti gateway
+// sungguhan, jadi handler webhook harus idempotent.
+func (p *MockProvider) sendWebhook(event WebhookEvent) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		slog.Error("Mock payment webhook error", "err", err)
+		return
+	}
+
+	backoff := time.Second
+	for attempt := 1; attempt <= 4; attempt++ {
+		req, err := http.NewRequest("POST", p.WebhookURL, bytes.NewReader(body))
+		if err != nil {
+			slog.Error("Mock payment webhook error", "err", err)
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(MockSignatureHeader, p.sign(body))
+
+		res, err := p.client.Do(req)
+		if err == nil {
+			res.Body.Close()
+			if res.StatusCode < 300 {
+				return
+			}
+			err = fmt.Errorf("status %d", res.StatusCode)
+		}
+		slog.Warn("Mock payment webhook gagal", "event_id", event.EventID, "attempt", attempt, "err", err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (p *MockProvider) VerifyWebhook(header http.Header, body []byte) (*WebhookEvent, error) {
+	got, err := hex.DecodeString(header.Get(MockSignatureHeader))
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	want, _ := hex.DecodeString(p.sign(body))
+	if !hmac.Equal(got, want) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, err
+	}
+	if event.EventID == "" || event.ProviderRef == "" {
+		return nil, fmt.Errorf("webhook tidak lengkap")
+	}
+	return &event, nil
+}
+
+func (p *MockProvider) sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(p.Secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func randomDigits(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = '0' + b[i]%10
+	}
+	return string(b), nil
+}
@@ -0,0 +1,134 @@
+// Package payments menangani pembayaran pesanan. Gateway pembayaran
+// diabstraksikan lewat interface PaymentProvider; implementasi dipilih
+// lewat environment variable PAYMENT_PROVIDER yang wajib diisi. Saat ini
+// tersedia "mock", gateway lokal yang mensimulasikan QRIS, e-wallet dan
+// transfer bank lengkap dengan webhook asinkron. Mock hanya untuk
+// development dan harus diaktifkan eksplisit dengan
+// PAYMENT_MOCK_ENABLED=true.
+package payments
+
+import (
+	"backend/config"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Method adalah metode pembayaran
+type Method string
+
+const (
+	MethodQRIS         Method = "qris"
+	MethodEWallet      Method = "ewallet"
+	MethodBankTransfer Method = "bank_transfer"
+)
+
+// ParseMethod memvalidasi metode pembayaran dari request
+func ParseMethod(v string) (Method, error) {
+	switch m := Method(v); m {
+	case MethodQRIS, MethodEWallet, MethodBankTransfer:
+		return m, nil
+	default:
+		return "", fmt.Errorf("method harus qris, ewallet atau bank_transfer")
+	}
+}
+
+// Status adalah status pembayaran
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusPaid     Status = "paid"
+	StatusFailed   Status = "failed"
+	StatusExpired  Status = "expired"
+	StatusRefunded Status = "refunded"
+	// Hanya di database: refund sedang diproses gateway
+	StatusRefunding Status = "refunding"
+)
+
+// ErrInvalidSignature dikembalikan jika signature webhook tidak cocok
+var ErrInvalidSignature = errors.New("signature webhook tidak valid")
+
+// ErrChargeNotFound dikembalikan provider jika charge tidak dikenal
+var ErrChargeNotFound = errors.New("charge tidak ditemukan")
+
+// ChargeRequest adalah permintaan tagihan ke gateway
+type ChargeRequest struct {
+	Reference   string // ID pembayaran internal, dikirim balik di webhook
+	Amount      float64
+	Method      Method
+	Description string
+	ExpiresAt   time.Time
+}
+
+// Instructions adalah cara membayar yang ditampilkan ke customer
+type Instructions struct {
+	QRString    string `json:"qr_string,omitempty"`
+	CheckoutURL string `json:"checkout_url,omitempty"`
+	DeeplinkURL string `json:"deeplink_url,omitempty"`
+	BankCode    string `json:"bank_code,omitempty"`
+	VANumber    string `json:"va_number,omitempty"`
+}
+
+// Charge adalah tagihan yang dibuat di gateway
+type Charge struct {
+	ProviderRef  string
+	Status       Status
+	Instructions Instructions
+	ExpiresAt    time.Time
+}
+
+// WebhookEvent adalah notifikasi perubahan status dari gateway. EventID
+// unik per notifikasi sehingga pengiriman ulang bisa dikenali.
+type WebhookEvent struct {
+	EventID     string  `json:"event_id"`
+	ProviderRef string  `json:"provider_ref"`
+	Reference   string  `json:"reference"`
+	Status      Status  `json:"status"`
+	Amount      float64 `json:"amount"`
+}
+
+// PaymentProvider adalah gateway pembayaran
+type PaymentProvider interface {
+	Name() string
+	CreateCharge(ctx context.Context, req ChargeRequest) (*Charge, error)
+	GetStatus(ctx context.Context, providerRef string) (Status, error)
+	Refund(ctx context.Context, providerRef string, amount float64) error
+	// Cancel membatalkan tagihan yang belum dibayar
+	Cancel(ctx context.Context, providerRef string) error
+	// VerifyWebhook memeriksa signature request webhook dan membaca isinya
+	VerifyWebhook(header http.Header, body []byte) (*WebhookEvent, error)
+}
+
+// FromEnv membuat PaymentProvider sesuai konfigurasi environment. Tanpa
+// PAYMENT_PROVIDER startup gagal, supaya deployment yang lupa
+// mengaturnya tidak diam-diam memakai gateway mock.
+func FromEnv() (PaymentProvider, error) {
+	switch name := config.Getenv("PAYMENT_PROVIDER", ""); name {
+	case "":
+		return nil, fmt.Errorf("PAYMENT_PROVIDER wajib diisi")
+	case "mock":
+		// Siapa pun yang tahu secret bisa menandai pesanan lunas
+		if config.Getenv("PAYMENT_MOCK_ENABLED", "") != "true" {
+			return nil, fmt.Errorf("PAYMENT_PROVIDER=mock hanya untuk development, set PAYMENT_MOCK_ENABLED=true")
+		}
+		secret := config.Getenv("PAYMENT_WEBHOOK_SECRET", "")
+		if secret == "" {
+			return nil, fmt.Errorf("PAYMENT_WEBHOOK_SECRET wajib diisi")
+		}
+		delay, err := strconv.Atoi(config.Getenv("MOCK_PAYMENT_DELAY_SECONDS", "10"))
+		if err != nil || delay < 0 {
+			return nil, fmt.Errorf("MOCK_PAYMENT_DELAY_SECONDS tidak valid")
+		}
+		return NewMockProvider(
+			secret,
+			config.Getenv("PAYMENT_WEBHOOK_URL", "http://localhost:8080/payments/webhook/mock"),
+			time.Duration(delay)*time.Second,
+		), nil
+	default:
+		return nil, fmt.Errorf("PAYMENT_PROVIDER tidak dikenal: %q", name)
+	}
+}
@@ -0,0 +1,34 @@
+package payments_test
+
+import (
+	"backend/payments"
+	"testing"
+)
+
+func TestFromEnv(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		wantOK bool
+	}{
+		{"provider kosong", map[string]string{}, false},
+		{"provider tidak dikenal", map[string]string{"PAYMENT_PROVIDER": "midtrans"}, false},
+		{"mock tanpa flag dev", map[string]string{"PAYMENT_PROVIDER": "mock", "PAYMENT_WEBHOOK_SECRET": "rahasia"}, false},
+		{"mock tanpa secret", map[string]string{"PAYMENT_PROVIDER": "mock", "PAYMENT_MOCK_ENABLED": "true"}, false},
+		{"mock", map[string]string{"PAYMENT_PROVIDER": "mock", "PAYMENT_MOCK_ENABLED": "true", "PAYMENT_WEBHOOK_SECRET": "rahasia"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"PAYMENT_PROVIDER", "PAYMENT_MOCK_ENABLED", "PAYMENT_WEBHOOK_SECRET", "MOCK_PAYMENT_DELAY_SECONDS"} {
+				t.Setenv(key, tt.env[key])
+			}
+			p, err := payments.FromEnv()
+			if tt.wantOK && (err != nil || p.Name() != "mock") {
+				t.Fatalf("FromEnv = %v, %v", p, err)
+			}
+			if !tt.wantOK && err == nil {
+				t.Fatalf("FromEnv = %v, mau error", p.Name())
+			}
+		})
+	}
+}
@@ -0,0 +1,816 @@
+package payments
+
+import (
+	"backend/dbtime"
+	"backend/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Jenis objek yang dibayar
+const (
+	ReferenceOrder        = "order"
+	ReferenceSubscription = "subscription"
+)
+
+// Lama tagihan berlaku sebelum kedaluwarsa
+const chargeTTL = 30 * time.Minute
+
+// Jadwal rekonsiliasi: pembayaran dianggap macet jika masih pending
+// 15 menit setelah tagihannya seharusnya kedaluwarsa
+const (
+	ReconcileInterval = 5 * time.Minute
+	StuckAfter        = chargeTTL + 15*time.Minute
+)
+
+var (
+	ErrPaymentNotFound = errors.New("pembayaran tidak ditemukan")
+	ErrNotPayable      = errors.New("pesanan tidak bisa dibayar")
+	ErrAlreadyPaid     = errors.New("pesanan sudah dibayar")
+	ErrNotRefundable   = errors.New("hanya pembayaran berstatus paid yang bisa di-refund")
+	ErrInProgress      = errors.New("pembayaran pesanan sedang dibuat, coba lagi")
+
+	ErrSubscriptionNotPayable = errors.New("langganan tidak sedang menunggu pembayaran")
+)
+
+// Payment adalah satu tagihan pembayaran
+type Payment struct {
+	ID            int          `json:"id"`
+	ReferenceType string       `json:"reference_type"`
+	ReferenceID   int          `json:"reference_id"`
+	Provider      string       `json:"provider"`
+	ProviderRef   string       `json:"provider_ref"`
+	Method        Method       `json:"method"`
+	Amount        float64      `json:"amount"`
+	Status        Status       `json:"status"`
+	Instructions  Instructions `json:"instructions"`
+	FailureReason string       `json:"failure_reason,omitempty"`
+	CreatedAt     time.Time    `json:"created_at"`
+	ExpiresAt     *time.Time   `json:"expires_at"`
+	PaidAt        *time.Time   `json:"paid_at"`
+	RefundedAt    *time.Time   `json:"refunded_at"`
+	FlaggedAt     *time.Time   `json:"flagged_at"`
+}
+
+// paymentTransitions adalah perubahan status yang diterima. Event lain
+// (mis. "expired" yang datang setelah "paid") diabaikan.
+var paymentTransitions = map[Status][]Status{
+	StatusPending:   {StatusPaid, StatusFailed, StatusExpired},
+	StatusPaid:      {StatusRefunded},
+	StatusRefunding: {StatusRefunded},
+}
+
+func canTransition(from, to Status) bool {
+	for _, s := range paymentTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Store adalah pembayaran pesanan & langganan serta webhook gateway;
+// diimplementasikan Service (PostgreSQL). Reconcile dan ChargeRenewal
+// dijalankan scheduler langsung lewat Service.
+type Store interface {
+	Provider() PaymentProvider
+	CreateForOrder(ctx context.Context, orderID int, method Method) (*Payment, error)
+	CreateForSubscription(ctx context.Context, subscriptionID int, method Method) (*Payment, error)
+	GetByID(ctx context.Context, id int) (*Payment, error)
+	HandleWebhook(ctx context.Context, event *WebhookEvent) (duplicate bool, err error)
+	Refund(ctx context.Context, id int) (*Payment, error)
+	CancelForOrder(ctx context.Context, orderID int) error
+}
+
+var _ Store = (*Service)(nil)
+
+type Service struct {
+	db       *sql.DB
+	provider PaymentProvider
+	location *time.Location
+}
+
+// location adalah zona waktu cafe; kolom TIMESTAMP menyimpan waktu lokal
+func NewService(db *sql.DB, provider PaymentProvider, location *time.Location) *Service {
+	return &Service{db: db, provider: provider, location: location}
+}
+
+// Provider mengembalikan gateway yang dipakai
+func (s *Service) Provider() PaymentProvider {
+	return s.provider
+}
+
+// =========================
+// Buat pembayaran pesanan
+// =========================
+// Jika masih ada tagihan pending dengan metode yang sama, tagihan itu
+// dikembalikan (aman untuk retry). Tagihan pending dengan metode lain
+// dianggap kedaluwarsa.
+func (s *Service) CreateForOrder(ctx context.Context, orderID int, method Method) (*Payment, error) {
+	now := time.Now().In(s.location)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var total float64
+	var orderStatus, paymentStatus string
+	err = tx.QueryRowContext(ctx, "SELECT total, status, payment_status FROM orders WHERE id=$1 FOR UPDATE", orderID).
+		Scan(&total, &orderStatus, &paymentStatus)
+	if err == sql.ErrNoRows {
+		return nil, ErrPaymentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if orderStatus == "cancelled" || total <= 0 {
+		return nil, ErrNotPayable
+	}
+	if paymentStatus == string(StatusPaid) || paymentStatus == string(StatusRefunded) {
+		return nil, ErrAlreadyPaid
+	}
+
+	p, existing, err := s.insertPending(ctx, tx, ReferenceOrder, orderID, total, method, now)
+	if err != nil || existing {
+		return p, err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE orders SET payment_status='pending' WHERE id=$1", orderID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.charge(ctx, p, fmt.Sprintf("Pesanan #%d", orderID))
+}
+
+// =========================
+// Buat pembayaran langganan
+// =========================
+// Tagihan dibuat untuk langganan yang menunggu pembayaran pertama
+// (pending) atau perpanjangan (past_due) sebesar harga paket saat ini.
+// Seperti CreateForOrder, tagihan pending dengan metode sama dipakai ulang.
+func (s *Service) CreateForSubscription(ctx context.Context, subscriptionID int, method Method) (*Payment, error) {
+	now := time.Now().In(s.location)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var status, planName string
+	var price float64
+	err = tx.QueryRowContext(ctx, `
+		SELECT s.status, p.name, p.price::float8
+		FROM subscriptions s JOIN subscription_plans p ON p.id = s.plan_id
+		WHERE s.id=$1
+		FOR UPDATE OF s`, subscriptionID,
+	).Scan(&status, &planName, &price)
+	if err == sql.ErrNoRows {
+		return nil, ErrPaymentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if (status != models.SubscriptionPending && status != models.SubscriptionPastDue) || price <= 0 {
+		return nil, ErrSubscriptionNotPayable
+	}
+
+	p, existing, err := s.insertPending(ctx, tx, ReferenceSubscription, subscriptionID, price, method, now)
+	if err != nil || existing {
+		return p, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.charge(ctx, p, planName)
+}
+
+// ChargeRenewal menagih perpanjangan langganan berstatus past_due dengan
+// metode pembayaran terakhir yang lunas. Dipanggil scheduler langganan.
+func (s *Service) ChargeRenewal(ctx context.Context, subscriptionID int) error {
+	method := MethodQRIS
+	var last string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT method FROM payments
+		WHERE reference_type=$1 AND reference_id=$2 AND paid_at IS NOT NULL
+		ORDER BY id DESC LIMIT 1`,
+		ReferenceSubscription, subscriptionID,
+	).Scan(&last)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if err == nil {
+		method = Method(last)
+	}
+	_, err = s.CreateForSubscription(ctx, subscriptionID, method)
+	return err
+}
+
+// insertPending menyimpan tagihan pending baru di dalam tx yang sudah
+// mengunci objek yang dibayar. existing bernilai true jika tagihan
+// pending dengan metode & nominal yang sama masih berlaku; tagihan itu
+// dikembalikan dan tx tidak perlu di-commit.
+func (s *Service) insertPending(ctx context.Context, tx *sql.Tx, refType string, refID int, amount float64, method Method, now time.Time) (p *Payment, existing bool, err error) {
+	old, err := s.scanPayment(tx.QueryRowContext(ctx, "SELECT "+paymentColumns+`
+		FROM payments WHERE reference_type=$1 AND reference_id=$2 AND status='pending'`,
+		refType, refID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, false, err
+	}
+	if err == nil {
+		if old.Method == method && old.ProviderRef != "" && old.Amount == amount &&
+			(old.ExpiresAt == nil || old.ExpiresAt.After(now)) {
+			return old, true, nil
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE payments SET status='expired', updated_at=$2 WHERE id=$1",
+			old.ID, dbtime.Format(now)); err != nil {
+			return nil, false, err
+		}
+	}
+
+	expiresAt := now.Add(chargeTTL)
+	p = &Payment{
+		ReferenceType: refType,
+		ReferenceID:   refID,
+		Provider:      s.provider.Name(),
+		Method:        method,
+		Amount:        amount,
+		Status:        StatusPending,
+		CreatedAt:     now,
+		ExpiresAt:     &expiresAt,
+	}
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO payments (reference_type, reference_id, provider, method, amount, status, expires_at, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$8) RETURNING id`,
+		p.ReferenceType, p.ReferenceID, p.Provider, p.Method, p.Amount, p.Status,
+		dbtime.Format(expiresAt), dbtime.Format(now),
+	).Scan(&p.ID)
+	if err != nil {
+		// Request paralel sudah membuat tagihan pending untuk objek ini
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return nil, false, ErrInProgress
+		}
+		return nil, false, err
+	}
+	return p, false, nil
+}
+
+// charge membuat tagihan di gateway untuk pembayaran yang baru disimpan.
+// Gateway dipanggil di luar transaksi supaya lock tidak tertahan selama
+// request jaringan.
+func (s *Service) charge(ctx context.Context, p *Payment, description string) (*Payment, error) {
+	charge, err := s.provider.CreateCharge(ctx, ChargeRequest{
+		Reference:   fmt.Sprint(p.ID),
+		Amount:      p.Amount,
+		Method:      p.Method,
+		Description: description,
+		ExpiresAt:   *p.ExpiresAt,
+	})
+	if err != nil {
+		// Gateway bisa gagal karena request dibatalkan; pembersihan tetap
+		// dijalankan supaya objek yang dibayar tidak tertahan menunggu
+		// tagihan yang tidak pernah ada
+		cleanup := context.WithoutCancel(ctx)
+		if cerr := s.fail(cleanup, p, err.Error()); cerr != nil {
+			slog.Error("Payment cleanup error", "payment_id", p.ID, "err", cerr)
+		}
+		return nil, err
+	}
+
+	instructions, err := json.Marshal(charge.Instructions)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.db.ExecContext(ctx, "UPDATE payments SET provider_ref=$2, instructions=$3 WHERE id=$1",
+		p.ID, charge.ProviderRef, string(instructions))
+	if err != nil {
+		return nil, err
+	}
+	p.ProviderRef = charge.ProviderRef
+	p.Instructions = charge.Instructions
+	return p, nil
+}
+
+// fail menandai tagihan yang gagal dibuat di gateway sebagai failed
+func (s *Service) fail(ctx context.Context, p *Payment, reason string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().In(s.location)
+	if _, err := tx.ExecContext(ctx, `UPDATE payments SET failure_reason=$2 WHERE id=$1`, p.ID, reason); err != nil {
+		return err
+	}
+	if err := s.applyStatus(ctx, tx, p, StatusFailed, now); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+const paymentColumns = `id, reference_type, reference_id, provider, COALESCE(provider_ref, ''), method,
+	amount, status, COALESCE(instructions::text, '{}'), COALESCE(failure_reason, ''),
+	created_at, expires_at, paid_at, refunded_at, flagged_at`
+
+func (s *Service) scanPayment(row interface{ Scan(...interface{}) error }) (*Payment, error) {
+	p := &Payment{}
+	var instructions string
+	var createdAt time.Time
+	var expires, paid, refunded, flagged pq.NullTime
+
+	err := row.Scan(&p.ID, &p.ReferenceType, &p.ReferenceID, &p.Provider, &p.ProviderRef, &p.Method,
+		&p.Amount, &p.Status, &instructions, &p.FailureReason,
+		&createdAt, &expires, &paid, &refunded, &flagged)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(instructions), &p.Instructions); err != nil {
+		return nil, err
+	}
+	p.CreatedAt = dbtime.WallClock(createdAt, s.location)
+	p.ExpiresAt = dbtime.WallClockNull(expires, s.location)
+	p.PaidAt = dbtime.WallClockNull(paid, s.location)
+	p.RefundedAt = dbtime.WallClockNull(refunded, s.location)
+	p.FlaggedAt = dbtime.WallClockNull(flagged, s.location)
+	return p, nil
+}
+
+// =========================
+// Ambil pembayaran
+// =========================
+func (s *Service) GetByID(ctx context.Context, id int) (*Payment, error) {
+	p, err := s.scanPayment(s.db.QueryRowContext(ctx, "SELECT "+paymentColumns+" FROM payments WHERE id=$1", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrPaymentNotFound
+	}
+	return p, err
+}
+
+// =========================
+// Proses webhook gateway
+// =========================
+// Idempotent: event yang sama (provider + event_id) hanya diproses
+// sekali, dan perubahan status yang tidak valid diabaikan. duplicate
+// bernilai true jika event sudah pernah diterima.
+func (s *Service) HandleWebhook(ctx context.Context, event *WebhookEvent) (duplicate bool, err error) {
+	now := time.Now().In(s.location)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return false, err
+	}
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO payment_events (provider, event_id, provider_ref, status, payload, received_at)
+		VALUES ($1,$2,$3,$4,$5,$6)
+		ON CONFLICT (provider, event_id) DO NOTHING`,
+		s.provider.Name(), event.EventID, event.ProviderRef, event.Status, string(payload), dbtime.Format(now),
+	)
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return false, err
+	} else if n == 0 {
+		return true, nil
+	}
+
+	p, err := s.scanPayment(tx.QueryRowContext(ctx, "SELECT "+paymentColumns+`
+		FROM payments WHERE provider=$1 AND provider_ref=$2 FOR UPDATE`,
+		s.provider.Name(), event.ProviderRef))
+	if err == sql.ErrNoRows {
+		return false, ErrPaymentNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// Nominal yang dibayar harus sama dengan tagihan
+	if event.Status == StatusPaid && math.Abs(event.Amount-p.Amount) > 0.005 {
+		reason := fmt.Sprintf("Nominal webhook %.2f tidak sama dengan tagihan %.2f", event.Amount, p.Amount)
+		if err := s.flag(ctx, tx, p.ID, now, reason); err != nil {
+			return false, err
+		}
+		return false, tx.Commit()
+	}
+
+	// Tagihan lama (sudah kedaluwarsa/diganti) tetap dibayar customer:
+	// uang masuk tapi pesanan tidak berubah, jadi perlu dicek manual
+	if event.Status == StatusPaid && p.Status != StatusPending && p.Status != StatusPaid {
+		if err := s.flag(ctx, tx, p.ID, now, fmt.Sprintf("Dibayar setelah tagihan berstatus %s", p.Status)); err != nil {
+			return false, err
+		}
+		return false, tx.Commit()
+	}
+
+	if err := s.applyStatus(ctx, tx, p, event.Status, now); err != nil {
+		return false, err
+	}
+	return false, tx.Commit()
+}
+
+// flag menandai pembayaran untuk dicek manual oleh admin
+func (s *Service) flag(ctx context.Context, tx *sql.Tx, id int, now time.Time, reason string) error {
+	_, err := tx.ExecContext(ctx, `UPDATE payments SET flagged_at=$2, failure_reason=$3, updated_at=$2 WHERE id=$1`,
+		id, dbtime.Format(now), reason)
+	if err == nil {
+		slog.Warn("Payment flagged", "payment_id", id, "reason", reason)
+	}
+	return err
+}
+
+// applyStatus mengubah status pembayaran beserta status pembayaran pesanan
+func (s *Service) applyStatus(ctx context.Context, tx *sql.Tx, p *Payment, status Status, now time.Time) error {
+	if !canTransition(p.Status, status) {
+		return nil
+	}
+
+	ts := dbtime.Format(now)
+	_, err := tx.ExecContext(ctx, `
+		UPDATE payments SET status=$2, updated_at=$3,
+			paid_at=CASE WHEN $2='paid' THEN $3::timestamp ELSE paid_at END,
+			refunded_at=CASE WHEN $2='refunded' THEN $3::timestamp ELSE refunded_at END
+		WHERE id=$1`,
+		p.ID, status, ts,
+	)
+	if err != nil {
+		return err
+	}
+	p.Status = status
+
+	switch p.ReferenceType {
+	case ReferenceOrder:
+		return s.applyOrder(ctx, tx, p, status, now)
+	case ReferenceSubscription:
+		return s.applySubscription(ctx, tx, p, status, now)
+	}
+	return nil
+}
+
+// applyOrder meneruskan status pembayaran ke status pembayaran pesanan.
+// Pembayaran yang lunas setelah pesanan dibatalkan tidak mengubah
+// pesanan dan ditandai supaya di-refund.
+func (s *Service) applyOrder(ctx context.Context, tx *sql.Tx, p *Payment, status Status, now time.Time) error {
+	ts := dbtime.Format(now)
+	var err error
+	switch status {
+	case StatusPaid:
+		var orderStatus string
+		err = tx.QueryRowContext(ctx, "SELECT status FROM orders WHERE id=$1 FOR UPDATE", p.ReferenceID).Scan(&orderStatus)
+		if err != nil {
+			return err
+		}
+		if orderStatus == models.OrderCancelled {
+			return s.flag(ctx, tx, p.ID, now, "Dibayar setelah pesanan dibatalkan, perlu di-refund")
+		}
+		_, err = tx.ExecContext(ctx, "UPDATE orders SET payment_status=$2, updated_at=$3 WHERE id=$1", p.ReferenceID, status, ts)
+	case StatusRefunded:
+		_, err = tx.ExecContext(ctx, "UPDATE orders SET payment_status=$2, updated_at=$3 WHERE id=$1", p.ReferenceID, status, ts)
+	default:
+		// Gagal/kedaluwarsa: pesanan bisa dibayar ulang
+		_, err = tx.ExecContext(ctx, "UPDATE orders SET payment_status='unpaid', updated_at=$2 WHERE id=$1 AND payment_status='pending'",
+			p.ReferenceID, ts)
+	}
+	return err
+}
+
+// applySubscription meneruskan status pembayaran ke langganan. Lunas
+// mengaktifkan langganan baru atau memperpanjang satu periode; tagihan
+// perpanjangan yang gagal/kedaluwarsa mengakhiri langganan, sedangkan
+// langganan baru tetap pending supaya bisa dibayar ulang. Refund
+// pembayaran periode berjalan mengakhiri langganan saat itu juga.
+func (s *Service) applySubscription(ctx context.Context, tx *sql.Tx, p *Payment, status Status, now time.Time) error {
+	var current string
+	var end time.Time
+	var plan models.SubscriptionPlan
+	err := tx.QueryRowContext(ctx, `
+		SELECT s.status, s.current_period_end, p.billing_period
+		FROM subscriptions s JOIN subscription_plans p ON p.id = s.plan_id
+		WHERE s.id=$1
+		FOR UPDATE OF s`, p.ReferenceID,
+	).Scan(&current, &end, &plan.BillingPeriod)
+	if err != nil {
+		return err
+	}
+	ts := dbtime.Format(now)
+
+	switch status {
+	case StatusPaid:
+		var start time.Time
+		switch current {
+		case models.SubscriptionPending:
+			start = now
+		case models.SubscriptionPastDue:
+			// Periode baru dimulai tepat saat periode lama habis
+			start = dbtime.WallClock(end, s.location)
+		default:
+			// Uang masuk tapi tidak ada yang bisa diaktifkan, perlu dicek manual
+			return s.flag(ctx, tx, p.ID, now, fmt.Sprintf("Dibayar saat langganan berstatus %s", current))
+		}
+		next := plan.PeriodEnd(start)
+		_, err = tx.ExecContext(ctx, `
+			UPDATE subscriptions SET status='active', current_period_start=$2, current_period_end=$3, updated_at=$4
+			WHERE id=$1`,
+			p.ReferenceID, dbtime.Format(start), dbtime.Format(next), ts,
+		)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO subscription_periods (subscription_id, payment_id, period_start, period_end, amount, created_at)
+			VALUES ($1,$2,$3,$4,$5,$6)`,
+			p.ReferenceID, p.ID, dbtime.Format(start), dbtime.Format(next), p.Amount, ts,
+		)
+	case StatusRefunded:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE subscriptions s SET status='expired', expired_at=$3, updated_at=$3
+			WHERE s.id=$1 AND s.status <> 'expired' AND EXISTS (
+				SELECT 1 FROM subscription_periods sp
+				WHERE sp.subscription_id = s.id AND sp.payment_id = $2 AND sp.period_end = s.current_period_end
+			)`,
+			p.ReferenceID, p.ID, ts,
+		)
+	default:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE subscriptions SET status='expired', expired_at=current_period_end, updated_at=$2
+			WHERE id=$1 AND status='past_due'`,
+			p.ReferenceID, ts,
+		)
+	}
+	return err
+}
+
+// =========================
+// Refund pembayaran
+// =========================
+// Pembayaran ditandai refunding di bawah lock sebelum gateway dipanggil,
+// supaya dua request refund bersamaan tidak sama-sama lolos.
+func (s *Service) Refund(ctx context.Context, id int) (*Payment, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	p, err := s.scanPayment(tx.QueryRowContext(ctx, "SELECT "+paymentColumns+" FROM payments WHERE id=$1 FOR UPDATE", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrPaymentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != StatusPaid {
+		return nil, ErrNotRefundable
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE payments SET status='refunding', updated_at=$2 WHERE id=$1",
+		id, dbtime.Format(time.Now().In(s.location)))
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if err := s.provider.Refund(ctx, p.ProviderRef, p.Amount); err != nil {
+		// Kembalikan ke paid supaya refund bisa dicoba lagi
+		if _, rerr := s.db.ExecContext(context.WithoutCancel(ctx),
+			"UPDATE payments SET status='paid' WHERE id=$1 AND status='refunding'", id); rerr != nil {
+			slog.Error("Payment refund rollback error", "payment_id", id, "err", rerr)
+		}
+		return nil, err
+	}
+
+	tx, err = s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Webhook refund bisa saja sudah diproses lebih dulu
+	p, err = s.scanPayment(tx.QueryRowContext(ctx, "SELECT "+paymentColumns+" FROM payments WHERE id=$1 FOR UPDATE", id))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyStatus(ctx, tx, p, StatusRefunded, time.Now().In(s.location)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.GetByID(ctx, id)
+}
+
+// =========================
+// Pesanan dibatalkan
+// =========================
+// CancelForOrder dipanggil setelah pesanan dibatalkan: tagihan pending
+// dibatalkan di gateway dan pembayaran yang sudah lunas di-refund.
+// Pembayaran yang gagal dibatalkan atau di-refund ditandai untuk dicek
+// manual; error hanya dikembalikan jika penandaan itu pun gagal.
+func (s *Service) CancelForOrder(ctx context.Context, orderID int) error {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+paymentColumns+`
+		FROM payments WHERE reference_type=$1 AND reference_id=$2 AND status IN ('pending', 'paid')
+		ORDER BY id`, ReferenceOrder, orderID)
+	if err != nil {
+		return err
+	}
+	var list []*Payment
+	for rows.Next() {
+		p, err := s.scanPayment(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		list = append(list, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, p := range list {
+		var failure error
+		if p.Status == StatusPaid {
+			if _, err := s.Refund(ctx, p.ID); err != nil && err != ErrNotRefundable {
+				failure = fmt.Errorf("Refund otomatis gagal: %w", err)
+			}
+		} else if err := s.cancelPending(ctx, p); err != nil {
+			failure = fmt.Errorf("Pembatalan tagihan gagal: %w", err)
+		}
+		if failure == nil {
+			continue
+		}
+		slog.WarnContext(ctx, "Payment cancel error", "payment_id", p.ID, "order_id", orderID, "err", failure)
+		if err := s.flagPayment(ctx, p.ID, failure.Error()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cancelPending membatalkan tagihan pending di gateway lalu mencatatnya
+// sebagai expired
+func (s *Service) cancelPending(ctx context.Context, p *Payment) error {
+	if p.ProviderRef != "" {
+		if err := s.provider.Cancel(ctx, p.ProviderRef); err != nil && err != ErrChargeNotFound {
+			return err
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	p, err = s.scanPayment(tx.QueryRowContext(ctx, "SELECT "+paymentColumns+" FROM payments WHERE id=$1 FOR UPDATE", p.ID))
+	if err != nil {
+		return err
+	}
+	if p.Status != StatusPending {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE payments SET failure_reason='Pesanan dibatalkan' WHERE id=$1", p.ID); err != nil {
+		return err
+	}
+	if err := s.applyStatus(ctx, tx, p, StatusExpired, time.Now().In(s.location)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// flagPayment menandai pembayaran di luar transaksi lain
+func (s *Service) flagPayment(ctx context.Context, id int, reason string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := s.flag(ctx, tx, id, time.Now().In(s.location), reason); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReconcileResult adalah ringkasan satu putaran rekonsiliasi
+type ReconcileResult struct {
+	Checked int
+	Updated int
+	Flagged int
+}
+
+// =========================
+// Rekonsiliasi pembayaran pending
+// =========================
+// Pembayaran yang masih pending lebih lama dari stuckAfter dicek ulang
+// ke gateway. Jika gateway sudah punya status akhir (webhook hilang),
+// status disinkronkan; jika tidak, pembayaran ditandai (flagged_at)
+// untuk dicek manual.
+func (s *Service) Reconcile(ctx context.Context, stuckAfter time.Duration) (ReconcileResult, error) {
+	var result ReconcileResult
+	now := time.Now().In(s.location)
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+paymentColumns+`
+		FROM payments WHERE provider=$1 AND status='pending' AND created_at < $2
+		ORDER BY id`,
+		s.provider.Name(), dbtime.Format(now.Add(-stuckAfter)))
+	if err != nil {
+		return result, err
+	}
+	var pending []*Payment
+	for rows.Next() {
+		p, err := s.scanPayment(rows)
+		if err != nil {
+			rows.Close()
+			return result, err
+		}
+		pending = append(pending, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+
+	for _, p := range pending {
+		result.Checked++
+
+		status := StatusPending
+		if p.ProviderRef != "" {
+			if status, err = s.provider.GetStatus(ctx, p.ProviderRef); err != nil && err != ErrChargeNotFound {
+				return result, err
+			}
+		}
+
+		if status != StatusPending && status != "" {
+			updated, err := s.syncStatus(ctx, p.ID, status)
+			if err != nil {
+				return result, err
+			}
+			if updated {
+				result.Updated++
+			}
+			continue
+		}
+
+		res, err := s.db.ExecContext(ctx, "UPDATE payments SET flagged_at=$2 WHERE id=$1 AND flagged_at IS NULL",
+			p.ID, dbtime.Format(now))
+		if err != nil {
+			return result, err
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			result.Flagged++
+			slog.Warn("Payment masih pending, ditandai untuk dicek", "payment_id", p.ID,
+				"provider_ref", p.ProviderRef, "created_at", p.CreatedAt.Format("2006-01-02 15:04"))
+		}
+	}
+	return result, nil
+}
+
+func (s *Service) syncStatus(ctx context.Context, id int, status Status) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	p, err := s.scanPayment(tx.QueryRowContext(ctx, "SELECT "+paymentColumns+" FROM payments WHERE id=$1 FOR UPDATE", id))
+	if err != nil {
+		return false, err
+	}
+	before := p.Status
+	if err := s.applyStatus(ctx, tx, p, status, time.Now().In(s.location)); err != nil {
+		return false, err
+	}
+	return p.Status != before, tx.Commit()
+}
+
+// RunReconcile menjalankan satu putaran Reconcile dan mencatat hasilnya.
+// Dijadwalkan setiap ReconcileInterval oleh supervisor di main.
+func (s *Service) RunReconcile(ctx context.Context, stuckAfter time.Duration) error {
+	result, err := s.Reconcile(ctx, stuckAfter)
+	if err != nil {
+		return err
+	}
+	if result.Updated > 0 || result.Flagged > 0 {
+		slog.Info("Payment reconcile", "checked", result.Checked,
+			"updated", result.Updated, "flagged", result.Flagged)
+	}
+	return nil
+}
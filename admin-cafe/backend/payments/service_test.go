@@ -0,0 +1,270 @@
+package payments_test
+
+import (
+	"backend/models"
+	"backend/payments"
+	"backend/pgtest"
+	"backend/repository"
+	"backend/repository/storetest"
+	"context"
+	"database/sql"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) { os.Exit(pgtest.Main(m)) }
+
+var loc = time.FixedZone("WIB", 7*60*60)
+
+// createOrder membuat pesanan belum dibayar berisi satu menu
+func createOrder(t *testing.T, db *sql.DB) *models.Order {
+	t.Helper()
+	cafeID := pgtest.CreateCafe(t, db, "kopikita")
+	customerID := pgtest.CreateCustomer(t, db, "budi")
+	menuID := pgtest.CreateMenu(t, db, cafeID, "Latte", 25000)
+	order, err := repository.NewOrderRepository(db, loc).Create(context.Background(), models.Cart{
+		CafeID: cafeID, OrderType: models.OrderPickup, PickupName: "Budi",
+		Items: []models.CartItem{{MenuID: menuID, Quantity: 1}},
+	}, &customerID, customerID, time.Now())
+	if err != nil {
+		t.Fatalf("Create order: %v", err)
+	}
+	return order
+}
+
+func TestService(t *testing.T) {
+	db := pgtest.Open(t)
+	order := createOrder(t, db)
+
+	// Delay 0: mock tidak mengirim webhook sendiri, semua event dari test
+	provider := payments.NewMockProvider("secret", "", 0)
+	storetest.Payments(t, payments.NewService(db, provider, loc), order.ID)
+}
+
+// countingProvider menghitung panggilan Refund ke gateway
+type countingProvider struct {
+	*payments.MockProvider
+	refunds atomic.Int32
+}
+
+func (p *countingProvider) Refund(ctx context.Context, providerRef string, amount float64) error {
+	p.refunds.Add(1)
+	time.Sleep(20 * time.Millisecond)
+	return p.MockProvider.Refund(ctx, providerRef, amount)
+}
+
+// Refund paralel untuk pembayaran yang sama hanya sampai ke gateway sekali
+func TestRefundConcurrent(t *testing.T) {
+	ctx := context.Background()
+
+	db := pgtest.Open(t)
+	order := createOrder(t, db)
+	provider := &countingProvider{MockProvider: payments.NewMockProvider("secret", "", 0)}
+	service := payments.NewService(db, provider, loc)
+
+	p, err := service.CreateForOrder(ctx, order.ID, payments.MethodQRIS)
+	if err != nil {
+		t.Fatalf("CreateForOrder: %v", err)
+	}
+	if err := provider.Simulate(p.ProviderRef, payments.StatusPaid); err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+	if _, err := service.HandleWebhook(ctx, &payments.WebhookEvent{
+		EventID: "evt_1", ProviderRef: p.ProviderRef, Status: payments.StatusPaid, Amount: p.Amount,
+	}); err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+
+	const n = 5
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = service.Refund(ctx, p.ID)
+		}(i)
+	}
+	wg.Wait()
+
+	refunded := 0
+	for _, err := range errs {
+		switch err {
+		case nil:
+			refunded++
+		case payments.ErrNotRefundable:
+		default:
+			t.Fatalf("Refund: %v", err)
+		}
+	}
+	if refunded != 1 || provider.refunds.Load() != 1 {
+		t.Fatalf("refund berhasil = %d, panggilan gateway = %d, mau 1 & 1", refunded, provider.refunds.Load())
+	}
+	got, err := service.GetByID(ctx, p.ID)
+	if err != nil || got.Status != payments.StatusRefunded {
+		t.Fatalf("GetByID = %+v, %v", got, err)
+	}
+}
+
+// Langganan baru aktif setelah dibayar; perpanjangan ditagih dan
+// langganan berakhir jika tagihannya kedaluwarsa
+func TestSubscriptionPayments(t *testing.T) {
+	ctx := context.Background()
+
+	db := pgtest.Open(t)
+	userID := pgtest.CreateCustomer(t, db, "budi")
+	service := payments.NewService(db, payments.NewMockProvider("secret", "", 0), loc)
+	subs := repository.NewSubscriptionRepository(db, loc, service)
+
+	premium := func(now time.Time) bool {
+		t.Helper()
+		ok, err := subs.IsPremium(ctx, userID, now)
+		if err != nil {
+			t.Fatalf("IsPremium: %v", err)
+		}
+		return ok
+	}
+	pay := func(p *payments.Payment, eventID string, status payments.Status) {
+		t.Helper()
+		if _, err := service.HandleWebhook(ctx, &payments.WebhookEvent{
+			EventID: eventID, ProviderRef: p.ProviderRef, Status: status, Amount: p.Amount,
+		}); err != nil {
+			t.Fatalf("HandleWebhook: %v", err)
+		}
+	}
+
+	sub, err := subs.Start(ctx, userID, "premium_monthly", time.Now())
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if sub.Status != models.SubscriptionPending || premium(time.Now()) {
+		t.Fatalf("Start = %+v, mau pending tanpa akses premium", sub)
+	}
+	p, err := service.CreateForSubscription(ctx, sub.ID, payments.MethodQRIS)
+	if err != nil {
+		t.Fatalf("CreateForSubscription: %v", err)
+	}
+	if p.Amount != sub.Plan.Price {
+		t.Fatalf("CreateForSubscription Amount = %v, mau %v", p.Amount, sub.Plan.Price)
+	}
+	pay(p, "evt_1", payments.StatusPaid)
+
+	sub, err = subs.GetByID(ctx, sub.ID)
+	if err != nil || sub.Status != models.SubscriptionActive || !premium(time.Now()) {
+		t.Fatalf("setelah bayar = %+v, %v", sub, err)
+	}
+	if _, err := service.CreateForSubscription(ctx, sub.ID, payments.MethodQRIS); err != payments.ErrSubscriptionNotPayable {
+		t.Fatalf("CreateForSubscription aktif: err = %v, mau ErrSubscriptionNotPayable", err)
+	}
+
+	// Periode habis: ditagih, bukan diperpanjang gratis
+	due := sub.CurrentPeriodEnd.Add(time.Minute)
+	result, err := subs.ProcessDue(ctx, due)
+	if err != nil || result.Charged != 1 || result.Renewed != 0 {
+		t.Fatalf("ProcessDue = %+v, %v", result, err)
+	}
+	sub, err = subs.GetByID(ctx, sub.ID)
+	if err != nil || sub.Status != models.SubscriptionPastDue || premium(due) {
+		t.Fatalf("setelah jatuh tempo = %+v, %v", sub, err)
+	}
+
+	var renewalID int
+	if err := db.QueryRow("SELECT id FROM payments WHERE reference_type='subscription' AND status='pending'").Scan(&renewalID); err != nil {
+		t.Fatalf("tagihan perpanjangan: %v", err)
+	}
+	renewal, err := service.GetByID(ctx, renewalID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	pay(renewal, "evt_2", payments.StatusExpired)
+
+	sub, err = subs.GetByID(ctx, sub.ID)
+	if err != nil || sub.Status != models.SubscriptionExpired || premium(due) {
+		t.Fatalf("setelah tagihan kedaluwarsa = %+v, %v", sub, err)
+	}
+}
+
+// Pesanan batal: tagihan pending dibatalkan di gateway, pembayaran lunas
+// di-refund, dan pembayaran yang masuk setelah batal ditandai
+func TestCancelForOrder(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*sql.DB, *payments.MockProvider, *payments.Service, *models.Order, *payments.Payment) {
+		t.Helper()
+		db := pgtest.Open(t)
+		order := createOrder(t, db)
+		provider := payments.NewMockProvider("secret", "", 0)
+		service := payments.NewService(db, provider, loc)
+		p, err := service.CreateForOrder(ctx, order.ID, payments.MethodQRIS)
+		if err != nil {
+			t.Fatalf("CreateForOrder: %v", err)
+		}
+		if _, err := repository.NewOrderRepository(db, loc).UpdateStatus(ctx, order.ID, order.CafeID, "", models.OrderCancelled, "Stok habis", time.Now()); err != nil {
+			t.Fatalf("UpdateStatus: %v", err)
+		}
+		return db, provider, service, order, p
+	}
+	paymentStatus := func(t *testing.T, db *sql.DB, orderID int) string {
+		t.Helper()
+		var status string
+		if err := db.QueryRow("SELECT payment_status FROM orders WHERE id=$1", orderID).Scan(&status); err != nil {
+			t.Fatalf("payment_status: %v", err)
+		}
+		return status
+	}
+	pay := func(t *testing.T, service *payments.Service, p *payments.Payment) {
+		t.Helper()
+		if _, err := service.HandleWebhook(ctx, &payments.WebhookEvent{
+			EventID: "evt_paid", ProviderRef: p.ProviderRef, Status: payments.StatusPaid, Amount: p.Amount,
+		}); err != nil {
+			t.Fatalf("HandleWebhook: %v", err)
+		}
+	}
+
+	t.Run("tagihan pending", func(t *testing.T) {
+		db, provider, service, order, p := setup(t)
+		if err := service.CancelForOrder(ctx, order.ID); err != nil {
+			t.Fatalf("CancelForOrder: %v", err)
+		}
+		got, err := service.GetByID(ctx, p.ID)
+		if err != nil || got.Status != payments.StatusExpired || got.FlaggedAt != nil {
+			t.Fatalf("GetByID = %+v, %v", got, err)
+		}
+		if err := provider.Simulate(p.ProviderRef, payments.StatusPaid); err == nil {
+			t.Fatal("Simulate: tagihan masih bisa dibayar di gateway")
+		}
+		if status := paymentStatus(t, db, order.ID); status != "unpaid" {
+			t.Fatalf("payment_status = %s, mau unpaid", status)
+		}
+	})
+
+	t.Run("sudah dibayar", func(t *testing.T) {
+		_, provider, service, order, p := setup(t)
+		if err := provider.Simulate(p.ProviderRef, payments.StatusPaid); err != nil {
+			t.Fatalf("Simulate: %v", err)
+		}
+		pay(t, service, p)
+		if err := service.CancelForOrder(ctx, order.ID); err != nil {
+			t.Fatalf("CancelForOrder: %v", err)
+		}
+		got, err := service.GetByID(ctx, p.ID)
+		if err != nil || got.Status != payments.StatusRefunded || got.RefundedAt == nil {
+			t.Fatalf("GetByID = %+v, %v", got, err)
+		}
+	})
+
+	t.Run("dibayar setelah batal", func(t *testing.T) {
+		db, _, service, order, p := setup(t)
+		pay(t, service, p)
+		got, err := service.GetByID(ctx, p.ID)
+		if err != nil || got.FlaggedAt == nil {
+			t.Fatalf("GetByID = %+v, %v, mau ditandai", got, err)
+		}
+		if status := paymentStatus(t, db, order.ID); status == "paid" {
+			t.Fatal("payment_status pesanan batal menjadi paid")
+		}
+	})
+}
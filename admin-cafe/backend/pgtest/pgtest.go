@@ -0,0 +1,270 @@
+// Package pgtest menyiapkan database PostgreSQL sementara untuk test
+// integrasi implementasi SQL.
+//
+// Server diambil dari TEST_DATABASE_URL (koneksi yang boleh CREATE
+// DATABASE), atau dijalankan sendiri dari initdb & pg_ctl di PATH
+// (atau di folder PG_BIN) dengan data directory sementara. Jika keduanya
+// tidak ada, test di-skip.
+//
+// Setiap Open membuat database baru berisi schema dari config.Migrate dan
+// menghapusnya setelah test selesai. Migrate memakai config.DB, jadi test
+// yang memanggil Open tidak boleh memakai t.Parallel.
+//
+// Package test yang memakai Open harus memanggil Main dari TestMain
+// supaya server yang dijalankan sendiri ikut dimatikan:
+//
+//	func TestMain(m *testing.M) { os.Exit(pgtest.Main(m)) }
+package pgtest
+
+import (
+	"backend/config"
+	"database/sql"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	startOnce sync.Once
+	serverDSN string
+	skipMsg   string
+	cluster   string // data directory server sementara, kosong jika memakai TEST_DATABASE_URL
+	dbSeq     int64
+)
+
+// Main menjalankan test lalu mematikan server sementara (jika ada)
+func Main(m *testing.M) int {
+	code := m.Run()
+	if cluster != "" {
+		stop()
+	}
+	return code
+}
+
+// Open membuat database kosong dengan schema lengkap untuk satu test
+func Open(t *testing.T) *sql.DB {
+	t.Helper()
+	startOnce.Do(start)
+	if serverDSN == "" {
+		t.Skip(skipMsg)
+	}
+
+	admin, err := sql.Open("postgres", serverDSN)
+	if err != nil {
+		t.Fatalf("open admin connection: %v", err)
+	}
+	defer admin.Close()
+
+	name := fmt.Sprintf("pgtest_%d_%d", os.Getpid(), atomic.AddInt64(&dbSeq, 1))
+	if _, err := admin.Exec("CREATE DATABASE " + pq.QuoteIdentifier(name)); err != nil {
+		t.Fatalf("create database %s: %v", name, err)
+	}
+
+	db, err := sql.Open("postgres", withDatabase(serverDSN, name))
+	if err != nil {
+		t.Fatalf("open %s: %v", name, err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		admin, err := sql.Open("postgres", serverDSN)
+		if err != nil {
+			t.Logf("drop database %s: %v", name, err)
+			return
+		}
+		defer admin.Close()
+		if _, err := admin.Exec("DROP DATABASE IF EXISTS " + pq.QuoteIdentifier(name)); err != nil {
+			t.Logf("drop database %s: %v", name, err)
+		}
+	})
+
+	prev := config.DB
+	config.DB = db
+	t.Cleanup(func() { config.DB = prev })
+	config.Migrate()
+	return db
+}
+
+// withDatabase mengganti nama database di DSN (URL maupun key=value)
+func withDatabase(dsn, name string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		converted, err := pq.ParseURL(dsn)
+		if err == nil {
+			dsn = converted
+		}
+	}
+	return dsn + " dbname=" + name
+}
+
+// =========================
+// Server sementara
+// =========================
+func start() {
+	if dsn := os.Getenv("TEST_DATABASE_URL"); dsn != "" {
+		serverDSN = dsn
+		return
+	}
+
+	initdb, pgCtl := findBinary("initdb"), findBinary("pg_ctl")
+	if initdb == "" || pgCtl == "" {
+		skipMsg = "PostgreSQL tidak tersedia: set TEST_DATABASE_URL atau pasang initdb & pg_ctl"
+		return
+	}
+	// initdb menolak berjalan sebagai root
+	if os.Geteuid() == 0 {
+		skipMsg = "initdb tidak bisa dijalankan sebagai root: set TEST_DATABASE_URL"
+		return
+	}
+
+	dir, err := os.MkdirTemp("", "pgtest")
+	if err != nil {
+		skipMsg = fmt.Sprintf("buat folder sementara: %v", err)
+		return
+	}
+	data := filepath.Join(dir, "data")
+	out, err := exec.Command(initdb, "-D", data, "-U", "postgres", "-A", "trust", "-E", "UTF8", "--no-sync").CombinedOutput()
+	if err != nil {
+		os.RemoveAll(dir)
+		skipMsg = fmt.Sprintf("initdb gagal: %v\n%s", err, out)
+		return
+	}
+
+	port, err := freePort()
+	if err != nil {
+		os.RemoveAll(dir)
+		skipMsg = fmt.Sprintf("cari port kosong: %v", err)
+		return
+	}
+	// Hanya unix socket di folder sementara; fsync dimatikan karena data
+	// dibuang setelah test
+	opts := fmt.Sprintf("-k %s -c listen_addresses='' -p %d -F", dir, port)
+	out, err = exec.Command(pgCtl, "-D", data, "-o", opts, "-l", filepath.Join(dir, "server.log"), "-w", "-t", "30", "start").CombinedOutput()
+	if err != nil {
+		os.RemoveAll(dir)
+		skipMsg = fmt.Sprintf("pg_ctl start gagal: %v\n%s", err, out)
+		return
+	}
+
+	cluster = dir
+	serverDSN = fmt.Sprintf("host=%s port=%d user=postgres sslmode=disable", dir, port)
+
+	// Tunggu sampai server menerima koneksi
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		db, err := sql.Open("postgres", serverDSN+" dbname=postgres")
+		if err == nil {
+			err = db.Ping()
+			db.Close()
+		}
+		if err == nil || time.Now().After(deadline) {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func stop() {
+	if pgCtl := findBinary("pg_ctl"); pgCtl != "" {
+		exec.Command(pgCtl, "-D", filepath.Join(cluster, "data"), "-m", "immediate", "-w", "stop").Run()
+	}
+	os.RemoveAll(cluster)
+}
+
+// findBinary mencari program PostgreSQL di PG_BIN lalu di PATH
+func findBinary(name string) string {
+	if dir := os.Getenv("PG_BIN"); dir != "" {
+		p := filepath.Join(dir, name)
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	p, err := exec.LookPath(name)
+	if err != nil {
+		return ""
+	}
+	return p
+}
+
+// freePort meminjam port TCP kosong; nomor port juga dipakai sebagai
+// nama file socket
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// =========================
+// Data awal
+// =========================
+
+// CreateCafe membuat akun cafe terverifikasi beserta profilnya dan
+// mengembalikan ID profil cafe
+func CreateCafe(t *testing.T, db *sql.DB, username string) int {
+	t.Helper()
+	var id int
+	err := db.QueryRow(`
+		WITH u AS (
+			INSERT INTO users (username, password, role, verified) VALUES ($1, 'x', 'cafe', true) RETURNING id
+		)
+		INSERT INTO cafe_profiles (user_id, nama, alamat, verified) SELECT id, $1, 'Jl. Test', true FROM u
+		RETURNING id`, username,
+	).Scan(&id)
+	if err != nil {
+		t.Fatalf("create cafe %s: %v", username, err)
+	}
+	return id
+}
+
+// CreateCustomer membuat akun customer dan mengembalikan ID-nya
+func CreateCustomer(t *testing.T, db *sql.DB, username string) int {
+	t.Helper()
+	var id int
+	err := db.QueryRow(
+		"INSERT INTO users (username, password, email, role, verified, email_verified) VALUES ($1, 'x', $1 || '@mail.com', 'customer', true, true) RETURNING id",
+		username,
+	).Scan(&id)
+	if err != nil {
+		t.Fatalf("create customer %s: %v", username, err)
+	}
+	return id
+}
+
+// CreateAdmin membuat akun super admin dan mengembalikan ID-nya
+func CreateAdmin(t *testing.T, db *sql.DB, username string) int {
+	t.Helper()
+	var id int
+	err := db.QueryRow(
+		"INSERT INTO users (username, password, role, verified) VALUES ($1, 'x', 'admin', true) RETURNING id",
+		username,
+	).Scan(&id)
+	if err != nil {
+		t.Fatalf("create admin %s: %v", username, err)
+	}
+	return id
+}
+
+// CreateMenu membuat menu aktif di cafe dan mengembalikan ID-nya
+func CreateMenu(t *testing.T, db *sql.DB, cafeID int, name string, price float64) string {
+	t.Helper()
+	var id string
+	err := db.QueryRow(`
+		INSERT INTO menus (cafe_profile_id, name, price, discounted_price, category, status)
+		VALUES ($1, $2, $3, $3, 'Kopi', 'Aktif') RETURNING id::text`,
+		cafeID, name, price,
+	).Scan(&id)
+	if err != nil {
+		t.Fatalf("create menu %s: %v", name, err)
+	}
+	return id
+}
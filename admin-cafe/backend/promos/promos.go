@@ -0,0 +1,157 @@
+// Package promos menyajikan promo cafe. Promo adalah menu yang punya
+// diskon; tidak ada tabel promo tersendiri. Perubahan diskon tetap lewat
+// antrean persetujuan di handler permintaan perubahan, dan harga promo
+// dihitung saat checkout sesuai start_date/end_date.
+package promos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Status promo relatif terhadap tanggal hari ini
+const (
+	StatusActive    = "active"
+	StatusScheduled = "scheduled"
+	StatusEnded     = "ended"
+)
+
+var ErrNotFound = errors.New("promo tidak ditemukan")
+
+// Promo adalah menu yang sedang/akan didiskon. Field JSON mengikuti
+// halaman Promo di frontend.
+type Promo struct {
+	MenuID          string  `json:"id"`
+	Name            string  `json:"name"`
+	Price           float64 `json:"price"`
+	Discount        float64 `json:"discount"`
+	DiscountedPrice float64 `json:"discountedPrice"`
+	StartDate       string  `json:"startDate"`
+	EndDate         string  `json:"endDate"`
+	Category        string  `json:"category"`
+	Img             string  `json:"img"`
+	MenuStatus      string  `json:"menuStatus"`
+	PremiumOnly     bool    `json:"premiumOnly"`
+	Status          string  `json:"status"`
+}
+
+// Stats adalah ringkasan promo satu cafe
+type Stats struct {
+	Active          int     `json:"active"`
+	Scheduled       int     `json:"scheduled"`
+	Ended           int     `json:"ended"`
+	AverageDiscount float64 `json:"average_discount"`
+	MaxDiscount     float64 `json:"max_discount"`
+}
+
+// Store membaca promo; diimplementasikan Service (PostgreSQL)
+type Store interface {
+	List(ctx context.Context, cafeID int, includeEnded bool, now time.Time) ([]Promo, error)
+	Get(ctx context.Context, cafeID int, menuID string, now time.Time) (*Promo, error)
+	Stats(ctx context.Context, cafeID int, now time.Time) (*Stats, error)
+}
+
+var _ Store = (*Service)(nil)
+
+type Service struct {
+	db       *sql.DB
+	location *time.Location
+}
+
+func NewService(db *sql.DB, location *time.Location) *Service {
+	return &Service{db: db, location: location}
+}
+
+const promoQuery = `
+	SELECT id::text, name, price::float8, discount::float8, COALESCE(discounted_price, 0)::float8,
+		COALESCE(to_char(start_date, 'YYYY-MM-DD'), ''), COALESCE(to_char(end_date, 'YYYY-MM-DD'), ''),
+		category, COALESCE(img, ''), COALESCE(status, ''), premium_only,
+		CASE
+			WHEN end_date IS NOT NULL AND end_date < $2::date THEN 'ended'
+			WHEN start_date IS NOT NULL AND start_date > $2::date THEN 'scheduled'
+			ELSE 'active'
+		END
+	FROM menus
+	WHERE cafe_profile_id=$1 AND COALESCE(discount, 0) > 0`
+
+func scanPromo(row interface{ Scan(...interface{}) error }) (*Promo, error) {
+	p := &Promo{}
+	err := row.Scan(&p.MenuID, &p.Name, &p.Price, &p.Discount, &p.DiscountedPrice,
+		&p.StartDate, &p.EndDate, &p.Category, &p.Img, &p.MenuStatus, &p.PremiumOnly, &p.Status)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// =========================
+// Daftar promo satu cafe
+// =========================
+// Promo yang sudah berakhir hanya ikut jika includeEnded.
+func (s *Service) List(ctx context.Context, cafeID int, includeEnded bool, now time.Time) ([]Promo, error) {
+	rows, err := s.db.QueryContext(ctx, promoQuery+`
+		AND ($3 OR end_date IS NULL OR end_date >= $2::date)
+		ORDER BY COALESCE(start_date, '-infinity'::date) DESC, name`,
+		cafeID, s.today(now), includeEnded,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Promo{}
+	for rows.Next() {
+		p, err := scanPromo(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *p)
+	}
+	return list, rows.Err()
+}
+
+// =========================
+// Detail promo satu menu
+// =========================
+// ErrNotFound jika menu tidak ada atau tidak punya diskon.
+func (s *Service) Get(ctx context.Context, cafeID int, menuID string, now time.Time) (*Promo, error) {
+	p, err := scanPromo(s.db.QueryRowContext(ctx, promoQuery+" AND id=$3", cafeID, s.today(now), menuID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return p, err
+}
+
+// =========================
+// Statistik promo
+// =========================
+// Rata-rata & diskon terbesar hanya dari promo yang sedang aktif.
+func (s *Service) Stats(ctx context.Context, cafeID int, now time.Time) (*Stats, error) {
+	st := &Stats{}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE active),
+			COUNT(*) FILTER (WHERE start_date > $2::date),
+			COUNT(*) FILTER (WHERE end_date < $2::date),
+			COALESCE(ROUND(AVG(discount) FILTER (WHERE active), 1), 0)::float8,
+			COALESCE(MAX(discount) FILTER (WHERE active), 0)::float8
+		FROM (
+			SELECT discount, start_date, end_date,
+				(start_date IS NULL OR start_date <= $2::date) AND (end_date IS NULL OR end_date >= $2::date) AS active
+			FROM menus
+			WHERE cafe_profile_id=$1 AND COALESCE(discount, 0) > 0
+		) p`,
+		cafeID, s.today(now),
+	).Scan(&st.Active, &st.Scheduled, &st.Ended, &st.AverageDiscount, &st.MaxDiscount)
+	if err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// today adalah tanggal lokal cafe, sama seperti saat harga dihitung
+func (s *Service) today(now time.Time) string {
+	return now.In(s.location).Format("2006-01-02")
+}
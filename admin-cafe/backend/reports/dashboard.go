@@ -0,0 +1,371 @@
+package reports
+
+import (
+	"backend/dbtime"
+	"context"
+	"sync"
+	"time"
+)
+
+// Jumlah hari maksimal untuk grafik dashboard super admin
+const MaxDashboardDays = 90
+
+// DashboardStats adalah ringkasan platform untuk dashboard super admin
+type DashboardStats struct {
+	GeneratedAt   time.Time     `json:"generated_at"`
+	Days          int           `json:"days"`
+	Accounts      AccountStats  `json:"accounts"`
+	Registrations Registrations `json:"registrations"`
+	Signups       []SignupPoint `json:"signups"`
+	ActiveAdmins  int           `json:"active_admins"`
+	Cafes         CafeStats     `json:"cafes"`
+	Events        EventStats    `json:"events"`
+	Visitors      VisitorStats  `json:"visitors"`
+}
+
+// AccountStats adalah jumlah akun per role
+type AccountStats struct {
+	Total  int            `json:"total"`
+	ByRole map[string]int `json:"by_role"`
+}
+
+// Registrations adalah jumlah akun per status pendaftaran: cafe menunggu
+// verifikasi admin, customer menunggu verifikasi email
+type Registrations struct {
+	Cafe     RegistrationCount `json:"cafe"`
+	Customer RegistrationCount `json:"customer"`
+}
+
+type RegistrationCount struct {
+	Pending  int `json:"pending"`
+	Verified int `json:"verified"`
+}
+
+// SignupPoint adalah pendaftaran akun baru dalam satu hari
+type SignupPoint struct {
+	Date     string `json:"date"`
+	Total    int    `json:"total"`
+	Cafe     int    `json:"cafe"`
+	Customer int    `json:"customer"`
+	Admin    int    `json:"admin"`
+}
+
+// CafeStats: cafe aktif adalah cafe terverifikasi yang menerima pesanan
+// dalam rentang hari dashboard
+type CafeStats struct {
+	Verified int `json:"verified"`
+	Active   int `json:"active"`
+}
+
+// EventStats bernilai null selama backend belum punya modul event &
+// akun event organizer
+type EventStats struct {
+	Organizers *int `json:"organizers"`
+	Running    *int `json:"running"`
+}
+
+// VisitorStats membagi customer yang memesan menjadi baru (pesanan
+// pertama), loyal (pernah memesan sebelumnya) dan premium (langganan
+// aktif saat memesan)
+type VisitorStats struct {
+	New     int            `json:"new"`
+	Loyal   int            `json:"loyal"`
+	Premium int            `json:"premium"`
+	Series  []VisitorPoint `json:"series"`
+}
+
+type VisitorPoint struct {
+	Date    string `json:"date"`
+	New     int    `json:"new"`
+	Loyal   int    `json:"loyal"`
+	Premium int    `json:"premium"`
+}
+
+// Segmen pengunjung
+const (
+	segmentNew     = "new"
+	segmentLoyal   = "loyal"
+	segmentPremium = "premium"
+)
+
+// =========================
+// Statistik dashboard
+// =========================
+// days adalah jumlah hari terakhir (termasuk hari ini) untuk grafik.
+func (s *Service) Dashboard(ctx context.Context, days int, now time.Time) (*DashboardStats, error) {
+	now = now.In(s.location)
+	end := truncateDay(now).AddDate(0, 0, 1)
+	start := end.AddDate(0, 0, -days)
+
+	stats := &DashboardStats{
+		GeneratedAt: now,
+		Days:        days,
+		Accounts:    AccountStats{ByRole: map[string]int{}},
+	}
+
+	// Akun per role & status pendaftaran
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT role, COUNT(*),
+			COUNT(*) FILTER (WHERE CASE role
+				WHEN 'cafe' THEN COALESCE(verified, false)
+				WHEN 'customer' THEN COALESCE(email_verified, false)
+				ELSE true END)
+		FROM users
+		WHERE deleted_at IS NULL
+		GROUP BY role`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var role string
+		var total, verified int
+		if err := rows.Scan(&role, &total, &verified); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		stats.Accounts.ByRole[role] = total
+		stats.Accounts.Total += total
+		switch role {
+		case "cafe":
+			stats.Registrations.Cafe = RegistrationCount{Pending: total - verified, Verified: verified}
+		case "customer":
+			stats.Registrations.Customer = RegistrationCount{Pending: total - verified, Verified: verified}
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Admin yang punya session login yang masih berlaku
+	err = s.db.QueryRowContext(ctx, `
+		SELECT COUNT(DISTINCT s.user_id)
+		FROM sessions s
+		JOIN users u ON u.id = s.user_id
+		WHERE u.role='admin' AND u.suspended_at IS NULL AND u.deleted_at IS NULL
+			AND s.revoked_at IS NULL AND s.expires_at > $1`,
+		dbtime.Format(now),
+	).Scan(&stats.ActiveAdmins)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cafe terverifikasi & yang menerima pesanan dalam rentang
+	err = s.db.QueryRowContext(ctx, `
+		SELECT COUNT(DISTINCT c.id),
+			COUNT(DISTINCT c.id) FILTER (WHERE EXISTS (
+				SELECT 1 FROM orders o
+				WHERE o.cafe_profile_id = c.id AND o.status <> 'cancelled'
+					AND o.created_at >= $1 AND o.created_at < $2))
+		FROM cafe_profiles c
+		JOIN users u ON u.id = c.user_id
+		WHERE u.role='cafe' AND u.verified = true AND u.deleted_at IS NULL`,
+		dbtime.Format(start), dbtime.Format(end),
+	).Scan(&stats.Cafes.Verified, &stats.Cafes.Active)
+	if err != nil {
+		return nil, err
+	}
+
+	if stats.Signups, err = s.signups(ctx, start, end); err != nil {
+		return nil, err
+	}
+	if stats.Visitors, err = s.visitors(ctx, start, end); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// signups menghitung akun baru per hari (zero-filled)
+func (s *Service) signups(ctx context.Context, start, end time.Time) ([]SignupPoint, error) {
+	points := []SignupPoint{}
+	index := map[string]int{}
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		key := d.Format("2006-01-02")
+		index[key] = len(points)
+		points = append(points, SignupPoint{Date: key})
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT to_char(created_at, 'YYYY-MM-DD') AS day, role, COUNT(*)
+		FROM users
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY day, role`,
+		dbtime.Format(start), dbtime.Format(end),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var day, role string
+		var n int
+		if err := rows.Scan(&day, &role, &n); err != nil {
+			return nil, err
+		}
+		i, ok := index[day]
+		if !ok {
+			continue
+		}
+		p := &points[i]
+		p.Total += n
+		switch role {
+		case "cafe":
+			p.Cafe += n
+		case "customer":
+			p.Customer += n
+		case "admin":
+			p.Admin += n
+		}
+	}
+	return points, rows.Err()
+}
+
+// visitors menghitung customer yang memesan per hari per segmen. Total
+// per segmen menghitung setiap customer sekali: premium jika pernah
+// premium saat memesan, baru jika pesanan pertamanya ada di rentang ini,
+// selain itu loyal.
+func (s *Service) visitors(ctx context.Context, start, end time.Time) (VisitorStats, error) {
+	stats := VisitorStats{Series: []VisitorPoint{}}
+	index := map[string]int{}
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		key := d.Format("2006-01-02")
+		index[key] = len(stats.Series)
+		stats.Series = append(stats.Series, VisitorPoint{Date: key})
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		WITH visits AS (
+			SELECT DISTINCT o.customer_user_id AS user_id, date_trunc('day', o.created_at) AS day
+			FROM orders o
+			WHERE o.customer_user_id IS NOT NULL AND o.status <> 'cancelled'
+				AND o.created_at >= $1 AND o.created_at < $2
+		), firsts AS (
+			SELECT o.customer_user_id AS user_id, MIN(date_trunc('day', o.created_at)) AS first_day
+			FROM orders o
+			WHERE o.status <> 'cancelled' AND o.customer_user_id IN (SELECT user_id FROM visits)
+			GROUP BY o.customer_user_id
+		)
+		SELECT v.user_id, to_char(v.day, 'YYYY-MM-DD'),
+			CASE
+				WHEN EXISTS (
+					SELECT 1 FROM subscriptions s
+					WHERE s.user_id = v.user_id
+						AND s.current_period_start < v.day + INTERVAL '1 day'
+						AND s.current_period_end > v.day) THEN 'premium'
+				WHEN f.first_day = v.day THEN 'new'
+				ELSE 'loyal'
+			END
+		FROM visits v
+		JOIN firsts f ON f.user_id = v.user_id`,
+		dbtime.Format(start), dbtime.Format(end),
+	)
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+
+	// Segmen per customer untuk total: premium > baru > loyal
+	rank := map[string]int{segmentLoyal: 0, segmentNew: 1, segmentPremium: 2}
+	perUser := map[int]string{}
+	for rows.Next() {
+		var userID int
+		var day, segment string
+		if err := rows.Scan(&userID, &day, &segment); err != nil {
+			return stats, err
+		}
+		if i, ok := index[day]; ok {
+			switch segment {
+			case segmentPremium:
+				stats.Series[i].Premium++
+			case segmentNew:
+				stats.Series[i].New++
+			default:
+				stats.Series[i].Loyal++
+			}
+		}
+		if prev, ok := perUser[userID]; !ok || rank[segment] > rank[prev] {
+			perUser[userID] = segment
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return stats, err
+	}
+
+	for _, segment := range perUser {
+		switch segment {
+		case segmentPremium:
+			stats.Premium++
+		case segmentNew:
+			stats.New++
+		default:
+			stats.Loyal++
+		}
+	}
+	return stats, nil
+}
+
+// =========================
+// Cache statistik dashboard
+// =========================
+
+// DashboardCache menyimpan hasil Dashboard selama TTL supaya dashboard
+// yang sering di-refresh tidak menghitung ulang agregat ke database.
+// Perhitungan dikunci per rentang hari: request bersamaan saat cache
+// kedaluwarsa hanya menghasilkan satu perhitungan, dan cache yang masih
+// berlaku tetap dilayani tanpa menunggu perhitungan rentang lain.
+type DashboardCache struct {
+	service Store
+	ttl     time.Duration
+
+	mu      sync.Mutex // menjaga entries & dashboardEntry.stats
+	entries map[int]*dashboardEntry
+}
+
+type dashboardEntry struct {
+	compute sync.Mutex // dipegang selama menghitung ulang
+	stats   *DashboardStats
+}
+
+func NewDashboardCache(service Store, ttl time.Duration) *DashboardCache {
+	return &DashboardCache{service: service, ttl: ttl, entries: map[int]*dashboardEntry{}}
+}
+
+// Get mengembalikan statistik dari cache jika umurnya belum melewati TTL
+func (c *DashboardCache) Get(ctx context.Context, days int, now time.Time) (*DashboardStats, error) {
+	e, stats := c.lookup(days, now)
+	if stats != nil {
+		return stats, nil
+	}
+
+	e.compute.Lock()
+	defer e.compute.Unlock()
+	// Request lain mungkin sudah menghitung selama kita menunggu
+	if _, stats := c.lookup(days, now); stats != nil {
+		return stats, nil
+	}
+	stats, err := c.service.Dashboard(ctx, days, now)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	e.stats = stats
+	c.mu.Unlock()
+	return stats, nil
+}
+
+// lookup mengembalikan entry untuk days beserta statistiknya jika masih
+// berlaku
+func (c *DashboardCache) lookup(days int, now time.Time) (*dashboardEntry, *DashboardStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[days]
+	if !ok {
+		e = &dashboardEntry{}
+		c.entries[days] = e
+	}
+	if e.stats != nil && now.Sub(e.stats.GeneratedAt) < c.ttl {
+		return e, e.stats
+	}
+	return e, nil
+}
@@ -0,0 +1,66 @@
+package reports
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingStore menahan Dashboard untuk rentang slowDays sampai release
+// ditutup
+type blockingStore struct {
+	Store
+	slowDays int
+	release  chan struct{}
+	calls    atomic.Int32
+}
+
+func (s *blockingStore) Dashboard(ctx context.Context, days int, now time.Time) (*DashboardStats, error) {
+	s.calls.Add(1)
+	if days == s.slowDays {
+		<-s.release
+	}
+	return &DashboardStats{GeneratedAt: now, Days: days}, nil
+}
+
+func TestDashboardCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := &blockingStore{slowDays: 30, release: make(chan struct{})}
+	cache := NewDashboardCache(store, time.Minute)
+
+	if _, err := cache.Get(ctx, 7, now); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if stats, err := cache.Get(ctx, 30, now); err != nil || stats.Days != 30 {
+				t.Errorf("Get = %+v, %v", stats, err)
+			}
+		}()
+	}
+
+	// Cache rentang lain tetap dilayani selama perhitungan berjalan
+	done := make(chan struct{})
+	go func() {
+		cache.Get(ctx, 7, now)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Get untuk cache yang berlaku menunggu perhitungan lain")
+	}
+
+	close(store.release)
+	wg.Wait()
+	if n := store.calls.Load(); n != 2 {
+		t.Fatalf("Dashboard dipanggil %d kali, mau 2", n)
+	}
+}
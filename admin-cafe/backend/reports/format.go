@@ -0,0 +1,63 @@
+package reports
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+var namaBulan = []string{"", "Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+
+// FormatRupiah menulis nominal seperti "Rp 12.023.000"
+func FormatRupiah(v float64) string {
+	return "Rp " + formatThousands(int64(math.Round(v)))
+}
+
+func formatThousands(n int64) string {
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	s := fmt.Sprintf("%d", n)
+	var b strings.Builder
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(c)
+	}
+	return sign + b.String()
+}
+
+// formatTanggal menulis tanggal "2025-09-01" seperti "1 September 2025"
+func formatTanggal(date string) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return fmt.Sprintf("%d %s %d", t.Day(), namaBulan[t.Month()], t.Year())
+}
+
+// periodLabel menulis label periode sesuai granularity
+func periodLabel(date string, g Granularity) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	switch g {
+	case Week:
+		end := t.AddDate(0, 0, 6)
+		return fmt.Sprintf("%d %s - %s", t.Day(), namaBulan[t.Month()][:3], formatTanggal(end.Format("2006-01-02")))
+	case Month:
+		return fmt.Sprintf("%s %d", namaBulan[t.Month()], t.Year())
+	default:
+		return formatTanggal(date)
+	}
+}
+
+// ExportFilename membuat nama file seperti "laporan-2025-09-01_2025-09-10.pdf"
+func ExportFilename(r *SalesReport, ext string) string {
+	return fmt.Sprintf("laporan-%s_%s.%s", r.Start, r.End, ext)
+}
@@ -0,0 +1,398 @@
+package reports
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// Ukuran halaman A4 dalam point
+const (
+	pageWidth    = 595.0
+	pageHeight   = 842.0
+	marginX      = 40.0
+	marginTop    = 50.0
+	marginBottom = 60.0
+	rowHeight    = 18.0
+)
+
+// Lebar karakter Helvetica (per 1000 unit) untuk ASCII 32-126, dari AFM
+// standar. Dipakai untuk rata kanan & memotong teks yang terlalu panjang.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+type pdfColumn struct {
+	title string
+	width float64
+	right bool
+}
+
+// pdfDoc menyusun halaman-halaman PDF sederhana (teks, garis, kotak)
+// memakai font bawaan Helvetica sehingga tidak perlu menanam font.
+type pdfDoc struct {
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+	y     float64 // posisi baris berikutnya, dihitung dari atas
+}
+
+func (d *pdfDoc) newPage() {
+	d.page = &bytes.Buffer{}
+	d.pages = append(d.pages, d.page)
+	d.y = marginTop
+}
+
+// ensure membuat halaman baru jika sisa tinggi halaman kurang dari h
+func (d *pdfDoc) ensure(h float64) bool {
+	if d.y+h > pageHeight-marginBottom {
+		d.newPage()
+		return true
+	}
+	return false
+}
+
+func (d *pdfDoc) text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.page, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, pageHeight-y, pdfEscape(s))
+}
+
+func (d *pdfDoc) textRight(x, y, size float64, bold bool, s string) {
+	d.text(x-textWidth(s, size, bold), y, size, bold, s)
+}
+
+func (d *pdfDoc) line(x1, y1, x2, y2, width float64, gray float64) {
+	fmt.Fprintf(d.page, "%.2f G %.2f w %.2f %.2f m %.2f %.2f l S 0 G\n",
+		gray, width, x1, pageHeight-y1, x2, pageHeight-y2)
+}
+
+func (d *pdfDoc) fillRect(x, y, w, h float64, gray float64) {
+	fmt.Fprintf(d.page, "%.2f g %.2f %.2f %.2f %.2f re f 0 g\n", gray, x, pageHeight-y-h, w, h)
+}
+
+// =========================
+// Export PDF
+// =========================
+// WritePDF menulis laporan sebagai PDF A4: ringkasan, grafik pendapatan,
+// tabel per periode dan tabel per menu. Header tabel diulang di setiap
+// halaman baru.
+func WritePDF(w io.Writer, r *SalesReport, cafeName string) error {
+	d := &pdfDoc{}
+	d.newPage()
+
+	d.text(marginX, d.y, 18, true, "Laporan Penjualan")
+	d.y += 20
+	d.text(marginX, d.y, 11, false, cafeName)
+	d.y += 15
+	d.text(marginX, d.y, 10, false, "Periode: "+formatTanggal(r.Start)+" - "+formatTanggal(r.End))
+	d.y += 25
+
+	// Ringkasan dalam empat kotak
+	boxes := []struct{ label, value string }{
+		{"Total Penjualan", FormatRupiah(r.TotalRevenue)},
+		{"Total Pesanan", formatThousands(int64(r.TotalOrders))},
+		{"Rata-rata per Pesanan", FormatRupiah(r.AverageOrderValue)},
+		{"Rating", fmt.Sprintf("%.1f (%d ulasan)", r.AverageRating, r.TotalReviews)},
+	}
+	gap := 10.0
+	boxWidth := (pageWidth - 2*marginX - 3*gap) / 4
+	for i, b := range boxes {
+		x := marginX + float64(i)*(boxWidth+gap)
+		d.fillRect(x, d.y, boxWidth, 44, 0.93)
+		d.text(x+8, d.y+16, 8, false, b.label)
+		d.text(x+8, d.y+34, 11, true, fitText(b.value, boxWidth-16, 11, true))
+	}
+	d.y += 64
+
+	d.text(marginX, d.y, 12, true, "Grafik Pendapatan")
+	d.y += 10
+	d.revenueChart(r, 170)
+	d.y += 30
+
+	d.text(marginX, d.y, 12, true, "Penjualan per Periode")
+	d.y += 8
+	periodCols := []pdfColumn{
+		{"Periode", 215, false},
+		{"Pesanan", 80, true},
+		{"Item Terjual", 90, true},
+		{"Pendapatan", 130, true},
+	}
+	var rows [][]string
+	for _, p := range r.Series {
+		rows = append(rows, []string{
+			periodLabel(p.PeriodStart, r.Granularity),
+			formatThousands(int64(p.Orders)),
+			formatThousands(int64(p.ItemsSold)),
+			FormatRupiah(p.Revenue),
+		})
+	}
+	rows = append(rows, []string{"Total", formatThousands(int64(r.TotalOrders)),
+		formatThousands(int64(r.ItemsSold)), FormatRupiah(r.TotalRevenue)})
+	d.table(periodCols, rows, true)
+	d.y += 25
+
+	d.ensure(3 * rowHeight)
+	d.text(marginX, d.y, 12, true, "Penjualan per Menu")
+	d.y += 8
+	menuCols := []pdfColumn{
+		{"No", 35, true},
+		{"Menu", 260, false},
+		{"Terjual", 90, true},
+		{"Pendapatan", 130, true},
+	}
+	rows = nil
+	for i, m := range r.Menus {
+		rows = append(rows, []string{fmt.Sprint(i + 1), m.Name,
+			formatThousands(int64(m.Quantity)), FormatRupiah(m.Revenue)})
+	}
+	if len(rows) == 0 {
+		rows = append(rows, []string{"", "Belum ada penjualan", "", ""})
+	}
+	d.table(menuCols, rows, false)
+
+	// Nomor halaman baru diketahui setelah seluruh isi tersusun
+	for i, p := range d.pages {
+		d.page = p
+		d.line(marginX, pageHeight-40, pageWidth-marginX, pageHeight-40, 0.5, 0.7)
+		d.text(marginX, pageHeight-28, 8, false, fitText(cafeName+" - Laporan Penjualan", 380, 8, false))
+		d.textRight(pageWidth-marginX, pageHeight-28, 8, false, fmt.Sprintf("Halaman %d / %d", i+1, len(d.pages)))
+	}
+
+	return d.write(w)
+}
+
+// table menggambar tabel; jika lastBold, baris terakhir adalah total
+func (d *pdfDoc) table(cols []pdfColumn, rows [][]string, lastBold bool) {
+	header := func() {
+		d.fillRect(marginX, d.y, pageWidth-2*marginX, rowHeight, 0.85)
+		x := marginX
+		for _, c := range cols {
+			d.cellText(x, c, c.title, true)
+			x += c.width
+		}
+		d.y += rowHeight
+	}
+
+	d.ensure(2 * rowHeight)
+	header()
+	for i, row := range rows {
+		if d.ensure(rowHeight) {
+			header()
+		}
+		bold := lastBold && i == len(rows)-1
+		if bold {
+			d.line(marginX, d.y, pageWidth-marginX, d.y, 0.8, 0)
+		}
+		x := marginX
+		for j, c := range cols {
+			d.cellText(x, c, row[j], bold)
+			x += c.width
+		}
+		d.y += rowHeight
+		d.line(marginX, d.y, pageWidth-marginX, d.y, 0.3, 0.8)
+	}
+}
+
+func (d *pdfDoc) cellText(x float64, c pdfColumn, s string, bold bool) {
+	const size, pad = 9.0, 5.0
+	s = fitText(s, c.width-2*pad, size, bold)
+	baseline := d.y + rowHeight - 5.5
+	if c.right {
+		d.textRight(x+c.width-pad, baseline, size, bold, s)
+	} else {
+		d.text(x+pad, baseline, size, bold, s)
+	}
+}
+
+// revenueChart menggambar grafik garis pendapatan per periode
+func (d *pdfDoc) revenueChart(r *SalesReport, height float64) {
+	const axisLabelWidth = 70.0
+	left := marginX + axisLabelWidth
+	right := pageWidth - marginX
+	top := d.y + 10
+	bottom := top + height
+	d.y = bottom + 12
+
+	maxRevenue := 0.0
+	for _, p := range r.Series {
+		maxRevenue = math.Max(maxRevenue, p.Revenue)
+	}
+	scale := niceCeil(maxRevenue)
+
+	// Garis bantu & label sumbu Y
+	const ticks = 4
+	for i := 0; i <= ticks; i++ {
+		y := bottom - height*float64(i)/ticks
+		gray := 0.88
+		if i == 0 {
+			gray = 0.4
+		}
+		d.line(left, y, right, y, 0.5, gray)
+		d.textRight(left-6, y+3, 7, false, FormatRupiah(scale*float64(i)/ticks))
+	}
+
+	n := len(r.Series)
+	if n == 0 {
+		return
+	}
+	xAt := func(i int) float64 {
+		if n == 1 {
+			return (left + right) / 2
+		}
+		return left + (right-left)*float64(i)/float64(n-1)
+	}
+	yAt := func(v float64) float64 {
+		return bottom - height*v/scale
+	}
+
+	var path strings.Builder
+	for i, p := range r.Series {
+		op := "l"
+		if i == 0 {
+			op = "m"
+		}
+		fmt.Fprintf(&path, "%.2f %.2f %s ", xAt(i), pageHeight-yAt(p.Revenue), op)
+	}
+	fmt.Fprintf(d.page, "0.16 0.45 0.78 RG 1.5 w 1 j %sS 0 G\n", path.String())
+	if n <= 62 {
+		for i, p := range r.Series {
+			fmt.Fprintf(d.page, "0.16 0.45 0.78 rg %.2f %.2f 3 3 re f 0 g\n", xAt(i)-1.5, pageHeight-yAt(p.Revenue)-1.5)
+		}
+	}
+
+	// Label sumbu X: awal, tengah & akhir
+	labels := []int{0}
+	if n > 2 {
+		labels = append(labels, n/2)
+	}
+	if n > 1 {
+		labels = append(labels, n-1)
+	}
+	for _, i := range labels {
+		label := shortDate(r.Series[i].PeriodStart, r.Granularity)
+		x := xAt(i) - textWidth(label, 7, false)/2
+		x = math.Max(left, math.Min(x, right-textWidth(label, 7, false)))
+		d.text(x, bottom+12, 7, false, label)
+	}
+}
+
+// niceCeil membulatkan nilai maksimum grafik ke atas (1, 2, 2.5, 5 x 10^n)
+func niceCeil(v float64) float64 {
+	if v <= 0 {
+		return 100000
+	}
+	exp := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, m := range []float64{1, 2, 2.5, 5, 10} {
+		if v <= m*exp {
+			return m * exp
+		}
+	}
+	return 10 * exp
+}
+
+func shortDate(date string, g Granularity) string {
+	label := periodLabel(date, g)
+	if g == Day {
+		// "1 September 2025" -> "1 Sep 2025"
+		if parts := strings.Fields(label); len(parts) == 3 && len(parts[1]) > 3 {
+			return parts[0] + " " + parts[1][:3] + " " + parts[2]
+		}
+	}
+	return label
+}
+
+// textWidth memperkirakan lebar teks; Helvetica-Bold sedikit lebih lebar
+func textWidth(s string, size float64, bold bool) float64 {
+	total := 0
+	for _, c := range s {
+		if c >= 32 && c <= 126 {
+			total += helveticaWidths[c-32]
+		} else {
+			total += 556
+		}
+	}
+	w := float64(total) * size / 1000
+	if bold {
+		w *= 1.06
+	}
+	return w
+}
+
+// fitText memotong teks dengan "..." agar muat di lebar maxWidth
+func fitText(s string, maxWidth, size float64, bold bool) string {
+	if textWidth(s, size, bold) <= maxWidth {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(string(runes)+"...", size, bold) > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+// pdfEscape mengubah teks ke WinAnsiEncoding dan meng-escape karakter khusus
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(c)
+		case c >= 32 && c <= 126:
+			b.WriteRune(c)
+		case c >= 0xA0 && c <= 0xFF:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// write menyusun objek PDF beserta tabel xref
+func (d *pdfDoc) write(w io.Writer) error {
+	var out bytes.Buffer
+	var offsets []int
+	obj := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1 catalog, 2 pages, 3-4 font, lalu pasangan page + content
+	const firstPage = 5
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, p := range d.pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, firstPage+2*i+1))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.Len(), p.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
@@ -0,0 +1,291 @@
+// Package reports menghitung laporan penjualan cafe dari data pesanan.
+// Hanya pesanan berstatus "completed" yang dihitung sebagai penjualan,
+// dikelompokkan berdasarkan waktu selesai (completed_at).
+package reports
+
+import (
+	"backend/dbtime"
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Granularity menentukan ukuran satu titik data di grafik
+type Granularity string
+
+const (
+	Day   Granularity = "day"
+	Week  Granularity = "week"
+	Month Granularity = "month"
+)
+
+// Jumlah menu terlaris yang ditampilkan
+const (
+	topMenusLimit     = 5
+	topMenusPerPeriod = 3
+)
+
+// ParseGranularity membaca parameter granularity (default "day")
+func ParseGranularity(v string) (Granularity, error) {
+	switch g := Granularity(v); g {
+	case "":
+		return Day, nil
+	case Day, Week, Month:
+		return g, nil
+	default:
+		return "", fmt.Errorf("granularity harus day, week atau month")
+	}
+}
+
+// SalesReport adalah ringkasan penjualan satu cafe dalam rentang tanggal
+type SalesReport struct {
+	CafeID            int          `json:"cafe_id"`
+	Start             string       `json:"start"`
+	End               string       `json:"end"`
+	Granularity       Granularity  `json:"granularity"`
+	TotalRevenue      float64      `json:"total_revenue"`
+	TotalOrders       int          `json:"total_orders"`
+	ItemsSold         int          `json:"items_sold"`
+	AverageOrderValue float64      `json:"average_order_value"`
+	AverageRating     float64      `json:"average_rating"`
+	TotalReviews      int          `json:"total_reviews"`
+	TopMenus          []MenuSales  `json:"top_menus"`
+	Series            []SalesPoint `json:"series"`
+
+	// Semua menu terjual (urut terlaris), dipakai untuk export
+	Menus []MenuSales `json:"-"`
+}
+
+// SalesPoint adalah penjualan dalam satu periode (hari/minggu/bulan).
+// Periode tanpa penjualan tetap ada dengan nilai 0.
+type SalesPoint struct {
+	PeriodStart string      `json:"period_start"`
+	Revenue     float64     `json:"revenue"`
+	Orders      int         `json:"orders"`
+	ItemsSold   int         `json:"items_sold"`
+	TopMenus    []MenuSales `json:"top_menus"`
+}
+
+// MenuSales adalah jumlah terjual & pendapatan satu menu
+type MenuSales struct {
+	MenuID   *string `json:"menu_id"`
+	Name     string  `json:"name"`
+	Quantity int     `json:"quantity"`
+	Revenue  float64 `json:"revenue"`
+}
+
+// Store menghitung laporan; diimplementasikan Service (PostgreSQL)
+type Store interface {
+	Dashboard(ctx context.Context, days int, now time.Time) (*DashboardStats, error)
+	Sales(ctx context.Context, cafeID int, start, end time.Time, g Granularity) (*SalesReport, error)
+}
+
+var _ Store = (*Service)(nil)
+
+type Service struct {
+	db       *sql.DB
+	location *time.Location
+}
+
+// location adalah zona waktu cafe, dipakai untuk batas hari
+func NewService(db *sql.DB, location *time.Location) *Service {
+	return &Service{db: db, location: location}
+}
+
+// =========================
+// Laporan penjualan
+// =========================
+// start & end adalah tanggal (inklusif) dalam zona waktu cafe.
+func (s *Service) Sales(ctx context.Context, cafeID int, start, end time.Time, g Granularity) (*SalesReport, error) {
+	start = truncateDay(start.In(s.location))
+	end = truncateDay(end.In(s.location))
+	until := end.AddDate(0, 0, 1)
+
+	report := &SalesReport{
+		CafeID:      cafeID,
+		Start:       start.Format("2006-01-02"),
+		End:         end.Format("2006-01-02"),
+		Granularity: g,
+		TopMenus:    []MenuSales{},
+	}
+
+	// Kerangka periode lengkap (zero-filled)
+	index := map[string]int{}
+	for p := BucketStart(start, g); !p.After(end); p = nextBucket(p, g) {
+		key := p.Format("2006-01-02")
+		index[key] = len(report.Series)
+		report.Series = append(report.Series, SalesPoint{PeriodStart: key, TopMenus: []MenuSales{}})
+	}
+
+	// Pendapatan & jumlah pesanan per periode
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT to_char(date_trunc($2, o.completed_at), 'YYYY-MM-DD') AS bucket,
+			COALESCE(SUM(o.total), 0), COUNT(*)
+		FROM orders o
+		WHERE o.cafe_profile_id=$1 AND o.status='completed'
+			AND o.completed_at >= $3 AND o.completed_at < $4
+		GROUP BY bucket`,
+		cafeID, string(g), dbtime.Format(start), dbtime.Format(until),
+	)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var bucket string
+		var revenue float64
+		var orders int
+		if err := rows.Scan(&bucket, &revenue, &orders); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if i, ok := index[bucket]; ok {
+			report.Series[i].Revenue = revenue
+			report.Series[i].Orders = orders
+		}
+		report.TotalRevenue += revenue
+		report.TotalOrders += orders
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Menu terjual per periode
+	rows, err = s.db.QueryContext(ctx, `
+		SELECT to_char(date_trunc($2, o.completed_at), 'YYYY-MM-DD') AS bucket,
+			oi.menu_id::text, oi.menu_name, SUM(oi.quantity), COALESCE(SUM(oi.subtotal), 0)
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		WHERE o.cafe_profile_id=$1 AND o.status='completed'
+			AND o.completed_at >= $3 AND o.completed_at < $4
+		GROUP BY bucket, oi.menu_id, oi.menu_name`,
+		cafeID, string(g), dbtime.Format(start), dbtime.Format(until),
+	)
+	if err != nil {
+		return nil, err
+	}
+	perPeriod := map[string][]MenuSales{}
+	overall := map[string]*MenuSales{}
+	for rows.Next() {
+		var bucket string
+		var menuID sql.NullString
+		var m MenuSales
+		if err := rows.Scan(&bucket, &menuID, &m.Name, &m.Quantity, &m.Revenue); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if menuID.Valid {
+			m.MenuID = &menuID.String
+		}
+		perPeriod[bucket] = append(perPeriod[bucket], m)
+
+		// Menu yang sudah dihapus digabung berdasarkan nama
+		key := "name:" + m.Name
+		if m.MenuID != nil {
+			key = "id:" + *m.MenuID
+		}
+		if o, ok := overall[key]; ok {
+			o.Quantity += m.Quantity
+			o.Revenue += m.Revenue
+		} else {
+			ms := m
+			overall[key] = &ms
+		}
+		report.ItemsSold += m.Quantity
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for bucket, menus := range perPeriod {
+		i, ok := index[bucket]
+		if !ok {
+			continue
+		}
+		for _, m := range menus {
+			report.Series[i].ItemsSold += m.Quantity
+		}
+		report.Series[i].TopMenus = topMenus(menus, topMenusPerPeriod)
+	}
+
+	all := make([]MenuSales, 0, len(overall))
+	for _, m := range overall {
+		all = append(all, *m)
+	}
+	report.Menus = topMenus(all, len(all))
+	report.TopMenus = topMenus(all, topMenusLimit)
+
+	if report.TotalOrders > 0 {
+		report.AverageOrderValue = round2(report.TotalRevenue / float64(report.TotalOrders))
+	}
+
+	// Rating rata-rata dari ulasan yang sudah disetujui dalam rentang yang sama
+	err = s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(AVG(rating), 0)::float8, COUNT(*)
+		FROM ulasan
+		WHERE cafe_profile_id=$1 AND status='approved' AND created_at >= $2 AND created_at < $3`,
+		cafeID, dbtime.Format(start), dbtime.Format(until),
+	).Scan(&report.AverageRating, &report.TotalReviews)
+	if err != nil {
+		return nil, err
+	}
+	report.AverageRating = math.Round(report.AverageRating*10) / 10
+
+	return report, nil
+}
+
+// BucketStart mengembalikan awal periode yang memuat t. Minggu dimulai
+// hari Senin, sama seperti date_trunc('week') di PostgreSQL.
+func BucketStart(t time.Time, g Granularity) time.Time {
+	t = truncateDay(t)
+	switch g {
+	case Week:
+		offset := (int(t.Weekday()) + 6) % 7
+		return t.AddDate(0, 0, -offset)
+	case Month:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	default:
+		return t
+	}
+}
+
+func nextBucket(t time.Time, g Granularity) time.Time {
+	switch g {
+	case Week:
+		return t.AddDate(0, 0, 7)
+	case Month:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// topMenus mengurutkan menu berdasarkan jumlah terjual lalu pendapatan
+func topMenus(menus []MenuSales, limit int) []MenuSales {
+	sorted := append([]MenuSales(nil), menus...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Quantity != sorted[j].Quantity {
+			return sorted[i].Quantity > sorted[j].Quantity
+		}
+		if sorted[i].Revenue != sorted[j].Revenue {
+			return sorted[i].Revenue > sorted[j].Revenue
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	if len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+	return sorted
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,261 @@
+package reports
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Style cell (index cellXfs di styles.xml)
+const (
+	styleDefault = iota
+	styleBold
+	styleRupiah
+	styleInteger
+	styleDate
+	styleDecimal
+	styleTitle
+)
+
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts count="3">
+<numFmt numFmtId="164" formatCode="&quot;Rp&quot;\ #,##0"/>
+<numFmt numFmtId="165" formatCode="d\ mmmm\ yyyy"/>
+<numFmt numFmtId="166" formatCode="0.0"/>
+</numFmts>
+<fonts count="3">
+<font><sz val="11"/><name val="Calibri"/></font>
+<font><b/><sz val="11"/><name val="Calibri"/></font>
+<font><b/><sz val="14"/><name val="Calibri"/></font>
+</fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="7">
+<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>
+<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>
+<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="3" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="166" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="0" fontId="2" fillId="0" borderId="0" xfId="0" applyFont="1"/>
+</cellXfs>
+</styleSheet>`
+
+type xlsxCell struct {
+	value interface{} // string, int, float64 atau time.Time
+	style int
+}
+
+type xlsxSheet struct {
+	name   string
+	widths []float64
+	rows   [][]xlsxCell
+}
+
+func (s *xlsxSheet) addRow(cells ...xlsxCell) {
+	s.rows = append(s.rows, cells)
+}
+
+func cell(v interface{}, style int) xlsxCell {
+	return xlsxCell{value: v, style: style}
+}
+
+// =========================
+// Export XLSX
+// =========================
+// WriteXLSX menulis laporan sebagai workbook dengan sheet Ringkasan,
+// Harian (per periode) dan Per Menu.
+func WriteXLSX(w io.Writer, r *SalesReport, cafeName string) error {
+	summary := &xlsxSheet{name: "Ringkasan", widths: []float64{28, 24}}
+	summary.addRow(cell("Laporan Penjualan", styleTitle))
+	summary.addRow(cell("Cafe", styleBold), cell(cafeName, styleDefault))
+	summary.addRow(cell("Periode", styleBold), cell(formatTanggal(r.Start)+" - "+formatTanggal(r.End), styleDefault))
+	summary.addRow()
+	summary.addRow(cell("Total Penjualan", styleBold), cell(r.TotalRevenue, styleRupiah))
+	summary.addRow(cell("Total Pesanan", styleBold), cell(r.TotalOrders, styleInteger))
+	summary.addRow(cell("Rata-rata per Pesanan", styleBold), cell(r.AverageOrderValue, styleRupiah))
+	summary.addRow(cell("Item Terjual", styleBold), cell(r.ItemsSold, styleInteger))
+	summary.addRow(cell("Rating Rata-rata", styleBold), cell(r.AverageRating, styleDecimal))
+	summary.addRow(cell("Jumlah Ulasan", styleBold), cell(r.TotalReviews, styleInteger))
+	summary.addRow()
+	summary.addRow(cell("Menu Terlaris", styleBold))
+	for i, m := range r.TopMenus {
+		summary.addRow(cell(fmt.Sprintf("%d. %s", i+1, m.Name), styleDefault), cell(m.Quantity, styleInteger))
+	}
+
+	daily := &xlsxSheet{name: "Harian", widths: []float64{30, 12, 14, 20, 48}}
+	daily.addRow(cell("Periode", styleBold), cell("Pesanan", styleBold), cell("Item Terjual", styleBold),
+		cell("Pendapatan", styleBold), cell("Menu Terlaris", styleBold))
+	for _, p := range r.Series {
+		// Periode harian ditulis sebagai tanggal Excel, mingguan/bulanan sebagai label
+		period := cell(periodLabel(p.PeriodStart, r.Granularity), styleDefault)
+		if t, err := time.Parse("2006-01-02", p.PeriodStart); err == nil && r.Granularity == Day {
+			period = cell(t, styleDate)
+		}
+		daily.addRow(period, cell(p.Orders, styleInteger), cell(p.ItemsSold, styleInteger),
+			cell(p.Revenue, styleRupiah), cell(menuNames(p.TopMenus), styleDefault))
+	}
+	daily.addRow(cell("Total", styleBold), cell(r.TotalOrders, styleInteger), cell(r.ItemsSold, styleInteger),
+		cell(r.TotalRevenue, styleRupiah))
+
+	menus := &xlsxSheet{name: "Per Menu", widths: []float64{6, 36, 12, 20}}
+	menus.addRow(cell("No", styleBold), cell("Menu", styleBold), cell("Terjual", styleBold), cell("Pendapatan", styleBold))
+	for i, m := range r.Menus {
+		menus.addRow(cell(i+1, styleInteger), cell(m.Name, styleDefault), cell(m.Quantity, styleInteger), cell(m.Revenue, styleRupiah))
+	}
+
+	return writeWorkbook(w, []*xlsxSheet{summary, daily, menus})
+}
+
+func menuNames(menus []MenuSales) string {
+	names := make([]string, len(menus))
+	for i, m := range menus {
+		names[i] = m.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+func writeWorkbook(w io.Writer, sheets []*xlsxSheet) error {
+	zw := zip.NewWriter(w)
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypesXML(len(sheets))},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`},
+		{"xl/workbook.xml", workbookXML(sheets)},
+		{"xl/_rels/workbook.xml.rels", workbookRelsXML(len(sheets))},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for i, s := range sheets {
+		files = append(files, struct {
+			name    string
+			content string
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), sheetXML(s)})
+	}
+
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func contentTypesXML(n int) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+`)
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`+"\n", i)
+	}
+	b.WriteString(`</Types>`)
+	return b.String()
+}
+
+func workbookXML(sheets []*xlsxSheet) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets>
+`)
+	for i, s := range sheets {
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`+"\n", xmlEscape(s.name), i+1, i+1)
+	}
+	b.WriteString(`</sheets>
+</workbook>`)
+	return b.String()
+}
+
+func workbookRelsXML(n int) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+`)
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`+"\n", i, i)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`+"\n", n+1)
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+func sheetXML(s *xlsxSheet) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+`)
+	if len(s.widths) > 0 {
+		b.WriteString("<cols>")
+		for i, width := range s.widths {
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%.1f" customWidth="1"/>`, i+1, i+1, width)
+		}
+		b.WriteString("</cols>\n")
+	}
+
+	b.WriteString("<sheetData>\n")
+	for i, row := range s.rows {
+		fmt.Fprintf(&b, `<row r="%d">`, i+1)
+		for j, c := range row {
+			ref := fmt.Sprintf("%s%d", columnName(j), i+1)
+			writeCell(&b, ref, c)
+		}
+		b.WriteString("</row>\n")
+	}
+	b.WriteString("</sheetData>\n</worksheet>")
+	return b.String()
+}
+
+func writeCell(b *strings.Builder, ref string, c xlsxCell) {
+	switch v := c.value.(type) {
+	case int:
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%d</v></c>`, ref, c.style, v)
+	case float64:
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, c.style, fmt.Sprint(v))
+	case time.Time:
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%d</v></c>`, ref, c.style, excelSerial(v))
+	default:
+		fmt.Fprintf(b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`,
+			ref, c.style, xmlEscape(fmt.Sprint(v)))
+	}
+}
+
+// excelSerial mengubah tanggal ke nomor seri Excel (hari sejak 1899-12-30)
+func excelSerial(t time.Time) int {
+	base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return int(day.Sub(base).Hours() / 24)
+}
+
+// columnName mengubah index kolom (0) ke nama kolom Excel (A)
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,310 @@
+package repository
+
+import (
+	"backend/dbtime"
+	"backend/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrAccountNotFound  = errors.New("akun tidak ditemukan")
+	ErrLastAdmin        = errors.New("super admin aktif terakhir tidak boleh ditangguhkan, dihapus atau diturunkan")
+	ErrAlreadySuspended = errors.New("akun sudah ditangguhkan")
+	ErrNotSuspended     = errors.New("akun tidak sedang ditangguhkan")
+	ErrAccountDeleted   = errors.New("akun sudah dihapus")
+	ErrNotDeleted       = errors.New("akun tidak dalam status terhapus")
+)
+
+// AccountStore adalah akses data akun untuk halaman kelola akun
+type AccountStore interface {
+	List(ctx context.Context, f models.AccountFilter) ([]models.Account, int, error)
+	Get(ctx context.Context, id int) (*models.Account, error)
+	Suspend(ctx context.Context, id, actorID int, reason string, now time.Time) error
+	Unsuspend(ctx context.Context, id int) error
+	ChangeRole(ctx context.Context, id int, role string) error
+	SoftDelete(ctx context.Context, id, actorID int, now time.Time) error
+	Restore(ctx context.Context, id int) error
+}
+
+var _ AccountStore = (*AccountRepository)(nil)
+
+// AccountRepository dipakai super admin untuk mengelola semua akun
+type AccountRepository struct {
+	db       *sql.DB
+	location *time.Location
+}
+
+func NewAccountRepository(db *sql.DB, location *time.Location) *AccountRepository {
+	return &AccountRepository{db: db, location: location}
+}
+
+// Status akun dihitung dari kolom penangguhan, hapus & verifikasi
+const accountStatusExpr = `CASE
+		WHEN u.deleted_at IS NOT NULL THEN 'deleted'
+		WHEN u.suspended_at IS NOT NULL THEN 'suspended'
+		WHEN (u.role='cafe' AND NOT COALESCE(u.verified, false))
+			OR (u.role='customer' AND NOT COALESCE(u.email_verified, false)) THEN 'unverified'
+		ELSE 'active'
+	END`
+
+const accountColumns = `u.id, u.username, COALESCE(u.email, ''), u.role, COALESCE(u.display_name, ''),
+	` + accountStatusExpr + `, COALESCE(u.verified, false), COALESCE(u.email_verified, false),
+	u.suspended_at, COALESCE(u.suspended_reason, ''), u.deleted_at,
+	(SELECT COUNT(*) FROM sessions s WHERE s.user_id = u.id AND s.revoked_at IS NULL AND s.expires_at > NOW()),
+	u.created_at`
+
+func (r *AccountRepository) scanAccount(row interface{ Scan(...interface{}) error }) (models.Account, error) {
+	var a models.Account
+	var suspendedAt, deletedAt, created pq.NullTime
+	err := row.Scan(&a.ID, &a.Username, &a.Email, &a.Role, &a.DisplayName,
+		&a.Status, &a.Verified, &a.EmailVerified,
+		&suspendedAt, &a.SuspendedReason, &deletedAt, &a.ActiveSessions, &created)
+	if err != nil {
+		return a, err
+	}
+	a.SuspendedAt = dbtime.WallClockNull(suspendedAt, r.location)
+	a.DeletedAt = dbtime.WallClockNull(deletedAt, r.location)
+	if created.Valid {
+		a.CreatedAt = dbtime.WallClock(created.Time, r.location)
+	}
+	return a, nil
+}
+
+// =========================
+// Daftar & detail akun
+// =========================
+func (r *AccountRepository) List(ctx context.Context, f models.AccountFilter) ([]models.Account, int, error) {
+	var where []string
+	var args []interface{}
+	add := func(cond string, v interface{}) {
+		args = append(args, v)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.Role != "" {
+		add("u.role = $%d", f.Role)
+	}
+	if f.Status != "" {
+		add(accountStatusExpr+" = $%d", f.Status)
+	} else {
+		// Akun terhapus hanya tampil jika diminta
+		where = append(where, "u.deleted_at IS NULL")
+	}
+	if f.Search != "" {
+		args = append(args, "%"+escapeLike(f.Search)+"%")
+		where = append(where, fmt.Sprintf("(u.username ILIKE $%[1]d OR u.email ILIKE $%[1]d)", len(args)))
+	}
+	if f.From != nil {
+		add("u.created_at >= $%d", dbtime.Format(f.From.In(r.location)))
+	}
+	if f.To != nil {
+		add("u.created_at < $%d", dbtime.Format(f.To.In(r.location)))
+	}
+	clause := " WHERE " + strings.Join(where, " AND ")
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users u`+clause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, f.Limit, f.Offset)
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM users u%s
+		ORDER BY u.created_at DESC, u.id DESC
+		LIMIT $%d OFFSET $%d`, accountColumns, clause, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []models.Account{}
+	for rows.Next() {
+		a, err := r.scanAccount(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		list = append(list, a)
+	}
+	return list, total, rows.Err()
+}
+
+func (r *AccountRepository) Get(ctx context.Context, id int) (*models.Account, error) {
+	a, err := r.scanAccount(r.db.QueryRowContext(ctx, `SELECT `+accountColumns+` FROM users u WHERE u.id=$1`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// escapeLike meng-escape karakter wildcard LIKE dari input user
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// =========================
+// Tangguhkan / aktifkan kembali
+// =========================
+func (r *AccountRepository) Suspend(ctx context.Context, id, actorID int, reason string, now time.Time) error {
+	return r.mutate(ctx, id, true, func(a accountState) error {
+		if a.deleted {
+			return ErrAccountDeleted
+		}
+		if a.suspended {
+			return ErrAlreadySuspended
+		}
+		return nil
+	}, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE users SET suspended_at=$2, suspended_reason=$3, suspended_by=$4 WHERE id=$1`,
+			id, dbtime.Format(now.In(r.location)), reason, actorID)
+		if err != nil {
+			return err
+		}
+		return revokeSessions(ctx, tx, id)
+	})
+}
+
+func (r *AccountRepository) Unsuspend(ctx context.Context, id int) error {
+	return r.mutate(ctx, id, false, func(a accountState) error {
+		if a.deleted {
+			return ErrAccountDeleted
+		}
+		if !a.suspended {
+			return ErrNotSuspended
+		}
+		return nil
+	}, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE users SET suspended_at=NULL, suspended_reason=NULL, suspended_by=NULL WHERE id=$1`, id)
+		return err
+	})
+}
+
+// =========================
+// Ganti role
+// =========================
+func (r *AccountRepository) ChangeRole(ctx context.Context, id int, role string) error {
+	return r.mutate(ctx, id, role != models.RoleAdmin, func(a accountState) error {
+		if a.deleted {
+			return ErrAccountDeleted
+		}
+		return nil
+	}, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `UPDATE users SET role=$2 WHERE id=$1`, id, role)
+		return err
+	})
+}
+
+// =========================
+// Hapus lunak / pulihkan
+// =========================
+
+// SoftDelete menyembunyikan akun & mencabut semua session-nya. Data
+// (pesanan, ulasan, profil cafe) tetap ada sehingga akun bisa dipulihkan.
+func (r *AccountRepository) SoftDelete(ctx context.Context, id, actorID int, now time.Time) error {
+	return r.mutate(ctx, id, true, func(a accountState) error {
+		if a.deleted {
+			return ErrAccountDeleted
+		}
+		return nil
+	}, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `UPDATE users SET deleted_at=$2, deleted_by=$3 WHERE id=$1`,
+			id, dbtime.Format(now.In(r.location)), actorID)
+		if err != nil {
+			return err
+		}
+		return revokeSessions(ctx, tx, id)
+	})
+}
+
+func (r *AccountRepository) Restore(ctx context.Context, id int) error {
+	return r.mutate(ctx, id, false, func(a accountState) error {
+		if !a.deleted {
+			return ErrNotDeleted
+		}
+		return nil
+	}, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `UPDATE users SET deleted_at=NULL, deleted_by=NULL WHERE id=$1`, id)
+		return err
+	})
+}
+
+type accountState struct {
+	role      string
+	suspended bool
+	deleted   bool
+}
+
+// mutate mengunci akun, memeriksa status-nya lalu menjalankan apply dalam
+// satu transaksi. Jika removesAdmin, aksi ditolak saat akun adalah super
+// admin aktif terakhir.
+func (r *AccountRepository) mutate(ctx context.Context, id int, removesAdmin bool, check func(accountState) error, apply func(*sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if removesAdmin {
+		// Kunci semua admin aktif supaya dua aksi bersamaan tidak
+		// sama-sama lolos pengecekan admin terakhir
+		rows, err := tx.QueryContext(ctx, `
+			SELECT id FROM users
+			WHERE role='admin' AND suspended_at IS NULL AND deleted_at IS NULL
+			ORDER BY id
+			FOR UPDATE`)
+		if err != nil {
+			return err
+		}
+		var admins []int
+		for rows.Next() {
+			var adminID int
+			if err := rows.Scan(&adminID); err != nil {
+				rows.Close()
+				return err
+			}
+			admins = append(admins, adminID)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(admins) == 1 && admins[0] == id {
+			return ErrLastAdmin
+		}
+	}
+
+	var a accountState
+	err = tx.QueryRowContext(ctx, `
+		SELECT role, suspended_at IS NOT NULL, deleted_at IS NOT NULL
+		FROM users WHERE id=$1 FOR UPDATE`, id,
+	).Scan(&a.role, &a.suspended, &a.deleted)
+	if err == sql.ErrNoRows {
+		return ErrAccountNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := check(a); err != nil {
+		return err
+	}
+	if err := apply(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func revokeSessions(ctx context.Context, tx *sql.Tx, userID int) error {
+	_, err := tx.ExecContext(ctx, `UPDATE sessions SET revoked_at=NOW() WHERE user_id=$1 AND revoked_at IS NULL`, userID)
+	return err
+}
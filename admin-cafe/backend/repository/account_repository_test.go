@@ -0,0 +1,16 @@
+package repository_test
+
+import (
+	"backend/pgtest"
+	"backend/repository"
+	"backend/repository/storetest"
+	"testing"
+	"time"
+)
+
+func TestAccountRepository(t *testing.T) {
+	db := pgtest.Open(t)
+	adminID := pgtest.CreateAdmin(t, db, "admin")
+	customerID := pgtest.CreateCustomer(t, db, "budi")
+	storetest.Accounts(t, repository.NewAccountRepository(db, time.FixedZone("WIB", 7*60*60)), adminID, customerID)
+}
@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"backend/dbtime"
+	"backend/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// execer dipenuhi oleh *sql.DB maupun *sql.Tx, sehingga log aktivitas
+// bisa ditulis dalam transaksi yang sama dengan perubahannya
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// ActivityStore adalah akses log aktivitas super admin
+type ActivityStore interface {
+	Record(ctx context.Context, a models.Activity, now time.Time) error
+	List(ctx context.Context, f models.ActivityFilter) ([]models.Activity, int, error)
+	CountUnacknowledged(ctx context.Context) (int, error)
+	History(ctx context.Context, entityType, entityID string) ([]models.Activity, error)
+	Acknowledge(ctx context.Context, ids []int, adminID int, now time.Time) (int64, error)
+}
+
+var _ ActivityStore = (*ActivityRepository)(nil)
+
+type ActivityRepository struct {
+	db       *sql.DB
+	location *time.Location
+}
+
+func NewActivityRepository(db *sql.DB, location *time.Location) *ActivityRepository {
+	return &ActivityRepository{db: db, location: location}
+}
+
+// =========================
+// Catat aktivitas
+// =========================
+func (r *ActivityRepository) Record(ctx context.Context, a models.Activity, now time.Time) error {
+	return recordActivity(ctx, r.db, a, now.In(r.location))
+}
+
+func recordActivity(ctx context.Context, e execer, a models.Activity, now time.Time) error {
+	_, err := e.ExecContext(ctx, `
+		INSERT INTO activity_logs (actor_user_id, actor_name, actor_role, action, entity_type, entity_id,
+			title, subject, claim, before_data, after_data, ip_address, user_agent,
+			request_method, request_path, request_id, created_at)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, ''),
+			$10, $11, NULLIF($12, ''), NULLIF($13, ''), NULLIF($14, ''), NULLIF($15, ''), NULLIF($16, ''), $17)`,
+		a.ActorUserID, a.ActorName, a.ActorRole, a.Action, a.EntityType, a.EntityID,
+		a.Title, a.Subject, a.Claim, nullJSON(a.Before), nullJSON(a.After), a.IPAddress, a.UserAgent,
+		a.Method, a.Path, a.RequestID, dbtime.Format(now),
+	)
+	return err
+}
+
+// nullJSON mengubah JSON kosong menjadi NULL
+func nullJSON(b []byte) interface{} {
+	if len(b) == 0 {
+		return nil
+	}
+	return string(b)
+}
+
+// =========================
+// Daftar log aktivitas
+// =========================
+const activityColumns = `a.id, a.actor_user_id, COALESCE(a.actor_name, u.username, ''), COALESCE(a.actor_role, ''),
+	a.action, a.entity_type, a.entity_id, a.title, COALESCE(a.subject, ''), COALESCE(a.claim, ''),
+	a.before_data, a.after_data, COALESCE(a.ip_address, ''), COALESCE(a.user_agent, ''),
+	COALESCE(a.request_method, ''), COALESCE(a.request_path, ''), COALESCE(a.request_id, ''), a.acknowledged_at, a.acknowledged_by, a.created_at`
+
+const activityJoins = `
+	FROM activity_logs a
+	LEFT JOIN users u ON u.id = a.actor_user_id`
+
+func (r *ActivityRepository) scanActivity(row interface{ Scan(...interface{}) error }) (models.Activity, error) {
+	var a models.Activity
+	var actorID, ackBy sql.NullInt64
+	var before, after []byte
+	var ackAt pq.NullTime
+	var created time.Time
+
+	err := row.Scan(&a.ID, &actorID, &a.ActorName, &a.ActorRole, &a.Action, &a.EntityType, &a.EntityID,
+		&a.Title, &a.Subject, &a.Claim, &before, &after, &a.IPAddress, &a.UserAgent,
+		&a.Method, &a.Path, &a.RequestID, &ackAt, &ackBy, &created)
+	if err != nil {
+		return a, err
+	}
+	if actorID.Valid {
+		id := int(actorID.Int64)
+		a.ActorUserID = &id
+	}
+	if ackBy.Valid {
+		id := int(ackBy.Int64)
+		a.AcknowledgedBy = &id
+	}
+	a.Before = before
+	a.After = after
+	a.AcknowledgedAt = dbtime.WallClockNull(ackAt, r.location)
+	a.CreatedAt = dbtime.WallClock(created, r.location)
+	return a, nil
+}
+
+// activityWhere menyusun klausa WHERE dari filter
+func (r *ActivityRepository) activityWhere(f models.ActivityFilter) (string, []interface{}) {
+	var where []string
+	var args []interface{}
+	add := func(cond string, v interface{}) {
+		args = append(args, v)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.Action != "" {
+		add("a.action = $%d", f.Action)
+	}
+	if f.EntityType != "" {
+		add("a.entity_type = $%d", f.EntityType)
+	}
+	if f.EntityID != "" {
+		add("a.entity_id = $%d", f.EntityID)
+	}
+	if f.ActorUserID > 0 {
+		add("a.actor_user_id = $%d", f.ActorUserID)
+	}
+	if f.ActorRole != "" {
+		add("a.actor_role = $%d", f.ActorRole)
+	}
+	if f.Acknowledged != nil {
+		if *f.Acknowledged {
+			where = append(where, "a.acknowledged_at IS NOT NULL")
+		} else {
+			where = append(where, "a.acknowledged_at IS NULL")
+		}
+	}
+	if f.From != nil {
+		add("a.created_at >= $%d", dbtime.Format(f.From.In(r.location)))
+	}
+	if f.To != nil {
+		add("a.created_at < $%d", dbtime.Format(f.To.In(r.location)))
+	}
+
+	if len(where) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(where, " AND "), args
+}
+
+// List mengembalikan log aktivitas terbaru dulu beserta jumlah total
+// entri yang cocok dengan filter
+func (r *ActivityRepository) List(ctx context.Context, f models.ActivityFilter) ([]models.Activity, int, error) {
+	where, args := r.activityWhere(f)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM activity_logs a"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, f.Limit, f.Offset)
+	rows, err := r.db.QueryContext(ctx, "SELECT "+activityColumns+activityJoins+where+
+		fmt.Sprintf(" ORDER BY a.created_at DESC, a.id DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args)),
+		args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []models.Activity{}
+	for rows.Next() {
+		a, err := r.scanActivity(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		list = append(list, a)
+	}
+	return list, total, rows.Err()
+}
+
+// CountUnacknowledged menghitung entri yang belum dikonfirmasi
+func (r *ActivityRepository) CountUnacknowledged(ctx context.Context) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM activity_logs WHERE acknowledged_at IS NULL").Scan(&n)
+	return n, err
+}
+
+// History mengembalikan seluruh riwayat satu entitas, terlama dulu
+func (r *ActivityRepository) History(ctx context.Context, entityType, entityID string) ([]models.Activity, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+activityColumns+activityJoins+`
+		WHERE a.entity_type=$1 AND a.entity_id=$2
+		ORDER BY a.created_at, a.id LIMIT 500`, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.Activity{}
+	for rows.Next() {
+		a, err := r.scanActivity(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+// =========================
+// Konfirmasi log aktivitas
+// =========================
+// Acknowledge menandai entri sebagai sudah dikonfirmasi. ids kosong
+// berarti semua entri yang belum dikonfirmasi sampai now.
+func (r *ActivityRepository) Acknowledge(ctx context.Context, ids []int, adminID int, now time.Time) (int64, error) {
+	ts := dbtime.Format(now.In(r.location))
+
+	var res sql.Result
+	var err error
+	if len(ids) == 0 {
+		res, err = r.db.ExecContext(ctx, `
+			UPDATE activity_logs SET acknowledged_at=$1, acknowledged_by=$2
+			WHERE acknowledged_at IS NULL AND created_at <= $1`, ts, adminID)
+	} else {
+		ids64 := make([]int64, len(ids))
+		for i, id := range ids {
+			ids64[i] = int64(id)
+		}
+		res, err = r.db.ExecContext(ctx, `
+			UPDATE activity_logs SET acknowledged_at=$1, acknowledged_by=$2
+			WHERE acknowledged_at IS NULL AND id = ANY($3)`, ts, adminID, pq.Array(ids64))
+	}
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Radius bumi rata-rata dalam km
+const earthRadiusKm = 6371.0
+
+// Nama hari sesuai kolom cafe_operational_hours.hari
+var namaHari = []string{"minggu", "senin", "selasa", "rabu", "kamis", "jumat", "sabtu"}
+
+// CafeProfileStore adalah akses data profil cafe
+type CafeProfileStore interface {
+	GetByID(ctx context.Context, id int) (*models.CafeProfile, error)
+	GetIDByUserID(ctx context.Context, userID int) (int, error)
+	FindNearby(ctx context.Context, q models.NearbyQuery) ([]models.NearbyCafe, error)
+}
+
+var _ CafeProfileStore = (*CafeProfileRepository)(nil)
+
+type CafeProfileRepository struct {
+	db *sql.DB
+}
+
+func NewCafeProfileRepository(db *sql.DB) *CafeProfileRepository {
+	return &CafeProfileRepository{db: db}
+}
+
+// =========================
+// Ambil profil cafe berdasarkan ID
+// =========================
+func (r *CafeProfileRepository) GetByID(ctx context.Context, id int) (*models.CafeProfile, error) {
+	p := &models.CafeProfile{}
+	var userID sql.NullInt64
+	var telepon, deskripsi, mainImage sql.NullString
+	var lat, lng sql.NullFloat64
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, nama, alamat, telepon, deskripsi, main_image, latitude, longitude, verified
+		FROM cafe_profiles WHERE id=$1`, id,
+	).Scan(&p.ID, &userID, &p.Nama, &p.Alamat, &telepon, &deskripsi, &mainImage, &lat, &lng, &p.Verified)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID.Valid {
+		uid := int(userID.Int64)
+		p.UserID = &uid
+	}
+	p.Telepon = telepon.String
+	p.Deskripsi = deskripsi.String
+	p.MainImage = mainImage.String
+	if lat.Valid && lng.Valid {
+		p.Latitude = &lat.Float64
+		p.Longitude = &lng.Float64
+	}
+	return p, nil
+}
+
+// =========================
+// Ambil ID profil cafe milik akun cafe
+// =========================
+func (r *CafeProfileRepository) GetIDByUserID(ctx context.Context, userID int) (int, error) {
+	var id int
+	err := r.db.QueryRowContext(ctx, "SELECT id FROM cafe_profiles WHERE user_id=$1 ORDER BY id LIMIT 1", userID).Scan(&id)
+	return id, err
+}
+
+// =========================
+// Cari cafe terdekat
+// =========================
+// Tidak butuh PostGIS/earthdistance: kandidat disaring dulu dengan
+// bounding box (memakai index lat/lng), lalu jarak dihitung dengan
+// rumus haversine dan dibatasi radius.
+func (r *CafeProfileRepository) FindNearby(ctx context.Context, q models.NearbyQuery) ([]models.NearbyCafe, error) {
+	minLat, maxLat, minLng, maxLng := boundingBox(q.Latitude, q.Longitude, q.RadiusKm)
+
+	hari := namaHari[q.Now.Weekday()]
+	hariLalu := namaHari[(q.Now.Weekday()+6)%7]
+	jam := q.Now.Format("15:04:05")
+
+	args := []interface{}{q.Latitude, q.Longitude, minLat, maxLat, minLng, maxLng, hari, hariLalu, jam}
+	var filters []string
+
+	args = append(args, q.RadiusKm)
+	filters = append(filters, fmt.Sprintf("distance_km <= $%d", len(args)))
+
+	if q.MinRating > 0 {
+		args = append(args, q.MinRating)
+		filters = append(filters, fmt.Sprintf("rating >= $%d", len(args)))
+	}
+	if q.OpenNow {
+		filters = append(filters, "open_now")
+	}
+	if len(q.Facilities) > 0 {
+		args = append(args, pq.Array(q.Facilities))
+		filters = append(filters, fmt.Sprintf(`(
+			SELECT COUNT(DISTINCT LOWER(f.nama_fasilitas)) FROM cafe_facilities f
+			WHERE f.cafe_profile_id = c.id AND f.tersedia = true AND LOWER(f.nama_fasilitas) = ANY($%d)
+		) = %d`, len(args), len(q.Facilities)))
+	}
+
+	args = append(args, q.Limit)
+	query := fmt.Sprintf(`
+		SELECT id, nama, alamat, main_image, latitude, longitude, distance_km, rating, total_ulasan, open_now
+		FROM (
+			SELECT cp.id, cp.nama, cp.alamat, COALESCE(cp.main_image, '') AS main_image,
+				cp.latitude, cp.longitude,
+				%d * 2 * ASIN(SQRT(
+					POWER(SIN(RADIANS(cp.latitude - $1) / 2), 2) +
+					COS(RADIANS($1)) * COS(RADIANS(cp.latitude)) *
+					POWER(SIN(RADIANS(cp.longitude - $2) / 2), 2)
+				)) AS distance_km,
+				COALESCE(u.avg_rating, 0) AS rating,
+				COALESCE(u.total, 0) AS total_ulasan,
+				EXISTS (
+					SELECT 1 FROM cafe_operational_hours oh
+					WHERE oh.cafe_profile_id = cp.id AND (
+						(LOWER(oh.hari) = $7 AND (
+							(oh.buka <= oh.tutup AND $9::time >= oh.buka AND $9::time < oh.tutup) OR
+							(oh.buka > oh.tutup AND ($9::time >= oh.buka OR $9::time < oh.tutup))
+						)) OR
+						-- jam buka lewat tengah malam dari hari sebelumnya
+						(LOWER(oh.hari) = $8 AND oh.buka > oh.tutup AND $9::time < oh.tutup)
+					)
+				) AS open_now
+			FROM cafe_profiles cp
+			LEFT JOIN users us ON us.id = cp.user_id
+			LEFT JOIN (
+				SELECT cafe_profile_id, AVG(rating)::float8 AS avg_rating, COUNT(*) AS total
+				FROM ulasan WHERE status = 'approved' GROUP BY cafe_profile_id
+			) u ON u.cafe_profile_id = cp.id
+			WHERE COALESCE(us.verified, cp.verified) = true
+				AND cp.latitude BETWEEN $3 AND $4
+				AND cp.longitude BETWEEN $5 AND $6
+		) c
+		WHERE %s
+		ORDER BY distance_km ASC
+		LIMIT $%d`,
+		int(earthRadiusKm), strings.Join(filters, " AND "), len(args),
+	)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cafes := []models.NearbyCafe{}
+	for rows.Next() {
+		var c models.NearbyCafe
+		err := rows.Scan(&c.ID, &c.Nama, &c.Alamat, &c.MainImage, &c.Latitude, &c.Longitude,
+			&c.DistanceKm, &c.Rating, &c.TotalUlasan, &c.OpenNow)
+		if err != nil {
+			return nil, err
+		}
+		c.DistanceKm = math.Round(c.DistanceKm*100) / 100
+		c.Rating = math.Round(c.Rating*10) / 10
+		cafes = append(cafes, c)
+	}
+	return cafes, rows.Err()
+}
+
+// boundingBox menghitung kotak lat/lng yang memuat lingkaran radius km
+func boundingBox(lat, lng, radiusKm float64) (minLat, maxLat, minLng, maxLng float64) {
+	dLat := radiusKm / earthRadiusKm * 180 / math.Pi
+	minLat = math.Max(lat-dLat, -90)
+	maxLat = math.Min(lat+dLat, 90)
+
+	// Dekat kutub seluruh garis bujur masuk
+	cosLat := math.Cos(lat * math.Pi / 180)
+	if cosLat < 1e-6 || maxLat >= 90 || minLat <= -90 {
+		return minLat, maxLat, -180, 180
+	}
+	dLng := dLat / cosLat
+	minLng = math.Max(lng-dLng, -180)
+	maxLng = math.Min(lng+dLng, 180)
+	return minLat, maxLat, minLng, maxLng
+}
@@ -0,0 +1,398 @@
+package repository
+
+import (
+	"backend/dbtime"
+	"backend/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrChangeNotFound   = errors.New("permintaan perubahan tidak ditemukan")
+	ErrChangeNotPending = errors.New("permintaan perubahan sudah diproses")
+)
+
+// ChangeRequestStore adalah antrean permintaan perubahan data cafe
+type ChangeRequestStore interface {
+	ProfileSnapshot(ctx context.Context, cafeID int) (*models.ProfileChanges, error)
+	DiscountSnapshot(ctx context.Context, menuID string) (*models.DiscountChange, error)
+	UpdateProfile(ctx context.Context, cafeID int, c models.ProfileChanges) error
+	UpdateDiscount(ctx context.Context, menuID string, d models.DiscountChange) error
+	Submit(ctx context.Context, cafeID int, entityType, entityID string, changes, previous interface{}, userID int, now time.Time) (*models.ChangeRequest, error)
+	GetByID(ctx context.Context, id int) (*models.ChangeRequest, error)
+	List(ctx context.Context, cafeID int, status string, limit, offset int) ([]models.ChangeRequest, int, error)
+	Approve(ctx context.Context, id, adminID int, now time.Time) (*models.ChangeRequest, error)
+	Reject(ctx context.Context, id, adminID int, reason string, now time.Time) (*models.ChangeRequest, error)
+	OwnerID(ctx context.Context, cafeID int) (int, error)
+}
+
+var _ ChangeRequestStore = (*ChangeRequestRepository)(nil)
+
+type ChangeRequestRepository struct {
+	db       *sql.DB
+	location *time.Location
+}
+
+func NewChangeRequestRepository(db *sql.DB, location *time.Location) *ChangeRequestRepository {
+	return &ChangeRequestRepository{db: db, location: location}
+}
+
+// =========================
+// Snapshot data live
+// =========================
+// ProfileSnapshot mengambil nilai profil cafe yang sedang berlaku,
+// termasuk izin usaha dari akun pemilik cafe
+func (r *ChangeRequestRepository) ProfileSnapshot(ctx context.Context, cafeID int) (*models.ProfileChanges, error) {
+	var nama, alamat, telepon, deskripsi, izin string
+	var lat, lng sql.NullFloat64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT c.nama, c.alamat, COALESCE(c.telepon, ''), COALESCE(c.deskripsi, ''),
+			c.latitude, c.longitude, COALESCE(u.izin_usaha, '')
+		FROM cafe_profiles c
+		LEFT JOIN users u ON u.id = c.user_id
+		WHERE c.id=$1`, cafeID,
+	).Scan(&nama, &alamat, &telepon, &deskripsi, &lat, &lng, &izin)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &models.ProfileChanges{Nama: &nama, Alamat: &alamat, Telepon: &telepon, Deskripsi: &deskripsi, IzinUsaha: &izin}
+	if lat.Valid && lng.Valid {
+		s.Latitude = &lat.Float64
+		s.Longitude = &lng.Float64
+	}
+	return s, nil
+}
+
+// DiscountSnapshot mengambil diskon menu yang sedang berlaku
+func (r *ChangeRequestRepository) DiscountSnapshot(ctx context.Context, menuID string) (*models.DiscountChange, error) {
+	d := &models.DiscountChange{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(discount, 0)::float8,
+			COALESCE(to_char(start_date, 'YYYY-MM-DD'), ''),
+			COALESCE(to_char(end_date, 'YYYY-MM-DD'), '')
+		FROM menus WHERE id=$1`, menuID,
+	).Scan(&d.Discount, &d.StartDate, &d.EndDate)
+	return d, err
+}
+
+// =========================
+// Terapkan perubahan ke data live
+// =========================
+// UpdateProfile langsung mengubah field profil yang tidak butuh persetujuan
+func (r *ChangeRequestRepository) UpdateProfile(ctx context.Context, cafeID int, c models.ProfileChanges) error {
+	return applyProfile(ctx, r.db, cafeID, c)
+}
+
+// UpdateDiscount langsung mengubah diskon di bawah batas persetujuan
+func (r *ChangeRequestRepository) UpdateDiscount(ctx context.Context, menuID string, d models.DiscountChange) error {
+	return applyDiscount(ctx, r.db, menuID, d)
+}
+
+// applyProfile mengubah field profil yang tidak nil. Dipakai langsung
+// untuk field biasa, dan saat super admin menyetujui field sensitif.
+func applyProfile(ctx context.Context, q execer, cafeID int, c models.ProfileChanges) error {
+	sets := []string{}
+	args := []interface{}{cafeID}
+	add := func(column string, v interface{}) {
+		args = append(args, v)
+		sets = append(sets, fmt.Sprintf("%s=$%d", column, len(args)))
+	}
+	if c.Nama != nil {
+		add("nama", *c.Nama)
+	}
+	if c.Alamat != nil {
+		add("alamat", *c.Alamat)
+	}
+	if c.Telepon != nil {
+		add("telepon", *c.Telepon)
+	}
+	if c.Deskripsi != nil {
+		add("deskripsi", *c.Deskripsi)
+	}
+	if c.Latitude != nil && c.Longitude != nil {
+		add("latitude", *c.Latitude)
+		add("longitude", *c.Longitude)
+	}
+
+	if len(sets) > 0 {
+		res, err := q.ExecContext(ctx,
+			"UPDATE cafe_profiles SET "+strings.Join(sets, ", ")+", updated_at=CURRENT_TIMESTAMP WHERE id=$1",
+			args...,
+		)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return sql.ErrNoRows
+		}
+	}
+
+	if c.IzinUsaha != nil {
+		_, err := q.ExecContext(ctx,
+			"UPDATE users SET izin_usaha=$2 WHERE id=(SELECT user_id FROM cafe_profiles WHERE id=$1)",
+			cafeID, *c.IzinUsaha,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyDiscount mengubah diskon menu beserta harga setelah diskon
+func applyDiscount(ctx context.Context, q execer, menuID string, d models.DiscountChange) error {
+	res, err := q.ExecContext(ctx, `
+		UPDATE menus
+		SET discount=$2, discounted_price=ROUND(price * (1 - $2::numeric / 100), 2),
+			start_date=NULLIF($3, '')::date, end_date=NULLIF($4, '')::date,
+			updated_at=CURRENT_TIMESTAMP
+		WHERE id=$1`,
+		menuID, d.Discount, d.StartDate, d.EndDate,
+	)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// =========================
+// Ajukan perubahan
+// =========================
+// Submit menyimpan perubahan sebagai pending. Jika entitas sudah punya
+// perubahan pending, field baru digabung ke sana; nilai previous yang
+// lama dipertahankan supaya admin melihat nilai live yang asli.
+func (r *ChangeRequestRepository) Submit(ctx context.Context, cafeID int, entityType, entityID string, changes, previous interface{}, userID int, now time.Time) (*models.ChangeRequest, error) {
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return nil, err
+	}
+	previousJSON, err := json.Marshal(previous)
+	if err != nil {
+		return nil, err
+	}
+
+	var id int
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO cafe_change_requests
+			(cafe_profile_id, entity_type, entity_id, changes, previous, status, requested_by, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,'pending',$6,$7,$7)
+		ON CONFLICT (entity_type, entity_id) WHERE status='pending'
+		DO UPDATE SET
+			changes = cafe_change_requests.changes || EXCLUDED.changes,
+			previous = EXCLUDED.previous || cafe_change_requests.previous,
+			requested_by = EXCLUDED.requested_by,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id`,
+		cafeID, entityType, entityID, string(changesJSON), string(previousJSON), userID,
+		dbtime.Format(now.In(r.location)),
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+// =========================
+// Ambil permintaan perubahan
+// =========================
+const changeRequestColumns = `cr.id, cr.cafe_profile_id, c.nama, cr.entity_type, cr.entity_id, cr.changes, cr.previous,
+	cr.status, COALESCE(cr.reason, ''), cr.requested_by, cr.reviewed_by, cr.reviewed_at, cr.created_at, cr.updated_at`
+
+func (r *ChangeRequestRepository) scan(row interface{ Scan(...interface{}) error }) (*models.ChangeRequest, error) {
+	c := &models.ChangeRequest{}
+	var requestedBy, reviewedBy sql.NullInt64
+	var changes, previous []byte
+	var reviewedAt pq.NullTime
+	err := row.Scan(&c.ID, &c.CafeID, &c.CafeName, &c.EntityType, &c.EntityID, &changes, &previous,
+		&c.Status, &c.Reason, &requestedBy, &reviewedBy, &reviewedAt, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Changes = changes
+	c.Previous = previous
+	if requestedBy.Valid {
+		id := int(requestedBy.Int64)
+		c.RequestedBy = &id
+	}
+	if reviewedBy.Valid {
+		id := int(reviewedBy.Int64)
+		c.ReviewedBy = &id
+	}
+	c.ReviewedAt = dbtime.WallClockNull(reviewedAt, r.location)
+	c.CreatedAt = dbtime.WallClock(c.CreatedAt, r.location)
+	c.UpdatedAt = dbtime.WallClock(c.UpdatedAt, r.location)
+	return c, nil
+}
+
+func (r *ChangeRequestRepository) GetByID(ctx context.Context, id int) (*models.ChangeRequest, error) {
+	c, err := r.scan(r.db.QueryRowContext(ctx, `
+		SELECT `+changeRequestColumns+`
+		FROM cafe_change_requests cr
+		JOIN cafe_profiles c ON c.id = cr.cafe_profile_id
+		WHERE cr.id=$1`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrChangeNotFound
+	}
+	return c, err
+}
+
+// List mengambil permintaan perubahan, terbaru dulu. cafeID 0 berarti
+// semua cafe, status kosong berarti semua status.
+func (r *ChangeRequestRepository) List(ctx context.Context, cafeID int, status string, limit, offset int) ([]models.ChangeRequest, int, error) {
+	where := []string{"TRUE"}
+	args := []interface{}{}
+	if cafeID > 0 {
+		args = append(args, cafeID)
+		where = append(where, fmt.Sprintf("cr.cafe_profile_id=$%d", len(args)))
+	}
+	if status != "" {
+		args = append(args, status)
+		where = append(where, fmt.Sprintf("cr.status=$%d", len(args)))
+	}
+	cond := strings.Join(where, " AND ")
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM cafe_change_requests cr WHERE "+cond, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, limit, offset)
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT `+changeRequestColumns+`
+		FROM cafe_change_requests cr
+		JOIN cafe_profiles c ON c.id = cr.cafe_profile_id
+		WHERE %s
+		ORDER BY cr.created_at DESC, cr.id DESC
+		LIMIT $%d OFFSET $%d`, cond, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []models.ChangeRequest{}
+	for rows.Next() {
+		c, err := r.scan(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		list = append(list, *c)
+	}
+	return list, total, rows.Err()
+}
+
+// =========================
+// Setujui / tolak perubahan
+// =========================
+// Approve menerapkan perubahan ke data live dan menandai permintaan
+// approved dalam satu transaksi
+func (r *ChangeRequestRepository) Approve(ctx context.Context, id, adminID int, now time.Time) (*models.ChangeRequest, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var cafeID int
+	var entityType, entityID, status string
+	var changes []byte
+	err = tx.QueryRowContext(ctx, `
+		SELECT cafe_profile_id, entity_type, entity_id, status, changes
+		FROM cafe_change_requests WHERE id=$1 FOR UPDATE`, id,
+	).Scan(&cafeID, &entityType, &entityID, &status, &changes)
+	if err == sql.ErrNoRows {
+		return nil, ErrChangeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status != models.ChangePending {
+		return nil, ErrChangeNotPending
+	}
+
+	switch entityType {
+	case models.ChangeEntityCafe:
+		var c models.ProfileChanges
+		if err := json.Unmarshal(changes, &c); err != nil {
+			return nil, err
+		}
+		err = applyProfile(ctx, tx, cafeID, c)
+	case models.ChangeEntityMenu:
+		var d models.DiscountChange
+		if err := json.Unmarshal(changes, &d); err != nil {
+			return nil, err
+		}
+		err = applyDiscount(ctx, tx, entityID, d)
+	default:
+		err = fmt.Errorf("entity_type tidak dikenal: %s", entityType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.review(ctx, tx, id, models.ChangeApproved, "", adminID, now); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+// Reject menandai permintaan ditolak; data live tidak berubah
+func (r *ChangeRequestRepository) Reject(ctx context.Context, id, adminID int, reason string, now time.Time) (*models.ChangeRequest, error) {
+	err := r.review(ctx, r.db, id, models.ChangeRejected, reason, adminID, now)
+	if err == ErrChangeNotPending {
+		if _, getErr := r.GetByID(ctx, id); getErr == ErrChangeNotFound {
+			return nil, ErrChangeNotFound
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+func (r *ChangeRequestRepository) review(ctx context.Context, q execer, id int, status, reason string, adminID int, now time.Time) error {
+	res, err := q.ExecContext(ctx, `
+		UPDATE cafe_change_requests
+		SET status=$2, reason=NULLIF($3, ''), reviewed_by=$4, reviewed_at=$5, updated_at=$5
+		WHERE id=$1 AND status='pending'`,
+		id, status, reason, adminID, dbtime.Format(now.In(r.location)),
+	)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrChangeNotPending
+	}
+	return nil
+}
+
+// OwnerID mengembalikan user pemilik cafe, penerima notifikasi hasil review
+func (r *ChangeRequestRepository) OwnerID(ctx context.Context, cafeID int) (int, error) {
+	var userID int
+	err := r.db.QueryRowContext(ctx, `SELECT user_id FROM cafe_profiles WHERE id=$1`, cafeID).Scan(&userID)
+	return userID, err
+}
@@ -0,0 +1,431 @@
+package repository
+
+import (
+	"backend/dbtime"
+	"backend/models"
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var ErrThreadNotFound = errors.New("percakapan tidak ditemukan")
+
+// ChatStore adalah akses thread & pesan chat
+type ChatStore interface {
+	ChatUsers(ctx context.Context, ids []int) ([]int, error)
+	AdminIDs(ctx context.Context) ([]int, error)
+	IsParticipant(ctx context.Context, threadID, userID int) (bool, error)
+	AddParticipant(ctx context.Context, threadID, userID int, now time.Time) error
+	ParticipantIDs(ctx context.Context, threadID int) ([]int, error)
+	CreateThread(ctx context.Context, subject, entityType, entityID string, creatorID int, participantIDs []int, now time.Time) (int, error)
+	FindByEntity(ctx context.Context, entityType, entityID string, userID int) (int, error)
+	GetThread(ctx context.Context, id, userID int) (*models.ChatThread, error)
+	ListThreads(ctx context.Context, userID, limit, offset int) ([]models.ChatThread, error)
+	UnreadCounts(ctx context.Context, userID int) (map[int]int, error)
+	Messages(ctx context.Context, threadID int, afterID, beforeID int64, limit int) ([]models.ChatMessage, error)
+	PostMessage(ctx context.Context, threadID, senderID int, body string, now time.Time) (*models.ChatMessage, error)
+	MarkRead(ctx context.Context, threadID, userID int, messageID int64) (int64, error)
+}
+
+var _ ChatStore = (*ChatRepository)(nil)
+
+type ChatRepository struct {
+	db       *sql.DB
+	location *time.Location
+}
+
+func NewChatRepository(db *sql.DB, location *time.Location) *ChatRepository {
+	return &ChatRepository{db: db, location: location}
+}
+
+// =========================
+// Peserta chat
+// =========================
+// ChatUsers mengembalikan id yang valid sebagai peserta chat (akun admin
+// atau cafe yang masih ada) dari daftar ids
+func (r *ChatRepository) ChatUsers(ctx context.Context, ids []int) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT id FROM users WHERE id = ANY($1) AND role IN ('admin','cafe') ORDER BY id",
+		pq.Array(ids),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	valid := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		valid = append(valid, id)
+	}
+	return valid, rows.Err()
+}
+
+// AdminIDs mengembalikan semua akun super admin
+func (r *ChatRepository) AdminIDs(ctx context.Context) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id FROM users WHERE role='admin' ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *ChatRepository) IsParticipant(ctx context.Context, threadID, userID int) (bool, error) {
+	var ok bool
+	err := r.db.QueryRowContext(ctx,
+		"SELECT EXISTS(SELECT 1 FROM chat_participants WHERE thread_id=$1 AND user_id=$2)",
+		threadID, userID,
+	).Scan(&ok)
+	return ok, err
+}
+
+// AddParticipant menambahkan user ke thread; tidak error jika sudah ada
+func (r *ChatRepository) AddParticipant(ctx context.Context, threadID, userID int, now time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO chat_participants (thread_id, user_id, joined_at)
+		VALUES ($1,$2,$3)
+		ON CONFLICT (thread_id, user_id) DO NOTHING`,
+		threadID, userID, dbtime.Format(now.In(r.location)),
+	)
+	return err
+}
+
+// ParticipantIDs mengembalikan id semua peserta thread
+func (r *ChatRepository) ParticipantIDs(ctx context.Context, threadID int) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT user_id FROM chat_participants WHERE thread_id=$1", threadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// =========================
+// Thread
+// =========================
+// CreateThread membuat thread baru beserta pesertanya (termasuk pembuat)
+func (r *ChatRepository) CreateThread(ctx context.Context, subject, entityType, entityID string, creatorID int, participantIDs []int, now time.Time) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	ts := dbtime.Format(now.In(r.location))
+	var id int
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO chat_threads (subject, entity_type, entity_id, created_by, created_at)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5) RETURNING id`,
+		subject, entityType, entityID, creatorID, ts,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO chat_participants (thread_id, user_id, joined_at)
+		SELECT $1, u, $3 FROM unnest($2::int[]) AS u
+		ON CONFLICT (thread_id, user_id) DO NOTHING`,
+		id, pq.Array(append([]int{creatorID}, participantIDs...)), ts,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// FindByEntity mencari thread terbaru untuk entitas yang diikuti user
+func (r *ChatRepository) FindByEntity(ctx context.Context, entityType, entityID string, userID int) (int, error) {
+	var id int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT t.id FROM chat_threads t
+		JOIN chat_participants p ON p.thread_id = t.id AND p.user_id = $3
+		WHERE t.entity_type=$1 AND t.entity_id=$2
+		ORDER BY t.id DESC LIMIT 1`,
+		entityType, entityID, userID,
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, ErrThreadNotFound
+	}
+	return id, err
+}
+
+// GetThread mengambil thread beserta peserta & read receipt-nya
+func (r *ChatRepository) GetThread(ctx context.Context, id, userID int) (*models.ChatThread, error) {
+	threads, err := r.listThreads(ctx, `WHERE t.id=$1`, []interface{}{id}, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(threads) == 0 {
+		return nil, ErrThreadNotFound
+	}
+	t := &threads[0]
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT p.user_id, u.username, u.role, p.last_read_message_id
+		FROM chat_participants p
+		JOIN users u ON u.id = p.user_id
+		WHERE p.thread_id=$1
+		ORDER BY p.joined_at, p.user_id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	t.Participants = []models.ChatParticipant{}
+	for rows.Next() {
+		var p models.ChatParticipant
+		if err := rows.Scan(&p.UserID, &p.Username, &p.Role, &p.LastReadMessageID); err != nil {
+			return nil, err
+		}
+		t.Participants = append(t.Participants, p)
+	}
+	return t, rows.Err()
+}
+
+// ListThreads mengambil thread yang diikuti user, aktivitas terbaru dulu
+func (r *ChatRepository) ListThreads(ctx context.Context, userID, limit, offset int) ([]models.ChatThread, error) {
+	return r.listThreads(ctx, `
+		JOIN chat_participants me ON me.thread_id = t.id AND me.user_id = $1
+		ORDER BY COALESCE(t.last_message_at, t.created_at) DESC, t.id DESC
+		LIMIT $2 OFFSET $3`, []interface{}{userID, limit, offset}, userID)
+}
+
+// listThreads menjalankan query thread dengan pesan terakhir & jumlah
+// pesan belum dibaca untuk userID. tail dimulai dari $1 = args[0].
+func (r *ChatRepository) listThreads(ctx context.Context, tail string, args []interface{}, userID int) ([]models.ChatThread, error) {
+	args = append(args, userID)
+	userParam := "$" + strconv.Itoa(len(args))
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT t.id, t.subject, COALESCE(t.entity_type, ''), COALESCE(t.entity_id, ''),
+			t.created_by, t.created_at, t.last_message_at,
+			COALESCE((
+				SELECT COUNT(*) FROM chat_messages m
+				WHERE m.thread_id = t.id
+					AND (m.sender_id IS NULL OR m.sender_id <> `+userParam+`)
+					AND m.id > COALESCE((
+						SELECT last_read_message_id FROM chat_participants
+						WHERE thread_id = t.id AND user_id = `+userParam+`), 0)
+			), 0),
+			lm.id, lm.sender_id, COALESCE(lu.username, ''), lm.body, lm.created_at
+		FROM chat_threads t
+		LEFT JOIN LATERAL (
+			SELECT id, sender_id, body, created_at FROM chat_messages
+			WHERE thread_id = t.id ORDER BY id DESC LIMIT 1
+		) lm ON true
+		LEFT JOIN users lu ON lu.id = lm.sender_id
+		`+tail, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	threads := []models.ChatThread{}
+	for rows.Next() {
+		var t models.ChatThread
+		var createdBy, lastSender sql.NullInt64
+		var lastMessageAt, lastCreatedAt pq.NullTime
+		var lastID sql.NullInt64
+		var lastName string
+		var lastBody sql.NullString
+		err := rows.Scan(&t.ID, &t.Subject, &t.EntityType, &t.EntityID, &createdBy, &t.CreatedAt, &lastMessageAt,
+			&t.Unread, &lastID, &lastSender, &lastName, &lastBody, &lastCreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if createdBy.Valid {
+			id := int(createdBy.Int64)
+			t.CreatedBy = &id
+		}
+		t.CreatedAt = dbtime.WallClock(t.CreatedAt, r.location)
+		t.LastMessageAt = dbtime.WallClockNull(lastMessageAt, r.location)
+		if lastID.Valid {
+			m := &models.ChatMessage{
+				ID:         lastID.Int64,
+				ThreadID:   t.ID,
+				SenderName: lastName,
+				Body:       lastBody.String,
+				CreatedAt:  dbtime.WallClock(lastCreatedAt.Time, r.location),
+			}
+			if lastSender.Valid {
+				id := int(lastSender.Int64)
+				m.SenderID = &id
+			}
+			t.LastMessage = m
+		}
+		threads = append(threads, t)
+	}
+	return threads, rows.Err()
+}
+
+// UnreadCounts mengembalikan jumlah pesan belum dibaca per thread (hanya
+// thread yang punya pesan belum dibaca)
+func (r *ChatRepository) UnreadCounts(ctx context.Context, userID int) (map[int]int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT m.thread_id, COUNT(*)
+		FROM chat_participants p
+		JOIN chat_messages m ON m.thread_id = p.thread_id AND m.id > p.last_read_message_id
+		WHERE p.user_id=$1 AND (m.sender_id IS NULL OR m.sender_id <> $1)
+		GROUP BY m.thread_id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[int]int{}
+	for rows.Next() {
+		var threadID, n int
+		if err := rows.Scan(&threadID, &n); err != nil {
+			return nil, err
+		}
+		counts[threadID] = n
+	}
+	return counts, rows.Err()
+}
+
+// =========================
+// Pesan
+// =========================
+// Messages mengambil pesan thread terurut dari yang lama. afterID > 0
+// untuk polling pesan baru; beforeID > 0 untuk memuat pesan lebih lama.
+func (r *ChatRepository) Messages(ctx context.Context, threadID int, afterID, beforeID int64, limit int) ([]models.ChatMessage, error) {
+	query := `
+		SELECT m.id, m.thread_id, m.sender_id, COALESCE(u.username, ''), m.body, m.created_at
+		FROM chat_messages m
+		LEFT JOIN users u ON u.id = m.sender_id
+		WHERE m.thread_id=$1`
+	args := []interface{}{threadID}
+	order := "DESC"
+	if afterID > 0 {
+		args = append(args, afterID)
+		query += " AND m.id > $2"
+		order = "ASC"
+	} else if beforeID > 0 {
+		args = append(args, beforeID)
+		query += " AND m.id < $2"
+	}
+	args = append(args, limit)
+	query += " ORDER BY m.id " + order + " LIMIT $" + strconv.Itoa(len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []models.ChatMessage{}
+	for rows.Next() {
+		m, err := r.scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, *m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Halaman terbaru diambil DESC lalu dibalik supaya tetap urut waktu
+	if order == "DESC" {
+		for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+			messages[i], messages[j] = messages[j], messages[i]
+		}
+	}
+	return messages, nil
+}
+
+func (r *ChatRepository) scanMessage(row interface{ Scan(...interface{}) error }) (*models.ChatMessage, error) {
+	m := &models.ChatMessage{}
+	var senderID sql.NullInt64
+	if err := row.Scan(&m.ID, &m.ThreadID, &senderID, &m.SenderName, &m.Body, &m.CreatedAt); err != nil {
+		return nil, err
+	}
+	if senderID.Valid {
+		id := int(senderID.Int64)
+		m.SenderID = &id
+	}
+	m.CreatedAt = dbtime.WallClock(m.CreatedAt, r.location)
+	return m, nil
+}
+
+// PostMessage menyimpan pesan; pesan sendiri otomatis dianggap sudah dibaca
+func (r *ChatRepository) PostMessage(ctx context.Context, threadID, senderID int, body string, now time.Time) (*models.ChatMessage, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	ts := dbtime.Format(now.In(r.location))
+	m, err := r.scanMessage(tx.QueryRowContext(ctx, `
+		WITH m AS (
+			INSERT INTO chat_messages (thread_id, sender_id, body, created_at)
+			VALUES ($1,$2,$3,$4)
+			RETURNING id, thread_id, sender_id, body, created_at
+		)
+		SELECT m.id, m.thread_id, m.sender_id, COALESCE(u.username, ''), m.body, m.created_at
+		FROM m LEFT JOIN users u ON u.id = m.sender_id`,
+		threadID, senderID, body, ts,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE chat_threads SET last_message_at=$2 WHERE id=$1", threadID, ts); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE chat_participants SET last_read_message_id=$3 WHERE thread_id=$1 AND user_id=$2",
+		threadID, senderID, m.ID,
+	); err != nil {
+		return nil, err
+	}
+	return m, tx.Commit()
+}
+
+// MarkRead memajukan read receipt user sampai messageID (tidak pernah
+// mundur, dan dibatasi pesan terakhir di thread). Mengembalikan posisi
+// read receipt setelah update.
+func (r *ChatRepository) MarkRead(ctx context.Context, threadID, userID int, messageID int64) (int64, error) {
+	var lastRead int64
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE chat_participants
+		SET last_read_message_id = GREATEST(last_read_message_id, LEAST($3,
+			COALESCE((SELECT MAX(id) FROM chat_messages WHERE thread_id=$1), 0)))
+		WHERE thread_id=$1 AND user_id=$2
+		RETURNING last_read_message_id`,
+		threadID, userID, messageID,
+	).Scan(&lastRead)
+	if err == sql.ErrNoRows {
+		return 0, ErrThreadNotFound
+	}
+	return lastRead, err
+}
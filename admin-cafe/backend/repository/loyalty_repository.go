@@ -0,0 +1,730 @@
+package repository
+
+import (
+	"backend/dbtime"
+	"backend/models"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrRewardNotFound      = errors.New("hadiah tidak ditemukan")
+	ErrInsufficientPoints  = errors.New("poin tidak cukup")
+	ErrInsufficientStamps  = errors.New("stamp belum cukup")
+	ErrLoyaltyDisabled     = errors.New("cafe ini belum mengaktifkan program loyalty")
+	ErrStampCardDisabled   = errors.New("cafe ini tidak memakai kartu stamp")
+	errLoyaltyOrderMissing = errors.New("pesanan tidak ditemukan, belum selesai, atau sudah di-refund")
+)
+
+// Rentang "poin segera kedaluwarsa" pada saldo
+const expiringWindow = 30 * 24 * time.Hour
+
+// Pesanan selesai yang belum mendapat poin dicari sejauh ini ke belakang
+const earnBackfillWindow = 7 * 24 * time.Hour
+
+// LoyaltyStore adalah akses program loyalitas. Job terjadwal (Process &
+// RunScheduler) dipanggil langsung dari main.go.
+type LoyaltyStore interface {
+	GetSettings(ctx context.Context, cafeID int) (*models.LoyaltySettings, error)
+	SaveSettings(ctx context.Context, s *models.LoyaltySettings) error
+	ListRewards(ctx context.Context, cafeID int, activeOnly bool) ([]models.LoyaltyReward, error)
+	SaveReward(ctx context.Context, rw *models.LoyaltyReward) error
+	Balances(ctx context.Context, userID int, now time.Time) ([]models.LoyaltyBalance, error)
+	Balance(ctx context.Context, userID, cafeID int, now time.Time) (*models.LoyaltyBalance, error)
+	History(ctx context.Context, cafeID, userID, limit, offset int) ([]models.LoyaltyTransaction, error)
+	EarnForOrder(ctx context.Context, orderID int, now time.Time) (earned bool, err error)
+	ReverseForOrder(ctx context.Context, orderID int, now time.Time) (reversed bool, err error)
+	RedeemReward(ctx context.Context, userID, cafeID, rewardID int, now time.Time) (*models.LoyaltyTransaction, error)
+	RedeemStamps(ctx context.Context, userID, cafeID int, now time.Time) (*models.LoyaltyTransaction, error)
+}
+
+var _ LoyaltyStore = (*LoyaltyRepository)(nil)
+
+type LoyaltyRepository struct {
+	db       *sql.DB
+	location *time.Location
+}
+
+func NewLoyaltyRepository(db *sql.DB, location *time.Location) *LoyaltyRepository {
+	return &LoyaltyRepository{db: db, location: location}
+}
+
+// =========================
+// Pengaturan loyalty cafe
+// =========================
+// Cafe yang belum mengatur mendapat nilai default (nonaktif)
+func (r *LoyaltyRepository) GetSettings(ctx context.Context, cafeID int) (*models.LoyaltySettings, error) {
+	return getLoyaltySettings(ctx, r.db, cafeID)
+}
+
+func getLoyaltySettings(ctx context.Context, q queryer, cafeID int) (*models.LoyaltySettings, error) {
+	s := &models.LoyaltySettings{
+		CafeID:          cafeID,
+		PointsPerUnit:   1,
+		AmountUnit:      10000,
+		PointExpiryDays: 365,
+		StampsRequired:  9,
+		StampReward:     "1 minuman gratis",
+	}
+	err := q.QueryRowContext(ctx, `
+		SELECT enabled, points_per_unit, amount_unit::float8, point_expiry_days,
+			stamp_enabled, stamps_required, stamp_reward
+		FROM loyalty_settings WHERE cafe_profile_id=$1`, cafeID,
+	).Scan(&s.Enabled, &s.PointsPerUnit, &s.AmountUnit, &s.PointExpiryDays,
+		&s.StampEnabled, &s.StampsRequired, &s.StampReward)
+	if err == sql.ErrNoRows {
+		return s, nil
+	}
+	return s, err
+}
+
+// SaveSettings menyimpan aturan baru. Poin yang sudah didapat tetap
+// memakai tanggal kedaluwarsa saat diperoleh.
+func (r *LoyaltyRepository) SaveSettings(ctx context.Context, s *models.LoyaltySettings) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO loyalty_settings (cafe_profile_id, enabled, points_per_unit, amount_unit,
+			point_expiry_days, stamp_enabled, stamps_required, stamp_reward, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,CURRENT_TIMESTAMP)
+		ON CONFLICT (cafe_profile_id) DO UPDATE SET
+			enabled=EXCLUDED.enabled, points_per_unit=EXCLUDED.points_per_unit,
+			amount_unit=EXCLUDED.amount_unit, point_expiry_days=EXCLUDED.point_expiry_days,
+			stamp_enabled=EXCLUDED.stamp_enabled, stamps_required=EXCLUDED.stamps_required,
+			stamp_reward=EXCLUDED.stamp_reward, updated_at=CURRENT_TIMESTAMP`,
+		s.CafeID, s.Enabled, s.PointsPerUnit, s.AmountUnit,
+		s.PointExpiryDays, s.StampEnabled, s.StampsRequired, s.StampReward,
+	)
+	return err
+}
+
+// =========================
+// Katalog hadiah
+// =========================
+func (r *LoyaltyRepository) ListRewards(ctx context.Context, cafeID int, activeOnly bool) ([]models.LoyaltyReward, error) {
+	query := "SELECT id, cafe_profile_id, name, points_cost, active FROM loyalty_rewards WHERE cafe_profile_id=$1"
+	if activeOnly {
+		query += " AND active = true"
+	}
+	rows, err := r.db.QueryContext(ctx, query+" ORDER BY points_cost, id", cafeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rewards := []models.LoyaltyReward{}
+	for rows.Next() {
+		var rw models.LoyaltyReward
+		if err := rows.Scan(&rw.ID, &rw.CafeID, &rw.Name, &rw.PointsCost, &rw.Active); err != nil {
+			return nil, err
+		}
+		rewards = append(rewards, rw)
+	}
+	return rewards, rows.Err()
+}
+
+// SaveReward membuat hadiah baru (ID 0) atau mengubah hadiah milik cafe.
+// Hadiah tidak dihapus karena direferensikan ledger; nonaktifkan saja.
+func (r *LoyaltyRepository) SaveReward(ctx context.Context, rw *models.LoyaltyReward) error {
+	if rw.ID == 0 {
+		return r.db.QueryRowContext(ctx, `
+			INSERT INTO loyalty_rewards (cafe_profile_id, name, points_cost, active)
+			VALUES ($1,$2,$3,$4) RETURNING id`,
+			rw.CafeID, rw.Name, rw.PointsCost, rw.Active,
+		).Scan(&rw.ID)
+	}
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE loyalty_rewards SET name=$3, points_cost=$4, active=$5
+		WHERE id=$1 AND cafe_profile_id=$2`,
+		rw.ID, rw.CafeID, rw.Name, rw.PointsCost, rw.Active,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrRewardNotFound
+	}
+	return nil
+}
+
+// =========================
+// Saldo
+// =========================
+// Kedaluwarsa memakai FIFO: poin yang ditukar/kedaluwarsa dianggap
+// mengurangi poin tertua lebih dulu. Poin yang jatuh tempo tetapi belum
+// dicatat scheduler sebagai transaksi expire tetap tidak ikut dihitung.
+type balanceRow struct {
+	models.LoyaltyBalance
+	total    int
+	consumed int
+	dueNow   int
+	dueSoon  int
+}
+
+func (b *balanceRow) finish() models.LoyaltyBalance {
+	pending := maxInt(0, b.dueNow-b.consumed)
+	b.Points = b.total - pending
+	b.ExpiringPoints = maxInt(0, b.dueSoon-b.consumed) - pending
+	return b.LoyaltyBalance
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func (r *LoyaltyRepository) balances(ctx context.Context, q queryer, userID, cafeID int, now time.Time) ([]models.LoyaltyBalance, error) {
+	args := []interface{}{userID, dbtime.Format(now), dbtime.Format(now.Add(expiringWindow))}
+	filter := ""
+	if cafeID > 0 {
+		args = append(args, cafeID)
+		filter = " AND lt.cafe_profile_id=$4"
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT lt.cafe_profile_id, cp.nama,
+			COALESCE(SUM(lt.points), 0), COALESCE(SUM(lt.stamps), 0),
+			COALESCE(-SUM(lt.points) FILTER (WHERE lt.points < 0), 0),
+			COALESCE(SUM(lt.points) FILTER (WHERE lt.type='earn' AND lt.expires_at <= $2), 0),
+			COALESCE(SUM(lt.points) FILTER (WHERE lt.type='earn' AND lt.expires_at <= $3), 0),
+			COALESCE(ls.stamps_required, 9), CASE WHEN ls.stamp_enabled THEN ls.stamp_reward ELSE '' END
+		FROM loyalty_transactions lt
+		JOIN cafe_profiles cp ON cp.id = lt.cafe_profile_id
+		LEFT JOIN loyalty_settings ls ON ls.cafe_profile_id = lt.cafe_profile_id
+		WHERE lt.user_id=$1`+filter+`
+		GROUP BY lt.cafe_profile_id, cp.nama, ls.stamps_required, ls.stamp_enabled, ls.stamp_reward
+		ORDER BY cp.nama`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.LoyaltyBalance{}
+	for rows.Next() {
+		var b balanceRow
+		var reward sql.NullString
+		if err := rows.Scan(&b.CafeID, &b.CafeNama, &b.total, &b.Stamps, &b.consumed,
+			&b.dueNow, &b.dueSoon, &b.StampsRequired, &reward); err != nil {
+			return nil, err
+		}
+		b.StampReward = reward.String
+		list = append(list, b.finish())
+	}
+	return list, rows.Err()
+}
+
+// Balances mengembalikan saldo customer di semua cafe tempat ia punya transaksi
+func (r *LoyaltyRepository) Balances(ctx context.Context, userID int, now time.Time) ([]models.LoyaltyBalance, error) {
+	return r.balances(ctx, r.db, userID, 0, now.In(r.location))
+}
+
+// Balance mengembalikan saldo customer di satu cafe (nol jika belum ada transaksi)
+func (r *LoyaltyRepository) Balance(ctx context.Context, userID, cafeID int, now time.Time) (*models.LoyaltyBalance, error) {
+	return r.balance(ctx, r.db, userID, cafeID, now.In(r.location))
+}
+
+func (r *LoyaltyRepository) balance(ctx context.Context, q queryer, userID, cafeID int, now time.Time) (*models.LoyaltyBalance, error) {
+	list, err := r.balances(ctx, q, userID, cafeID, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) > 0 {
+		return &list[0], nil
+	}
+
+	settings, err := getLoyaltySettings(ctx, q, cafeID)
+	if err != nil {
+		return nil, err
+	}
+	b := &models.LoyaltyBalance{CafeID: cafeID, StampsRequired: settings.StampsRequired}
+	if settings.StampEnabled {
+		b.StampReward = settings.StampReward
+	}
+	return b, nil
+}
+
+// =========================
+// Riwayat transaksi
+// =========================
+const loyaltyTxColumns = `lt.id, lt.cafe_profile_id, lt.user_id, COALESCE(NULLIF(u.display_name, ''), u.username, ''),
+	lt.type, lt.points, lt.stamps, lt.order_id, lt.reward_id, COALESCE(lt.redeem_code, ''),
+	COALESCE(lt.description, ''), lt.expires_at, lt.created_at`
+
+// History mengembalikan ledger terbaru dulu. userID 0 berarti semua
+// customer cafe (untuk dashboard cafe).
+func (r *LoyaltyRepository) History(ctx context.Context, cafeID, userID, limit, offset int) ([]models.LoyaltyTransaction, error) {
+	args := []interface{}{cafeID, limit, offset}
+	filter := ""
+	if userID > 0 {
+		args = append(args, userID)
+		filter = " AND lt.user_id=$4"
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+loyaltyTxColumns+`
+		FROM loyalty_transactions lt
+		LEFT JOIN users u ON u.id = lt.user_id
+		WHERE lt.cafe_profile_id=$1`+filter+`
+		ORDER BY lt.created_at DESC, lt.id DESC
+		LIMIT $2 OFFSET $3`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.LoyaltyTransaction{}
+	for rows.Next() {
+		var t models.LoyaltyTransaction
+		var orderID, rewardID sql.NullInt64
+		var expires pq.NullTime
+		var created time.Time
+		if err := rows.Scan(&t.ID, &t.CafeID, &t.UserID, &t.CustomerName, &t.Type, &t.Points, &t.Stamps,
+			&orderID, &rewardID, &t.RedeemCode, &t.Description, &expires, &created); err != nil {
+			return nil, err
+		}
+		if orderID.Valid {
+			id := int(orderID.Int64)
+			t.OrderID = &id
+		}
+		if rewardID.Valid {
+			id := int(rewardID.Int64)
+			t.RewardID = &id
+		}
+		t.ExpiresAt = dbtime.WallClockNull(expires, r.location)
+		t.CreatedAt = dbtime.WallClock(created, r.location)
+		list = append(list, t)
+	}
+	return list, rows.Err()
+}
+
+// =========================
+// Dapat poin dari pesanan selesai
+// =========================
+// EarnForOrder aman dipanggil berulang: satu pesanan hanya menghasilkan
+// satu transaksi earn. earned bernilai false jika tidak ada yang dicatat.
+// Pesanan yang pembayarannya sudah di-refund tidak mendapat poin.
+func (r *LoyaltyRepository) EarnForOrder(ctx context.Context, orderID int, now time.Time) (earned bool, err error) {
+	now = now.In(r.location)
+
+	var cafeID int
+	var customerID sql.NullInt64
+	var total float64
+	var status, paymentStatus string
+	err = r.db.QueryRowContext(ctx, `
+		SELECT cafe_profile_id, customer_user_id, total::float8, status, payment_status
+		FROM orders WHERE id=$1`, orderID,
+	).Scan(&cafeID, &customerID, &total, &status, &paymentStatus)
+	if err == sql.ErrNoRows || (err == nil && (status != models.OrderCompleted || paymentStatus == "refunded")) {
+		return false, errLoyaltyOrderMissing
+	}
+	if err != nil {
+		return false, err
+	}
+	// Pesanan kasir tanpa akun customer tidak mendapat poin
+	if !customerID.Valid {
+		return false, nil
+	}
+
+	settings, err := r.GetSettings(ctx, cafeID)
+	if err != nil {
+		return false, err
+	}
+	if !settings.Enabled {
+		return false, nil
+	}
+	points := settings.PointsFor(total)
+	stamps := 0
+	if settings.StampEnabled {
+		stamps = 1
+	}
+	if points == 0 && stamps == 0 {
+		return false, nil
+	}
+
+	var expiresAt interface{}
+	if points > 0 && settings.PointExpiryDays > 0 {
+		expiresAt = dbtime.Format(now.AddDate(0, 0, settings.PointExpiryDays))
+	}
+
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO loyalty_transactions (cafe_profile_id, user_id, type, points, stamps,
+			order_id, description, expires_at, created_at)
+		VALUES ($1,$2,'earn',$3,$4,$5,$6,$7,$8)
+		ON CONFLICT (order_id) WHERE type='earn' DO NOTHING`,
+		cafeID, customerID.Int64, points, stamps, orderID,
+		fmt.Sprintf("Pesanan #%d", orderID), expiresAt, dbtime.Format(now),
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// =========================
+// Tarik poin pesanan yang di-refund
+// =========================
+// ReverseForOrder mencatat transaksi reverse yang meniadakan poin & stamp
+// dari transaksi earn pesanan. Ledger tetap append-only; saldo bisa
+// negatif jika poinnya sudah ditukar. Aman dipanggil berulang.
+func (r *LoyaltyRepository) ReverseForOrder(ctx context.Context, orderID int, now time.Time) (reversed bool, err error) {
+	now = now.In(r.location)
+
+	var cafeID, userID, points, stamps int
+	err = r.db.QueryRowContext(ctx, `
+		SELECT cafe_profile_id, user_id, points, stamps FROM loyalty_transactions
+		WHERE order_id=$1 AND type='earn'`, orderID,
+	).Scan(&cafeID, &userID, &points, &stamps)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if err := lockCustomer(ctx, tx, cafeID, userID); err != nil {
+		return false, err
+	}
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO loyalty_transactions (cafe_profile_id, user_id, type, points, stamps,
+			order_id, description, created_at)
+		VALUES ($1,$2,'reverse',$3,$4,$5,$6,$7)
+		ON CONFLICT (order_id) WHERE type='reverse' DO NOTHING`,
+		cafeID, userID, -points, -stamps, orderID,
+		fmt.Sprintf("Refund pesanan #%d", orderID), dbtime.Format(now),
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, tx.Commit()
+}
+
+// =========================
+// Tukar poin / stamp
+// =========================
+// lockCustomer menyerialkan penukaran per customer per cafe supaya saldo
+// tidak terpakai dua kali
+func lockCustomer(ctx context.Context, tx *sql.Tx, cafeID, userID int) error {
+	_, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1, $2)", cafeID, userID)
+	return err
+}
+
+func newRedeemCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.EncodeToString(b), nil
+}
+
+// RedeemReward menukar poin customer dengan hadiah. Kode penukaran
+// ditunjukkan ke kasir.
+func (r *LoyaltyRepository) RedeemReward(ctx context.Context, userID, cafeID, rewardID int, now time.Time) (*models.LoyaltyTransaction, error) {
+	now = now.In(r.location)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	settings, err := getLoyaltySettings(ctx, tx, cafeID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.Enabled {
+		return nil, ErrLoyaltyDisabled
+	}
+
+	var reward models.LoyaltyReward
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, name, points_cost FROM loyalty_rewards
+		WHERE id=$1 AND cafe_profile_id=$2 AND active = true`, rewardID, cafeID,
+	).Scan(&reward.ID, &reward.Name, &reward.PointsCost)
+	if err == sql.ErrNoRows {
+		return nil, ErrRewardNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lockCustomer(ctx, tx, cafeID, userID); err != nil {
+		return nil, err
+	}
+	balance, err := r.balance(ctx, tx, userID, cafeID, now)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Points < reward.PointsCost {
+		return nil, ErrInsufficientPoints
+	}
+
+	t := &models.LoyaltyTransaction{
+		CafeID:      cafeID,
+		UserID:      userID,
+		Type:        models.LoyaltyRedeem,
+		Points:      -reward.PointsCost,
+		RewardID:    &reward.ID,
+		Description: "Tukar " + reward.Name,
+	}
+	if err := r.insertRedemption(ctx, tx, t, now); err != nil {
+		return nil, err
+	}
+	return t, tx.Commit()
+}
+
+// RedeemStamps menukar kartu stamp yang sudah penuh
+func (r *LoyaltyRepository) RedeemStamps(ctx context.Context, userID, cafeID int, now time.Time) (*models.LoyaltyTransaction, error) {
+	now = now.In(r.location)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	settings, err := getLoyaltySettings(ctx, tx, cafeID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.Enabled || !settings.StampEnabled {
+		return nil, ErrStampCardDisabled
+	}
+
+	if err := lockCustomer(ctx, tx, cafeID, userID); err != nil {
+		return nil, err
+	}
+	balance, err := r.balance(ctx, tx, userID, cafeID, now)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Stamps < settings.StampsRequired {
+		return nil, ErrInsufficientStamps
+	}
+
+	t := &models.LoyaltyTransaction{
+		CafeID:      cafeID,
+		UserID:      userID,
+		Type:        models.LoyaltyStampRedeem,
+		Stamps:      -settings.StampsRequired,
+		Description: "Kartu stamp: " + settings.StampReward,
+	}
+	if err := r.insertRedemption(ctx, tx, t, now); err != nil {
+		return nil, err
+	}
+	return t, tx.Commit()
+}
+
+func (r *LoyaltyRepository) insertRedemption(ctx context.Context, tx *sql.Tx, t *models.LoyaltyTransaction, now time.Time) error {
+	code, err := newRedeemCode()
+	if err != nil {
+		return err
+	}
+	t.RedeemCode = code
+	t.CreatedAt = now
+
+	return tx.QueryRowContext(ctx, `
+		INSERT INTO loyalty_transactions (cafe_profile_id, user_id, type, points, stamps,
+			reward_id, redeem_code, description, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING id`,
+		t.CafeID, t.UserID, t.Type, t.Points, t.Stamps,
+		t.RewardID, t.RedeemCode, t.Description, dbtime.Format(now),
+	).Scan(&t.ID)
+}
+
+// =========================
+// Scheduler: poin kedaluwarsa & pesanan terlewat
+// =========================
+// LoyaltyRunResult adalah ringkasan satu kali Process
+type LoyaltyRunResult struct {
+	Earned          int
+	Reversed        int
+	ExpiredAccounts int
+	ExpiredPoints   int
+}
+
+// Process mencatat poin yang jatuh tempo sebagai transaksi expire, memberi
+// poin untuk pesanan selesai yang terlewat (mis. server mati tepat setelah
+// status pesanan diubah), dan menarik poin pesanan yang di-refund.
+func (r *LoyaltyRepository) Process(ctx context.Context, now time.Time) (LoyaltyRunResult, error) {
+	var result LoyaltyRunResult
+	now = now.In(r.location)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT o.id FROM orders o
+		JOIN loyalty_settings ls ON ls.cafe_profile_id = o.cafe_profile_id AND ls.enabled
+		WHERE o.status='completed' AND o.payment_status <> 'refunded'
+			AND o.customer_user_id IS NOT NULL AND o.completed_at >= $1
+			AND NOT EXISTS (SELECT 1 FROM loyalty_transactions lt WHERE lt.order_id = o.id AND lt.type='earn')
+		LIMIT 500`,
+		dbtime.Format(now.Add(-earnBackfillWindow)),
+	)
+	if err != nil {
+		return result, err
+	}
+	var orderIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return result, err
+		}
+		orderIDs = append(orderIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+	for _, id := range orderIDs {
+		earned, err := r.EarnForOrder(ctx, id, now)
+		if err != nil {
+			return result, err
+		}
+		if earned {
+			result.Earned++
+		}
+	}
+
+	// Refund bisa datang dari admin maupun webhook gateway; semuanya
+	// ditarik di sini
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT lt.order_id FROM loyalty_transactions lt
+		JOIN orders o ON o.id = lt.order_id
+		WHERE lt.type='earn' AND o.payment_status='refunded'
+			AND NOT EXISTS (SELECT 1 FROM loyalty_transactions rv WHERE rv.order_id = lt.order_id AND rv.type='reverse')
+		LIMIT 500`)
+	if err != nil {
+		return result, err
+	}
+	orderIDs = orderIDs[:0]
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return result, err
+		}
+		orderIDs = append(orderIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+	for _, id := range orderIDs {
+		reversed, err := r.ReverseForOrder(ctx, id, now)
+		if err != nil {
+			return result, err
+		}
+		if reversed {
+			result.Reversed++
+		}
+	}
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT cafe_profile_id, user_id FROM loyalty_transactions
+		WHERE (cafe_profile_id, user_id) IN (
+			SELECT cafe_profile_id, user_id FROM loyalty_transactions
+			WHERE type='earn' AND expires_at <= $1
+		)
+		GROUP BY cafe_profile_id, user_id
+		HAVING COALESCE(SUM(points) FILTER (WHERE type='earn' AND expires_at <= $1), 0)
+			> COALESCE(-SUM(points) FILTER (WHERE points < 0), 0)`,
+		dbtime.Format(now),
+	)
+	if err != nil {
+		return result, err
+	}
+	type account struct{ cafeID, userID int }
+	var accounts []account
+	for rows.Next() {
+		var a account
+		if err := rows.Scan(&a.cafeID, &a.userID); err != nil {
+			rows.Close()
+			return result, err
+		}
+		accounts = append(accounts, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+	for _, a := range accounts {
+		points, err := r.expireAccount(ctx, a.cafeID, a.userID, now)
+		if err != nil {
+			return result, err
+		}
+		if points > 0 {
+			result.ExpiredAccounts++
+			result.ExpiredPoints += points
+		}
+	}
+	return result, nil
+}
+
+func (r *LoyaltyRepository) expireAccount(ctx context.Context, cafeID, userID int, now time.Time) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := lockCustomer(ctx, tx, cafeID, userID); err != nil {
+		return 0, err
+	}
+	var due, consumed int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(points) FILTER (WHERE type='earn' AND expires_at <= $3), 0),
+			COALESCE(-SUM(points) FILTER (WHERE points < 0), 0)
+		FROM loyalty_transactions WHERE cafe_profile_id=$1 AND user_id=$2`,
+		cafeID, userID, dbtime.Format(now),
+	).Scan(&due, &consumed)
+	if err != nil {
+		return 0, err
+	}
+	points := due - consumed
+	if points <= 0 {
+		return 0, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO loyalty_transactions (cafe_profile_id, user_id, type, points, description, created_at)
+		VALUES ($1,$2,'expire',$3,'Poin kedaluwarsa',$4)`,
+		cafeID, userID, -points, dbtime.Format(now),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return points, tx.Commit()
+}
+
+// RunScheduler menjalankan satu putaran Process dan mencatat hasilnya.
+// Dijadwalkan berkala oleh supervisor di main.
+func (r *LoyaltyRepository) RunScheduler(ctx context.Context) error {
+	result, err := r.Process(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if result.Earned > 0 || result.Reversed > 0 || result.ExpiredAccounts > 0 {
+		slog.Info("Loyalty scheduler", "earned_orders", result.Earned, "reversed_orders", result.Reversed,
+			"expired_points", result.ExpiredPoints, "expired_accounts", result.ExpiredAccounts)
+	}
+	return nil
+}
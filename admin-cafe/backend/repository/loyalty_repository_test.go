@@ -0,0 +1,100 @@
+package repository_test
+
+import (
+	"backend/models"
+	"backend/pgtest"
+	"backend/repository"
+	"context"
+	"testing"
+	"time"
+)
+
+// Poin pesanan yang di-refund ditarik kembali dan tidak diberikan ulang
+func TestLoyaltyRefund(t *testing.T) {
+	ctx := context.Background()
+	loc := time.FixedZone("WIB", 7*60*60)
+
+	db := pgtest.Open(t)
+	cafeID := pgtest.CreateCafe(t, db, "kopikita")
+	customerID := pgtest.CreateCustomer(t, db, "budi")
+	menuID := pgtest.CreateMenu(t, db, cafeID, "Latte", 25000)
+
+	loyalty := repository.NewLoyaltyRepository(db, loc)
+	if err := loyalty.SaveSettings(ctx, &models.LoyaltySettings{
+		CafeID: cafeID, Enabled: true, PointsPerUnit: 1, AmountUnit: 10000,
+		StampEnabled: true, StampsRequired: 9,
+	}); err != nil {
+		t.Fatalf("SaveSettings: %v", err)
+	}
+
+	orders := repository.NewOrderRepository(db, loc)
+	now := time.Now()
+	complete := func(t *testing.T) int {
+		t.Helper()
+		o, err := orders.Create(ctx, models.Cart{
+			CafeID: cafeID, OrderType: models.OrderPickup, PickupName: "Budi",
+			Items: []models.CartItem{{MenuID: menuID, Quantity: 2}},
+		}, &customerID, customerID, now)
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		for _, status := range []string{models.OrderPreparing, models.OrderReady, models.OrderCompleted} {
+			if _, err := orders.UpdateStatus(ctx, o.ID, cafeID, "", status, "", now); err != nil {
+				t.Fatalf("UpdateStatus %s: %v", status, err)
+			}
+		}
+		return o.ID
+	}
+	refund := func(t *testing.T, orderID int) {
+		t.Helper()
+		if _, err := db.Exec("UPDATE orders SET payment_status='refunded' WHERE id=$1", orderID); err != nil {
+			t.Fatalf("refund: %v", err)
+		}
+	}
+	balance := func(t *testing.T) *models.LoyaltyBalance {
+		t.Helper()
+		b, err := loyalty.Balance(ctx, customerID, cafeID, now)
+		if err != nil {
+			t.Fatalf("Balance: %v", err)
+		}
+		return b
+	}
+
+	t.Run("refund setelah dapat poin", func(t *testing.T) {
+		orderID := complete(t)
+		if earned, err := loyalty.EarnForOrder(ctx, orderID, now); err != nil || !earned {
+			t.Fatalf("EarnForOrder = %v, %v", earned, err)
+		}
+		if b := balance(t); b.Points != 5 || b.Stamps != 1 {
+			t.Fatalf("Balance = %+v, mau 5 poin 1 stamp", b)
+		}
+
+		refund(t, orderID)
+		result, err := loyalty.Process(ctx, now)
+		if err != nil || result.Reversed != 1 {
+			t.Fatalf("Process = %+v, %v", result, err)
+		}
+		if b := balance(t); b.Points != 0 || b.Stamps != 0 {
+			t.Fatalf("Balance = %+v, mau kosong", b)
+		}
+		// Ditarik sekali saja
+		if reversed, err := loyalty.ReverseForOrder(ctx, orderID, now); err != nil || reversed {
+			t.Fatalf("ReverseForOrder = %v, %v", reversed, err)
+		}
+	})
+
+	t.Run("refund sebelum dapat poin", func(t *testing.T) {
+		orderID := complete(t)
+		refund(t, orderID)
+		if earned, err := loyalty.EarnForOrder(ctx, orderID, now); err == nil || earned {
+			t.Fatalf("EarnForOrder = %v, %v, mau ditolak", earned, err)
+		}
+		result, err := loyalty.Process(ctx, now)
+		if err != nil || result.Earned != 0 {
+			t.Fatalf("Process = %+v, %v", result, err)
+		}
+		if b := balance(t); b.Points != 0 || b.Stamps != 0 {
+			t.Fatalf("Balance = %+v, mau kosong", b)
+		}
+	})
+}
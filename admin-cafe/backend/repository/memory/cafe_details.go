@@ -0,0 +1,167 @@
+package memory
+
+import (
+	"backend/cafes"
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CafeDetails adalah cafes.Store di memori. Foto utama disimpan terpisah
+// dari CafeProfiles, jadi SetMainImage menerima cafe apa pun.
+type CafeDetails struct {
+	mu         sync.Mutex
+	location   *time.Location
+	nextID     int
+	social     map[int][]cafes.SocialMedia
+	hours      map[int]map[string]cafes.OperationalHour
+	facilities map[int][]cafes.Facility
+	gallery    map[int][]cafes.GalleryImage
+	mainImages map[int]string
+}
+
+var _ cafes.Store = (*CafeDetails)(nil)
+
+func NewCafeDetails(loc *time.Location) *CafeDetails {
+	return &CafeDetails{
+		location:   loc,
+		nextID:     1,
+		social:     map[int][]cafes.SocialMedia{},
+		hours:      map[int]map[string]cafes.OperationalHour{},
+		facilities: map[int][]cafes.Facility{},
+		gallery:    map[int][]cafes.GalleryImage{},
+		mainImages: map[int]string{},
+	}
+}
+
+func (s *CafeDetails) id() int {
+	id := s.nextID
+	s.nextID++
+	return id
+}
+
+func (s *CafeDetails) SocialMedia(ctx context.Context, cafeID int) ([]cafes.SocialMedia, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]cafes.SocialMedia{}, s.social[cafeID]...), nil
+}
+
+func (s *CafeDetails) AddSocialMedia(ctx context.Context, cafeID int, m cafes.SocialMedia) (*cafes.SocialMedia, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.social[cafeID]) >= cafes.MaxSocialMedia {
+		return nil, cafes.ErrLimitReached
+	}
+	m.ID = s.id()
+	s.social[cafeID] = append(s.social[cafeID], m)
+	return &m, nil
+}
+
+func (s *CafeDetails) DeleteAllSocialMedia(ctx context.Context, cafeID int) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.social[cafeID])
+	delete(s.social, cafeID)
+	return int64(n), nil
+}
+
+// OperationalHours mengurutkan jadwal minggu..sabtu seperti query SQL
+func (s *CafeDetails) OperationalHours(ctx context.Context, cafeID int) ([]cafes.OperationalHour, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := []cafes.OperationalHour{}
+	for _, hari := range cafes.Days {
+		if h, ok := s.hours[cafeID][hari]; ok {
+			list = append(list, h)
+		}
+	}
+	return list, nil
+}
+
+func (s *CafeDetails) ReplaceOperationalHours(ctx context.Context, cafeID int, hours []cafes.OperationalHour) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	byDay := map[string]cafes.OperationalHour{}
+	for _, h := range hours {
+		byDay[h.Hari] = h
+	}
+	s.hours[cafeID] = byDay
+	return nil
+}
+
+func (s *CafeDetails) SetOperationalHour(ctx context.Context, cafeID int, h cafes.OperationalHour) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.hours[cafeID] == nil {
+		s.hours[cafeID] = map[string]cafes.OperationalHour{}
+	}
+	s.hours[cafeID][h.Hari] = h
+	return nil
+}
+
+func (s *CafeDetails) Status(ctx context.Context, cafeID int, now time.Time) (*cafes.OpenStatus, error) {
+	hours, err := s.OperationalHours(ctx, cafeID)
+	if err != nil {
+		return nil, err
+	}
+	return cafes.StatusAt(hours, now.In(s.location)), nil
+}
+
+func (s *CafeDetails) Facilities(ctx context.Context, cafeID int) ([]cafes.Facility, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]cafes.Facility{}, s.facilities[cafeID]...), nil
+}
+
+func (s *CafeDetails) ReplaceFacilities(ctx context.Context, cafeID int, facilities []cafes.Facility) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.facilities[cafeID] = append([]cafes.Facility{}, facilities...)
+	return nil
+}
+
+func (s *CafeDetails) Gallery(ctx context.Context, cafeID int) ([]cafes.GalleryImage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := append([]cafes.GalleryImage{}, s.gallery[cafeID]...)
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Urutan != list[j].Urutan {
+			return list[i].Urutan < list[j].Urutan
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+func (s *CafeDetails) AddGalleryImage(ctx context.Context, cafeID int, imageURL string, urutan int) (*cafes.GalleryImage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.gallery[cafeID]) >= cafes.MaxGallery {
+		return nil, cafes.ErrLimitReached
+	}
+	g := cafes.GalleryImage{ID: s.id(), ImageURL: imageURL, Urutan: urutan}
+	s.gallery[cafeID] = append(s.gallery[cafeID], g)
+	return &g, nil
+}
+
+func (s *CafeDetails) DeleteGalleryImage(ctx context.Context, cafeID, id int) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := s.gallery[cafeID]
+	for i, g := range list {
+		if g.ID == id {
+			s.gallery[cafeID] = append(list[:i:i], list[i+1:]...)
+			return g.ImageURL, nil
+		}
+	}
+	return "", cafes.ErrNotFound
+}
+
+func (s *CafeDetails) SetMainImage(ctx context.Context, cafeID int, imageURL string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old := s.mainImages[cafeID]
+	s.mainImages[cafeID] = imageURL
+	return old, nil
+}
@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"backend/models"
+	"backend/repository"
+	"context"
+	"database/sql"
+	"math"
+	"sort"
+	"sync"
+)
+
+// CafeProfiles adalah repository.CafeProfileStore di memori. Belum ada
+// ulasan, jam operasional maupun fasilitas, jadi FindNearby memperlakukan
+// setiap cafe dengan rating 0, tutup, dan tanpa fasilitas.
+type CafeProfiles struct {
+	mu       sync.Mutex
+	nextID   int
+	profiles map[int]*models.CafeProfile
+}
+
+var _ repository.CafeProfileStore = (*CafeProfiles)(nil)
+
+func NewCafeProfiles() *CafeProfiles {
+	return &CafeProfiles{nextID: 1, profiles: map[int]*models.CafeProfile{}}
+}
+
+// Add menyimpan profil cafe dan mengembalikan ID-nya. ID 0 diisi
+// otomatis.
+func (s *CafeProfiles) Add(p models.CafeProfile) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p.ID == 0 {
+		p.ID = s.nextID
+	}
+	if p.ID >= s.nextID {
+		s.nextID = p.ID + 1
+	}
+	s.profiles[p.ID] = &p
+	return p.ID
+}
+
+func (s *CafeProfiles) GetByID(ctx context.Context, id int) (*models.CafeProfile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.profiles[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	cp := *p
+	return &cp, nil
+}
+
+func (s *CafeProfiles) GetIDByUserID(ctx context.Context, userID int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := 0
+	for _, p := range s.profiles {
+		if p.UserID != nil && *p.UserID == userID && (id == 0 || p.ID < id) {
+			id = p.ID
+		}
+	}
+	if id == 0 {
+		return 0, sql.ErrNoRows
+	}
+	return id, nil
+}
+
+func (s *CafeProfiles) FindNearby(ctx context.Context, q models.NearbyQuery) ([]models.NearbyCafe, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cafes := []models.NearbyCafe{}
+	// Tanpa ulasan, jam operasional & fasilitas tidak ada cafe yang lolos
+	// filter ini
+	if q.MinRating > 0 || q.OpenNow || len(q.Facilities) > 0 {
+		return cafes, nil
+	}
+	for _, p := range s.profiles {
+		if !p.Verified || p.Latitude == nil || p.Longitude == nil {
+			continue
+		}
+		d := haversineKm(q.Latitude, q.Longitude, *p.Latitude, *p.Longitude)
+		if d > q.RadiusKm {
+			continue
+		}
+		cafes = append(cafes, models.NearbyCafe{
+			ID:         p.ID,
+			Nama:       p.Nama,
+			Alamat:     p.Alamat,
+			MainImage:  p.MainImage,
+			Latitude:   *p.Latitude,
+			Longitude:  *p.Longitude,
+			DistanceKm: math.Round(d*100) / 100,
+		})
+	}
+	sort.Slice(cafes, func(i, j int) bool { return cafes[i].DistanceKm < cafes[j].DistanceKm })
+	if len(cafes) > q.Limit {
+		cafes = cafes[:q.Limit]
+	}
+	return cafes, nil
+}
+
+// haversineKm menghitung jarak dua titik, sama seperti query SQL
+func haversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	const earthRadiusKm = 6371
+	rad := math.Pi / 180
+	a := math.Pow(math.Sin((lat2-lat1)*rad/2), 2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Pow(math.Sin((lng2-lng1)*rad/2), 2)
+	return earthRadiusKm * 2 * math.Asin(math.Sqrt(a))
+}
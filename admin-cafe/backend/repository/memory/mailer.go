@@ -0,0 +1,28 @@
+package memory
+
+import (
+	"backend/mailer"
+	"sync"
+)
+
+// Mailer adalah mailer.Mailer yang hanya mencatat email
+type Mailer struct {
+	mu   sync.Mutex
+	sent []mailer.Message
+}
+
+var _ mailer.Mailer = (*Mailer)(nil)
+
+func (m *Mailer) Send(msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// Sent mengembalikan semua email yang sudah dicatat
+func (m *Mailer) Sent() []mailer.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]mailer.Message(nil), m.sent...)
+}
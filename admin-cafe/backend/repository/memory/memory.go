@@ -0,0 +1,45 @@
+// Package memory berisi implementasi in-memory dari interface repository
+// (UserStore, SessionStore, TokenStore, CafeProfileStore), menus.Store,
+// reviews.Store dan notify.Notifier untuk unit test handler.
+//
+// Perilakunya mengikuti implementasi SQL sejauh yang terlihat dari
+// interface: error "tidak ditemukan" yang sama (sql.ErrNoRows atau
+// ErrNotFound milik package domain), unique violation sebagai *pq.Error
+// 23505, dan urutan daftar yang sama. Kesesuaian ini dicek oleh suite
+// kontrak di package storetest yang juga dijalankan terhadap PostgreSQL.
+// Efek lintas tabel (mis. ulasan dianonimkan saat customer dihapus)
+// tidak ditiru.
+package memory
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// uniqueViolation meniru error PostgreSQL untuk constraint UNIQUE
+func uniqueViolation(constraint string) error {
+	return &pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint", Constraint: constraint}
+}
+
+// newUUID membuat UUID v4 seperti gen_random_uuid()
+func newUUID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// newToken membuat token acak 32 byte (hex)
+func newToken() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,30 @@
+package memory_test
+
+import (
+	"backend/repository/memory"
+	"backend/repository/storetest"
+	"testing"
+	"time"
+)
+
+func TestUsers(t *testing.T) {
+	storetest.Users(t, memory.NewUsers())
+}
+
+func TestSessions(t *testing.T) {
+	users := memory.NewUsers()
+	storetest.Sessions(t, users, memory.NewSessions(users))
+}
+
+func TestTokens(t *testing.T) {
+	storetest.Tokens(t, memory.NewUsers(), memory.NewTokens())
+}
+
+func TestMenus(t *testing.T) {
+	storetest.Menus(t, memory.NewMenus(), 1, 2)
+}
+
+func TestReviews(t *testing.T) {
+	loc := time.FixedZone("WIB", 7*60*60)
+	storetest.Reviews(t, memory.NewReviews(loc), loc, 1, 2, 10)
+}
@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"backend/menus"
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Menus adalah menus.Store di memori
+type Menus struct {
+	mu    sync.Mutex
+	menus map[string]*menus.Menu
+}
+
+var _ menus.Store = (*Menus)(nil)
+
+func NewMenus() *Menus {
+	return &Menus{menus: map[string]*menus.Menu{}}
+}
+
+// Add menyimpan menu apa adanya (mis. yang sudah punya diskon) dan
+// mengembalikan ID-nya. ID kosong diisi UUID baru.
+func (s *Menus) Add(m menus.Menu) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m.ID == "" {
+		m.ID = newUUID()
+	}
+	s.menus[m.ID] = &m
+	return m.ID
+}
+
+// find mengembalikan menu milik cafe, atau nil
+func (s *Menus) find(cafeID int, id string) *menus.Menu {
+	m, ok := s.menus[id]
+	if !ok || m.CafeID != cafeID {
+		return nil
+	}
+	return m
+}
+
+// view menyalin menu seperti hasil scan SQL: tanpa diskon, harga diskon
+// sama dengan harga normal
+func view(m *menus.Menu) *menus.Menu {
+	v := *m
+	if v.Discount <= 0 {
+		v.DiscountedPrice = v.Price
+	}
+	return &v
+}
+
+func (s *Menus) List(ctx context.Context, cafeID int, f menus.Filter) ([]menus.Menu, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	search := strings.ToLower(f.Search)
+	list := []menus.Menu{}
+	for _, m := range s.menus {
+		if m.CafeID != cafeID ||
+			(f.Category != "" && !strings.EqualFold(m.Category, f.Category)) ||
+			(f.Status != "" && m.Status != f.Status) ||
+			(search != "" && !strings.Contains(strings.ToLower(m.Name), search) &&
+				!strings.Contains(strings.ToLower(m.Category), search)) {
+			continue
+		}
+		list = append(list, *view(m))
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Category != list[j].Category {
+			return list[i].Category < list[j].Category
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list, nil
+}
+
+func (s *Menus) Get(ctx context.Context, cafeID int, id string) (*menus.Menu, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := s.find(cafeID, id)
+	if m == nil {
+		return nil, menus.ErrNotFound
+	}
+	return view(m), nil
+}
+
+func (s *Menus) Create(ctx context.Context, cafeID int, in menus.NewMenu) (*menus.Menu, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if in.Status == "" {
+		in.Status = menus.StatusActive
+	}
+	m := &menus.Menu{
+		ID:              newUUID(),
+		CafeID:          cafeID,
+		Name:            in.Name,
+		Price:           in.Price,
+		DiscountedPrice: in.Price,
+		Category:        in.Category,
+		Status:          in.Status,
+		Img:             in.Img,
+	}
+	s.menus[m.ID] = m
+	return view(m), nil
+}
+
+func (s *Menus) Update(ctx context.Context, cafeID int, id string, c menus.Changes) (*menus.Menu, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := s.find(cafeID, id)
+	if m == nil {
+		return nil, menus.ErrNotFound
+	}
+	if c.Name != nil {
+		m.Name = *c.Name
+	}
+	if c.Price != nil {
+		m.Price = *c.Price
+		m.DiscountedPrice = math.Round(m.Price*(1-m.Discount/100)*100) / 100
+	}
+	if c.Category != nil {
+		m.Category = *c.Category
+	}
+	if c.Status != nil {
+		m.Status = *c.Status
+	}
+	if c.Img != nil {
+		m.Img = *c.Img
+	}
+	return view(m), nil
+}
+
+func (s *Menus) Delete(ctx context.Context, cafeID int, id string) (*menus.Menu, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := s.find(cafeID, id)
+	if m == nil {
+		return nil, menus.ErrNotFound
+	}
+	delete(s.menus, id)
+	return view(m), nil
+}
@@ -0,0 +1,36 @@
+package memory
+
+import (
+	"backend/notify"
+	"context"
+	"sync"
+)
+
+// Notification adalah satu panggilan Notify yang dicatat Notifier
+type Notification struct {
+	UserID int
+	Event  string
+	Data   notify.Data
+}
+
+// Notifier adalah notify.Notifier yang hanya mencatat notifikasi
+type Notifier struct {
+	mu   sync.Mutex
+	sent []Notification
+}
+
+var _ notify.Notifier = (*Notifier)(nil)
+
+func (n *Notifier) Notify(ctx context.Context, userID int, event string, data notify.Data) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, Notification{UserID: userID, Event: event, Data: data})
+	return nil
+}
+
+// Sent mengembalikan semua notifikasi yang sudah dicatat
+func (n *Notifier) Sent() []Notification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]Notification(nil), n.sent...)
+}
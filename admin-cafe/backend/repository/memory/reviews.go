@@ -0,0 +1,175 @@
+package memory
+
+import (
+	"backend/reviews"
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Reviews adalah reviews.Store di memori. Waktu disimpan per detik di
+// zona waktu cafe, sama seperti kolom TIMESTAMP yang dibaca Service.
+type Reviews struct {
+	location *time.Location
+
+	mu      sync.Mutex
+	seq     int
+	reviews map[string]*storedReview
+}
+
+type storedReview struct {
+	reviews.Review
+	seq int
+}
+
+var _ reviews.Store = (*Reviews)(nil)
+
+func NewReviews(location *time.Location) *Reviews {
+	return &Reviews{location: location, reviews: map[string]*storedReview{}}
+}
+
+func (s *Reviews) stamp(now time.Time) time.Time {
+	return now.In(s.location).Truncate(time.Second)
+}
+
+func (s *Reviews) find(cafeID int, id string) *storedReview {
+	rv, ok := s.reviews[id]
+	if !ok || rv.CafeID != cafeID {
+		return nil
+	}
+	return rv
+}
+
+func (s *Reviews) List(ctx context.Context, cafeID int, f reviews.Filter) ([]reviews.Review, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var matched []*storedReview
+	for _, rv := range s.reviews {
+		if rv.CafeID != cafeID ||
+			(f.Status != "" && rv.Status != f.Status) ||
+			(f.Rating > 0 && rv.Rating != f.Rating) {
+			continue
+		}
+		matched = append(matched, rv)
+	}
+	// Terbaru dulu; ulasan di detik yang sama diurutkan dari yang
+	// terakhir dibuat
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].Date.Equal(matched[j].Date) {
+			return matched[i].Date.After(matched[j].Date)
+		}
+		return matched[i].seq > matched[j].seq
+	})
+
+	list := []reviews.Review{}
+	for i := f.Offset; i < len(matched) && i < f.Offset+f.Limit; i++ {
+		list = append(list, matched[i].Review)
+	}
+	return list, len(matched), nil
+}
+
+func (s *Reviews) Get(ctx context.Context, cafeID int, id string) (*reviews.Review, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rv := s.find(cafeID, id)
+	if rv == nil {
+		return nil, reviews.ErrNotFound
+	}
+	out := rv.Review
+	return &out, nil
+}
+
+func (s *Reviews) Create(ctx context.Context, cafeID int, in reviews.NewReview, now time.Time) (*reviews.Review, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	userID := in.UserID
+	rv := &storedReview{seq: s.seq, Review: reviews.Review{
+		ID:        newUUID(),
+		CafeID:    cafeID,
+		UserID:    &userID,
+		Name:      in.Name,
+		Email:     in.Email,
+		Rating:    in.Rating,
+		Text:      in.Text,
+		Image:     in.Image,
+		Avatar:    in.Avatar,
+		Status:    reviews.StatusPending,
+		Date:      s.stamp(now),
+		UpdatedAt: s.stamp(now),
+	}}
+	s.reviews[rv.ID] = rv
+	out := rv.Review
+	return &out, nil
+}
+
+// update mengubah satu ulasan milik cafe dan mengembalikan hasilnya
+func (s *Reviews) update(cafeID int, id string, now time.Time, apply func(*reviews.Review)) (*reviews.Review, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rv := s.find(cafeID, id)
+	if rv == nil {
+		return nil, reviews.ErrNotFound
+	}
+	apply(&rv.Review)
+	rv.UpdatedAt = s.stamp(now)
+	out := rv.Review
+	return &out, nil
+}
+
+func (s *Reviews) SetReply(ctx context.Context, cafeID int, id, reply string, now time.Time) (*reviews.Review, error) {
+	return s.update(cafeID, id, now, func(rv *reviews.Review) {
+		rv.Reply = reply
+		if rv.Status == reviews.StatusPending {
+			rv.Status = reviews.StatusApproved
+		}
+	})
+}
+
+func (s *Reviews) ClearReply(ctx context.Context, cafeID int, id string, now time.Time) (*reviews.Review, error) {
+	return s.update(cafeID, id, now, func(rv *reviews.Review) { rv.Reply = "" })
+}
+
+func (s *Reviews) SetStatus(ctx context.Context, cafeID int, id, status string, now time.Time) (*reviews.Review, error) {
+	return s.update(cafeID, id, now, func(rv *reviews.Review) { rv.Status = status })
+}
+
+func (s *Reviews) Delete(ctx context.Context, cafeID int, id string) (*reviews.Review, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rv := s.find(cafeID, id)
+	if rv == nil {
+		return nil, reviews.ErrNotFound
+	}
+	delete(s.reviews, id)
+	out := rv.Review
+	return &out, nil
+}
+
+func (s *Reviews) Stats(ctx context.Context, cafeID int) (*reviews.Stats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := &reviews.Stats{
+		ByRating: map[int]int{1: 0, 2: 0, 3: 0, 4: 0, 5: 0},
+		ByStatus: map[string]int{reviews.StatusPending: 0, reviews.StatusApproved: 0, reviews.StatusHidden: 0},
+	}
+	var sum int
+	for _, rv := range s.reviews {
+		if rv.CafeID != cafeID {
+			continue
+		}
+		st.Total++
+		st.ByRating[rv.Rating]++
+		st.ByStatus[rv.Status]++
+		if rv.Reply == "" {
+			st.Unreplied++
+		}
+		sum += rv.Rating
+	}
+	if st.Total > 0 {
+		st.Average = math.Round(float64(sum)/float64(st.Total)*10) / 10
+	}
+	return st, nil
+}
@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"backend/models"
+	"backend/repository"
+	"context"
+	"sync"
+	"time"
+)
+
+type session struct {
+	userID    int
+	expiresAt time.Time
+	revoked   bool
+}
+
+// Sessions adalah repository.SessionStore di memori. Data user untuk
+// Validate dibaca dari Users, sama seperti JOIN ke tabel users.
+type Sessions struct {
+	users *Users
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+var _ repository.SessionStore = (*Sessions)(nil)
+
+func NewSessions(users *Users) *Sessions {
+	return &Sessions{users: users, sessions: map[string]*session{}}
+}
+
+func (s *Sessions) Create(ctx context.Context, userID int, ttl time.Duration) (string, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token := newToken()
+	expiresAt := time.Now().Add(ttl)
+	s.sessions[token] = &session{userID: userID, expiresAt: expiresAt}
+	return token, expiresAt, nil
+}
+
+func (s *Sessions) Validate(ctx context.Context, token string) (*models.SessionUser, error) {
+	s.mu.Lock()
+	sess, ok := s.sessions[token]
+	s.mu.Unlock()
+	if !ok || sess.revoked || !time.Now().Before(sess.expiresAt) {
+		return nil, repository.ErrSessionInvalid
+	}
+
+	s.users.mu.Lock()
+	defer s.users.mu.Unlock()
+	u := s.users.active(sess.userID)
+	if u == nil || u.Suspended {
+		return nil, repository.ErrSessionInvalid
+	}
+	return &models.SessionUser{ID: u.ID, Username: u.Username, Role: u.Role, MustChangePassword: u.MustChangePassword}, nil
+}
+
+func (s *Sessions) Revoke(ctx context.Context, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sess, ok := s.sessions[token]; ok {
+		sess.revoked = true
+	}
+	return nil
+}
+
+func (s *Sessions) RevokeAllForUser(ctx context.Context, userID int) error {
+	return s.RevokeOthers(ctx, userID, "")
+}
+
+func (s *Sessions) RevokeOthers(ctx context.Context, userID int, keepToken string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for token, sess := range s.sessions {
+		if sess.userID == userID && token != keepToken {
+			sess.revoked = true
+		}
+	}
+	return nil
+}
+
+type authToken struct {
+	userID    int
+	purpose   string
+	expiresAt time.Time
+	used      bool
+}
+
+// Tokens adalah repository.TokenStore di memori
+type Tokens struct {
+	mu     sync.Mutex
+	tokens map[string]*authToken
+}
+
+var _ repository.TokenStore = (*Tokens)(nil)
+
+func NewTokens() *Tokens {
+	return &Tokens{tokens: map[string]*authToken{}}
+}
+
+func (s *Tokens) Create(ctx context.Context, userID int, purpose string, ttl time.Duration) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Token lama dengan tujuan yang sama dibatalkan
+	for _, t := range s.tokens {
+		if t.userID == userID && t.purpose == purpose {
+			t.used = true
+		}
+	}
+	token := newToken()
+	s.tokens[token] = &authToken{userID: userID, purpose: purpose, expiresAt: time.Now().Add(ttl)}
+	return token, nil
+}
+
+func (s *Tokens) Consume(ctx context.Context, token, purpose string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tokens[token]
+	if !ok || t.used || t.purpose != purpose || !time.Now().Before(t.expiresAt) {
+		return 0, repository.ErrTokenInvalid
+	}
+	t.used = true
+	return t.userID, nil
+}
@@ -0,0 +1,336 @@
+package memory
+
+import (
+	"backend/models"
+	"backend/repository"
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// User adalah satu akun di Users. Field di luar models.User (profil
+// customer & status akun) hanya ada di tabel users.
+type User struct {
+	models.User
+	DisplayName        string
+	Phone              string
+	Avatar             string
+	EmailVerified      bool
+	MustChangePassword bool
+	Suspended          bool
+	SuspendedReason    string
+	Deleted            bool
+	CreatedAt          time.Time
+}
+
+// Users adalah repository.UserStore di memori
+type Users struct {
+	mu     sync.Mutex
+	nextID int
+	users  map[int]*User
+}
+
+var _ repository.UserStore = (*Users)(nil)
+
+func NewUsers() *Users {
+	return &Users{nextID: 1, users: map[int]*User{}}
+}
+
+// Add menyimpan akun apa adanya (untuk menyiapkan data test) dan
+// mengembalikan ID-nya. ID 0 diisi otomatis.
+func (s *Users) Add(u User) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.insert(u)
+}
+
+func (s *Users) insert(u User) int {
+	if u.ID == 0 {
+		u.ID = s.nextID
+	}
+	if u.ID >= s.nextID {
+		s.nextID = u.ID + 1
+	}
+	if u.CreatedAt.IsZero() {
+		u.CreatedAt = time.Now()
+	}
+	s.users[u.ID] = &u
+	return u.ID
+}
+
+// Get mengembalikan salinan akun untuk dicek di test
+func (s *Users) Get(id int) (User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[id]
+	if !ok {
+		return User{}, false
+	}
+	return *u, true
+}
+
+// active mengembalikan akun yang belum dihapus lunak
+func (s *Users) active(id int) *User {
+	u, ok := s.users[id]
+	if !ok || u.Deleted {
+		return nil
+	}
+	return u
+}
+
+func (s *Users) byUsername(username string) *User {
+	for _, u := range s.users {
+		if u.Username == username {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *Users) customerByEmail(email string) *User {
+	for _, u := range s.users {
+		if u.Role == models.RoleCustomer && u.Email != "" && strings.EqualFold(u.Email, email) {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *Users) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.byUsername(username)
+	if u == nil {
+		return nil, sql.ErrNoRows
+	}
+	return &models.User{ID: u.ID, Username: u.Username, Password: u.Password, Role: u.Role, Verified: u.Verified}, nil
+}
+
+func (s *Users) GetLoginUser(ctx context.Context, username, role string) (*models.LoginUser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.byUsername(username)
+	if u == nil || u.Role != role || u.Deleted {
+		return nil, sql.ErrNoRows
+	}
+	return &models.LoginUser{
+		ID:                 u.ID,
+		Username:           u.Username,
+		Password:           u.Password,
+		Role:               u.Role,
+		EmailVerified:      u.EmailVerified,
+		MustChangePassword: u.MustChangePassword,
+		Suspended:          u.Suspended,
+		SuspendedReason:    u.SuspendedReason,
+	}, nil
+}
+
+func (s *Users) GetUserByID(ctx context.Context, id int) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &models.User{ID: u.ID, Username: u.Username, Password: u.Password, Email: u.Email, Role: u.Role, Verified: u.Verified}, nil
+}
+
+func (s *Users) FindByEmail(ctx context.Context, email string) ([]models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var list []models.User
+	for _, u := range s.sorted() {
+		if strings.EqualFold(u.Email, email) && !u.Deleted && !u.Suspended {
+			list = append(list, models.User{ID: u.ID, Username: u.Username, Email: u.Email, Role: u.Role})
+		}
+	}
+	return list, nil
+}
+
+func (s *Users) Exists(ctx context.Context, username string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.byUsername(username) != nil, nil
+}
+
+func (s *Users) CreateCafe(ctx context.Context, user *models.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.byUsername(user.Username) != nil {
+		return uniqueViolation("users_username_key")
+	}
+	user.ID = s.insert(User{User: models.User{
+		Username:  user.Username,
+		Password:  user.Password,
+		Email:     user.Email,
+		Role:      user.Role,
+		IzinUsaha: user.IzinUsaha,
+	}})
+	return nil
+}
+
+func (s *Users) VerifyCafe(ctx context.Context, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if u, ok := s.users[userID]; ok {
+		u.Verified = true
+	}
+	return nil
+}
+
+func (s *Users) RejectCafe(ctx context.Context, userID int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[userID]
+	if !ok || u.Role != models.RoleCafe || u.Verified {
+		return false, nil
+	}
+	delete(s.users, userID)
+	return true, nil
+}
+
+func (s *Users) ListCafes(ctx context.Context) ([]models.CafeAccount, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cafes := []models.CafeAccount{}
+	for _, u := range s.sorted() {
+		if u.Role == models.RoleCafe {
+			cafes = append(cafes, models.CafeAccount{
+				ID:        u.ID,
+				Username:  u.Username,
+				Email:     u.Email,
+				IzinUsaha: u.IzinUsaha,
+				Verified:  u.Verified,
+			})
+		}
+	}
+	return cafes, nil
+}
+
+func (s *Users) CustomerEmailExists(ctx context.Context, email string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.customerByEmail(email) != nil, nil
+}
+
+func (s *Users) CreateCustomer(ctx context.Context, user *models.User, displayName, phone string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.byUsername(user.Username) != nil {
+		return 0, uniqueViolation("users_username_key")
+	}
+	if s.customerByEmail(user.Email) != nil {
+		return 0, uniqueViolation("idx_users_email_customer")
+	}
+	return s.insert(User{
+		User: models.User{
+			Username: user.Username,
+			Password: user.Password,
+			Email:    user.Email,
+			Role:     models.RoleCustomer,
+			Verified: true,
+		},
+		DisplayName: displayName,
+		Phone:       phone,
+	}), nil
+}
+
+func (s *Users) MarkEmailVerified(ctx context.Context, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if u, ok := s.users[userID]; ok {
+		u.EmailVerified = true
+	}
+	return nil
+}
+
+func (s *Users) customer(userID int) *User {
+	u, ok := s.users[userID]
+	if !ok || u.Role != models.RoleCustomer {
+		return nil
+	}
+	return u
+}
+
+func (s *Users) GetCustomerProfile(ctx context.Context, userID int) (*models.CustomerProfile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.customer(userID)
+	if u == nil {
+		return nil, sql.ErrNoRows
+	}
+	return &models.CustomerProfile{
+		ID:            u.ID,
+		Username:      u.Username,
+		Email:         u.Email,
+		DisplayName:   u.DisplayName,
+		Phone:         u.Phone,
+		Avatar:        u.Avatar,
+		EmailVerified: u.EmailVerified,
+		CreatedAt:     u.CreatedAt,
+	}, nil
+}
+
+func (s *Users) UpdateCustomerProfile(ctx context.Context, userID int, displayName, phone string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if u := s.customer(userID); u != nil {
+		u.DisplayName = displayName
+		u.Phone = phone
+	}
+	return nil
+}
+
+func (s *Users) UpdateAvatar(ctx context.Context, userID int, avatar string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[userID]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+	old := u.Avatar
+	u.Avatar = avatar
+	return old, nil
+}
+
+func (s *Users) DeleteCustomer(ctx context.Context, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.customer(userID) == nil {
+		return sql.ErrNoRows
+	}
+	delete(s.users, userID)
+	return nil
+}
+
+func (s *Users) UpdatePassword(ctx context.Context, userID int, password string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if u, ok := s.users[userID]; ok {
+		u.Password = password
+	}
+	return nil
+}
+
+func (s *Users) ChangePassword(ctx context.Context, userID int, password string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if u, ok := s.users[userID]; ok {
+		u.Password = password
+		u.MustChangePassword = false
+	}
+	return nil
+}
+
+// sorted mengembalikan semua akun urut ID
+func (s *Users) sorted() []*User {
+	list := make([]*User, 0, len(s.users))
+	for _, u := range s.users {
+		list = append(list, u)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"backend/dbtime"
+	"backend/models"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// NotificationStore adalah akses notifikasi in-app & preferensinya
+type NotificationStore interface {
+	List(ctx context.Context, userID int, unreadOnly bool, limit, offset int) ([]models.Notification, int, error)
+	UnreadCount(ctx context.Context, userID int) (int, error)
+	MarkRead(ctx context.Context, userID int, id int64, now time.Time) (bool, error)
+	MarkAllRead(ctx context.Context, userID int, now time.Time) (int64, error)
+	Preferences(ctx context.Context, userID int) ([]models.NotificationPreference, error)
+	SavePreferences(ctx context.Context, userID int, prefs []models.NotificationPreference) error
+}
+
+var _ NotificationStore = (*NotificationRepository)(nil)
+
+// NotificationRepository membaca inbox notifikasi & pengaturan channel
+// user. Notifikasi baru ditulis lewat package notify (outbox).
+type NotificationRepository struct {
+	db       *sql.DB
+	location *time.Location
+}
+
+func NewNotificationRepository(db *sql.DB, location *time.Location) *NotificationRepository {
+	return &NotificationRepository{db: db, location: location}
+}
+
+// =========================
+// Inbox
+// =========================
+func (r *NotificationRepository) List(ctx context.Context, userID int, unreadOnly bool, limit, offset int) ([]models.Notification, int, error) {
+	filter := ""
+	if unreadOnly {
+		filter = " AND read_at IS NULL"
+	}
+
+	var total int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM notifications WHERE user_id=$1`+filter, userID).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, event_type, title, body, COALESCE(link, ''), data, read_at, created_at
+		FROM notifications
+		WHERE user_id=$1`+filter+`
+		ORDER BY id DESC
+		LIMIT $2 OFFSET $3`, userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		var data []byte
+		var readAt pq.NullTime
+		var created time.Time
+		if err := rows.Scan(&n.ID, &n.EventType, &n.Title, &n.Body, &n.Link, &data, &readAt, &created); err != nil {
+			return nil, 0, err
+		}
+		n.Data = data
+		n.ReadAt = dbtime.WallClockNull(readAt, r.location)
+		n.CreatedAt = dbtime.WallClock(created, r.location)
+		list = append(list, n)
+	}
+	return list, total, rows.Err()
+}
+
+func (r *NotificationRepository) UnreadCount(ctx context.Context, userID int) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM notifications WHERE user_id=$1 AND read_at IS NULL`, userID).Scan(&n)
+	return n, err
+}
+
+// MarkRead menandai satu notifikasi milik user sudah dibaca. false jika
+// notifikasi tidak ditemukan.
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID int, id int64, now time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE notifications SET read_at=COALESCE(read_at, $3)
+		WHERE id=$1 AND user_id=$2`,
+		id, userID, dbtime.Format(now.In(r.location)))
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// MarkAllRead menandai semua notifikasi user sudah dibaca
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID int, now time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE notifications SET read_at=$2
+		WHERE user_id=$1 AND read_at IS NULL`,
+		userID, dbtime.Format(now.In(r.location)))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// =========================
+// Pengaturan channel
+// =========================
+
+// Preferences mengembalikan pengaturan yang pernah disimpan user.
+// Kombinasi event & channel yang tidak ada dianggap aktif.
+func (r *NotificationRepository) Preferences(ctx context.Context, userID int) ([]models.NotificationPreference, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT event_type, channel, enabled FROM notification_preferences
+		WHERE user_id=$1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prefs []models.NotificationPreference
+	for rows.Next() {
+		var p models.NotificationPreference
+		if err := rows.Scan(&p.EventType, &p.Channel, &p.Enabled); err != nil {
+			return nil, err
+		}
+		prefs = append(prefs, p)
+	}
+	return prefs, rows.Err()
+}
+
+func (r *NotificationRepository) SavePreferences(ctx context.Context, userID int, prefs []models.NotificationPreference) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, p := range prefs {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO notification_preferences (user_id, event_type, channel, enabled)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id, event_type, channel) DO UPDATE SET enabled=EXCLUDED.enabled`,
+			userID, p.EventType, p.Channel, p.Enabled)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,547 @@
+package repository
+
+import (
+	"backend/dbtime"
+	"backend/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ErrOrderNotFound dikembalikan jika pesanan tidak ada (atau bukan milik cafe)
+var ErrOrderNotFound = errors.New("pesanan tidak ditemukan")
+
+// ErrVariantPriceNegative dikembalikan jika harga menu ditambah selisih
+// harga varian kurang dari nol
+var ErrVariantPriceNegative = errors.New("Harga menu dengan varian tidak boleh negatif")
+
+// OrderItemError menjelaskan baris keranjang yang tidak bisa dipesan
+// (menu tidak ada, nonaktif, atau varian tidak cocok). Format & argumen
+// disimpan terpisah supaya pesannya bisa diterjemahkan handler.
+type OrderItemError struct {
+	Format string
+	Args   []interface{}
+}
+
+func (e *OrderItemError) Error() string {
+	return fmt.Sprintf(e.Format, e.Args...)
+}
+
+// OrderTransitionError dikembalikan jika perubahan status tidak diizinkan
+type OrderTransitionError struct {
+	From, To string
+}
+
+func (e *OrderTransitionError) Error() string {
+	return fmt.Sprintf("Status pesanan tidak bisa diubah dari %s ke %s", e.From, e.To)
+}
+
+// queryer dipenuhi *sql.DB dan *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// OrderStore adalah akses pesanan, harga menu & varian menu
+type OrderStore interface {
+	Quote(ctx context.Context, cart models.Cart, now time.Time) ([]models.OrderItem, error)
+	Create(ctx context.Context, cart models.Cart, customerID *int, createdBy int, now time.Time) (*models.Order, error)
+	GetByID(ctx context.Context, id int) (*models.Order, error)
+	ListByCafe(ctx context.Context, cafeID int, statuses []string, limit, offset int) ([]*models.Order, error)
+	ListByCustomer(ctx context.Context, userID, limit, offset int) ([]*models.Order, error)
+	UpdateStatus(ctx context.Context, id, cafeID int, from, status, reason string, now time.Time) (*models.Order, error)
+	MenuCafeID(ctx context.Context, menuID string) (int, error)
+	SetMenuPremiumOnly(ctx context.Context, menuID string, premiumOnly bool) error
+	ListVariants(ctx context.Context, menuID string) ([]models.MenuVariant, error)
+	CreateVariant(ctx context.Context, v *models.MenuVariant) error
+	DeleteVariant(ctx context.Context, menuID string, variantID int) error
+}
+
+var _ OrderStore = (*OrderRepository)(nil)
+
+type OrderRepository struct {
+	db       *sql.DB
+	location *time.Location
+}
+
+// location adalah zona waktu cafe; kolom TIMESTAMP menyimpan waktu lokal
+func NewOrderRepository(db *sql.DB, location *time.Location) *OrderRepository {
+	return &OrderRepository{db: db, location: location}
+}
+
+// =========================
+// Hitung harga keranjang
+// =========================
+// Harga diambil dari menu saat ini beserta diskon yang sedang berlaku
+// (start_date/end_date), tanpa menyimpan apa pun. Diskon menu
+// premium_only hanya berlaku jika cart.Premium.
+func (r *OrderRepository) Quote(ctx context.Context, cart models.Cart, now time.Time) ([]models.OrderItem, error) {
+	return priceCart(ctx, r.db, cart, now.In(r.location))
+}
+
+func priceCart(ctx context.Context, q queryer, cart models.Cart, now time.Time) ([]models.OrderItem, error) {
+	var menuIDs []string
+	var variantIDs []int64
+	for _, item := range cart.Items {
+		if !uuidPattern.MatchString(item.MenuID) {
+			return nil, &OrderItemError{Format: "menu_id %q tidak valid", Args: []interface{}{item.MenuID}}
+		}
+		menuIDs = append(menuIDs, strings.ToLower(item.MenuID))
+		if item.VariantID != nil {
+			variantIDs = append(variantIDs, int64(*item.VariantID))
+		}
+	}
+
+	type menuRow struct {
+		name           string
+		price          float64
+		discount       float64
+		discountActive bool
+		status         string
+	}
+	menus := map[string]menuRow{}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT m.id::text, m.name, m.price, COALESCE(m.discount, 0)::float8, COALESCE(m.status, ''),
+			COALESCE(m.discount, 0) > 0
+				AND (m.start_date IS NULL OR m.start_date <= $3::date)
+				AND (m.end_date IS NULL OR m.end_date >= $3::date)
+				AND (NOT m.premium_only OR $4)
+		FROM menus m
+		JOIN cafe_profiles cp ON cp.id = m.cafe_profile_id
+		LEFT JOIN users us ON us.id = cp.user_id
+		WHERE m.cafe_profile_id=$1 AND m.id = ANY($2::uuid[])
+			AND COALESCE(us.verified, cp.verified) = true`,
+		cart.CafeID, pq.Array(menuIDs), now.Format("2006-01-02"), cart.Premium,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var id string
+		var m menuRow
+		if err := rows.Scan(&id, &m.name, &m.price, &m.discount, &m.status, &m.discountActive); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		menus[id] = m
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	variants := map[int]models.MenuVariant{}
+	if len(variantIDs) > 0 {
+		rows, err := q.QueryContext(ctx, `
+			SELECT id, menu_id::text, name, price_delta, available
+			FROM menu_variants WHERE id = ANY($1)`,
+			pq.Array(variantIDs),
+		)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var v models.MenuVariant
+			if err := rows.Scan(&v.ID, &v.MenuID, &v.Name, &v.PriceDelta, &v.Available); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			variants[v.ID] = v
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	items := make([]models.OrderItem, 0, len(cart.Items))
+	for _, c := range cart.Items {
+		menuID := strings.ToLower(c.MenuID)
+		m, ok := menus[menuID]
+		if !ok {
+			return nil, &OrderItemError{Format: "Menu %s tidak ditemukan di cafe ini", Args: []interface{}{c.MenuID}}
+		}
+		if !strings.EqualFold(m.status, "Aktif") {
+			return nil, &OrderItemError{Format: "Menu %s sedang tidak tersedia", Args: []interface{}{m.name}}
+		}
+
+		item := models.OrderItem{
+			MenuID:        &menuID,
+			MenuName:      m.name,
+			Quantity:      c.Quantity,
+			OriginalPrice: m.price,
+			Note:          c.Note,
+		}
+		if c.VariantID != nil {
+			v, ok := variants[*c.VariantID]
+			if !ok || v.MenuID != menuID {
+				return nil, &OrderItemError{Format: "Varian tidak ditemukan untuk menu %s", Args: []interface{}{m.name}}
+			}
+			if !v.Available {
+				return nil, &OrderItemError{Format: "Varian %s untuk menu %s sedang tidak tersedia", Args: []interface{}{v.Name, m.name}}
+			}
+			item.VariantID = &v.ID
+			item.VariantName = v.Name
+			item.OriginalPrice += v.PriceDelta
+			// Harga menu bisa turun setelah varian dibuat
+			if item.OriginalPrice < 0 {
+				return nil, &OrderItemError{Format: "Varian %s untuk menu %s sedang tidak tersedia", Args: []interface{}{v.Name, m.name}}
+			}
+		}
+
+		// Diskon persen berlaku untuk harga menu termasuk varian
+		item.UnitPrice = item.OriginalPrice
+		if m.discountActive {
+			item.DiscountPercent = m.discount
+			item.UnitPrice = roundMoney(item.OriginalPrice * (1 - m.discount/100))
+		}
+		item.DiscountAmount = roundMoney((item.OriginalPrice - item.UnitPrice) * float64(c.Quantity))
+		item.Subtotal = roundMoney(item.UnitPrice * float64(c.Quantity))
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// =========================
+// Buat pesanan
+// =========================
+// customerID nil untuk pesanan yang diinput kasir (POS).
+func (r *OrderRepository) Create(ctx context.Context, cart models.Cart, customerID *int, createdBy int, now time.Time) (*models.Order, error) {
+	now = now.In(r.location)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	items, err := priceCart(ctx, tx, cart, now)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &models.Order{
+		CafeID:         cart.CafeID,
+		CustomerUserID: customerID,
+		Status:         models.OrderPlaced,
+		PaymentStatus:  "unpaid",
+		OrderType:      cart.OrderType,
+		TableNumber:    cart.TableNumber,
+		PickupName:     cart.PickupName,
+		Note:           cart.Note,
+		CreatedAt:      now,
+		Items:          items,
+	}
+	for _, item := range items {
+		order.Subtotal += item.OriginalPrice * float64(item.Quantity)
+		order.DiscountTotal += item.DiscountAmount
+		order.Total += item.Subtotal
+	}
+	order.Subtotal = roundMoney(order.Subtotal)
+	order.DiscountTotal = roundMoney(order.DiscountTotal)
+	order.Total = roundMoney(order.Total)
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO orders (cafe_profile_id, customer_user_id, created_by, status, order_type,
+			table_number, pickup_name, note, subtotal, discount_total, total, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,NULLIF($6,''),NULLIF($7,''),NULLIF($8,''),$9,$10,$11,$12,$12)
+		RETURNING id`,
+		order.CafeID, order.CustomerUserID, createdBy, order.Status, order.OrderType,
+		order.TableNumber, order.PickupName, order.Note,
+		order.Subtotal, order.DiscountTotal, order.Total, dbtime.Format(now),
+	).Scan(&order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range order.Items {
+		item := &order.Items[i]
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO order_items (order_id, menu_id, menu_name, variant_id, variant_name, quantity,
+				original_price, discount_percent, unit_price, discount_amount, subtotal, note)
+			VALUES ($1,$2,$3,$4,NULLIF($5,''),$6,$7,$8,$9,$10,$11,NULLIF($12,''))
+			RETURNING id`,
+			order.ID, item.MenuID, item.MenuName, item.VariantID, item.VariantName, item.Quantity,
+			item.OriginalPrice, item.DiscountPercent, item.UnitPrice, item.DiscountAmount, item.Subtotal, item.Note,
+		).Scan(&item.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return order, tx.Commit()
+}
+
+const orderColumns = `id, cafe_profile_id, customer_user_id, status, order_type,
+	COALESCE(table_number, ''), COALESCE(pickup_name, ''), COALESCE(note, ''), payment_status,
+	subtotal, discount_total, total, COALESCE(cancel_reason, ''),
+	created_at, preparing_at, ready_at, completed_at, cancelled_at`
+
+func (r *OrderRepository) scanOrder(row interface{ Scan(...interface{}) error }) (*models.Order, error) {
+	o := &models.Order{Items: []models.OrderItem{}}
+	var customerID sql.NullInt64
+	var createdAt time.Time
+	var preparing, ready, completed, cancelled pq.NullTime
+
+	err := row.Scan(&o.ID, &o.CafeID, &customerID, &o.Status, &o.OrderType,
+		&o.TableNumber, &o.PickupName, &o.Note, &o.PaymentStatus,
+		&o.Subtotal, &o.DiscountTotal, &o.Total, &o.CancelReason,
+		&createdAt, &preparing, &ready, &completed, &cancelled)
+	if err != nil {
+		return nil, err
+	}
+
+	if customerID.Valid {
+		id := int(customerID.Int64)
+		o.CustomerUserID = &id
+	}
+	o.CreatedAt = dbtime.WallClock(createdAt, r.location)
+	o.PreparingAt = dbtime.WallClockNull(preparing, r.location)
+	o.ReadyAt = dbtime.WallClockNull(ready, r.location)
+	o.CompletedAt = dbtime.WallClockNull(completed, r.location)
+	o.CancelledAt = dbtime.WallClockNull(cancelled, r.location)
+	return o, nil
+}
+
+// =========================
+// Ambil pesanan berdasarkan ID
+// =========================
+func (r *OrderRepository) GetByID(ctx context.Context, id int) (*models.Order, error) {
+	o, err := r.scanOrder(r.db.QueryRowContext(ctx, "SELECT "+orderColumns+" FROM orders WHERE id=$1", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadItems(ctx, []*models.Order{o}); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// =========================
+// Daftar pesanan cafe
+// =========================
+// statuses kosong berarti semua status. Urut dari yang terbaru.
+func (r *OrderRepository) ListByCafe(ctx context.Context, cafeID int, statuses []string, limit, offset int) ([]*models.Order, error) {
+	args := []interface{}{cafeID}
+	where := "cafe_profile_id=$1"
+	if len(statuses) > 0 {
+		args = append(args, pq.Array(statuses))
+		where += fmt.Sprintf(" AND status = ANY($%d)", len(args))
+	}
+	args = append(args, limit, offset)
+	query := fmt.Sprintf("SELECT %s FROM orders WHERE %s ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d",
+		orderColumns, where, len(args)-1, len(args))
+	return r.list(ctx, query, args...)
+}
+
+// =========================
+// Riwayat pesanan customer
+// =========================
+func (r *OrderRepository) ListByCustomer(ctx context.Context, userID, limit, offset int) ([]*models.Order, error) {
+	return r.list(ctx, "SELECT "+orderColumns+` FROM orders WHERE customer_user_id=$1
+		ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3`, userID, limit, offset)
+}
+
+func (r *OrderRepository) list(ctx context.Context, query string, args ...interface{}) ([]*models.Order, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []*models.Order{}
+	for rows.Next() {
+		o, err := r.scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return orders, r.loadItems(ctx, orders)
+}
+
+// loadItems mengisi baris pesanan dengan satu query
+func (r *OrderRepository) loadItems(ctx context.Context, orders []*models.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(orders))
+	byID := map[int]*models.Order{}
+	for i, o := range orders {
+		ids[i] = int64(o.ID)
+		byID[o.ID] = o
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT order_id, id, menu_id::text, menu_name, variant_id, COALESCE(variant_name, ''), quantity,
+			original_price, discount_percent, unit_price, discount_amount, subtotal, COALESCE(note, '')
+		FROM order_items WHERE order_id = ANY($1) ORDER BY id`,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderID int
+		var menuID sql.NullString
+		var variantID sql.NullInt64
+		var item models.OrderItem
+		err := rows.Scan(&orderID, &item.ID, &menuID, &item.MenuName, &variantID, &item.VariantName,
+			&item.Quantity, &item.OriginalPrice, &item.DiscountPercent, &item.UnitPrice,
+			&item.DiscountAmount, &item.Subtotal, &item.Note)
+		if err != nil {
+			return err
+		}
+		if menuID.Valid {
+			item.MenuID = &menuID.String
+		}
+		if variantID.Valid {
+			id := int(variantID.Int64)
+			item.VariantID = &id
+		}
+		if o, ok := byID[orderID]; ok {
+			o.Items = append(o.Items, item)
+		}
+	}
+	return rows.Err()
+}
+
+// =========================
+// Ubah status pesanan
+// =========================
+// cafeID > 0 membatasi ke pesanan cafe tersebut. Status dikunci dengan
+// SELECT ... FOR UPDATE supaya dua kasir tidak memproses pesanan yang sama.
+// from != "" mensyaratkan status saat ini (dibaca di baris yang terkunci)
+// sama dengan from; jika tidak, dikembalikan *OrderTransitionError.
+func (r *OrderRepository) UpdateStatus(ctx context.Context, id, cafeID int, from, status, reason string, now time.Time) (*models.Order, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var current string
+	var orderCafeID int
+	err = tx.QueryRowContext(ctx, "SELECT status, cafe_profile_id FROM orders WHERE id=$1 FOR UPDATE", id).Scan(&current, &orderCafeID)
+	if err == sql.ErrNoRows || (err == nil && cafeID > 0 && orderCafeID != cafeID) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if (from != "" && current != from) || !models.CanTransition(current, status) {
+		return nil, &OrderTransitionError{From: current, To: status}
+	}
+
+	column := map[string]string{
+		models.OrderPreparing: "preparing_at",
+		models.OrderReady:     "ready_at",
+		models.OrderCompleted: "completed_at",
+		models.OrderCancelled: "cancelled_at",
+	}[status]
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`
+		UPDATE orders SET status=$1, %s=$2, updated_at=$2,
+			cancel_reason=CASE WHEN $1='cancelled' THEN NULLIF($3,'') ELSE cancel_reason END
+		WHERE id=$4`, column),
+		status, dbtime.Format(now.In(r.location)), reason, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+// =========================
+// Varian menu
+// =========================
+// MenuCafeID mengembalikan cafe pemilik menu
+func (r *OrderRepository) MenuCafeID(ctx context.Context, menuID string) (int, error) {
+	if !uuidPattern.MatchString(menuID) {
+		return 0, sql.ErrNoRows
+	}
+	var cafeID sql.NullInt64
+	err := r.db.QueryRowContext(ctx, "SELECT cafe_profile_id FROM menus WHERE id=$1", menuID).Scan(&cafeID)
+	return int(cafeID.Int64), err
+}
+
+// SetMenuPremiumOnly menandai diskon menu sebagai promo khusus anggota premium
+func (r *OrderRepository) SetMenuPremiumOnly(ctx context.Context, menuID string, premiumOnly bool) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE menus SET premium_only=$2, updated_at=CURRENT_TIMESTAMP WHERE id=$1", menuID, premiumOnly)
+	return err
+}
+
+func (r *OrderRepository) ListVariants(ctx context.Context, menuID string) ([]models.MenuVariant, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, menu_id::text, name, price_delta, available
+		FROM menu_variants WHERE menu_id=$1 ORDER BY price_delta, id`, menuID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := []models.MenuVariant{}
+	for rows.Next() {
+		var v models.MenuVariant
+		if err := rows.Scan(&v.ID, &v.MenuID, &v.Name, &v.PriceDelta, &v.Available); err != nil {
+			return nil, err
+		}
+		variants = append(variants, v)
+	}
+	return variants, rows.Err()
+}
+
+// CreateVariant menolak varian yang membuat harga menu negatif
+// (ErrVariantPriceNegative)
+func (r *OrderRepository) CreateVariant(ctx context.Context, v *models.MenuVariant) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO menu_variants (menu_id, name, price_delta, available)
+		SELECT id, $2, $3, $4 FROM menus WHERE id=$1 AND price + $3 >= 0
+		RETURNING id`,
+		v.MenuID, v.Name, v.PriceDelta, v.Available,
+	).Scan(&v.ID)
+	if err == sql.ErrNoRows {
+		return ErrVariantPriceNegative
+	}
+	return err
+}
+
+func (r *OrderRepository) DeleteVariant(ctx context.Context, menuID string, variantID int) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM menu_variants WHERE id=$1 AND menu_id=$2", variantID, menuID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
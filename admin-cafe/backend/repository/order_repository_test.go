@@ -0,0 +1,18 @@
+package repository_test
+
+import (
+	"backend/pgtest"
+	"backend/repository"
+	"backend/repository/storetest"
+	"testing"
+	"time"
+)
+
+func TestOrderRepository(t *testing.T) {
+	db := pgtest.Open(t)
+	cafeID := pgtest.CreateCafe(t, db, "kopikita")
+	otherCafeID := pgtest.CreateCafe(t, db, "senja")
+	customerID := pgtest.CreateCustomer(t, db, "budi")
+	menuID := pgtest.CreateMenu(t, db, cafeID, "Latte", 25000)
+	storetest.Orders(t, repository.NewOrderRepository(db, time.FixedZone("WIB", 7*60*60)), cafeID, otherCafeID, customerID, menuID)
+}
@@ -0,0 +1,429 @@
+package repository
+
+import (
+	"backend/dbtime"
+	"backend/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrReservationNotFound = errors.New("reservasi tidak ditemukan")
+	ErrNoTableAvailable    = errors.New("tidak ada meja tersedia untuk waktu tersebut")
+	ErrTableNotFound       = errors.New("meja tidak ditemukan")
+	ErrTableInUse          = errors.New("meja masih memiliki reservasi, nonaktifkan saja")
+	// ErrReservationChanged dikembalikan jika status reservasi berubah
+	// di antara dibaca dan di-update (mis. hold baru saja dilepas)
+	ErrReservationChanged = errors.New("status reservasi sudah berubah, muat ulang data")
+)
+
+// Reservasi yang memblokir meja: confirmed, atau pending yang hold-nya
+// belum habis. $n adalah waktu sekarang.
+const blockingReservation = `(r.status='confirmed' OR (r.status='pending' AND r.hold_expires_at > $%d))`
+
+// ReservationStore adalah akses meja & reservasi. Job pelepasan hold
+// (ReleaseExpiredHolds & RunHoldRelease) dipanggil langsung dari main.go.
+type ReservationStore interface {
+	ListTables(ctx context.Context, cafeID int, activeOnly bool) ([]models.CafeTable, error)
+	CreateTable(ctx context.Context, t *models.CafeTable) error
+	UpdateTable(ctx context.Context, t *models.CafeTable) error
+	DeleteTable(ctx context.Context, id, cafeID int) error
+	WithinOperationalHours(ctx context.Context, cafeID int, start, end time.Time) (ok, hasHours bool, err error)
+	AvailableTables(ctx context.Context, cafeID int, start, end time.Time, partySize int, area string, now time.Time) ([]models.CafeTable, error)
+	Create(ctx context.Context, req models.ReservationRequest, now time.Time) (*models.Reservation, error)
+	GetByID(ctx context.Context, id int) (*models.Reservation, error)
+	ListByCafe(ctx context.Context, cafeID int, date *time.Time, statuses []string) ([]*models.Reservation, error)
+	ListByCustomer(ctx context.Context, userID int) ([]*models.Reservation, error)
+	UpdateStatus(ctx context.Context, id int, from, to, reason string, now time.Time) (*models.Reservation, error)
+}
+
+var _ ReservationStore = (*ReservationRepository)(nil)
+
+type ReservationRepository struct {
+	db       *sql.DB
+	location *time.Location
+}
+
+// location adalah zona waktu cafe; kolom TIMESTAMP menyimpan waktu lokal
+func NewReservationRepository(db *sql.DB, location *time.Location) *ReservationRepository {
+	return &ReservationRepository{db: db, location: location}
+}
+
+// =========================
+// Meja cafe
+// =========================
+func (r *ReservationRepository) ListTables(ctx context.Context, cafeID int, activeOnly bool) ([]models.CafeTable, error) {
+	query := "SELECT id, cafe_profile_id, label, area, capacity, active FROM cafe_tables WHERE cafe_profile_id=$1"
+	if activeOnly {
+		query += " AND active = true"
+	}
+	rows, err := r.db.QueryContext(ctx, query+" ORDER BY area, label", cafeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := []models.CafeTable{}
+	for rows.Next() {
+		var t models.CafeTable
+		if err := rows.Scan(&t.ID, &t.CafeID, &t.Label, &t.Area, &t.Capacity, &t.Active); err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+	return tables, rows.Err()
+}
+
+func (r *ReservationRepository) CreateTable(ctx context.Context, t *models.CafeTable) error {
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO cafe_tables (cafe_profile_id, label, area, capacity, active)
+		VALUES ($1,$2,$3,$4,$5) RETURNING id`,
+		t.CafeID, t.Label, t.Area, t.Capacity, t.Active,
+	).Scan(&t.ID)
+}
+
+func (r *ReservationRepository) UpdateTable(ctx context.Context, t *models.CafeTable) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE cafe_tables SET label=$1, area=$2, capacity=$3, active=$4
+		WHERE id=$5 AND cafe_profile_id=$6`,
+		t.Label, t.Area, t.Capacity, t.Active, t.ID, t.CafeID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrTableNotFound
+	}
+	return nil
+}
+
+func (r *ReservationRepository) DeleteTable(ctx context.Context, id, cafeID int) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM cafe_tables WHERE id=$1 AND cafe_profile_id=$2", id, cafeID)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+		return ErrTableInUse
+	}
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrTableNotFound
+	}
+	return nil
+}
+
+// IsUniqueViolation memeriksa error duplikat (mis. label meja sama)
+func IsUniqueViolation(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "23505"
+}
+
+// =========================
+// Cek jam operasional
+// =========================
+// WithinOperationalHours memeriksa apakah [start, end) berada dalam satu
+// rentang jam buka cafe, termasuk jam buka yang melewati tengah malam.
+// hasHours false jika cafe belum mengatur jam operasional.
+func (r *ReservationRepository) WithinOperationalHours(ctx context.Context, cafeID int, start, end time.Time) (ok, hasHours bool, err error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT LOWER(hari), to_char(buka, 'HH24:MI:SS'), to_char(tutup, 'HH24:MI:SS')
+		FROM cafe_operational_hours WHERE cafe_profile_id=$1`, cafeID)
+	if err != nil {
+		return false, false, err
+	}
+	defer rows.Close()
+
+	start = start.In(r.location)
+	end = end.In(r.location)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, r.location)
+	prevDay := day.AddDate(0, 0, -1)
+
+	for rows.Next() {
+		var hari, buka, tutup string
+		if err := rows.Scan(&hari, &buka, &tutup); err != nil {
+			return false, false, err
+		}
+		hasHours = true
+
+		// Jam buka hari ini, atau jam buka kemarin yang melewati tengah malam
+		for _, base := range []time.Time{day, prevDay} {
+			if hari != namaHari[base.Weekday()] {
+				continue
+			}
+			open := atClock(base, buka)
+			close := atClock(base, tutup)
+			if !close.After(open) {
+				close = close.AddDate(0, 0, 1)
+			}
+			if !start.Before(open) && !end.After(close) {
+				return true, true, rows.Err()
+			}
+		}
+	}
+	return false, hasHours, rows.Err()
+}
+
+// atClock menggabungkan tanggal day dengan jam "HH:MM:SS"
+func atClock(day time.Time, clock string) time.Time {
+	var h, m, s int
+	fmt.Sscanf(clock, "%d:%d:%d", &h, &m, &s)
+	return time.Date(day.Year(), day.Month(), day.Day(), h, m, s, 0, day.Location())
+}
+
+// =========================
+// Meja yang tersedia
+// =========================
+func (r *ReservationRepository) AvailableTables(ctx context.Context, cafeID int, start, end time.Time, partySize int, area string, now time.Time) ([]models.CafeTable, error) {
+	return availableTables(ctx, r.db, cafeID, start, end, partySize, area, now, false)
+}
+
+// availableTables mencari meja aktif yang cukup untuk partySize dan tidak
+// bentrok dengan reservasi lain, urut dari kapasitas terkecil. Dengan
+// lock, baris meja dikunci (FOR UPDATE) supaya dua reservasi paralel
+// tidak mendapat meja yang sama; hasilnya harus dicek ulang setelah lock
+// didapat (lihat Create).
+func availableTables(ctx context.Context, q queryer, cafeID int, start, end time.Time, partySize int, area string, now time.Time, lock bool) ([]models.CafeTable, error) {
+	args := []interface{}{cafeID, partySize, dbtime.Format(start), dbtime.Format(end), dbtime.Format(now)}
+	filter := ""
+	if area != "" {
+		args = append(args, area)
+		filter = fmt.Sprintf(" AND t.area=$%d", len(args))
+	}
+	query := fmt.Sprintf(`
+		SELECT t.id, t.cafe_profile_id, t.label, t.area, t.capacity, t.active
+		FROM cafe_tables t
+		WHERE t.cafe_profile_id=$1 AND t.active = true AND t.capacity >= $2%s
+			AND NOT EXISTS (
+				SELECT 1 FROM reservations r
+				WHERE r.table_id = t.id AND r.start_at < $4 AND r.end_at > $3
+					AND `+blockingReservation+`
+			)
+		ORDER BY t.capacity, t.id`, filter, 5)
+	if lock {
+		query += " FOR UPDATE OF t"
+	}
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := []models.CafeTable{}
+	for rows.Next() {
+		var t models.CafeTable
+		if err := rows.Scan(&t.ID, &t.CafeID, &t.Label, &t.Area, &t.Capacity, &t.Active); err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+	return tables, rows.Err()
+}
+
+// =========================
+// Buat reservasi (hold)
+// =========================
+// Reservasi dibuat berstatus pending dan menahan meja sampai HoldUntil.
+// Jika TableID kosong, dipilih meja terkecil yang cukup.
+func (r *ReservationRepository) Create(ctx context.Context, req models.ReservationRequest, now time.Time) (*models.Reservation, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Kunci meja kandidat dulu. Cek bentrok di query yang sama masih
+	// memakai snapshot sebelum menunggu lock, jadi reservasi yang baru
+	// di-commit pemegang lock sebelumnya belum terlihat.
+	locked, err := availableTables(ctx, tx, req.CafeID, req.Start, req.End, req.PartySize, req.Area, now, true)
+	if err != nil {
+		return nil, err
+	}
+	isLocked := map[int]bool{}
+	for _, t := range locked {
+		isLocked[t.ID] = true
+	}
+
+	// Cek ulang dengan snapshot baru setelah lock didapat (READ COMMITTED)
+	tables, err := availableTables(ctx, tx, req.CafeID, req.Start, req.End, req.PartySize, req.Area, now, false)
+	if err != nil {
+		return nil, err
+	}
+	var table *models.CafeTable
+	for i := range tables {
+		if isLocked[tables[i].ID] && (req.TableID == nil || tables[i].ID == *req.TableID) {
+			table = &tables[i]
+			break
+		}
+	}
+	if table == nil {
+		return nil, ErrNoTableAvailable
+	}
+
+	var id int
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO reservations (cafe_profile_id, table_id, customer_user_id, party_size,
+			start_at, end_at, status, note, hold_expires_at, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,'pending',NULLIF($7,''),$8,$9,$9) RETURNING id`,
+		req.CafeID, table.ID, req.CustomerID, req.PartySize,
+		dbtime.Format(req.Start), dbtime.Format(req.End), req.Note,
+		dbtime.Format(req.HoldUntil), dbtime.Format(now),
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+const reservationColumns = `r.id, r.cafe_profile_id, cp.nama, r.table_id, t.label, t.area,
+	r.customer_user_id, COALESCE(NULLIF(u.display_name, ''), u.username, ''), r.party_size,
+	r.start_at, r.end_at, r.status, COALESCE(r.note, ''), COALESCE(r.decline_reason, ''),
+	r.hold_expires_at, r.created_at`
+
+const reservationJoins = `
+	FROM reservations r
+	JOIN cafe_tables t ON t.id = r.table_id
+	JOIN cafe_profiles cp ON cp.id = r.cafe_profile_id
+	LEFT JOIN users u ON u.id = r.customer_user_id`
+
+func (r *ReservationRepository) scanReservation(row interface{ Scan(...interface{}) error }) (*models.Reservation, error) {
+	res := &models.Reservation{}
+	var customerID sql.NullInt64
+	var start, end, created time.Time
+	var hold pq.NullTime
+
+	err := row.Scan(&res.ID, &res.CafeID, &res.CafeNama, &res.TableID, &res.TableLabel, &res.Area,
+		&customerID, &res.CustomerName, &res.PartySize,
+		&start, &end, &res.Status, &res.Note, &res.DeclineReason, &hold, &created)
+	if err != nil {
+		return nil, err
+	}
+	res.CustomerID = int(customerID.Int64)
+	res.StartAt = dbtime.WallClock(start, r.location)
+	res.EndAt = dbtime.WallClock(end, r.location)
+	res.CreatedAt = dbtime.WallClock(created, r.location)
+	if res.Status == models.ReservationPending {
+		res.HoldExpiresAt = dbtime.WallClockNull(hold, r.location)
+	}
+	return res, nil
+}
+
+func (r *ReservationRepository) GetByID(ctx context.Context, id int) (*models.Reservation, error) {
+	res, err := r.scanReservation(r.db.QueryRowContext(ctx, "SELECT "+reservationColumns+reservationJoins+" WHERE r.id=$1", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrReservationNotFound
+	}
+	return res, err
+}
+
+// =========================
+// Daftar reservasi cafe
+// =========================
+// date nil berarti semua tanggal; statuses kosong berarti semua status
+func (r *ReservationRepository) ListByCafe(ctx context.Context, cafeID int, date *time.Time, statuses []string) ([]*models.Reservation, error) {
+	args := []interface{}{cafeID}
+	where := []string{"r.cafe_profile_id=$1"}
+	if date != nil {
+		day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, r.location)
+		args = append(args, dbtime.Format(day), dbtime.Format(day.AddDate(0, 0, 1)))
+		where = append(where, fmt.Sprintf("r.start_at >= $%d AND r.start_at < $%d", len(args)-1, len(args)))
+	}
+	if len(statuses) > 0 {
+		args = append(args, pq.Array(statuses))
+		where = append(where, fmt.Sprintf("r.status = ANY($%d)", len(args)))
+	}
+	return r.list(ctx, "SELECT "+reservationColumns+reservationJoins+" WHERE "+strings.Join(where, " AND ")+
+		" ORDER BY r.start_at, t.label", args...)
+}
+
+// =========================
+// Reservasi milik customer
+// =========================
+func (r *ReservationRepository) ListByCustomer(ctx context.Context, userID int) ([]*models.Reservation, error) {
+	return r.list(ctx, "SELECT "+reservationColumns+reservationJoins+
+		" WHERE r.customer_user_id=$1 ORDER BY r.start_at DESC LIMIT 100", userID)
+}
+
+func (r *ReservationRepository) list(ctx context.Context, query string, args ...interface{}) ([]*models.Reservation, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*models.Reservation{}
+	for rows.Next() {
+		res, err := r.scanReservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, res)
+	}
+	return list, rows.Err()
+}
+
+// =========================
+// Ubah status reservasi
+// =========================
+// Update hanya berhasil jika status masih sama dengan from (dan untuk
+// pending, hold-nya belum habis), sehingga aman dari race dengan
+// pelepasan hold otomatis.
+func (r *ReservationRepository) UpdateStatus(ctx context.Context, id int, from, to, reason string, now time.Time) (*models.Reservation, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE reservations
+		SET status=$3, updated_at=$5,
+			decline_reason=CASE WHEN $3 IN ('declined', 'cancelled') THEN NULLIF($4, '') ELSE decline_reason END
+		WHERE id=$1 AND status=$2 AND (status <> 'pending' OR hold_expires_at > $5)`,
+		id, from, to, reason, dbtime.Format(now),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, ErrReservationChanged
+	}
+	return r.GetByID(ctx, id)
+}
+
+// =========================
+// Lepas hold yang tidak dikonfirmasi
+// =========================
+func (r *ReservationRepository) ReleaseExpiredHolds(ctx context.Context, now time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE reservations SET status='expired', updated_at=$1
+		WHERE status='pending' AND hold_expires_at <= $1`,
+		dbtime.Format(now.In(r.location)),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// RunHoldRelease menjalankan satu putaran ReleaseExpiredHolds dan
+// mencatat hasilnya. Dijadwalkan berkala oleh supervisor di main.
+func (r *ReservationRepository) RunHoldRelease(ctx context.Context) error {
+	n, err := r.ReleaseExpiredHolds(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		slog.Info("Reservasi: hold kedaluwarsa dilepas", "released", n)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package repository_test
+
+import (
+	"backend/models"
+	"backend/pgtest"
+	"backend/repository"
+	"backend/repository/storetest"
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReservationRepository(t *testing.T) {
+	db := pgtest.Open(t)
+	loc := time.FixedZone("WIB", 7*60*60)
+	cafeID := pgtest.CreateCafe(t, db, "kopikita")
+	customerID := pgtest.CreateCustomer(t, db, "budi")
+	storetest.Reservations(t, repository.NewReservationRepository(db, loc), loc, cafeID, customerID)
+}
+
+// Dua customer memesan meja yang sama di jam yang sama secara paralel;
+// hanya satu yang boleh berhasil
+func TestReservationCreateConcurrent(t *testing.T) {
+	ctx := context.Background()
+
+	db := pgtest.Open(t)
+	loc := time.FixedZone("WIB", 7*60*60)
+	repo := repository.NewReservationRepository(db, loc)
+
+	cafeID := pgtest.CreateCafe(t, db, "kopikita")
+	table := &models.CafeTable{CafeID: cafeID, Label: "A1", Area: "indoor", Capacity: 4, Active: true}
+	if err := repo.CreateTable(ctx, table); err != nil {
+		t.Fatalf("CreateTable: %v", err)
+	}
+
+	now := time.Now().In(loc)
+	start := now.Add(24 * time.Hour).Truncate(time.Hour)
+	const n = 8
+	customers := make([]int, n)
+	for i := range customers {
+		customers[i] = pgtest.CreateCustomer(t, db, "budi"+string(rune('a'+i)))
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = repo.Create(ctx, models.ReservationRequest{
+				CafeID: cafeID, CustomerID: customers[i], PartySize: 2,
+				Start: start, End: start.Add(2 * time.Hour), HoldUntil: now.Add(15 * time.Minute),
+			}, now)
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		switch err {
+		case nil:
+			created++
+		case repository.ErrNoTableAvailable:
+		default:
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	if created != 1 {
+		t.Fatalf("reservasi berhasil = %d, mau 1", created)
+	}
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Opsi ts_headline untuk highlight hasil pencarian
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=25, MinWords=8, HighlightAll=false"
+
+// Batas minimal similarity trigram untuk fallback typo
+const trigramThreshold = 0.3
+
+// htmlText meng-escape kolom teks di SQL supaya highlight & snippet aman
+// dirender sebagai HTML; hanya tag <mark> dari ts_headline yang tersisa
+func htmlText(col string) string {
+	return fmt.Sprintf(`replace(replace(replace(replace(%s, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;')`, col)
+}
+
+// likeContains membuat pola ILIKE "mengandung text"; % dan _ dari input
+// user dicocokkan apa adanya, bukan sebagai wildcard
+func likeContains(text string) string {
+	return "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(text) + "%"
+}
+
+// SearchStore adalah pencarian cafe & menu
+type SearchStore interface {
+	Search(ctx context.Context, q models.SearchQuery) ([]models.SearchHit, bool, error)
+}
+
+var _ SearchStore = (*SearchRepository)(nil)
+
+type SearchRepository struct {
+	db       *sql.DB
+	tsConfig string
+	trigram  bool
+}
+
+// tsConfig adalah text search config yang juga dipakai trigger index
+// (lihat config.SearchConfig), trigram menandakan pg_trgm tersedia.
+func NewSearchRepository(db *sql.DB, tsConfig string, trigram bool) *SearchRepository {
+	return &SearchRepository{db: db, tsConfig: tsConfig, trigram: trigram}
+}
+
+// =========================
+// Full-text search cafe & menu
+// =========================
+// Mengembalikan hasil full-text; jika kosong dicoba lagi dengan
+// pencocokan trigram/ILIKE supaya typo tetap menemukan hasil.
+// Nilai kedua bernilai true jika hasil berasal dari fallback.
+func (r *SearchRepository) Search(ctx context.Context, q models.SearchQuery) ([]models.SearchHit, bool, error) {
+	hits, err := r.fullText(ctx, q)
+	if err != nil || len(hits) > 0 {
+		return hits, false, err
+	}
+	hits, err = r.fuzzy(ctx, q)
+	return hits, true, err
+}
+
+func (r *SearchRepository) fullText(ctx context.Context, q models.SearchQuery) ([]models.SearchHit, error) {
+	var parts []string
+
+	if hasType(q.Types, models.SearchTypeCafe) {
+		parts = append(parts, `
+			SELECT 'cafe' AS type, cp.id::text AS id, cp.id AS cafe_id, cp.nama AS title,
+				cp.alamat AS subtitle,
+				ts_headline($2::regconfig, `+htmlText("cp.nama")+`, query, $3) AS highlight,
+				ts_headline($2::regconfig, `+htmlText("COALESCE(cp.deskripsi, '')")+`, query, $3) AS snippet,
+				ts_rank(cp.search_vector, query) AS rank
+			FROM cafe_profiles cp
+			LEFT JOIN users us ON us.id = cp.user_id,
+				websearch_to_tsquery($2::regconfig, $1) query
+			WHERE cp.search_vector @@ query AND COALESCE(us.verified, cp.verified) = true`)
+	}
+	if hasType(q.Types, models.SearchTypeMenu) {
+		parts = append(parts, `
+			SELECT 'menu' AS type, m.id::text AS id, m.cafe_profile_id AS cafe_id, m.name AS title,
+				m.category AS subtitle,
+				ts_headline($2::regconfig, `+htmlText("m.name")+`, query, $3) AS highlight,
+				ts_headline($2::regconfig, `+htmlText("m.category")+`, query, $3) AS snippet,
+				ts_rank(m.search_vector, query) AS rank
+			FROM menus m
+			JOIN cafe_profiles cp ON cp.id = m.cafe_profile_id
+			LEFT JOIN users us ON us.id = cp.user_id,
+				websearch_to_tsquery($2::regconfig, $1) query
+			WHERE m.search_vector @@ query AND m.status = 'Aktif'
+				AND COALESCE(us.verified, cp.verified) = true`)
+	}
+
+	query := fmt.Sprintf(`%s ORDER BY rank DESC, title ASC LIMIT $4`, strings.Join(parts, " UNION ALL "))
+	return r.queryHits(ctx, query, q.Text, r.tsConfig, headlineOptions, q.Limit)
+}
+
+func (r *SearchRepository) fuzzy(ctx context.Context, q models.SearchQuery) ([]models.SearchHit, error) {
+	// Tanpa pg_trgm: pencocokan substring biasa, rank 0. $3 adalah pola
+	// ILIKE dari likeContains.
+	match := func(col string) (string, string) {
+		if r.trigram {
+			return fmt.Sprintf("(%[1]s %% $1 OR %[1]s ILIKE $3)", col),
+				fmt.Sprintf("similarity(%s, $1)", col)
+		}
+		return fmt.Sprintf("%s ILIKE $3", col), "0"
+	}
+
+	var parts []string
+	if hasType(q.Types, models.SearchTypeCafe) {
+		where, rank := match("cp.nama")
+		parts = append(parts, fmt.Sprintf(`
+			SELECT 'cafe' AS type, cp.id::text AS id, cp.id AS cafe_id, cp.nama AS title,
+				cp.alamat AS subtitle, %s AS highlight, %s AS snippet,
+				%s AS rank
+			FROM cafe_profiles cp
+			LEFT JOIN users us ON us.id = cp.user_id
+			WHERE %s AND COALESCE(us.verified, cp.verified) = true`,
+			htmlText("cp.nama"), htmlText("COALESCE(cp.deskripsi, '')"), rank, where))
+	}
+	if hasType(q.Types, models.SearchTypeMenu) {
+		where, rank := match("m.name")
+		parts = append(parts, fmt.Sprintf(`
+			SELECT 'menu' AS type, m.id::text AS id, m.cafe_profile_id AS cafe_id, m.name AS title,
+				m.category AS subtitle, %s AS highlight, %s AS snippet,
+				%s AS rank
+			FROM menus m
+			JOIN cafe_profiles cp ON cp.id = m.cafe_profile_id
+			LEFT JOIN users us ON us.id = cp.user_id
+			WHERE %s AND m.status = 'Aktif' AND COALESCE(us.verified, cp.verified) = true`,
+			htmlText("m.name"), htmlText("m.category"), rank, where))
+	}
+
+	query := fmt.Sprintf(`%s ORDER BY rank DESC, title ASC LIMIT $2`, strings.Join(parts, " UNION ALL "))
+
+	if r.trigram {
+		// Threshold operator % berlaku per transaksi
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer tx.Rollback()
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL pg_trgm.similarity_threshold = %.2f", trigramThreshold)); err != nil {
+			return nil, err
+		}
+		rows, err := tx.QueryContext(ctx, query, q.Text, q.Limit, likeContains(q.Text))
+		if err != nil {
+			return nil, err
+		}
+		return scanHits(rows)
+	}
+	return r.queryHits(ctx, query, q.Text, q.Limit, likeContains(q.Text))
+}
+
+func (r *SearchRepository) queryHits(ctx context.Context, query string, args ...interface{}) ([]models.SearchHit, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanHits(rows)
+}
+
+func scanHits(rows *sql.Rows) ([]models.SearchHit, error) {
+	defer rows.Close()
+
+	hits := []models.SearchHit{}
+	for rows.Next() {
+		var h models.SearchHit
+		var cafeID sql.NullInt64
+		err := rows.Scan(&h.Type, &h.ID, &cafeID, &h.Title, &h.Subtitle, &h.Highlight, &h.Snippet, &h.Rank)
+		if err != nil {
+			return nil, err
+		}
+		if cafeID.Valid {
+			id := int(cafeID.Int64)
+			h.CafeID = &id
+		}
+		hits = append(hits, h)
+	}
+	return hits, rows.Err()
+}
+
+func hasType(types []string, t string) bool {
+	for _, v := range types {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
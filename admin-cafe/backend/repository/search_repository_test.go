@@ -0,0 +1,78 @@
+package repository_test
+
+import (
+	"backend/config"
+	"backend/models"
+	"backend/pgtest"
+	"backend/repository"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSearchEscapesUserInput(t *testing.T) {
+	ctx := context.Background()
+
+	db := pgtest.Open(t)
+	repo := repository.NewSearchRepository(db, config.SearchConfig, config.TrigramEnabled)
+
+	cafeID := pgtest.CreateCafe(t, db, "kopikita")
+	_, err := db.Exec(`UPDATE cafe_profiles SET nama='Kopi <img src=x onerror=alert(1)> Senja' WHERE id=$1`, cafeID)
+	if err != nil {
+		t.Fatalf("update cafe: %v", err)
+	}
+	_, err = db.Exec(`
+		INSERT INTO menus (cafe_profile_id, name, price, discounted_price, category, status)
+		VALUES ($1, 'Diskon 50% Latte', 20000, 20000, 'Kopi', 'Aktif'), ($1, 'Es Teh', 8000, 8000, 'Teh', 'Aktif')`, cafeID)
+	if err != nil {
+		t.Fatalf("insert menu: %v", err)
+	}
+
+	t.Run("highlight di-escape", func(t *testing.T) {
+		hits, _, err := repo.Search(ctx, models.SearchQuery{Text: "senja", Types: []string{models.SearchTypeCafe}, Limit: 10})
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		if len(hits) != 1 {
+			t.Fatalf("hits = %+v", hits)
+		}
+		if strings.Contains(hits[0].Highlight, "<img") || !strings.Contains(hits[0].Highlight, "&lt;img") ||
+			!strings.Contains(hits[0].Highlight, "<mark>") {
+			t.Fatalf("highlight = %q", hits[0].Highlight)
+		}
+	})
+
+	t.Run("wildcard dicocokkan apa adanya", func(t *testing.T) {
+		for _, text := range []string{"%", "_"} {
+			hits, _, err := repo.Search(ctx, models.SearchQuery{Text: text, Types: []string{models.SearchTypeMenu}, Limit: 10})
+			if err != nil {
+				t.Fatalf("Search(%q): %v", text, err)
+			}
+			want := 0
+			if text == "%" {
+				want = 1
+			}
+			if len(hits) != want {
+				t.Errorf("Search(%q) = %+v, mau %d hasil", text, hits, want)
+			}
+		}
+	})
+
+	t.Run("menu cafe belum terverifikasi", func(t *testing.T) {
+		for _, q := range []string{
+			"UPDATE users SET verified=false WHERE id=(SELECT user_id FROM cafe_profiles WHERE id=$1)",
+			"UPDATE cafe_profiles SET verified=false WHERE id=$1",
+		} {
+			if _, err := db.Exec(q, cafeID); err != nil {
+				t.Fatalf("update cafe: %v", err)
+			}
+		}
+		hits, _, err := repo.Search(ctx, models.SearchQuery{Text: "latte", Types: []string{models.SearchTypeMenu}, Limit: 10})
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		if len(hits) != 0 {
+			t.Fatalf("hits = %+v, mau kosong", hits)
+		}
+	})
+}
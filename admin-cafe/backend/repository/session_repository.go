@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"backend/models"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrSessionInvalid dikembalikan jika token session tidak dikenal,
+// sudah logout, atau kedaluwarsa.
+var ErrSessionInvalid = errors.New("session tidak valid")
+
+// SessionStore menyimpan session login (token Bearer)
+type SessionStore interface {
+	Create(ctx context.Context, userID int, ttl time.Duration) (string, time.Time, error)
+	Validate(ctx context.Context, token string) (*models.SessionUser, error)
+	Revoke(ctx context.Context, token string) error
+	RevokeAllForUser(ctx context.Context, userID int) error
+	RevokeOthers(ctx context.Context, userID int, keepToken string) error
+}
+
+var _ SessionStore = (*SessionRepository)(nil)
+
+type SessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// =========================
+// Buat session baru setelah login
+// =========================
+func (r *SessionRepository) Create(ctx context.Context, userID int, ttl time.Duration) (string, time.Time, error) {
+	token, hash, err := newToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	_, err = r.db.ExecContext(ctx,
+		"INSERT INTO sessions (user_id, token_hash, expires_at) VALUES ($1,$2,$3)",
+		userID, hash, expiresAt,
+	)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// =========================
+// Validasi token session
+// =========================
+func (r *SessionRepository) Validate(ctx context.Context, token string) (*models.SessionUser, error) {
+	user := &models.SessionUser{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT u.id, u.username, u.role, COALESCE(u.must_change_password, false)
+		FROM sessions s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.token_hash=$1 AND s.revoked_at IS NULL AND s.expires_at > NOW()
+			AND u.suspended_at IS NULL AND u.deleted_at IS NULL`,
+		hashToken(token),
+	).Scan(&user.ID, &user.Username, &user.Role, &user.MustChangePassword)
+	if err == sql.ErrNoRows {
+		return nil, ErrSessionInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// =========================
+// Logout (cabut satu session)
+// =========================
+func (r *SessionRepository) Revoke(ctx context.Context, token string) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE sessions SET revoked_at=NOW() WHERE token_hash=$1 AND revoked_at IS NULL",
+		hashToken(token),
+	)
+	return err
+}
+
+// =========================
+// Cabut semua session milik user
+// =========================
+func (r *SessionRepository) RevokeAllForUser(ctx context.Context, userID int) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE sessions SET revoked_at=NOW() WHERE user_id=$1 AND revoked_at IS NULL",
+		userID,
+	)
+	return err
+}
+
+// =========================
+// Cabut semua session user kecuali token yang sedang dipakai
+// =========================
+func (r *SessionRepository) RevokeOthers(ctx context.Context, userID int, keepToken string) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE sessions SET revoked_at=NOW() WHERE user_id=$1 AND token_hash<>$2 AND revoked_at IS NULL",
+		userID, hashToken(keepToken),
+	)
+	return err
+}
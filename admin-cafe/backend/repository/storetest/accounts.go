@@ -0,0 +1,75 @@
+package storetest
+
+import (
+	"backend/models"
+	"backend/repository"
+	"context"
+	"testing"
+	"time"
+)
+
+// Accounts menguji pengaman super admin terakhir di
+// repository.AccountStore. adminID harus satu-satunya admin aktif;
+// customerID adalah akun customer yang sementara dijadikan admin kedua.
+func Accounts(t *testing.T, accounts repository.AccountStore, adminID, customerID int) {
+	ctx := context.Background()
+
+	now := time.Now()
+	lastAdmin := func(t *testing.T, id int) {
+		t.Helper()
+		if err := accounts.Suspend(ctx, id, id, "Uji", now); err != repository.ErrLastAdmin {
+			t.Errorf("Suspend: err = %v, mau ErrLastAdmin", err)
+		}
+		if err := accounts.SoftDelete(ctx, id, id, now); err != repository.ErrLastAdmin {
+			t.Errorf("SoftDelete: err = %v, mau ErrLastAdmin", err)
+		}
+		if err := accounts.ChangeRole(ctx, id, models.RoleCustomer); err != repository.ErrLastAdmin {
+			t.Errorf("ChangeRole: err = %v, mau ErrLastAdmin", err)
+		}
+		a, err := accounts.Get(ctx, id)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if a.Role != models.RoleAdmin || a.Status != models.AccountActive {
+			t.Fatalf("Get = %+v, mau admin aktif", a)
+		}
+	}
+
+	t.Run("admin terakhir", func(t *testing.T) {
+		lastAdmin(t, adminID)
+		if err := accounts.ChangeRole(ctx, adminID, models.RoleAdmin); err != nil {
+			t.Fatalf("ChangeRole ke admin: %v", err)
+		}
+		if err := accounts.Suspend(ctx, 999999, adminID, "Uji", now); err != repository.ErrAccountNotFound {
+			t.Fatalf("Suspend: err = %v, mau ErrAccountNotFound", err)
+		}
+	})
+
+	t.Run("admin kedua", func(t *testing.T) {
+		if err := accounts.ChangeRole(ctx, customerID, models.RoleAdmin); err != nil {
+			t.Fatalf("ChangeRole: %v", err)
+		}
+		if err := accounts.Suspend(ctx, adminID, customerID, "Uji", now); err != nil {
+			t.Fatalf("Suspend: %v", err)
+		}
+		// Admin yang ditangguhkan tidak dihitung
+		lastAdmin(t, customerID)
+
+		if err := accounts.Unsuspend(ctx, adminID); err != nil {
+			t.Fatalf("Unsuspend: %v", err)
+		}
+		if err := accounts.SoftDelete(ctx, customerID, adminID, now); err != nil {
+			t.Fatalf("SoftDelete: %v", err)
+		}
+		// Admin yang dihapus juga tidak dihitung
+		lastAdmin(t, adminID)
+
+		if err := accounts.Restore(ctx, customerID); err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+		if err := accounts.ChangeRole(ctx, customerID, models.RoleCustomer); err != nil {
+			t.Fatalf("ChangeRole: %v", err)
+		}
+		lastAdmin(t, adminID)
+	})
+}
@@ -0,0 +1,110 @@
+package storetest
+
+import (
+	"backend/menus"
+	"context"
+	"testing"
+)
+
+// Menus menguji menus.Store. Menu dibuat di cafeID; otherCafeID dipakai
+// untuk memastikan cafe lain tidak bisa membaca atau mengubahnya.
+func Menus(t *testing.T, store menus.Store, cafeID, otherCafeID int) {
+	ctx := context.Background()
+
+	latte, err := store.Create(ctx, cafeID, menus.NewMenu{Name: "Latte", Price: 25000, Category: "Kopi"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if latte.ID == "" || latte.CafeID != cafeID || latte.Status != menus.StatusActive || latte.DiscountedPrice != 25000 {
+		t.Fatalf("Create = %+v", latte)
+	}
+	if _, err := store.Create(ctx, cafeID, menus.NewMenu{Name: "Americano", Price: 20000, Category: "Kopi", Status: menus.StatusInactive}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := store.Create(ctx, cafeID, menus.NewMenu{Name: "Croissant", Price: 18000, Category: "Bakery", Img: "/uploads/c.png"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := store.Create(ctx, otherCafeID, menus.NewMenu{Name: "Teh Tarik", Price: 15000, Category: "Teh"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	names := func(list []menus.Menu) []string {
+		out := []string{}
+		for _, m := range list {
+			out = append(out, m.Name)
+		}
+		return out
+	}
+
+	t.Run("daftar & filter", func(t *testing.T) {
+		cases := []struct {
+			filter menus.Filter
+			want   []string
+		}{
+			{menus.Filter{}, []string{"Croissant", "Americano", "Latte"}},
+			{menus.Filter{Category: "kopi"}, []string{"Americano", "Latte"}},
+			{menus.Filter{Status: menus.StatusActive}, []string{"Croissant", "Latte"}},
+			{menus.Filter{Search: "LAT"}, []string{"Latte"}},
+			{menus.Filter{Search: "bake"}, []string{"Croissant"}},
+		}
+		for _, c := range cases {
+			list, err := store.List(ctx, cafeID, c.filter)
+			if err != nil {
+				t.Fatalf("List(%+v): %v", c.filter, err)
+			}
+			if got := names(list); !equalStrings(got, c.want) {
+				t.Errorf("List(%+v) = %v, mau %v", c.filter, got, c.want)
+			}
+		}
+	})
+
+	t.Run("ubah", func(t *testing.T) {
+		price, status := 27000.0, menus.StatusInactive
+		m, err := store.Update(ctx, cafeID, latte.ID, menus.Changes{Price: &price, Status: &status})
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if m.Price != 27000 || m.DiscountedPrice != 27000 || m.Status != status || m.Name != "Latte" {
+			t.Fatalf("Update = %+v", m)
+		}
+		m, err = store.Update(ctx, cafeID, latte.ID, menus.Changes{})
+		if err != nil || m.Price != 27000 {
+			t.Fatalf("Update tanpa perubahan = %+v, %v", m, err)
+		}
+	})
+
+	t.Run("cafe lain", func(t *testing.T) {
+		name := "Bukan milikmu"
+		if _, err := store.Get(ctx, otherCafeID, latte.ID); err != menus.ErrNotFound {
+			t.Errorf("Get: err = %v, mau ErrNotFound", err)
+		}
+		if _, err := store.Update(ctx, otherCafeID, latte.ID, menus.Changes{Name: &name}); err != menus.ErrNotFound {
+			t.Errorf("Update: err = %v, mau ErrNotFound", err)
+		}
+		if _, err := store.Delete(ctx, otherCafeID, latte.ID); err != menus.ErrNotFound {
+			t.Errorf("Delete: err = %v, mau ErrNotFound", err)
+		}
+	})
+
+	t.Run("hapus", func(t *testing.T) {
+		m, err := store.Delete(ctx, cafeID, latte.ID)
+		if err != nil || m.Name != "Latte" {
+			t.Fatalf("Delete = %+v, %v", m, err)
+		}
+		if _, err := store.Get(ctx, cafeID, latte.ID); err != menus.ErrNotFound {
+			t.Fatalf("Get setelah dihapus: err = %v, mau ErrNotFound", err)
+		}
+	})
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
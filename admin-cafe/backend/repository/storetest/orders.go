@@ -0,0 +1,121 @@
+package storetest
+
+import (
+	"backend/models"
+	"backend/repository"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Orders menguji perubahan status di repository.OrderStore. menuID adalah
+// menu aktif milik cafeID; otherCafeID dipakai untuk memastikan cafe lain
+// tidak bisa mengubah pesanan.
+func Orders(t *testing.T, store repository.OrderStore, cafeID, otherCafeID, customerID int, menuID string) {
+	ctx := context.Background()
+
+	now := time.Now()
+	place := func(t *testing.T) *models.Order {
+		t.Helper()
+		o, err := store.Create(ctx, models.Cart{
+			CafeID: cafeID, OrderType: models.OrderPickup, PickupName: "Budi",
+			Items: []models.CartItem{{MenuID: menuID, Quantity: 2}},
+		}, &customerID, customerID, now)
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if o.Status != models.OrderPlaced || o.Total <= 0 || len(o.Items) != 1 {
+			t.Fatalf("Create = %+v", o)
+		}
+		return o
+	}
+	update := func(id int, from, status string) (*models.Order, error) {
+		return store.UpdateStatus(ctx, id, cafeID, from, status, "", now)
+	}
+	rejected := func(t *testing.T, err error, from, to string) {
+		t.Helper()
+		var te *repository.OrderTransitionError
+		if !errors.As(err, &te) || te.From != from || te.To != to {
+			t.Fatalf("err = %v, mau OrderTransitionError %s -> %s", err, from, to)
+		}
+	}
+
+	t.Run("alur normal", func(t *testing.T) {
+		o := place(t)
+		for _, step := range []struct{ from, to string }{
+			{models.OrderPlaced, models.OrderPreparing},
+			{"", models.OrderReady},
+			{models.OrderReady, models.OrderCompleted},
+		} {
+			got, err := update(o.ID, step.from, step.to)
+			if err != nil {
+				t.Fatalf("UpdateStatus %s: %v", step.to, err)
+			}
+			if got.Status != step.to {
+				t.Fatalf("UpdateStatus status = %s, mau %s", got.Status, step.to)
+			}
+		}
+		got, err := store.GetByID(ctx, o.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Status != models.OrderCompleted || got.CompletedAt == nil || got.PreparingAt == nil || got.ReadyAt == nil {
+			t.Fatalf("GetByID = %+v", got)
+		}
+		_, err = update(o.ID, "", models.OrderCancelled)
+		rejected(t, err, models.OrderCompleted, models.OrderCancelled)
+	})
+
+	t.Run("perpindahan tidak valid", func(t *testing.T) {
+		o := place(t)
+		_, err := update(o.ID, "", models.OrderReady)
+		rejected(t, err, models.OrderPlaced, models.OrderReady)
+		_, err = update(o.ID, "", models.OrderCompleted)
+		rejected(t, err, models.OrderPlaced, models.OrderCompleted)
+	})
+
+	t.Run("status awal berubah", func(t *testing.T) {
+		o := place(t)
+		if _, err := update(o.ID, models.OrderPlaced, models.OrderPreparing); err != nil {
+			t.Fatalf("UpdateStatus: %v", err)
+		}
+		// Pembatalan oleh customer hanya berlaku selama pesanan masih placed
+		_, err := update(o.ID, models.OrderPlaced, models.OrderCancelled)
+		rejected(t, err, models.OrderPreparing, models.OrderCancelled)
+	})
+
+	t.Run("batal", func(t *testing.T) {
+		o := place(t)
+		got, err := store.UpdateStatus(ctx, o.ID, cafeID, models.OrderPlaced, models.OrderCancelled, "Stok habis", now)
+		if err != nil {
+			t.Fatalf("UpdateStatus: %v", err)
+		}
+		if got.Status != models.OrderCancelled || got.CancelReason != "Stok habis" || got.CancelledAt == nil {
+			t.Fatalf("UpdateStatus = %+v", got)
+		}
+		_, err = update(o.ID, "", models.OrderPreparing)
+		rejected(t, err, models.OrderCancelled, models.OrderPreparing)
+	})
+
+	t.Run("varian negatif", func(t *testing.T) {
+		v := &models.MenuVariant{MenuID: menuID, Name: "Diskon", PriceDelta: -100000000, Available: true}
+		if err := store.CreateVariant(ctx, v); err != repository.ErrVariantPriceNegative {
+			t.Fatalf("CreateVariant: err = %v, mau ErrVariantPriceNegative", err)
+		}
+	})
+
+	t.Run("cafe lain", func(t *testing.T) {
+		o := place(t)
+		if _, err := store.UpdateStatus(ctx, o.ID, otherCafeID, "", models.OrderPreparing, "", now); err != repository.ErrOrderNotFound {
+			t.Fatalf("UpdateStatus: err = %v, mau ErrOrderNotFound", err)
+		}
+		if _, err := update(999999, "", models.OrderPreparing); err != repository.ErrOrderNotFound {
+			t.Fatalf("UpdateStatus tidak ada: err = %v, mau ErrOrderNotFound", err)
+		}
+		got, err := store.GetByID(ctx, o.ID)
+		if err != nil || got.Status != models.OrderPlaced {
+			t.Fatalf("GetByID = %+v, %v", got, err)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package storetest
+
+import (
+	"backend/payments"
+	"context"
+	"testing"
+)
+
+// Payments menguji payments.Store terhadap webhook yang dikirim ulang.
+// orderID adalah pesanan yang belum dibayar dengan total di atas nol.
+func Payments(t *testing.T, store payments.Store, orderID int) {
+	ctx := context.Background()
+
+	p, err := store.CreateForOrder(ctx, orderID, payments.MethodQRIS)
+	if err != nil {
+		t.Fatalf("CreateForOrder: %v", err)
+	}
+	if p.Status != payments.StatusPending || p.ProviderRef == "" || p.Amount <= 0 {
+		t.Fatalf("CreateForOrder = %+v", p)
+	}
+
+	paid := &payments.WebhookEvent{
+		EventID: "evt_1", ProviderRef: p.ProviderRef, Status: payments.StatusPaid, Amount: p.Amount,
+	}
+	duplicate, err := store.HandleWebhook(ctx, paid)
+	if err != nil || duplicate {
+		t.Fatalf("HandleWebhook = %v, %v", duplicate, err)
+	}
+	got, err := store.GetByID(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Status != payments.StatusPaid || got.PaidAt == nil {
+		t.Fatalf("GetByID = %+v", got)
+	}
+	paidAt := *got.PaidAt
+
+	t.Run("event dikirim ulang", func(t *testing.T) {
+		duplicate, err := store.HandleWebhook(ctx, paid)
+		if err != nil || !duplicate {
+			t.Fatalf("HandleWebhook = %v, %v, mau duplicate", duplicate, err)
+		}
+		// Event ID yang sama tidak diproses lagi meskipun isinya berbeda
+		duplicate, err = store.HandleWebhook(ctx, &payments.WebhookEvent{
+			EventID: "evt_1", ProviderRef: p.ProviderRef, Status: payments.StatusRefunded, Amount: p.Amount,
+		})
+		if err != nil || !duplicate {
+			t.Fatalf("HandleWebhook = %v, %v, mau duplicate", duplicate, err)
+		}
+		got, err := store.GetByID(ctx, p.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Status != payments.StatusPaid || got.RefundedAt != nil || !got.PaidAt.Equal(paidAt) {
+			t.Fatalf("GetByID = %+v", got)
+		}
+	})
+
+	t.Run("event baru status lama", func(t *testing.T) {
+		duplicate, err := store.HandleWebhook(ctx, &payments.WebhookEvent{
+			EventID: "evt_2", ProviderRef: p.ProviderRef, Status: payments.StatusExpired, Amount: p.Amount,
+		})
+		if err != nil || duplicate {
+			t.Fatalf("HandleWebhook = %v, %v", duplicate, err)
+		}
+		got, err := store.GetByID(ctx, p.ID)
+		if err != nil || got.Status != payments.StatusPaid {
+			t.Fatalf("GetByID = %+v, %v", got, err)
+		}
+	})
+
+	t.Run("pesanan sudah dibayar", func(t *testing.T) {
+		if _, err := store.CreateForOrder(ctx, orderID, payments.MethodQRIS); err != payments.ErrAlreadyPaid {
+			t.Fatalf("CreateForOrder: err = %v, mau ErrAlreadyPaid", err)
+		}
+	})
+}
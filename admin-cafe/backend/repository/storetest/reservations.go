@@ -0,0 +1,117 @@
+package storetest
+
+import (
+	"backend/models"
+	"backend/repository"
+	"context"
+	"testing"
+	"time"
+)
+
+// Reservations menguji bentrok jadwal di repository.ReservationStore.
+// Cafe cafeID belum punya meja; suite membuat satu meja supaya setiap
+// reservasi memperebutkan meja yang sama.
+func Reservations(t *testing.T, store repository.ReservationStore, loc *time.Location, cafeID, customerID int) {
+	ctx := context.Background()
+
+	table := &models.CafeTable{CafeID: cafeID, Label: "A1", Area: "indoor", Capacity: 4, Active: true}
+	if err := store.CreateTable(ctx, table); err != nil {
+		t.Fatalf("CreateTable: %v", err)
+	}
+
+	now := time.Now().In(loc)
+	day := now.Add(48 * time.Hour).Truncate(time.Hour)
+	book := func(start, end, holdUntil, now time.Time) (*models.Reservation, error) {
+		return store.Create(ctx, models.ReservationRequest{
+			CafeID: cafeID, CustomerID: customerID, PartySize: 2,
+			Start: start, End: end, HoldUntil: holdUntil,
+		}, now)
+	}
+
+	first, err := book(day, day.Add(2*time.Hour), now.Add(15*time.Minute), now)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if first.TableID != table.ID || first.Status != models.ReservationPending {
+		t.Fatalf("Create = %+v", first)
+	}
+
+	t.Run("bentrok", func(t *testing.T) {
+		slots := []struct{ start, end time.Time }{
+			{day.Add(time.Hour), day.Add(3 * time.Hour)},
+			{day.Add(-time.Hour), day.Add(time.Hour)},
+			{day.Add(30 * time.Minute), day.Add(90 * time.Minute)},
+			{day.Add(-time.Hour), day.Add(3 * time.Hour)},
+		}
+		for _, s := range slots {
+			if _, err := book(s.start, s.end, now.Add(15*time.Minute), now); err != repository.ErrNoTableAvailable {
+				t.Errorf("Create %s-%s: err = %v, mau ErrNoTableAvailable", s.start.Format("15:04"), s.end.Format("15:04"), err)
+			}
+		}
+		tables, err := store.AvailableTables(ctx, cafeID, day.Add(time.Hour), day.Add(3*time.Hour), 2, "", now)
+		if err != nil || len(tables) != 0 {
+			t.Fatalf("AvailableTables = %v, %v, mau kosong", tables, err)
+		}
+		if _, err := store.Create(ctx, models.ReservationRequest{
+			CafeID: cafeID, CustomerID: customerID, PartySize: 6,
+			Start: day.Add(5 * time.Hour), End: day.Add(6 * time.Hour), HoldUntil: now.Add(15 * time.Minute),
+		}, now); err != repository.ErrNoTableAvailable {
+			t.Errorf("Create melebihi kapasitas: err = %v, mau ErrNoTableAvailable", err)
+		}
+	})
+
+	t.Run("bersebelahan", func(t *testing.T) {
+		tables, err := store.AvailableTables(ctx, cafeID, day.Add(2*time.Hour), day.Add(4*time.Hour), 2, "", now)
+		if err != nil || len(tables) != 1 || tables[0].ID != table.ID {
+			t.Fatalf("AvailableTables = %v, %v", tables, err)
+		}
+		if _, err := book(day.Add(2*time.Hour), day.Add(4*time.Hour), now.Add(15*time.Minute), now); err != nil {
+			t.Fatalf("Create setelah reservasi lain selesai: %v", err)
+		}
+	})
+
+	t.Run("ubah status", func(t *testing.T) {
+		res, err := store.UpdateStatus(ctx, first.ID, models.ReservationPending, models.ReservationConfirmed, "", now)
+		if err != nil || res.Status != models.ReservationConfirmed {
+			t.Fatalf("UpdateStatus = %+v, %v", res, err)
+		}
+		if _, err := store.UpdateStatus(ctx, first.ID, models.ReservationPending, models.ReservationCancelled, "", now); err != repository.ErrReservationChanged {
+			t.Fatalf("UpdateStatus status lama: err = %v, mau ErrReservationChanged", err)
+		}
+		if _, err := store.UpdateStatus(ctx, 999999, models.ReservationPending, models.ReservationConfirmed, "", now); err != repository.ErrReservationChanged {
+			t.Fatalf("UpdateStatus tidak ada: err = %v, mau ErrReservationChanged", err)
+		}
+		if _, err := store.GetByID(ctx, 999999); err != repository.ErrReservationNotFound {
+			t.Fatalf("GetByID: err = %v, mau ErrReservationNotFound", err)
+		}
+	})
+
+	t.Run("batal membebaskan meja", func(t *testing.T) {
+		res, err := store.UpdateStatus(ctx, first.ID, models.ReservationConfirmed, models.ReservationCancelled, "Batal", now)
+		if err != nil || res.Status != models.ReservationCancelled || res.DeclineReason != "Batal" {
+			t.Fatalf("UpdateStatus = %+v, %v", res, err)
+		}
+		if _, err := book(day.Add(time.Hour), day.Add(2*time.Hour), now.Add(15*time.Minute), now); err != nil {
+			t.Fatalf("Create setelah batal: %v", err)
+		}
+	})
+
+	t.Run("hold kedaluwarsa", func(t *testing.T) {
+		start := day.Add(24 * time.Hour)
+		held, err := book(start, start.Add(time.Hour), now.Add(time.Minute), now)
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if _, err := book(start, start.Add(time.Hour), now.Add(15*time.Minute), now); err != repository.ErrNoTableAvailable {
+			t.Fatalf("Create saat hold aktif: err = %v, mau ErrNoTableAvailable", err)
+		}
+
+		later := now.Add(2 * time.Minute)
+		if _, err := store.UpdateStatus(ctx, held.ID, models.ReservationPending, models.ReservationConfirmed, "", later); err != repository.ErrReservationChanged {
+			t.Fatalf("UpdateStatus hold habis: err = %v, mau ErrReservationChanged", err)
+		}
+		if _, err := book(start, start.Add(time.Hour), later.Add(15*time.Minute), later); err != nil {
+			t.Fatalf("Create setelah hold habis: %v", err)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package storetest
+
+import (
+	"backend/reviews"
+	"context"
+	"testing"
+	"time"
+)
+
+// Reviews menguji reviews.Store. userID adalah akun customer penulis
+// ulasan; waktu dibandingkan di zona loc yang dipakai store.
+func Reviews(t *testing.T, store reviews.Store, loc *time.Location, cafeID, otherCafeID, userID int) {
+	ctx := context.Background()
+
+	base := time.Date(2026, 3, 1, 9, 30, 15, 500, loc)
+	create := func(cafeID, rating int, text string, at time.Time) *reviews.Review {
+		t.Helper()
+		rv, err := store.Create(ctx, cafeID, reviews.NewReview{
+			Rating: rating, Text: text, UserID: userID, Name: "Budi", Email: "budi@mail.com",
+		}, at)
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		return rv
+	}
+
+	first := create(cafeID, 5, "Kopinya enak", base)
+	if first.ID == "" || first.CafeID != cafeID || first.Status != reviews.StatusPending ||
+		first.UserID == nil || *first.UserID != userID || first.Name != "Budi" {
+		t.Fatalf("Create = %+v", first)
+	}
+	if want := base.Truncate(time.Second); !first.Date.Equal(want) || first.Date.Location().String() != loc.String() {
+		t.Fatalf("Create Date = %v, mau %v", first.Date, want)
+	}
+	second := create(cafeID, 3, "Agak lama", base.Add(time.Minute))
+	third := create(cafeID, 5, "Tempatnya nyaman", base.Add(2*time.Minute))
+	create(otherCafeID, 1, "Bukan cafe ini", base)
+
+	ids := func(list []reviews.Review) []string {
+		out := []string{}
+		for _, rv := range list {
+			out = append(out, rv.ID)
+		}
+		return out
+	}
+
+	t.Run("daftar & filter", func(t *testing.T) {
+		cases := []struct {
+			filter reviews.Filter
+			want   []string
+			total  int
+		}{
+			{reviews.Filter{Limit: 10}, []string{third.ID, second.ID, first.ID}, 3},
+			{reviews.Filter{Limit: 1, Offset: 1}, []string{second.ID}, 3},
+			{reviews.Filter{Rating: 5, Limit: 10}, []string{third.ID, first.ID}, 2},
+			{reviews.Filter{Status: reviews.StatusApproved, Limit: 10}, []string{}, 0},
+		}
+		for _, c := range cases {
+			list, total, err := store.List(ctx, cafeID, c.filter)
+			if err != nil {
+				t.Fatalf("List(%+v): %v", c.filter, err)
+			}
+			if got := ids(list); !equalStrings(got, c.want) || total != c.total {
+				t.Errorf("List(%+v) = %v (total %d), mau %v (total %d)", c.filter, got, total, c.want, c.total)
+			}
+		}
+	})
+
+	t.Run("balasan menyetujui ulasan pending", func(t *testing.T) {
+		rv, err := store.SetReply(ctx, cafeID, first.ID, "Terima kasih!", base.Add(time.Hour))
+		if err != nil {
+			t.Fatalf("SetReply: %v", err)
+		}
+		if rv.Reply != "Terima kasih!" || rv.Status != reviews.StatusApproved || !rv.UpdatedAt.Equal(base.Add(time.Hour).Truncate(time.Second)) {
+			t.Fatalf("SetReply = %+v", rv)
+		}
+
+		if _, err := store.SetStatus(ctx, cafeID, second.ID, reviews.StatusHidden, base.Add(time.Hour)); err != nil {
+			t.Fatalf("SetStatus: %v", err)
+		}
+		rv, err = store.SetReply(ctx, cafeID, second.ID, "Maaf ya", base.Add(time.Hour))
+		if err != nil || rv.Status != reviews.StatusHidden {
+			t.Fatalf("SetReply ulasan tersembunyi = %+v, %v; status harus tetap hidden", rv, err)
+		}
+
+		rv, err = store.ClearReply(ctx, cafeID, first.ID, base.Add(2*time.Hour))
+		if err != nil || rv.Reply != "" || rv.Status != reviews.StatusApproved {
+			t.Fatalf("ClearReply = %+v, %v", rv, err)
+		}
+	})
+
+	t.Run("statistik", func(t *testing.T) {
+		st, err := store.Stats(ctx, cafeID)
+		if err != nil {
+			t.Fatalf("Stats: %v", err)
+		}
+		if st.Total != 3 || st.Average != 4.3 || st.ByRating[5] != 2 || st.ByRating[3] != 1 || st.ByRating[1] != 0 ||
+			st.ByStatus[reviews.StatusPending] != 1 || st.ByStatus[reviews.StatusApproved] != 1 ||
+			st.ByStatus[reviews.StatusHidden] != 1 || st.Unreplied != 2 {
+			t.Fatalf("Stats = %+v", st)
+		}
+	})
+
+	t.Run("cafe lain", func(t *testing.T) {
+		if _, err := store.Get(ctx, otherCafeID, first.ID); err != reviews.ErrNotFound {
+			t.Errorf("Get: err = %v, mau ErrNotFound", err)
+		}
+		if _, err := store.SetReply(ctx, otherCafeID, first.ID, "x", base); err != reviews.ErrNotFound {
+			t.Errorf("SetReply: err = %v, mau ErrNotFound", err)
+		}
+		if _, err := store.Delete(ctx, otherCafeID, first.ID); err != reviews.ErrNotFound {
+			t.Errorf("Delete: err = %v, mau ErrNotFound", err)
+		}
+	})
+
+	t.Run("hapus", func(t *testing.T) {
+		rv, err := store.Delete(ctx, cafeID, third.ID)
+		if err != nil || rv.ID != third.ID {
+			t.Fatalf("Delete = %+v, %v", rv, err)
+		}
+		if _, err := store.Get(ctx, cafeID, third.ID); err != reviews.ErrNotFound {
+			t.Fatalf("Get setelah dihapus: err = %v, mau ErrNotFound", err)
+		}
+	})
+}
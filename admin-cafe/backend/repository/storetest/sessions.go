@@ -0,0 +1,98 @@
+package storetest
+
+import (
+	"backend/models"
+	"backend/repository"
+	"context"
+	"testing"
+	"time"
+)
+
+// Sessions menguji repository.SessionStore. users dipakai untuk membuat
+// pemilik session.
+func Sessions(t *testing.T, users repository.UserStore, sessions repository.SessionStore) {
+	ctx := context.Background()
+
+	owner := &models.User{Username: "kopikita", Password: "rahasia123", Role: models.RoleCafe}
+	if err := users.CreateCafe(ctx, owner); err != nil {
+		t.Fatalf("CreateCafe: %v", err)
+	}
+
+	first, _, err := sessions.Create(ctx, owner.ID, time.Hour)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	second, _, err := sessions.Create(ctx, owner.ID, time.Hour)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	u, err := sessions.Validate(ctx, first)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if u.ID != owner.ID || u.Username != "kopikita" || u.Role != models.RoleCafe {
+		t.Fatalf("Validate = %+v", u)
+	}
+	if _, err := sessions.Validate(ctx, "bukan-token"); err != repository.ErrSessionInvalid {
+		t.Fatalf("Validate token asing: err = %v, mau ErrSessionInvalid", err)
+	}
+
+	expired, _, err := sessions.Create(ctx, owner.ID, -time.Minute)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := sessions.Validate(ctx, expired); err != repository.ErrSessionInvalid {
+		t.Fatalf("Validate kedaluwarsa: err = %v, mau ErrSessionInvalid", err)
+	}
+
+	if err := sessions.RevokeOthers(ctx, owner.ID, second); err != nil {
+		t.Fatalf("RevokeOthers: %v", err)
+	}
+	if _, err := sessions.Validate(ctx, first); err != repository.ErrSessionInvalid {
+		t.Fatalf("Validate setelah RevokeOthers: err = %v, mau ErrSessionInvalid", err)
+	}
+	if _, err := sessions.Validate(ctx, second); err != nil {
+		t.Fatalf("Validate token yang disimpan: %v", err)
+	}
+
+	if err := sessions.Revoke(ctx, second); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := sessions.Validate(ctx, second); err != repository.ErrSessionInvalid {
+		t.Fatalf("Validate setelah logout: err = %v, mau ErrSessionInvalid", err)
+	}
+}
+
+// Tokens menguji repository.TokenStore
+func Tokens(t *testing.T, users repository.UserStore, tokens repository.TokenStore) {
+	ctx := context.Background()
+
+	userID, err := users.CreateCustomer(ctx, &models.User{Username: "budi", Password: "rahasia123", Email: "budi@mail.com"}, "", "")
+	if err != nil {
+		t.Fatalf("CreateCustomer: %v", err)
+	}
+
+	old, err := tokens.Create(ctx, userID, repository.TokenPurposeVerifyEmail, time.Hour)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	token, err := tokens.Create(ctx, userID, repository.TokenPurposeVerifyEmail, time.Hour)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if _, err := tokens.Consume(ctx, old, repository.TokenPurposeVerifyEmail); err != repository.ErrTokenInvalid {
+		t.Fatalf("Consume token lama: err = %v, mau ErrTokenInvalid", err)
+	}
+	if _, err := tokens.Consume(ctx, token, repository.TokenPurposeResetPassword); err != repository.ErrTokenInvalid {
+		t.Fatalf("Consume tujuan lain: err = %v, mau ErrTokenInvalid", err)
+	}
+	got, err := tokens.Consume(ctx, token, repository.TokenPurposeVerifyEmail)
+	if err != nil || got != userID {
+		t.Fatalf("Consume = %d, %v; mau %d", got, err, userID)
+	}
+	if _, err := tokens.Consume(ctx, token, repository.TokenPurposeVerifyEmail); err != repository.ErrTokenInvalid {
+		t.Fatalf("Consume kedua kali: err = %v, mau ErrTokenInvalid", err)
+	}
+}
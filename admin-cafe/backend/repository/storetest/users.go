@@ -0,0 +1,171 @@
+// Package storetest berisi suite kontrak untuk interface penyimpanan.
+// Suite yang sama dijalankan terhadap fake di package memory dan terhadap
+// implementasi SQL (lewat pgtest), supaya perilaku fake yang dipakai unit
+// test handler tidak menyimpang dari database sungguhan. Store yang belum
+// punya fake (reservasi, akun, pesanan, pembayaran) hanya diuji lewat
+// pgtest.
+//
+// Setiap suite mengharapkan store yang masih kosong.
+package storetest
+
+import (
+	"backend/models"
+	"backend/repository"
+	"context"
+	"database/sql"
+	"testing"
+)
+
+// Users menguji repository.UserStore
+func Users(t *testing.T, users repository.UserStore) {
+	ctx := context.Background()
+
+	cafe := &models.User{Username: "kopikita", Password: "rahasia123", Email: "owner@kopikita.id", Role: models.RoleCafe, IzinUsaha: "uploads/izin.pdf"}
+	if err := users.CreateCafe(ctx, cafe); err != nil {
+		t.Fatalf("CreateCafe: %v", err)
+	}
+	if cafe.ID == 0 {
+		t.Fatal("CreateCafe tidak mengisi ID")
+	}
+	verifiedCafe := &models.User{Username: "senja", Password: "rahasia123", Email: "owner@senja.id", Role: models.RoleCafe, IzinUsaha: "uploads/izin2.pdf"}
+	if err := users.CreateCafe(ctx, verifiedCafe); err != nil {
+		t.Fatalf("CreateCafe: %v", err)
+	}
+
+	t.Run("username unik", func(t *testing.T) {
+		err := users.CreateCafe(ctx, &models.User{Username: "kopikita", Password: "x", Role: models.RoleCafe})
+		if !repository.IsUniqueViolation(err) {
+			t.Fatalf("CreateCafe duplikat: err = %v, mau unique violation", err)
+		}
+		exists, err := users.Exists(ctx, "kopikita")
+		if err != nil || !exists {
+			t.Fatalf("Exists = %v, %v", exists, err)
+		}
+		exists, err = users.Exists(ctx, "tidakada")
+		if err != nil || exists {
+			t.Fatalf("Exists(tidakada) = %v, %v", exists, err)
+		}
+	})
+
+	t.Run("verifikasi cafe", func(t *testing.T) {
+		if err := users.VerifyCafe(ctx, verifiedCafe.ID); err != nil {
+			t.Fatalf("VerifyCafe: %v", err)
+		}
+		u, err := users.GetUserByID(ctx, verifiedCafe.ID)
+		if err != nil {
+			t.Fatalf("GetUserByID: %v", err)
+		}
+		if !u.Verified || u.Email != "owner@senja.id" || u.Role != models.RoleCafe {
+			t.Fatalf("GetUserByID = %+v", u)
+		}
+
+		list, err := users.ListCafes(ctx)
+		if err != nil {
+			t.Fatalf("ListCafes: %v", err)
+		}
+		want := []models.CafeAccount{
+			{ID: cafe.ID, Username: "kopikita", Email: "owner@kopikita.id", IzinUsaha: "uploads/izin.pdf"},
+			{ID: verifiedCafe.ID, Username: "senja", Email: "owner@senja.id", IzinUsaha: "uploads/izin2.pdf", Verified: true},
+		}
+		if len(list) != len(want) || list[0] != want[0] || list[1] != want[1] {
+			t.Fatalf("ListCafes = %+v, mau %+v", list, want)
+		}
+	})
+
+	t.Run("tolak cafe", func(t *testing.T) {
+		rejected, err := users.RejectCafe(ctx, verifiedCafe.ID)
+		if err != nil || rejected {
+			t.Fatalf("RejectCafe(terverifikasi) = %v, %v; mau false", rejected, err)
+		}
+		rejected, err = users.RejectCafe(ctx, cafe.ID)
+		if err != nil || !rejected {
+			t.Fatalf("RejectCafe(pending) = %v, %v; mau true", rejected, err)
+		}
+		if _, err := users.GetUserByID(ctx, cafe.ID); err != sql.ErrNoRows {
+			t.Fatalf("GetUserByID setelah ditolak: err = %v, mau sql.ErrNoRows", err)
+		}
+	})
+
+	customer := &models.User{Username: "budi", Password: "rahasia123", Email: "Budi@Mail.com"}
+	customerID, err := users.CreateCustomer(ctx, customer, "Budi", "08123")
+	if err != nil {
+		t.Fatalf("CreateCustomer: %v", err)
+	}
+
+	t.Run("email customer unik", func(t *testing.T) {
+		exists, err := users.CustomerEmailExists(ctx, "budi@mail.com")
+		if err != nil || !exists {
+			t.Fatalf("CustomerEmailExists = %v, %v", exists, err)
+		}
+		_, err = users.CreateCustomer(ctx, &models.User{Username: "budi2", Password: "x", Email: "BUDI@mail.com"}, "", "")
+		if !repository.IsUniqueViolation(err) {
+			t.Fatalf("CreateCustomer email duplikat: err = %v, mau unique violation", err)
+		}
+	})
+
+	t.Run("login customer", func(t *testing.T) {
+		u, err := users.GetLoginUser(ctx, "budi", models.RoleCustomer)
+		if err != nil {
+			t.Fatalf("GetLoginUser: %v", err)
+		}
+		if u.ID != customerID || u.Password != "rahasia123" || u.EmailVerified || u.Suspended {
+			t.Fatalf("GetLoginUser = %+v", u)
+		}
+		if _, err := users.GetLoginUser(ctx, "budi", models.RoleCafe); err != sql.ErrNoRows {
+			t.Fatalf("GetLoginUser role lain: err = %v, mau sql.ErrNoRows", err)
+		}
+
+		if err := users.MarkEmailVerified(ctx, customerID); err != nil {
+			t.Fatalf("MarkEmailVerified: %v", err)
+		}
+		if err := users.ChangePassword(ctx, customerID, "baru12345"); err != nil {
+			t.Fatalf("ChangePassword: %v", err)
+		}
+		u, err = users.GetLoginUser(ctx, "budi", models.RoleCustomer)
+		if err != nil || !u.EmailVerified || u.Password != "baru12345" || u.MustChangePassword {
+			t.Fatalf("GetLoginUser setelah verifikasi = %+v, %v", u, err)
+		}
+	})
+
+	t.Run("profil customer", func(t *testing.T) {
+		if err := users.UpdateCustomerProfile(ctx, customerID, "Budi S.", "0899"); err != nil {
+			t.Fatalf("UpdateCustomerProfile: %v", err)
+		}
+		old, err := users.UpdateAvatar(ctx, customerID, "/uploads/a.png")
+		if err != nil || old != "" {
+			t.Fatalf("UpdateAvatar pertama = %q, %v", old, err)
+		}
+		old, err = users.UpdateAvatar(ctx, customerID, "/uploads/b.png")
+		if err != nil || old != "/uploads/a.png" {
+			t.Fatalf("UpdateAvatar kedua = %q, %v", old, err)
+		}
+
+		p, err := users.GetCustomerProfile(ctx, customerID)
+		if err != nil {
+			t.Fatalf("GetCustomerProfile: %v", err)
+		}
+		if p.DisplayName != "Budi S." || p.Phone != "0899" || p.Avatar != "/uploads/b.png" || p.Email != "Budi@Mail.com" {
+			t.Fatalf("GetCustomerProfile = %+v", p)
+		}
+		if _, err := users.GetCustomerProfile(ctx, verifiedCafe.ID); err != sql.ErrNoRows {
+			t.Fatalf("GetCustomerProfile akun cafe: err = %v, mau sql.ErrNoRows", err)
+		}
+
+		found, err := users.FindByEmail(ctx, "budi@mail.com")
+		if err != nil || len(found) != 1 || found[0].ID != customerID {
+			t.Fatalf("FindByEmail = %+v, %v", found, err)
+		}
+	})
+
+	t.Run("hapus customer", func(t *testing.T) {
+		if err := users.DeleteCustomer(ctx, verifiedCafe.ID); err != sql.ErrNoRows {
+			t.Fatalf("DeleteCustomer akun cafe: err = %v, mau sql.ErrNoRows", err)
+		}
+		if err := users.DeleteCustomer(ctx, customerID); err != nil {
+			t.Fatalf("DeleteCustomer: %v", err)
+		}
+		if _, err := users.GetUserByUsername(ctx, "budi"); err != sql.ErrNoRows {
+			t.Fatalf("GetUserByUsername setelah dihapus: err = %v, mau sql.ErrNoRows", err)
+		}
+	})
+}
@@ -0,0 +1,565 @@
+package repository
+
+import (
+	"backend/dbtime"
+	"backend/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrPlanNotFound         = errors.New("paket langganan tidak ditemukan")
+	ErrSubscriptionNotFound = errors.New("tidak ada langganan premium yang aktif")
+	ErrAlreadySubscribed    = errors.New("akun ini sudah berlangganan premium")
+	ErrNotResumable         = errors.New("hanya langganan yang dibatalkan dan belum berakhir yang bisa dilanjutkan")
+)
+
+// Teks klaim di kartu log aktivitas
+const PremiumClaim = "Klaim : Anggota Premium"
+
+// SubscriptionStore adalah akses paket & langganan premium. Job
+// terjadwal (ProcessDue & RunScheduler) dipanggil langsung dari main.go.
+// Langganan berbayar dimulai berstatus pending dan baru aktif setelah
+// pembayarannya lunas (lihat payments.Service).
+type SubscriptionStore interface {
+	ListPlans(ctx context.Context, activeOnly bool) ([]models.SubscriptionPlan, error)
+	GetPlanByCode(ctx context.Context, code string) (*models.SubscriptionPlan, error)
+	SavePlan(ctx context.Context, p *models.SubscriptionPlan) error
+	GetByID(ctx context.Context, id int) (*models.Subscription, error)
+	Current(ctx context.Context, userID int) (*models.Subscription, error)
+	IsPremium(ctx context.Context, userID int, now time.Time) (bool, error)
+	Periods(ctx context.Context, subscriptionID int) ([]models.SubscriptionPeriod, error)
+	Start(ctx context.Context, userID int, planCode string, now time.Time) (*models.Subscription, error)
+	Cancel(ctx context.Context, userID int, now time.Time) (*models.Subscription, error)
+	Resume(ctx context.Context, userID int, now time.Time) (*models.Subscription, error)
+}
+
+var _ SubscriptionStore = (*SubscriptionRepository)(nil)
+
+// RenewalCharger membuat tagihan perpanjangan untuk langganan berstatus
+// past_due; dipenuhi payments.Service
+type RenewalCharger interface {
+	ChargeRenewal(ctx context.Context, subscriptionID int) error
+}
+
+type SubscriptionRepository struct {
+	db       *sql.DB
+	location *time.Location
+	renewals RenewalCharger
+}
+
+func NewSubscriptionRepository(db *sql.DB, location *time.Location, renewals RenewalCharger) *SubscriptionRepository {
+	return &SubscriptionRepository{db: db, location: location, renewals: renewals}
+}
+
+// =========================
+// Paket langganan
+// =========================
+const planColumns = `id, code, name, price::float8, billing_period, active`
+
+func scanPlan(row interface{ Scan(...interface{}) error }) (*models.SubscriptionPlan, error) {
+	p := &models.SubscriptionPlan{}
+	err := row.Scan(&p.ID, &p.Code, &p.Name, &p.Price, &p.BillingPeriod, &p.Active)
+	return p, err
+}
+
+func (r *SubscriptionRepository) ListPlans(ctx context.Context, activeOnly bool) ([]models.SubscriptionPlan, error) {
+	query := "SELECT " + planColumns + " FROM subscription_plans"
+	if activeOnly {
+		query += " WHERE active = true"
+	}
+	rows, err := r.db.QueryContext(ctx, query+" ORDER BY price, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plans := []models.SubscriptionPlan{}
+	for rows.Next() {
+		p, err := scanPlan(rows)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, *p)
+	}
+	return plans, rows.Err()
+}
+
+func (r *SubscriptionRepository) GetPlanByCode(ctx context.Context, code string) (*models.SubscriptionPlan, error) {
+	p, err := scanPlan(r.db.QueryRowContext(ctx, "SELECT "+planColumns+" FROM subscription_plans WHERE code=$1", code))
+	if err == sql.ErrNoRows {
+		return nil, ErrPlanNotFound
+	}
+	return p, err
+}
+
+// SavePlan membuat paket baru (ID 0) atau mengubah paket yang ada.
+// Perubahan harga berlaku mulai perpanjangan berikutnya.
+func (r *SubscriptionRepository) SavePlan(ctx context.Context, p *models.SubscriptionPlan) error {
+	if p.ID == 0 {
+		return r.db.QueryRowContext(ctx, `
+			INSERT INTO subscription_plans (code, name, price, billing_period, active)
+			VALUES ($1,$2,$3,$4,$5) RETURNING id`,
+			p.Code, p.Name, p.Price, p.BillingPeriod, p.Active,
+		).Scan(&p.ID)
+	}
+
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE subscription_plans SET name=$2, price=$3, billing_period=$4, active=$5
+		WHERE id=$1 RETURNING code`,
+		p.ID, p.Name, p.Price, p.BillingPeriod, p.Active,
+	).Scan(&p.Code)
+	if err == sql.ErrNoRows {
+		return ErrPlanNotFound
+	}
+	return err
+}
+
+// =========================
+// Langganan user
+// =========================
+const subscriptionColumns = `s.id, s.user_id, p.id, p.code, p.name, p.price::float8, p.billing_period, p.active,
+	s.status, s.current_period_start, s.current_period_end, s.cancelled_at, s.expired_at, s.created_at`
+
+const subscriptionJoins = `
+	FROM subscriptions s
+	JOIN subscription_plans p ON p.id = s.plan_id`
+
+func (r *SubscriptionRepository) scanSubscription(row interface{ Scan(...interface{}) error }) (*models.Subscription, error) {
+	s := &models.Subscription{}
+	var start, end, created time.Time
+	var cancelled, expired pq.NullTime
+
+	err := row.Scan(&s.ID, &s.UserID, &s.Plan.ID, &s.Plan.Code, &s.Plan.Name, &s.Plan.Price,
+		&s.Plan.BillingPeriod, &s.Plan.Active, &s.Status, &start, &end, &cancelled, &expired, &created)
+	if err != nil {
+		return nil, err
+	}
+	s.CurrentPeriodStart = dbtime.WallClock(start, r.location)
+	s.CurrentPeriodEnd = dbtime.WallClock(end, r.location)
+	s.CancelledAt = dbtime.WallClockNull(cancelled, r.location)
+	s.ExpiredAt = dbtime.WallClockNull(expired, r.location)
+	s.CreatedAt = dbtime.WallClock(created, r.location)
+	return s, nil
+}
+
+func (r *SubscriptionRepository) GetByID(ctx context.Context, id int) (*models.Subscription, error) {
+	s, err := r.scanSubscription(r.db.QueryRowContext(ctx, "SELECT "+subscriptionColumns+subscriptionJoins+" WHERE s.id=$1", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrSubscriptionNotFound
+	}
+	return s, err
+}
+
+// Current mengembalikan langganan user yang belum berakhir, atau yang
+// terakhir berakhir jika tidak ada
+func (r *SubscriptionRepository) Current(ctx context.Context, userID int) (*models.Subscription, error) {
+	s, err := r.scanSubscription(r.db.QueryRowContext(ctx, "SELECT "+subscriptionColumns+subscriptionJoins+`
+		WHERE s.user_id=$1
+		ORDER BY (s.status <> 'expired') DESC, s.id DESC LIMIT 1`, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrSubscriptionNotFound
+	}
+	return s, err
+}
+
+// IsPremium memeriksa apakah user sedang menjadi anggota premium. Dipakai
+// untuk menentukan promo khusus premium.
+func (r *SubscriptionRepository) IsPremium(ctx context.Context, userID int, now time.Time) (bool, error) {
+	var premium bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM subscriptions
+			WHERE user_id=$1 AND status IN ('active', 'cancelled') AND current_period_end > $2
+		)`, userID, dbtime.Format(now.In(r.location)),
+	).Scan(&premium)
+	return premium, err
+}
+
+// Periods mengembalikan riwayat tagihan langganan, terbaru dulu
+func (r *SubscriptionRepository) Periods(ctx context.Context, subscriptionID int) ([]models.SubscriptionPeriod, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, period_start, period_end, amount::float8, payment_id, created_at
+		FROM subscription_periods WHERE subscription_id=$1
+		ORDER BY period_start DESC`, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	periods := []models.SubscriptionPeriod{}
+	for rows.Next() {
+		var p models.SubscriptionPeriod
+		var start, end, created time.Time
+		var paymentID sql.NullInt64
+		if err := rows.Scan(&p.ID, &start, &end, &p.Amount, &paymentID, &created); err != nil {
+			return nil, err
+		}
+		if paymentID.Valid {
+			id := int(paymentID.Int64)
+			p.PaymentID = &id
+		}
+		p.PeriodStart = dbtime.WallClock(start, r.location)
+		p.PeriodEnd = dbtime.WallClock(end, r.location)
+		p.CreatedAt = dbtime.WallClock(created, r.location)
+		periods = append(periods, p)
+	}
+	return periods, rows.Err()
+}
+
+// =========================
+// Mulai berlangganan
+// =========================
+// Paket berbayar dibuat berstatus pending sampai pembayarannya lunas;
+// langganan pending yang belum dibayar diganti ke paket baru. Langganan
+// past_due dikembalikan apa adanya supaya tagihan perpanjangannya bisa
+// dibayar. Paket gratis langsung aktif.
+func (r *SubscriptionRepository) Start(ctx context.Context, userID int, planCode string, now time.Time) (*models.Subscription, error) {
+	plan, err := r.GetPlanByCode(ctx, planCode)
+	if err != nil {
+		return nil, err
+	}
+	if !plan.Active {
+		return nil, ErrPlanNotFound
+	}
+	now = now.In(r.location)
+	end := plan.PeriodEnd(now)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Langganan yang dibatalkan dan sudah lewat periodenya tapi belum
+	// diproses scheduler tidak boleh menghalangi langganan baru
+	_, err = tx.ExecContext(ctx, `
+		UPDATE subscriptions SET status='expired', expired_at=current_period_end, updated_at=$2
+		WHERE user_id=$1 AND status='cancelled' AND current_period_end <= $2`,
+		userID, dbtime.Format(now),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var id int
+	var status string
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, status FROM subscriptions
+		WHERE user_id=$1 AND status IN ('pending', 'active', 'cancelled', 'past_due')
+		FOR UPDATE`, userID,
+	).Scan(&id, &status)
+	switch {
+	case err == sql.ErrNoRows:
+		status = models.SubscriptionPending
+		if plan.Price <= 0 {
+			status = models.SubscriptionActive
+		}
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO subscriptions (user_id, plan_id, status, current_period_start, current_period_end, created_at, updated_at)
+			VALUES ($1,$2,$3,$4,$5,$4,$4) RETURNING id`,
+			userID, plan.ID, status, dbtime.Format(now), dbtime.Format(end),
+		).Scan(&id)
+		if IsUniqueViolation(err) {
+			return nil, ErrAlreadySubscribed
+		}
+		if err != nil {
+			return nil, err
+		}
+		if status == models.SubscriptionActive {
+			if err := insertPeriod(ctx, tx, id, now, end, plan.Price); err != nil {
+				return nil, err
+			}
+		}
+	case err != nil:
+		return nil, err
+	case status == models.SubscriptionPending:
+		// Periode sebenarnya ditetapkan saat pembayaran lunas
+		_, err = tx.ExecContext(ctx, `
+			UPDATE subscriptions SET plan_id=$2, current_period_start=$3, current_period_end=$4, updated_at=$3
+			WHERE id=$1`,
+			id, plan.ID, dbtime.Format(now), dbtime.Format(end),
+		)
+		if err != nil {
+			return nil, err
+		}
+	case status != models.SubscriptionPastDue:
+		return nil, ErrAlreadySubscribed
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+func insertPeriod(ctx context.Context, tx *sql.Tx, subscriptionID int, start, end time.Time, amount float64) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO subscription_periods (subscription_id, period_start, period_end, amount, created_at)
+		VALUES ($1,$2,$3,$4,$2)`,
+		subscriptionID, dbtime.Format(start), dbtime.Format(end), amount,
+	)
+	return err
+}
+
+// =========================
+// Batalkan / lanjutkan langganan
+// =========================
+// Pembatalan hanya menghentikan perpanjangan; akses premium tetap
+// berlaku sampai akhir periode yang sudah dibayar.
+func (r *SubscriptionRepository) Cancel(ctx context.Context, userID int, now time.Time) (*models.Subscription, error) {
+	return r.changeStatus(ctx, userID, models.SubscriptionActive, models.SubscriptionCancelled, now)
+}
+
+// Resume mengaktifkan kembali perpanjangan otomatis sebelum periode berakhir
+func (r *SubscriptionRepository) Resume(ctx context.Context, userID int, now time.Time) (*models.Subscription, error) {
+	return r.changeStatus(ctx, userID, models.SubscriptionCancelled, models.SubscriptionActive, now)
+}
+
+func (r *SubscriptionRepository) changeStatus(ctx context.Context, userID int, from, to string, now time.Time) (*models.Subscription, error) {
+	var id int
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE subscriptions
+		SET status=$3, updated_at=$4,
+			cancelled_at=CASE WHEN $3='cancelled' THEN $4::timestamp ELSE NULL END
+		WHERE user_id=$1 AND status=$2 AND current_period_end > $4
+		RETURNING id`,
+		userID, from, to, dbtime.Format(now.In(r.location)),
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		if from == models.SubscriptionCancelled {
+			return nil, ErrNotResumable
+		}
+		return nil, ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+// =========================
+// Scheduler: tagih / akhiri langganan
+// =========================
+// SubscriptionRunResult adalah ringkasan satu kali ProcessDue
+type SubscriptionRunResult struct {
+	Renewed int // paket gratis yang langsung diperpanjang
+	Charged int // tagihan perpanjangan yang dibuat
+	Expired int
+}
+
+// Hasil processOne
+type dueOutcome int
+
+const (
+	dueSkipped dueOutcome = iota
+	dueRenewed
+	dueCharge
+	dueExpired
+)
+
+// Langganan past_due tanpa tagihan pending selama ini dianggap gagal
+// ditagih (mis. proses berhenti sebelum tagihan dibuat)
+const pastDueGrace = time.Hour
+
+// ProcessDue memproses langganan yang periodenya sudah habis. Status
+// active dengan paket berbayar menjadi past_due lalu ditagih dengan
+// harga paket saat ini; akses premium berhenti sampai tagihan lunas.
+// Jika tagihan tidak bisa dibuat, langganan diakhiri. Status cancelled
+// (atau paketnya sudah dinonaktifkan) diakhiri.
+func (r *SubscriptionRepository) ProcessDue(ctx context.Context, now time.Time) (SubscriptionRunResult, error) {
+	var result SubscriptionRunResult
+	now = now.In(r.location)
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE subscriptions s SET status='expired', expired_at=current_period_end, updated_at=$1
+		WHERE s.status='past_due' AND s.updated_at < $2 AND NOT EXISTS (
+			SELECT 1 FROM payments p
+			WHERE p.reference_type='subscription' AND p.reference_id = s.id AND p.status='pending'
+		)`,
+		dbtime.Format(now), dbtime.Format(now.Add(-pastDueGrace)),
+	)
+	if err != nil {
+		return result, err
+	}
+	if n, err := res.RowsAffected(); err == nil {
+		result.Expired += int(n)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id FROM subscriptions
+		WHERE status IN ('active', 'cancelled') AND current_period_end <= $1
+		ORDER BY current_period_end LIMIT 500`,
+		dbtime.Format(now),
+	)
+	if err != nil {
+		return result, err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return result, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+
+	for _, id := range ids {
+		outcome, err := r.processOne(ctx, id, now)
+		if err != nil {
+			return result, err
+		}
+		switch outcome {
+		case dueRenewed:
+			result.Renewed++
+		case dueExpired:
+			result.Expired++
+		case dueCharge:
+			if err := r.renewals.ChargeRenewal(ctx, id); err != nil {
+				slog.WarnContext(ctx, "Tagihan perpanjangan langganan gagal dibuat", "subscription_id", id, "err", err)
+				if err := r.expirePastDue(ctx, id, now); err != nil {
+					return result, err
+				}
+				result.Expired++
+				continue
+			}
+			result.Charged++
+		}
+	}
+	return result, nil
+}
+
+// processOne mengunci satu langganan lalu memperpanjang (paket gratis),
+// menandainya past_due untuk ditagih, atau mengakhirinya. dueSkipped
+// berarti langganan sudah diproses di tempat lain.
+func (r *SubscriptionRepository) processOne(ctx context.Context, id int, now time.Time) (dueOutcome, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dueSkipped, err
+	}
+	defer tx.Rollback()
+
+	var userID int
+	var username, status string
+	var end time.Time
+	var plan models.SubscriptionPlan
+	err = tx.QueryRowContext(ctx, `
+		SELECT s.user_id, u.username, s.status, s.current_period_end,
+			p.id, p.code, p.name, p.price::float8, p.billing_period, p.active
+		FROM subscriptions s
+		JOIN subscription_plans p ON p.id = s.plan_id
+		JOIN users u ON u.id = s.user_id
+		WHERE s.id=$1
+		FOR UPDATE OF s`, id,
+	).Scan(&userID, &username, &status, &end,
+		&plan.ID, &plan.Code, &plan.Name, &plan.Price, &plan.BillingPeriod, &plan.Active)
+	if err == sql.ErrNoRows {
+		return dueSkipped, nil
+	}
+	if err != nil {
+		return dueSkipped, err
+	}
+	end = dbtime.WallClock(end, r.location)
+	if (status != models.SubscriptionActive && status != models.SubscriptionCancelled) || end.After(now) {
+		return dueSkipped, nil
+	}
+
+	activity := models.Activity{
+		ActorRole:  "system",
+		EntityType: "subscription",
+		EntityID:   strconv.Itoa(id),
+		Claim:      PremiumClaim,
+	}
+
+	var outcome dueOutcome
+	switch {
+	case status == models.SubscriptionActive && plan.Active && plan.Price <= 0:
+		// Periode baru dimulai tepat saat periode lama habis
+		next := plan.PeriodEnd(end)
+		_, err = tx.ExecContext(ctx, `
+			UPDATE subscriptions SET current_period_start=$2, current_period_end=$3, updated_at=$4
+			WHERE id=$1`,
+			id, dbtime.Format(end), dbtime.Format(next), dbtime.Format(now),
+		)
+		if err != nil {
+			return dueSkipped, err
+		}
+		if err := insertPeriod(ctx, tx, id, end, next, plan.Price); err != nil {
+			return dueSkipped, err
+		}
+		outcome = dueRenewed
+		activity.Action = "subscription.renewed"
+		activity.Title = fmt.Sprintf("USER %s memperpanjang langganan PREMIUM", username)
+		activity.Subject = "Perpanjangan " + plan.Name
+	case status == models.SubscriptionActive && plan.Active:
+		// Diperpanjang oleh payments.Service setelah tagihan lunas
+		_, err = tx.ExecContext(ctx, `UPDATE subscriptions SET status='past_due', updated_at=$2 WHERE id=$1`,
+			id, dbtime.Format(now))
+		if err != nil {
+			return dueSkipped, err
+		}
+		outcome = dueCharge
+		activity.Action = "subscription.renewal_due"
+		activity.Title = fmt.Sprintf("USER %s ditagih perpanjangan langganan PREMIUM", username)
+		activity.Subject = "Tagihan " + plan.Name
+	default:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE subscriptions SET status='expired', expired_at=current_period_end, updated_at=$2
+			WHERE id=$1`,
+			id, dbtime.Format(now),
+		)
+		if err != nil {
+			return dueSkipped, err
+		}
+		outcome = dueExpired
+		activity.Action = "subscription.expired"
+		activity.Title = fmt.Sprintf("Langganan PREMIUM USER %s berakhir", username)
+		activity.Subject = "Langganan Berakhir"
+		activity.Claim = ""
+	}
+
+	if err := recordActivity(ctx, tx, activity, now); err != nil {
+		return dueSkipped, err
+	}
+	if err := tx.Commit(); err != nil {
+		return dueSkipped, err
+	}
+	return outcome, nil
+}
+
+// expirePastDue mengakhiri langganan yang perpanjangannya tidak bisa
+// ditagih. Tagihan yang sempat dibuat lalu gagal sudah mengakhirinya
+// lewat payments.Service, jadi update ini boleh tidak mengubah apa pun.
+func (r *SubscriptionRepository) expirePastDue(ctx context.Context, id int, now time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE subscriptions SET status='expired', expired_at=current_period_end, updated_at=$2
+		WHERE id=$1 AND status='past_due'`,
+		id, dbtime.Format(now),
+	)
+	return err
+}
+
+// RunScheduler menjalankan satu putaran ProcessDue dan mencatat hasilnya.
+// Dijadwalkan berkala oleh supervisor di main.
+func (r *SubscriptionRepository) RunScheduler(ctx context.Context) error {
+	result, err := r.ProcessDue(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if result.Renewed > 0 || result.Charged > 0 || result.Expired > 0 {
+		slog.Info("Subscription scheduler", "renewed", result.Renewed, "charged", result.Charged, "expired", result.Expired)
+	}
+	return nil
+}
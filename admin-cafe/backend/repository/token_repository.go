@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// Tujuan token sekali pakai
+const (
+	TokenPurposeVerifyEmail   = "verify_email"
+	TokenPurposeResetPassword = "reset_password"
+)
+
+// ErrTokenInvalid dikembalikan jika token tidak ada, sudah dipakai,
+// atau sudah kedaluwarsa.
+var ErrTokenInvalid = errors.New("token tidak valid atau kedaluwarsa")
+
+// TokenStore menyimpan token sekali pakai untuk verifikasi email &
+// reset password
+type TokenStore interface {
+	Create(ctx context.Context, userID int, purpose string, ttl time.Duration) (string, error)
+	Consume(ctx context.Context, token, purpose string) (int, error)
+}
+
+var _ TokenStore = (*TokenRepository)(nil)
+
+type TokenRepository struct {
+	db *sql.DB
+}
+
+func NewTokenRepository(db *sql.DB) *TokenRepository {
+	return &TokenRepository{db: db}
+}
+
+// =========================
+// Buat token sekali pakai
+// =========================
+// Token lama dengan tujuan yang sama dibatalkan. Yang disimpan hanya
+// hash-nya, token asli dikembalikan untuk dikirim ke user.
+func (r *TokenRepository) Create(ctx context.Context, userID int, purpose string, ttl time.Duration) (string, error) {
+	token, hash, err := newToken()
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE auth_tokens SET used_at=NOW() WHERE user_id=$1 AND purpose=$2 AND used_at IS NULL",
+		userID, purpose,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO auth_tokens (user_id, purpose, token_hash, expires_at) VALUES ($1,$2,$3,$4)",
+		userID, purpose, hash, time.Now().Add(ttl),
+	)
+	if err != nil {
+		return "", err
+	}
+	return token, tx.Commit()
+}
+
+// =========================
+// Pakai token (sekali pakai)
+// =========================
+func (r *TokenRepository) Consume(ctx context.Context, token, purpose string) (int, error) {
+	var userID int
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE auth_tokens SET used_at=NOW()
+		WHERE token_hash=$1 AND purpose=$2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id`,
+		hashToken(token), purpose,
+	).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, ErrTokenInvalid
+	}
+	return userID, err
+}
+
+// newToken membuat token acak 32 byte (hex) beserta hash SHA-256-nya
+func newToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = hex.EncodeToString(b)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,25 +1,47 @@
 package repository
 
 import (
-    "backend/config"
     "backend/models"
-		"database/sql"
+    "context"
+    "database/sql"
 )
 
+// UserStore adalah akses data akun user (admin, cafe & customer)
+type UserStore interface {
+    GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+    GetLoginUser(ctx context.Context, username, role string) (*models.LoginUser, error)
+    GetUserByID(ctx context.Context, id int) (*models.User, error)
+    FindByEmail(ctx context.Context, email string) ([]models.User, error)
+    Exists(ctx context.Context, username string) (bool, error)
+    CreateCafe(ctx context.Context, user *models.User) error
+    VerifyCafe(ctx context.Context, userID int) error
+    RejectCafe(ctx context.Context, userID int) (bool, error)
+    ListCafes(ctx context.Context) ([]models.CafeAccount, error)
+    CustomerEmailExists(ctx context.Context, email string) (bool, error)
+    CreateCustomer(ctx context.Context, user *models.User, displayName, phone string) (int, error)
+    MarkEmailVerified(ctx context.Context, userID int) error
+    GetCustomerProfile(ctx context.Context, userID int) (*models.CustomerProfile, error)
+    UpdateCustomerProfile(ctx context.Context, userID int, displayName, phone string) error
+    UpdateAvatar(ctx context.Context, userID int, avatar string) (string, error)
+    DeleteCustomer(ctx context.Context, userID int) error
+    UpdatePassword(ctx context.Context, userID int, password string) error
+    ChangePassword(ctx context.Context, userID int, password string) error
+}
+
+var _ UserStore = (*UserRepository)(nil)
+
 type UserRepository struct {
-    DB *sql.DB
+    db *sql.DB
 }
 
-func NewUserRepository() *UserRepository {
-    return &UserRepository{
-        DB: config.DB,
-    }
+func NewUserRepository(db *sql.DB) *UserRepository {
+    return &UserRepository{db: db}
 }
 
 // Cari user berdasarkan username
-func (r *UserRepository) GetUserByUsername(username string) (*models.User, error) {
+func (r *UserRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
     user := &models.User{}
-    row := config.DB.QueryRow("SELECT id, username, password, role, verified FROM users WHERE username=$1", username)
+    row := r.db.QueryRowContext(ctx, "SELECT id, username, password, role, verified FROM users WHERE username=$1", username)
     err := row.Scan(&user.ID, &user.Username, &user.Password, &user.Role, &user.Verified)
     if err != nil {
         return nil, err
@@ -27,17 +49,218 @@ func (r *UserRepository) GetUserByUsername(username string) (*models.User, error
     return user, nil
 }
 
+// Data akun untuk login. Akun yang dihapus lunak dianggap tidak ada.
+func (r *UserRepository) GetLoginUser(ctx context.Context, username, role string) (*models.LoginUser, error) {
+    u := &models.LoginUser{}
+    var suspendedReason sql.NullString
+    err := r.db.QueryRowContext(ctx, `
+        SELECT id, username, password, role, COALESCE(email_verified,false), COALESCE(must_change_password,false),
+            suspended_at IS NOT NULL, suspended_reason
+        FROM users WHERE username=$1 AND role=$2 AND deleted_at IS NULL`,
+        username, role,
+    ).Scan(&u.ID, &u.Username, &u.Password, &u.Role, &u.EmailVerified, &u.MustChangePassword, &u.Suspended, &suspendedReason)
+    if err != nil {
+        return nil, err
+    }
+    u.SuspendedReason = suspendedReason.String
+    return u, nil
+}
+
 // Buat user cafe baru
-func (r *UserRepository) CreateCafe(user *models.User) error {
-    _, err := config.DB.Exec(
-        "INSERT INTO users (username, password, email, role, izin_usaha, verified) VALUES ($1,$2,$3,$4,$5,false)",
+func (r *UserRepository) CreateCafe(ctx context.Context, user *models.User) error {
+    return r.db.QueryRowContext(ctx,
+        "INSERT INTO users (username, password, email, role, izin_usaha, verified) VALUES ($1,$2,$3,$4,$5,false) RETURNING id",
         user.Username, user.Password, user.Email, user.Role, user.IzinUsaha,
+    ).Scan(&user.ID)
+}
+
+// Update verified cafe
+func (r *UserRepository) VerifyCafe(ctx context.Context, userID int) error {
+    _, err := r.db.ExecContext(ctx, "UPDATE users SET verified=true WHERE id=$1", userID)
+    return err
+}
+
+// Tolak cafe: akun cafe yang belum diverifikasi dihapus. false jika tidak
+// ada cafe pending dengan ID tersebut.
+func (r *UserRepository) RejectCafe(ctx context.Context, userID int) (bool, error) {
+    res, err := r.db.ExecContext(ctx, "DELETE FROM users WHERE id=$1 AND role='cafe' AND verified=false", userID)
+    if err != nil {
+        return false, err
+    }
+    n, err := res.RowsAffected()
+    return n > 0, err
+}
+
+// Daftar semua akun cafe untuk halaman persetujuan
+func (r *UserRepository) ListCafes(ctx context.Context) ([]models.CafeAccount, error) {
+    rows, err := r.db.QueryContext(ctx, `
+        SELECT id, username, COALESCE(email,''), COALESCE(izin_usaha,''), verified
+        FROM users WHERE role='cafe'
+        ORDER BY id`)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    cafes := []models.CafeAccount{}
+    for rows.Next() {
+        var c models.CafeAccount
+        if err := rows.Scan(&c.ID, &c.Username, &c.Email, &c.IzinUsaha, &c.Verified); err != nil {
+            return nil, err
+        }
+        cafes = append(cafes, c)
+    }
+    return cafes, rows.Err()
+}
+
+// Cek apakah username sudah dipakai
+func (r *UserRepository) Exists(ctx context.Context, username string) (bool, error) {
+    var exists bool
+    err := r.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE username=$1)", username).Scan(&exists)
+    return exists, err
+}
+
+// Cek apakah email customer sudah dipakai
+func (r *UserRepository) CustomerEmailExists(ctx context.Context, email string) (bool, error) {
+    var exists bool
+    err := r.db.QueryRowContext(ctx,
+        "SELECT EXISTS(SELECT 1 FROM users WHERE LOWER(email)=LOWER($1) AND role='customer')", email,
+    ).Scan(&exists)
+    return exists, err
+}
+
+// Buat akun customer baru (email belum terverifikasi)
+func (r *UserRepository) CreateCustomer(ctx context.Context, user *models.User, displayName, phone string) (int, error) {
+    var id int
+    err := r.db.QueryRowContext(ctx, `
+        INSERT INTO users (username, password, email, role, verified, display_name, phone, email_verified)
+        VALUES ($1,$2,$3,$4,true,$5,$6,false) RETURNING id`,
+        user.Username, user.Password, user.Email, models.RoleCustomer, displayName, phone,
+    ).Scan(&id)
+    return id, err
+}
+
+// Tandai email user sudah terverifikasi
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, userID int) error {
+    _, err := r.db.ExecContext(ctx, "UPDATE users SET email_verified=true WHERE id=$1", userID)
+    return err
+}
+
+// Ambil profil customer
+func (r *UserRepository) GetCustomerProfile(ctx context.Context, userID int) (*models.CustomerProfile, error) {
+    p := &models.CustomerProfile{}
+    err := r.db.QueryRowContext(ctx, `
+        SELECT id, username, COALESCE(email,''), COALESCE(display_name,''), COALESCE(phone,''),
+            COALESCE(avatar,''), COALESCE(email_verified,false), created_at
+        FROM users WHERE id=$1 AND role='customer'`, userID,
+    ).Scan(&p.ID, &p.Username, &p.Email, &p.DisplayName, &p.Phone, &p.Avatar, &p.EmailVerified, &p.CreatedAt)
+    if err != nil {
+        return nil, err
+    }
+    return p, nil
+}
+
+// Update nama tampilan & nomor HP customer
+func (r *UserRepository) UpdateCustomerProfile(ctx context.Context, userID int, displayName, phone string) error {
+    _, err := r.db.ExecContext(ctx,
+        "UPDATE users SET display_name=$1, phone=$2 WHERE id=$3 AND role='customer'",
+        displayName, phone, userID,
     )
     return err
 }
 
-// Update verified cafe
-func (r *UserRepository) VerifyCafe(userID int) error {
-    _, err := config.DB.Exec("UPDATE users SET verified=true WHERE id=$1", userID)
+// Ganti avatar, mengembalikan path avatar lama (bisa kosong)
+func (r *UserRepository) UpdateAvatar(ctx context.Context, userID int, avatar string) (string, error) {
+    var old sql.NullString
+    err := r.db.QueryRowContext(ctx, `
+        UPDATE users u SET avatar=$1
+        FROM (SELECT id, avatar FROM users WHERE id=$2 FOR UPDATE) prev
+        WHERE u.id = prev.id
+        RETURNING prev.avatar`,
+        avatar, userID,
+    ).Scan(&old)
+    return old.String, err
+}
+
+// Hapus akun customer. Ulasan lama tetap ada tapi dianonimkan.
+func (r *UserRepository) DeleteCustomer(ctx context.Context, userID int) error {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    var email sql.NullString
+    err = tx.QueryRowContext(ctx, "SELECT email FROM users WHERE id=$1 AND role='customer' FOR UPDATE", userID).Scan(&email)
+    if err != nil {
+        return err
+    }
+
+    // Ulasan yang ditulis sebelum punya akun dicocokkan lewat email
+    _, err = tx.ExecContext(ctx, `
+        UPDATE ulasan SET nama='Pengguna Terhapus', email=NULL, avatar=NULL, user_id=NULL,
+            updated_at=CURRENT_TIMESTAMP
+        WHERE user_id=$1 OR ($2 <> '' AND LOWER(email)=LOWER($2))`,
+        userID, email.String,
+    )
+    if err != nil {
+        return err
+    }
+
+    // Session & token ikut terhapus (ON DELETE CASCADE)
+    _, err = tx.ExecContext(ctx, "DELETE FROM users WHERE id=$1", userID)
+    if err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// Cari semua akun dengan email tertentu (email cafe/admin tidak unik)
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) ([]models.User, error) {
+    rows, err := r.db.QueryContext(ctx,
+        "SELECT id, username, email, role FROM users WHERE LOWER(email)=LOWER($1) AND deleted_at IS NULL AND suspended_at IS NULL", email,
+    )
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var users []models.User
+    for rows.Next() {
+        var u models.User
+        if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.Role); err != nil {
+            return nil, err
+        }
+        users = append(users, u)
+    }
+    return users, rows.Err()
+}
+
+// Ambil user berdasarkan ID
+func (r *UserRepository) GetUserByID(ctx context.Context, id int) (*models.User, error) {
+    user := &models.User{}
+    var email sql.NullString
+    err := r.db.QueryRowContext(ctx,
+        "SELECT id, username, password, email, role, verified FROM users WHERE id=$1", id,
+    ).Scan(&user.ID, &user.Username, &user.Password, &email, &user.Role, &user.Verified)
+    if err != nil {
+        return nil, err
+    }
+    user.Email = email.String
+    return user, nil
+}
+
+// Ganti password user
+func (r *UserRepository) UpdatePassword(ctx context.Context, userID int, password string) error {
+    _, err := r.db.ExecContext(ctx, "UPDATE users SET password=$1 WHERE id=$2", password, userID)
+    return err
+}
+
+// Ganti password sendiri & hapus tanda wajib ganti password
+func (r *UserRepository) ChangePassword(ctx context.Context, userID int, password string) error {
+    _, err := r.db.ExecContext(ctx,
+        "UPDATE users SET password=$1, must_change_password=false WHERE id=$2",
+        password, userID,
+    )
     return err
 }
@@ -0,0 +1,26 @@
+package repository_test
+
+import (
+	"backend/pgtest"
+	"backend/repository"
+	"backend/repository/storetest"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) { os.Exit(pgtest.Main(m)) }
+
+func TestUserRepository(t *testing.T) {
+	db := pgtest.Open(t)
+	storetest.Users(t, repository.NewUserRepository(db))
+}
+
+func TestSessionRepository(t *testing.T) {
+	db := pgtest.Open(t)
+	storetest.Sessions(t, repository.NewUserRepository(db), repository.NewSessionRepository(db))
+}
+
+func TestTokenRepository(t *testing.T) {
+	db := pgtest.Open(t)
+	storetest.Tokens(t, repository.NewUserRepository(db), repository.NewTokenRepository(db))
+}
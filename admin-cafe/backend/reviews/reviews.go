@@ -0,0 +1,269 @@
+// Package reviews mengelola ulasan customer untuk cafe. Ulasan baru
+// berstatus pending dan baru tampil di halaman publik setelah disetujui
+// cafe; cafe juga bisa membalas atau menyembunyikan ulasan.
+package reviews
+
+import (
+	"backend/dbtime"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Status ulasan
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusHidden   = "hidden"
+)
+
+var ErrNotFound = errors.New("ulasan tidak ditemukan")
+
+// Review adalah satu ulasan. Nama field JSON mengikuti halaman Ulasan di
+// frontend (name, text, image, reply, date).
+type Review struct {
+	ID        string    `json:"id"`
+	CafeID    int       `json:"cafe_id"`
+	UserID    *int      `json:"user_id,omitempty"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email,omitempty"`
+	Rating    int       `json:"rating"`
+	Text      string    `json:"text"`
+	Image     string    `json:"image"`
+	Avatar    string    `json:"avatar"`
+	Reply     string    `json:"reply"`
+	Status    string    `json:"status"`
+	Date      time.Time `json:"date"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewReview adalah ulasan yang dikirim customer. Nama, email & avatar
+// diambil dari akun customer oleh handler.
+type NewReview struct {
+	Rating int    `json:"rating" validate:"min=1,max=5"`
+	Text   string `json:"text" validate:"trim,required,max=2000"`
+	Image  string `json:"image" validate:"trim,max=500"`
+
+	UserID int    `json:"-"`
+	Name   string `json:"-"`
+	Email  string `json:"-"`
+	Avatar string `json:"-"`
+}
+
+// Filter untuk daftar ulasan
+type Filter struct {
+	Status string
+	Rating int
+	Limit  int
+	Offset int
+}
+
+// Stats adalah ringkasan ulasan satu cafe
+type Stats struct {
+	Total     int            `json:"total"`
+	Average   float64        `json:"average"`
+	ByRating  map[int]int    `json:"by_rating"`
+	ByStatus  map[string]int `json:"by_status"`
+	Unreplied int            `json:"unreplied"`
+}
+
+// Store adalah penyimpanan ulasan; diimplementasikan Service (PostgreSQL)
+type Store interface {
+	List(ctx context.Context, cafeID int, f Filter) ([]Review, int, error)
+	Get(ctx context.Context, cafeID int, id string) (*Review, error)
+	Create(ctx context.Context, cafeID int, in NewReview, now time.Time) (*Review, error)
+	SetReply(ctx context.Context, cafeID int, id, reply string, now time.Time) (*Review, error)
+	ClearReply(ctx context.Context, cafeID int, id string, now time.Time) (*Review, error)
+	SetStatus(ctx context.Context, cafeID int, id, status string, now time.Time) (*Review, error)
+	Delete(ctx context.Context, cafeID int, id string) (*Review, error)
+	Stats(ctx context.Context, cafeID int) (*Stats, error)
+}
+
+var _ Store = (*Service)(nil)
+
+type Service struct {
+	db       *sql.DB
+	location *time.Location
+}
+
+func NewService(db *sql.DB, location *time.Location) *Service {
+	return &Service{db: db, location: location}
+}
+
+const reviewColumns = `id::text, cafe_profile_id, user_id, nama, COALESCE(email, ''), rating, teks,
+	COALESCE(gambar, ''), COALESCE(avatar, ''), COALESCE(balasan, ''), COALESCE(status, 'pending'),
+	created_at, updated_at`
+
+func (s *Service) scanReview(row interface{ Scan(...interface{}) error }) (*Review, error) {
+	rv := &Review{}
+	var cafeID, userID sql.NullInt64
+	err := row.Scan(&rv.ID, &cafeID, &userID, &rv.Name, &rv.Email, &rv.Rating, &rv.Text,
+		&rv.Image, &rv.Avatar, &rv.Reply, &rv.Status, &rv.Date, &rv.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	rv.CafeID = int(cafeID.Int64)
+	if userID.Valid {
+		uid := int(userID.Int64)
+		rv.UserID = &uid
+	}
+	rv.Date = dbtime.WallClock(rv.Date, s.location)
+	rv.UpdatedAt = dbtime.WallClock(rv.UpdatedAt, s.location)
+	return rv, nil
+}
+
+// =========================
+// Daftar ulasan satu cafe
+// =========================
+func (s *Service) List(ctx context.Context, cafeID int, f Filter) ([]Review, int, error) {
+	where := []string{"cafe_profile_id=$1"}
+	args := []interface{}{cafeID}
+	if f.Status != "" {
+		args = append(args, f.Status)
+		where = append(where, fmt.Sprintf("status=$%d", len(args)))
+	}
+	if f.Rating > 0 {
+		args = append(args, f.Rating)
+		where = append(where, fmt.Sprintf("rating=$%d", len(args)))
+	}
+	cond := strings.Join(where, " AND ")
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM ulasan WHERE "+cond, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, f.Limit, f.Offset)
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf("SELECT "+reviewColumns+" FROM ulasan WHERE "+cond+
+		" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []Review{}
+	for rows.Next() {
+		rv, err := s.scanReview(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		list = append(list, *rv)
+	}
+	return list, total, rows.Err()
+}
+
+// =========================
+// Detail ulasan milik cafe
+// =========================
+func (s *Service) Get(ctx context.Context, cafeID int, id string) (*Review, error) {
+	rv, err := s.scanReview(s.db.QueryRowContext(ctx, "SELECT "+reviewColumns+" FROM ulasan WHERE id=$1 AND cafe_profile_id=$2", id, cafeID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return rv, err
+}
+
+// =========================
+// Kirim ulasan (customer)
+// =========================
+func (s *Service) Create(ctx context.Context, cafeID int, in NewReview, now time.Time) (*Review, error) {
+	return s.scanReview(s.db.QueryRowContext(ctx, `
+		INSERT INTO ulasan (cafe_profile_id, user_id, nama, email, rating, teks, gambar, avatar, status, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, NULLIF($7, ''), NULLIF($8, ''), $9, $10, $10)
+		RETURNING `+reviewColumns,
+		cafeID, in.UserID, in.Name, in.Email, in.Rating, in.Text, in.Image, in.Avatar, StatusPending, dbtime.Format(now),
+	))
+}
+
+// update menjalankan UPDATE pada satu ulasan milik cafe dan mengembalikan
+// hasilnya
+func (s *Service) update(ctx context.Context, cafeID int, id, set string, args ...interface{}) (*Review, error) {
+	args = append([]interface{}{id, cafeID}, args...)
+	rv, err := s.scanReview(s.db.QueryRowContext(ctx,
+		"UPDATE ulasan SET "+set+" WHERE id=$1 AND cafe_profile_id=$2 RETURNING "+reviewColumns, args...))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return rv, err
+}
+
+// =========================
+// Balas ulasan
+// =========================
+// Membalas ulasan pending sekaligus menyetujuinya.
+func (s *Service) SetReply(ctx context.Context, cafeID int, id, reply string, now time.Time) (*Review, error) {
+	return s.update(ctx, cafeID, id, `balasan=$3, updated_at=$4,
+		status=CASE WHEN status=$5 THEN $6 ELSE status END`,
+		reply, dbtime.Format(now), StatusPending, StatusApproved)
+}
+
+// =========================
+// Hapus balasan
+// =========================
+func (s *Service) ClearReply(ctx context.Context, cafeID int, id string, now time.Time) (*Review, error) {
+	return s.update(ctx, cafeID, id, "balasan=NULL, updated_at=$3", dbtime.Format(now))
+}
+
+// =========================
+// Ubah status ulasan
+// =========================
+func (s *Service) SetStatus(ctx context.Context, cafeID int, id, status string, now time.Time) (*Review, error) {
+	return s.update(ctx, cafeID, id, "status=$3, updated_at=$4", status, dbtime.Format(now))
+}
+
+// =========================
+// Hapus ulasan
+// =========================
+func (s *Service) Delete(ctx context.Context, cafeID int, id string) (*Review, error) {
+	rv, err := s.scanReview(s.db.QueryRowContext(ctx, "DELETE FROM ulasan WHERE id=$1 AND cafe_profile_id=$2 RETURNING "+reviewColumns, id, cafeID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return rv, err
+}
+
+// =========================
+// Statistik ulasan
+// =========================
+// Rata-rata rating dari semua ulasan, sama seperti rating di pencarian
+// cafe terdekat & laporan penjualan.
+func (s *Service) Stats(ctx context.Context, cafeID int) (*Stats, error) {
+	st := &Stats{
+		ByRating: map[int]int{1: 0, 2: 0, 3: 0, 4: 0, 5: 0},
+		ByStatus: map[string]int{StatusPending: 0, StatusApproved: 0, StatusHidden: 0},
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT COALESCE(status, 'pending'), rating, COUNT(*), COUNT(*) FILTER (WHERE COALESCE(balasan, '') = '')
+		FROM ulasan WHERE cafe_profile_id=$1
+		GROUP BY 1, 2`, cafeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sum int
+	for rows.Next() {
+		var status string
+		var rating, n, unreplied int
+		if err := rows.Scan(&status, &rating, &n, &unreplied); err != nil {
+			return nil, err
+		}
+		st.Total += n
+		st.Unreplied += unreplied
+		st.ByStatus[status] += n
+		st.ByRating[rating] += n
+		sum += rating * n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if st.Total > 0 {
+		st.Average = math.Round(float64(sum)/float64(st.Total)*10) / 10
+	}
+	return st, nil
+}
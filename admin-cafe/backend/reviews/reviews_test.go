@@ -0,0 +1,22 @@
+package reviews_test
+
+import (
+	"backend/pgtest"
+	"backend/repository/storetest"
+	"backend/reviews"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) { os.Exit(pgtest.Main(m)) }
+
+func TestService(t *testing.T) {
+	db := pgtest.Open(t)
+	cafeID := pgtest.CreateCafe(t, db, "kopikita")
+	otherCafeID := pgtest.CreateCafe(t, db, "senja")
+	userID := pgtest.CreateCustomer(t, db, "budi")
+
+	loc := time.FixedZone("WIB", 7*60*60)
+	storetest.Reviews(t, reviews.NewService(db, loc), loc, cafeID, otherCafeID, userID)
+}
@@ -1,25 +1,318 @@
 package routes
 
 import (
+    "net/http"
+
     "backend/handlers"
+    "backend/metrics"
+    "backend/middleware"
+    "backend/models"
+    "backend/repository"
+
     "github.com/gorilla/mux"
 )
 
-func SetupRoutes(auth *handlers.AuthHandler, cafe *handlers.CafeHandler) *mux.Router {
+// Handlers berisi semua handler yang didaftarkan ke router
+type Handlers struct {
+    Auth          *handlers.AuthHandler
+    Cafe          *handlers.CafeHandler
+    CafeProfile   *handlers.CafeProfileHandler
+    Search        *handlers.SearchHandler
+    Customer      *handlers.CustomerHandler
+    Report        *handlers.ReportHandler
+    Order         *handlers.OrderHandler
+    Payment       *handlers.PaymentHandler
+    Reservation   *handlers.ReservationHandler
+    Subscription  *handlers.SubscriptionHandler
+    Loyalty       *handlers.LoyaltyHandler
+    Activity      *handlers.ActivityHandler
+    ChangeRequest *handlers.ChangeRequestHandler
+    CafeDetails   *handlers.CafeDetailsHandler
+    Menu          *handlers.MenuHandler
+    Review        *handlers.ReviewHandler
+    Promo         *handlers.PromoHandler
+    Chat          *handlers.ChatHandler
+    Notification  *handlers.NotificationHandler
+    Account       *handlers.AccountHandler
+    Health        *handlers.HealthHandler
+}
+
+// Pola UUID untuk ID menu & ulasan di path
+const uuidPattern = "[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}"
+
+func SetupRoutes(h Handlers, sessions repository.SessionStore) *mux.Router {
     r := mux.NewRouter()
 
+    // ==============================
+    // Health check & metrik (untuk orchestrator & Prometheus)
+    // ==============================
+    r.HandleFunc("/healthz", h.Health.Live).Methods("GET")                       // Liveness
+    r.HandleFunc("/readyz", h.Health.Ready).Methods("GET")                       // Readiness: DB, uploads, background job
+    r.Handle("/metrics", metrics.Handler()).Methods("GET")
+
     // ==============================
     // Auth routes
     // ==============================
-    r.HandleFunc("/login", auth.Login).Methods("POST")
-    r.HandleFunc("/register-cafe", auth.RegisterCafe).Methods("POST")
+    r.HandleFunc("/login", h.Auth.Login).Methods("POST")
+    r.HandleFunc("/register-cafe", h.Auth.RegisterCafe).Methods("POST")
+    r.HandleFunc("/register-customer", h.Auth.RegisterCustomer).Methods("POST")
+    r.HandleFunc("/auth/verify-email", h.Auth.VerifyEmail).Methods("POST")
+    r.HandleFunc("/auth/forgot-password", h.Auth.ForgotPassword).Methods("POST")
+    r.HandleFunc("/auth/reset-password", h.Auth.ResetPassword).Methods("POST")
+    r.HandleFunc("/logout", h.Auth.Logout).Methods("POST")
+
+    authed := r.NewRoute().Subrouter()
+    authed.Use(middleware.Auth(sessions))
+    authed.HandleFunc("/auth/change-password", h.Auth.ChangePassword).Methods("POST")
 
     // ==============================
     // Admin Cafe routes
     // ==============================
-    r.HandleFunc("/approve-cafe", cafe.ApproveCafe).Methods("POST")      // Approve cafe
-    r.HandleFunc("/reject-cafe", cafe.RejectCafe).Methods("POST")        // Tolak cafe
-    r.HandleFunc("/all-cafes", cafe.ListAllCafes).Methods("GET")         // Ambil semua cafe beserta statusnya
+    admin := r.NewRoute().Subrouter()
+    admin.Use(middleware.Auth(sessions), middleware.RequireRole(models.RoleAdmin))
+    admin.HandleFunc("/approve-cafe", h.Cafe.ApproveCafe).Methods("POST")      // Approve cafe
+    admin.HandleFunc("/reject-cafe", h.Cafe.RejectCafe).Methods("POST")        // Tolak cafe
+    admin.HandleFunc("/all-cafes", h.Cafe.ListAllCafes).Methods("GET")         // Ambil semua cafe beserta statusnya
+    admin.HandleFunc("/admin/stats", h.Report.AdminStats).Methods("GET")       // Statistik dashboard super admin
+    admin.HandleFunc("/admin/subscription-plans", h.Subscription.AdminListPlans).Methods("GET")
+    admin.HandleFunc("/admin/subscription-plans", h.Subscription.SavePlan).Methods("POST")
+    admin.HandleFunc("/admin/subscription-plans/{id:[0-9]+}", h.Subscription.SavePlan).Methods("PUT")
+    admin.HandleFunc("/admin/activity", h.Activity.List).Methods("GET")
+    admin.HandleFunc("/admin/activity/acknowledge", h.Activity.AcknowledgeBulk).Methods("POST")
+    admin.HandleFunc("/admin/activity/{id:[0-9]+}/acknowledge", h.Activity.Acknowledge).Methods("POST")
+    admin.HandleFunc("/admin/activity/history/{entityType}/{entityId}", h.Activity.History).Methods("GET")
+    admin.HandleFunc("/admin/accounts", h.Account.List).Methods("GET")
+    admin.HandleFunc("/admin/accounts/{id:[0-9]+}", h.Account.Get).Methods("GET")
+    admin.HandleFunc("/admin/accounts/{id:[0-9]+}", h.Account.Delete).Methods("DELETE")
+    admin.HandleFunc("/admin/accounts/{id:[0-9]+}/restore", h.Account.Restore).Methods("POST")
+    admin.HandleFunc("/admin/accounts/{id:[0-9]+}/suspend", h.Account.Suspend).Methods("POST")
+    admin.HandleFunc("/admin/accounts/{id:[0-9]+}/unsuspend", h.Account.Unsuspend).Methods("POST")
+    admin.HandleFunc("/admin/accounts/{id:[0-9]+}/logout", h.Account.ForceLogout).Methods("POST")
+    admin.HandleFunc("/admin/accounts/{id:[0-9]+}/role", h.Account.ChangeRole).Methods("PUT")
+    admin.HandleFunc("/admin/change-requests", h.ChangeRequest.AdminList).Methods("GET")
+    admin.HandleFunc("/admin/change-requests/{id:[0-9]+}/approve", h.ChangeRequest.Approve).Methods("POST")
+    admin.HandleFunc("/admin/change-requests/{id:[0-9]+}/reject", h.ChangeRequest.Reject).Methods("POST")
+
+    // ==============================
+    // Cafe terdekat
+    // ==============================
+    r.HandleFunc("/cafes/nearby", h.CafeProfile.NearbyCafes).Methods("GET")      // Cari cafe terdekat
+
+    // ==============================
+    // Pencarian
+    // ==============================
+    r.HandleFunc("/search", h.Search.Search).Methods("GET")                      // Full-text cafe & menu
+
+    // ==============================
+    // Customer routes (wajib login)
+    // ==============================
+    customer := r.PathPrefix("/customer").Subrouter()
+    customer.Use(middleware.Auth(sessions), middleware.RequireRole(models.RoleCustomer))
+    customer.HandleFunc("/profile", h.Customer.GetProfile).Methods("GET")
+    customer.HandleFunc("/profile", h.Customer.UpdateProfile).Methods("PUT")
+    customer.HandleFunc("/profile/avatar", h.Customer.UploadAvatar).Methods("POST")
+    customer.HandleFunc("/account", h.Customer.DeleteAccount).Methods("DELETE")
+    customer.HandleFunc("/orders", h.Order.CustomerOrders).Methods("GET")
+    customer.HandleFunc("/orders/{id:[0-9]+}/cancel", h.Order.CancelOwnOrder).Methods("POST")
+    customer.HandleFunc("/reservations", h.Reservation.CustomerReservations).Methods("GET")
+    customer.HandleFunc("/reservations", h.Reservation.CreateReservation).Methods("POST")
+    customer.HandleFunc("/reservations/{id:[0-9]+}/cancel", h.Reservation.CancelReservation).Methods("POST")
+    customer.HandleFunc("/subscription", h.Subscription.GetSubscription).Methods("GET")
+    customer.HandleFunc("/subscription", h.Subscription.Subscribe).Methods("POST")
+    customer.HandleFunc("/subscription/cancel", h.Subscription.Cancel).Methods("POST")
+    customer.HandleFunc("/subscription/resume", h.Subscription.Resume).Methods("POST")
+    customer.HandleFunc("/loyalty", h.Loyalty.Balances).Methods("GET")
+    customer.HandleFunc("/loyalty/{cafeId:[0-9]+}", h.Loyalty.CafeBalance).Methods("GET")
+    customer.HandleFunc("/loyalty/{cafeId:[0-9]+}/redeem", h.Loyalty.RedeemReward).Methods("POST")
+    customer.HandleFunc("/loyalty/{cafeId:[0-9]+}/stamps/redeem", h.Loyalty.RedeemStamps).Methods("POST")
+
+    // ==============================
+    // Langganan premium
+    // ==============================
+    r.HandleFunc("/subscription-plans", h.Subscription.ListPlans).Methods("GET")  // Paket yang bisa dibeli
+
+    // ==============================
+    // Pesanan (customer memesan, akun cafe sebagai kasir/POS)
+    // ==============================
+    // Hitung harga keranjang; login opsional supaya promo premium ikut dihitung
+    r.Handle("/orders/quote", middleware.OptionalAuth(sessions)(http.HandlerFunc(h.Order.Quote))).Methods("POST")
+    r.HandleFunc("/menus/{id}/variants", h.Order.ListVariants).Methods("GET")    // Varian menu
+
+    order := r.PathPrefix("/orders").Subrouter()
+    order.Use(middleware.Auth(sessions))
+    order.Handle("", middleware.RequireRole(models.RoleCustomer, models.RoleCafe)(http.HandlerFunc(h.Order.PlaceOrder))).Methods("POST")
+    order.HandleFunc("/{id:[0-9]+}", h.Order.GetOrder).Methods("GET")
+    order.HandleFunc("/{id:[0-9]+}/payments", h.Payment.PayOrder).Methods("POST")
+
+    // ==============================
+    // Pembayaran
+    // ==============================
+    r.HandleFunc("/payments/webhook/{provider}", h.Payment.Webhook).Methods("POST")   // Dipanggil gateway
+
+    payment := r.PathPrefix("/payments").Subrouter()
+    payment.Use(middleware.Auth(sessions))
+    payment.HandleFunc("/{id:[0-9]+}", h.Payment.GetPayment).Methods("GET")
+    payment.HandleFunc("/{id:[0-9]+}/refund", h.Payment.Refund).Methods("POST")
+    payment.Handle("/mock/simulate", middleware.RequireRole(models.RoleAdmin)(http.HandlerFunc(h.Payment.MockSimulate))).Methods("POST")  // Hanya PAYMENT_PROVIDER=mock
+
+    cafeOrder := r.PathPrefix("/cafe").Subrouter()
+    cafeOrder.Use(middleware.Auth(sessions), middleware.RequireRole(models.RoleCafe, models.RoleAdmin))
+    cafeOrder.HandleFunc("/orders", h.Order.CafeOrders).Methods("GET")
+    cafeOrder.HandleFunc("/orders/{id:[0-9]+}/status", h.Order.UpdateStatus).Methods("PUT")
+    cafeOrder.HandleFunc("/menus/{id}/variants", h.Order.CreateVariant).Methods("POST")
+    cafeOrder.HandleFunc("/menus/{id}/variants/{variantId:[0-9]+}", h.Order.DeleteVariant).Methods("DELETE")
+    cafeOrder.HandleFunc("/menus/{id}/premium", h.Order.SetPremiumOnly).Methods("PUT")
+
+    // ==============================
+    // Reservasi meja
+    // ==============================
+    r.HandleFunc("/cafes/{id:[0-9]+}/availability", h.Reservation.Availability).Methods("GET")  // Cek meja kosong
+
+    cafeOrder.HandleFunc("/tables", h.Reservation.ListTables).Methods("GET")
+    cafeOrder.HandleFunc("/tables", h.Reservation.SaveTable).Methods("POST")
+    cafeOrder.HandleFunc("/tables/{id:[0-9]+}", h.Reservation.SaveTable).Methods("PUT")
+    cafeOrder.HandleFunc("/tables/{id:[0-9]+}", h.Reservation.DeleteTable).Methods("DELETE")
+    cafeOrder.HandleFunc("/reservations", h.Reservation.CafeReservations).Methods("GET")
+    cafeOrder.HandleFunc("/reservations/{id:[0-9]+}/confirm", h.Reservation.Confirm).Methods("POST")
+    cafeOrder.HandleFunc("/reservations/{id:[0-9]+}/decline", h.Reservation.Decline).Methods("POST")
+    cafeOrder.HandleFunc("/reservations/{id:[0-9]+}/no-show", h.Reservation.NoShow).Methods("POST")
+
+    // ==============================
+    // Loyalty: poin & kartu stamp
+    // ==============================
+    cafeOrder.HandleFunc("/loyalty/settings", h.Loyalty.GetSettings).Methods("GET")
+    cafeOrder.HandleFunc("/loyalty/settings", h.Loyalty.UpdateSettings).Methods("PUT")
+    cafeOrder.HandleFunc("/loyalty/rewards", h.Loyalty.ListRewards).Methods("GET")
+    cafeOrder.HandleFunc("/loyalty/rewards", h.Loyalty.SaveReward).Methods("POST")
+    cafeOrder.HandleFunc("/loyalty/rewards/{id:[0-9]+}", h.Loyalty.SaveReward).Methods("PUT")
+    cafeOrder.HandleFunc("/loyalty/transactions", h.Loyalty.CafeTransactions).Methods("GET")
+
+    // ==============================
+    // Profil cafe & perubahan yang butuh persetujuan super admin
+    // ==============================
+    cafeOrder.HandleFunc("/profile", h.ChangeRequest.GetProfile).Methods("GET")
+    cafeOrder.HandleFunc("/profile", h.ChangeRequest.UpdateProfile).Methods("PUT")
+    cafeOrder.HandleFunc("/profile/izin-usaha", h.ChangeRequest.UploadIzinUsaha).Methods("POST")
+    cafeOrder.HandleFunc("/menus/{id}/discount", h.ChangeRequest.UpdateDiscount).Methods("PUT")
+    cafeOrder.HandleFunc("/change-requests", h.ChangeRequest.CafeList).Methods("GET")
+
+    // Detail profil yang langsung berlaku tanpa persetujuan
+    cafeOrder.HandleFunc("/profile/image", h.CafeDetails.UploadMainImage).Methods("POST")
+    cafeOrder.HandleFunc("/profile/image", h.CafeDetails.DeleteMainImage).Methods("DELETE")
+    cafeOrder.HandleFunc("/social-media", h.CafeDetails.ListSocialMedia).Methods("GET")
+    cafeOrder.HandleFunc("/social-media", h.CafeDetails.AddSocialMedia).Methods("POST")
+    cafeOrder.HandleFunc("/social-media/all", h.CafeDetails.DeleteAllSocialMedia).Methods("DELETE")
+    cafeOrder.HandleFunc("/operational-hours", h.CafeDetails.ListOperationalHours).Methods("GET")
+    cafeOrder.HandleFunc("/operational-hours", h.CafeDetails.ReplaceOperationalHours).Methods("PUT")
+    cafeOrder.HandleFunc("/operational-hours/status", h.CafeDetails.OpenStatus).Methods("GET")
+    cafeOrder.HandleFunc("/operational-hours/{hari:[a-z]+}", h.CafeDetails.SetOperationalHour).Methods("PUT")
+    cafeOrder.HandleFunc("/facilities", h.CafeDetails.ListFacilities).Methods("GET")
+    cafeOrder.HandleFunc("/facilities", h.CafeDetails.ReplaceFacilities).Methods("PUT")
+    cafeOrder.HandleFunc("/gallery", h.CafeDetails.ListGallery).Methods("GET")
+    cafeOrder.HandleFunc("/gallery", h.CafeDetails.AddGalleryImage).Methods("POST")
+    cafeOrder.HandleFunc("/gallery/{id:[0-9]+}", h.CafeDetails.DeleteGalleryImage).Methods("DELETE")
+
+    // ==============================
+    // Menu & promo (diskon menu lewat persetujuan super admin)
+    // ==============================
+    cafeOrder.HandleFunc("/menus", h.Menu.List).Methods("GET")
+    cafeOrder.HandleFunc("/menus", h.Menu.Create).Methods("POST")
+    cafeOrder.HandleFunc("/menus/{id:"+uuidPattern+"}", h.Menu.Get).Methods("GET")
+    cafeOrder.HandleFunc("/menus/{id:"+uuidPattern+"}", h.Menu.Update).Methods("PUT")
+    cafeOrder.HandleFunc("/menus/{id:"+uuidPattern+"}", h.Menu.Delete).Methods("DELETE")
+    cafeOrder.HandleFunc("/upload", h.Menu.UploadImage).Methods("POST")                 // Gambar menu
+    cafeOrder.HandleFunc("/promos", h.Promo.List).Methods("GET")
+    cafeOrder.HandleFunc("/promos", h.Promo.Create).Methods("POST")
+    cafeOrder.HandleFunc("/promos/stats", h.Promo.Stats).Methods("GET")
+    cafeOrder.HandleFunc("/promos/{id:"+uuidPattern+"}", h.Promo.Get).Methods("GET")
+    cafeOrder.HandleFunc("/promos/{id:"+uuidPattern+"}", h.ChangeRequest.UpdateDiscount).Methods("PUT")
+    cafeOrder.HandleFunc("/promos/{id:"+uuidPattern+"}", h.Promo.Delete).Methods("DELETE")
+
+    // ==============================
+    // Ulasan (publik hanya yang disetujui, cafe memoderasi)
+    // ==============================
+    r.HandleFunc("/cafes/{id:[0-9]+}/reviews", h.Review.PublicList).Methods("GET")
+
+    reviewer := r.NewRoute().Subrouter()
+    reviewer.Use(middleware.Auth(sessions), middleware.RequireRole(models.RoleCustomer))
+    reviewer.HandleFunc("/cafes/{id:[0-9]+}/reviews", h.Review.Create).Methods("POST")
+    reviewer.HandleFunc("/customer/reviews/image", h.Review.UploadImage).Methods("POST")
+
+    cafeOrder.HandleFunc("/reviews", h.Review.CafeList).Methods("GET")
+    cafeOrder.HandleFunc("/reviews/stats", h.Review.Stats).Methods("GET")
+    cafeOrder.HandleFunc("/reviews/{id:"+uuidPattern+"}", h.Review.Delete).Methods("DELETE")
+    cafeOrder.HandleFunc("/reviews/{id:"+uuidPattern+"}/reply", h.Review.Reply).Methods("PUT")
+    cafeOrder.HandleFunc("/reviews/{id:"+uuidPattern+"}/reply", h.Review.DeleteReply).Methods("DELETE")
+    cafeOrder.HandleFunc("/reviews/{id:"+uuidPattern+"}/status", h.Review.SetStatus).Methods("PUT")
+
+    // ==============================
+    // Chat super admin <-> cafe (stream SSE + polling)
+    // ==============================
+    chatRoutes := r.PathPrefix("/chat").Subrouter()
+    chatRoutes.Use(middleware.Auth(sessions), middleware.RequireRole(models.RoleAdmin, models.RoleCafe))
+    chatRoutes.HandleFunc("/threads", h.Chat.ListThreads).Methods("GET")
+    chatRoutes.HandleFunc("/threads", h.Chat.CreateThread).Methods("POST")
+    chatRoutes.HandleFunc("/threads/{id:[0-9]+}", h.Chat.GetThread).Methods("GET")
+    chatRoutes.HandleFunc("/threads/{id:[0-9]+}/messages", h.Chat.Messages).Methods("GET")
+    chatRoutes.HandleFunc("/threads/{id:[0-9]+}/messages", h.Chat.SendMessage).Methods("POST")
+    chatRoutes.HandleFunc("/threads/{id:[0-9]+}/read", h.Chat.MarkRead).Methods("POST")
+    chatRoutes.HandleFunc("/unread", h.Chat.Unread).Methods("GET")
+    chatRoutes.HandleFunc("/stream", h.Chat.Stream).Methods("GET")
+
+    // ==============================
+    // Notifikasi milik user yang login (semua role)
+    // ==============================
+    notifications := r.PathPrefix("/notifications").Subrouter()
+    notifications.Use(middleware.Auth(sessions))
+    notifications.HandleFunc("", h.Notification.List).Methods("GET")
+    notifications.HandleFunc("/{id:[0-9]+}/read", h.Notification.MarkRead).Methods("POST")
+    notifications.HandleFunc("/read-all", h.Notification.MarkAllRead).Methods("POST")
+    notifications.HandleFunc("/preferences", h.Notification.Preferences).Methods("GET")
+    notifications.HandleFunc("/preferences", h.Notification.SavePreferences).Methods("PUT")
+
+    // ==============================
+    // Laporan (cafe untuk dirinya sendiri, admin dengan ?cafe_id=)
+    // ==============================
+    report := r.PathPrefix("/reports").Subrouter()
+    report.Use(middleware.Auth(sessions), middleware.RequireRole(models.RoleCafe, models.RoleAdmin))
+    report.HandleFunc("/sales", h.Report.Sales).Methods("GET")
+    report.HandleFunc("/sales/export", h.Report.ExportSales).Methods("GET")
+
+    // ==============================
+    // Alias path lama dari backend gin (admincafe/backend) yang sudah
+    // digabung ke sini; pakai path baru di atas untuk kode baru
+    // ==============================
+    r.HandleFunc("/health", h.Health.Live).Methods("GET")
+
+    legacy := r.NewRoute().Subrouter()
+    legacy.Use(middleware.Auth(sessions), middleware.RequireRole(models.RoleCafe, models.RoleAdmin))
+    legacy.HandleFunc("/menus", h.Menu.List).Methods("GET")
+    legacy.HandleFunc("/menus", h.Menu.Create).Methods("POST")
+    legacy.HandleFunc("/menus/{id:"+uuidPattern+"}", h.Menu.Get).Methods("GET")
+    legacy.HandleFunc("/menus/{id:"+uuidPattern+"}", h.Menu.Update).Methods("PUT")
+    legacy.HandleFunc("/menus/{id:"+uuidPattern+"}", h.Menu.Delete).Methods("DELETE")
+    legacy.HandleFunc("/upload", h.Menu.UploadImage).Methods("POST")
+    legacy.HandleFunc("/ulasan", h.Review.CafeList).Methods("GET")
+    legacy.HandleFunc("/admin/ulasan", h.Review.CafeList).Methods("GET")
+    legacy.HandleFunc("/admin/ulasan/stats", h.Review.Stats).Methods("GET")
+    legacy.HandleFunc("/admin/ulasan/{id:"+uuidPattern+"}", h.Review.Delete).Methods("DELETE")
+    legacy.HandleFunc("/admin/ulasan/{id:"+uuidPattern+"}/reply", h.Review.Reply).Methods("PUT")
+    legacy.HandleFunc("/admin/ulasan/{id:"+uuidPattern+"}/reply", h.Review.DeleteReply).Methods("DELETE")
+    legacy.HandleFunc("/admin/ulasan/{id:"+uuidPattern+"}/status", h.Review.SetStatus).Methods("PUT")
+    legacy.HandleFunc("/api/v1/promos", h.Promo.List).Methods("GET")
+    legacy.HandleFunc("/api/v1/promos", h.Promo.Create).Methods("POST")
+    legacy.HandleFunc("/api/v1/promos/stats", h.Promo.Stats).Methods("GET")
+    legacy.HandleFunc("/api/v1/promos/{id:"+uuidPattern+"}", h.Promo.Get).Methods("GET")
+    legacy.HandleFunc("/api/v1/promos/{id:"+uuidPattern+"}", h.ChangeRequest.UpdateDiscount).Methods("PUT")
+    legacy.HandleFunc("/api/v1/promos/{id:"+uuidPattern+"}", h.Promo.Delete).Methods("DELETE")
+    legacy.HandleFunc("/api/operational-hours/today", h.CafeDetails.OpenStatus).Methods("GET")
+    legacy.HandleFunc("/api/operational-hours/status", h.CafeDetails.OpenStatus).Methods("GET")
+    legacy.HandleFunc("/api/operational-hours/single", h.CafeDetails.SetOperationalHour).Methods("PUT")
+
+    // Ulasan dari aplikasi customer: POST /ulasan?cafe_id=
+    reviewer.HandleFunc("/ulasan", h.Review.Create).Methods("POST")
+    reviewer.HandleFunc("/ulasan/upload", h.Review.UploadImage).Methods("POST")
+    reviewer.HandleFunc("/ulasan/upload-avatar", h.Customer.UploadAvatar).Methods("POST")
 
     return r
 }
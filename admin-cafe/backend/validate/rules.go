@@ -0,0 +1,291 @@
+package validate
+
+import (
+	"backend/api"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ruleFunc memeriksa nilai v (sudah di-dereference) terhadap param.
+// other hanya diisi untuk aturan *field jika field pembanding diisi.
+type ruleFunc func(name string, v reflect.Value, param string, other *namedValue) *api.FieldError
+
+var rules = map[string]ruleFunc{
+	"min":      minRule,
+	"max":      maxRule,
+	"gt":       compareRule("gt", "%s harus lebih dari %s", func(c int) bool { return c > 0 }),
+	"gte":      compareRule("gte", "%s minimal %s", func(c int) bool { return c >= 0 }),
+	"lt":       compareRule("lt", "%s harus kurang dari %s", func(c int) bool { return c < 0 }),
+	"lte":      compareRule("lte", "%s maksimal %s", func(c int) bool { return c <= 0 }),
+	"notblank": notBlankRule,
+	"oneof":    oneofRule,
+	"email":    emailRule,
+	"username": patternRule("username", usernamePattern, "%s 3-50 karakter (huruf, angka, titik, underscore)"),
+	"phone":    patternRule("phone", phonePattern, "%s harus berupa nomor HP yang valid"),
+	"date":     layoutRule("date", "2006-01-02", "Format %s harus YYYY-MM-DD"),
+	"clock":    layoutRule("clock", "15:04", "Format %s harus HH:MM"),
+	"gtefield": gteFieldRule,
+	"nefield":  neFieldRule,
+}
+
+// parseRules mengurai tag validate untuk field berjenis kind (sudah
+// di-dereference). Aturan yang tidak dikenal, parameter angka yang salah
+// atau aturan yang tidak berlaku untuk kind tersebut dikembalikan sebagai
+// error, supaya tag yang salah ketahuan saat struct pertama kali dipakai
+// dan bukan saat nilai tertentu masuk. kind reflect.Invalid melewatkan
+// pemeriksaan kind.
+func parseRules(tag string, kind reflect.Kind) ([]rule, error) {
+	var list []rule
+	for _, part := range strings.Split(tag, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		name, param, _ := strings.Cut(part, "=")
+		if err := checkRule(name, param, kind); err != nil {
+			return nil, err
+		}
+		list = append(list, rule{name: name, param: param})
+	}
+	return list, nil
+}
+
+func checkRule(name, param string, kind reflect.Kind) error {
+	if _, ok := rules[name]; !ok && name != "required" && name != "trim" {
+		return fmt.Errorf("aturan %q tidak dikenal", name)
+	}
+	known := kind != reflect.Invalid
+	switch name {
+	case "trim", "email", "username", "phone", "date", "clock":
+		if known && kind != reflect.String {
+			return fmt.Errorf("aturan %s hanya untuk string, bukan %s", name, kind)
+		}
+	case "min", "max":
+		switch {
+		case kind == reflect.String || kind == reflect.Slice || kind == reflect.Map:
+			if n, err := strconv.Atoi(param); err != nil || n < 0 {
+				return fmt.Errorf("parameter panjang %s=%q tidak valid", name, param)
+			}
+		case known && !isNumber(kind):
+			return fmt.Errorf("aturan %s tidak berlaku untuk %s", name, kind)
+		default:
+			if _, err := strconv.ParseFloat(param, 64); err != nil {
+				return fmt.Errorf("parameter angka %s=%q tidak valid", name, param)
+			}
+		}
+	case "gt", "gte", "lt", "lte":
+		if known && !isNumber(kind) {
+			return fmt.Errorf("aturan %s hanya untuk angka, bukan %s", name, kind)
+		}
+		if _, err := strconv.ParseFloat(param, 64); err != nil {
+			return fmt.Errorf("parameter angka %s=%q tidak valid", name, param)
+		}
+	case "oneof":
+		if len(strings.Fields(param)) == 0 {
+			return fmt.Errorf("oneof tanpa pilihan")
+		}
+	case "gtefield", "nefield":
+		if param == "" {
+			return fmt.Errorf("%s tanpa nama field", name)
+		}
+		if name == "gtefield" && known && kind != reflect.String && kind != reflect.Struct && !isNumber(kind) {
+			return fmt.Errorf("aturan gtefield tidak berlaku untuk %s", kind)
+		}
+	}
+	return nil
+}
+
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+var (
+	usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.]{3,50}$`)
+	phonePattern    = regexp.MustCompile(`^\+?[0-9]{8,15}$`)
+)
+
+// Email memastikan input berupa alamat email tunggal tanpa nama
+func Email(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email
+}
+
+// minRule: panjang minimal untuk string & slice, nilai minimal untuk angka
+func minRule(name string, v reflect.Value, param string, _ *namedValue) *api.FieldError {
+	switch v.Kind() {
+	case reflect.String:
+		if utf8.RuneCountInString(v.String()) < atoi(param) {
+			return fail(name, "min", "%s minimal %s karakter", name, param)
+		}
+	case reflect.Slice, reflect.Map:
+		if v.Len() < atoi(param) {
+			return fail(name, "min", "%s minimal %s item", name, param)
+		}
+	default:
+		if compare(v, param) < 0 {
+			return fail(name, "min", "%s minimal %s", name, param)
+		}
+	}
+	return nil
+}
+
+// maxRule: panjang maksimal untuk string & slice, nilai maksimal untuk angka
+func maxRule(name string, v reflect.Value, param string, _ *namedValue) *api.FieldError {
+	switch v.Kind() {
+	case reflect.String:
+		if utf8.RuneCountInString(v.String()) > atoi(param) {
+			return fail(name, "max", "%s maksimal %s karakter", name, param)
+		}
+	case reflect.Slice, reflect.Map:
+		if v.Len() > atoi(param) {
+			return fail(name, "max", "%s maksimal %s item", name, param)
+		}
+	default:
+		if compare(v, param) > 0 {
+			return fail(name, "max", "%s maksimal %s", name, param)
+		}
+	}
+	return nil
+}
+
+func compareRule(code, format string, ok func(int) bool) ruleFunc {
+	return func(name string, v reflect.Value, param string, _ *namedValue) *api.FieldError {
+		if !ok(compare(v, param)) {
+			return fail(name, code, format, name, param)
+		}
+		return nil
+	}
+}
+
+// notBlankRule: pointer string yang dikirim tidak boleh kosong
+func notBlankRule(name string, v reflect.Value, _ string, _ *namedValue) *api.FieldError {
+	if isBlank(v) {
+		return fail(name, "notblank", "%s tidak boleh kosong", name)
+	}
+	return nil
+}
+
+// oneofRule: nilai harus salah satu dari daftar yang dipisah spasi
+func oneofRule(name string, v reflect.Value, param string, _ *namedValue) *api.FieldError {
+	s := fmt.Sprint(v.Interface())
+	for _, option := range strings.Fields(param) {
+		if s == option {
+			return nil
+		}
+	}
+	return fail(name, "oneof", "%s harus salah satu dari: %s", name, strings.Join(strings.Fields(param), ", "))
+}
+
+func emailRule(name string, v reflect.Value, _ string, _ *namedValue) *api.FieldError {
+	if !Email(v.String()) {
+		return fail(name, "email", "%s harus berupa alamat email yang valid", name)
+	}
+	return nil
+}
+
+func patternRule(code string, pattern *regexp.Regexp, format string) ruleFunc {
+	return func(name string, v reflect.Value, _ string, _ *namedValue) *api.FieldError {
+		if !pattern.MatchString(v.String()) {
+			return fail(name, code, format, name)
+		}
+		return nil
+	}
+}
+
+func layoutRule(code, layout, format string) ruleFunc {
+	return func(name string, v reflect.Value, _ string, _ *namedValue) *api.FieldError {
+		if _, err := time.Parse(layout, v.String()); err != nil {
+			return fail(name, code, format, name)
+		}
+		return nil
+	}
+}
+
+// gteFieldRule: nilai tidak boleh lebih kecil dari field lain, misal
+// end_date >= start_date. String dibandingkan apa adanya, jadi hanya
+// cocok untuk format yang urut secara leksikal (YYYY-MM-DD, HH:MM).
+func gteFieldRule(name string, v reflect.Value, _ string, other *namedValue) *api.FieldError {
+	if other == nil {
+		return nil
+	}
+	switch a, b := v.Interface(), other.value.Interface(); {
+	case v.Kind() == reflect.String:
+		if v.String() < other.value.String() {
+			return fail(name, "gtefield", "%s tidak boleh sebelum %s", name, other.name)
+		}
+	case isTime(a) && isTime(b):
+		if a.(time.Time).Before(b.(time.Time)) {
+			return fail(name, "gtefield", "%s tidak boleh sebelum %s", name, other.name)
+		}
+	default:
+		if number(v) < number(other.value) {
+			return fail(name, "gtefield", "%s tidak boleh lebih kecil dari %s", name, other.name)
+		}
+	}
+	return nil
+}
+
+// neFieldRule: nilai harus berbeda dari field lain (mis. password baru)
+func neFieldRule(name string, v reflect.Value, _ string, other *namedValue) *api.FieldError {
+	if other != nil && reflect.DeepEqual(v.Interface(), other.value.Interface()) {
+		return fail(name, "nefield", "%s harus berbeda dari %s", name, other.name)
+	}
+	return nil
+}
+
+func isTime(v interface{}) bool {
+	_, ok := v.(time.Time)
+	return ok
+}
+
+// compare membandingkan angka v dengan param: -1, 0 atau 1. Parameter
+// sudah diperiksa parseRules, jadi panic di sini berarti bug.
+func compare(v reflect.Value, param string) int {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validate: parameter angka %q tidak valid", param))
+	}
+	switch n := number(v); {
+	case n < limit:
+		return -1
+	case n > limit:
+		return 1
+	}
+	return 0
+}
+
+func number(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	panic(fmt.Sprintf("validate: %s bukan angka", v.Type()))
+}
+
+func atoi(param string) int {
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		panic(fmt.Sprintf("validate: parameter panjang %q tidak valid", param))
+	}
+	return n
+}
+
+func fail(name, code, format string, args ...interface{}) *api.FieldError {
+	fe := fieldError(name, code, format, args...)
+	return &fe
+}
@@ -0,0 +1,115 @@
+package validate
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestSourceTags memeriksa tag validate di semua struct pada source,
+// termasuk body request anonim di handler yang tidak bisa dijangkau
+// TestRequestDTOs. Kind field ditebak dari tipe di source; tipe bernama
+// selain bawaan Go dilewati pemeriksaan kind-nya.
+func TestSourceTags(t *testing.T) {
+	fset := token.NewFileSet()
+	checked := 0
+	err := filepath.WalkDir("..", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && (d.Name() == "vendor" || strings.HasPrefix(d.Name(), ".")) && path != ".." {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			st, ok := n.(*ast.StructType)
+			if !ok {
+				return true
+			}
+			names := map[string]bool{}
+			for _, fl := range st.Fields.List {
+				for _, name := range fl.Names {
+					names[name.Name] = true
+				}
+			}
+			for _, fl := range st.Fields.List {
+				if fl.Tag == nil {
+					continue
+				}
+				raw, _ := strconv.Unquote(fl.Tag.Value)
+				tag, ok := reflect.StructTag(raw).Lookup("validate")
+				if !ok {
+					continue
+				}
+				checked++
+				pos := fset.Position(fl.Pos())
+				list, err := parseRules(tag, sourceKind(fl.Type))
+				if err != nil {
+					t.Errorf("%s: %v", pos, err)
+					continue
+				}
+				for _, rl := range list {
+					if strings.HasSuffix(rl.name, "field") && !names[rl.param] {
+						t.Errorf("%s: field %q tidak ada", pos, rl.param)
+					}
+				}
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checked == 0 {
+		t.Fatal("tidak ada tag validate yang ditemukan")
+	}
+}
+
+// sourceKind menebak reflect.Kind dari ekspresi tipe (pointer
+// di-dereference), atau reflect.Invalid jika tidak diketahui
+func sourceKind(e ast.Expr) reflect.Kind {
+	switch e := e.(type) {
+	case *ast.StarExpr:
+		return sourceKind(e.X)
+	case *ast.ArrayType:
+		if e.Len == nil {
+			return reflect.Slice
+		}
+		return reflect.Array
+	case *ast.MapType:
+		return reflect.Map
+	case *ast.StructType:
+		return reflect.Struct
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok && x.Name == "time" && e.Sel.Name == "Time" {
+			return reflect.Struct
+		}
+	case *ast.Ident:
+		switch e.Name {
+		case "string":
+			return reflect.String
+		case "bool":
+			return reflect.Bool
+		case "int":
+			return reflect.Int
+		case "int64":
+			return reflect.Int64
+		case "float64":
+			return reflect.Float64
+		}
+	}
+	return reflect.Invalid
+}
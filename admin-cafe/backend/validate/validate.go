@@ -0,0 +1,295 @@
+// Package validate memeriksa DTO request berdasarkan tag struct, misal:
+//
+//	type body struct {
+//		Name     string   `json:"name" validate:"trim,required,max=255"`
+//		Email    string   `json:"email" validate:"trim,required,email"`
+//		Status   string   `json:"status" validate:"oneof=Aktif Nonaktif"`
+//		Price    float64  `json:"price" validate:"gt=0"`
+//		Discount *int     `json:"discount" validate:"min=0,max=100"`
+//		Start    string   `json:"start_date" validate:"date"`
+//		End      string   `json:"end_date" validate:"date,gtefield=Start"`
+//	}
+//
+// Aturan selain required dilewati jika field tidak diisi: string kosong,
+// slice kosong atau pointer nil. Angka selalu dicek; required pada angka
+// (bukan pointer) menolak 0. Pointer yang tidak nil selalu dicek, pakai
+// notblank untuk menolak string kosong pada field opsional.
+//
+// Hasilnya *api.Error berisi semua field yang salah, dengan nama field
+// sesuai tag json (atau form).
+package validate
+
+import (
+	"backend/api"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// DecodeJSON membaca body JSON ke v (pointer ke struct) lalu
+// memvalidasinya
+func DecodeJSON(r *http.Request, v interface{}) *api.Error {
+	if invalid := Decode(r, v); invalid != nil {
+		return invalid
+	}
+	return Struct(v)
+}
+
+// Decode membaca body JSON ke v tanpa validasi. Dipakai jika ada field
+// yang diisi dari luar body (mis. path) sebelum Struct dipanggil.
+func Decode(r *http.Request, v interface{}) *api.Error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		slog.DebugContext(r.Context(), "JSON decode error", "path", r.URL.Path, "err", err)
+		return api.New(http.StatusBadRequest, "Body request tidak valid").WithCode(api.CodeInvalidBody)
+	}
+	return nil
+}
+
+// Form mengisi field string v dari form value (tag form) lalu
+// memvalidasinya. Form harus sudah di-parse oleh pemanggil.
+func Form(r *http.Request, v interface{}) *api.Error {
+	rv := reflect.ValueOf(v).Elem()
+	for _, f := range fieldsOf(rv.Type()) {
+		if f.form != "" && rv.Field(f.index).Kind() == reflect.String {
+			rv.Field(f.index).SetString(r.FormValue(f.form))
+		}
+	}
+	return Struct(v)
+}
+
+// Struct memvalidasi v (pointer ke struct). Aturan trim mengubah v.
+func Struct(v interface{}) *api.Error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validate: butuh pointer ke struct, bukan %T", v))
+	}
+	var errs []api.FieldError
+	check(rv.Elem(), "", &errs)
+	if len(errs) > 0 {
+		return api.Validation(errs...)
+	}
+	return nil
+}
+
+// field adalah hasil parsing tag satu field struct
+type field struct {
+	index int
+	name  string // nama untuk pesan error (tag json/form)
+	form  string
+	rules []rule
+}
+
+type rule struct {
+	name  string
+	param string
+}
+
+var cache sync.Map // reflect.Type -> []field
+
+func fieldsOf(t reflect.Type) []field {
+	if cached, ok := cache.Load(t); ok {
+		return cached.([]field)
+	}
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		f := field{index: i, name: sf.Name}
+		f.form, _, _ = strings.Cut(sf.Tag.Get("form"), ",")
+		if f.form != "" {
+			f.name = f.form
+		}
+		if name, _, _ := strings.Cut(sf.Tag.Get("json"), ","); name != "" && name != "-" {
+			f.name = name
+		}
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		var err error
+		if f.rules, err = parseRules(sf.Tag.Get("validate"), ft.Kind()); err != nil {
+			panic(fmt.Sprintf("validate: %s.%s: %v", t.Name(), sf.Name, err))
+		}
+		for _, rl := range f.rules {
+			if !strings.HasSuffix(rl.name, "field") {
+				continue
+			}
+			if _, ok := t.FieldByName(rl.param); !ok {
+				panic(fmt.Sprintf("validate: %s.%s: field %q tidak ada", t.Name(), sf.Name, rl.param))
+			}
+		}
+		fields = append(fields, f)
+	}
+	cache.Store(t, fields)
+
+	// Tag struct di dalamnya ikut diperiksa sekarang, bukan saat slice-nya
+	// pertama kali berisi
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i).Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		switch {
+		case ft.Kind() == reflect.Struct && ft.PkgPath() != "time":
+			fieldsOf(ft)
+		case ft.Kind() == reflect.Slice && elemStruct(ft):
+			el := ft.Elem()
+			if el.Kind() == reflect.Ptr {
+				el = el.Elem()
+			}
+			fieldsOf(el)
+		}
+	}
+	return fields
+}
+
+// check memvalidasi semua field struct sv. Struct & slice of struct di
+// dalamnya ikut diperiksa dengan prefix nama, misal items[0].quantity.
+func check(sv reflect.Value, prefix string, errs *[]api.FieldError) {
+	fields := fieldsOf(sv.Type())
+	for _, f := range fields {
+		fv := sv.Field(f.index)
+		name := prefix + f.name
+		if !checkField(sv, fields, f, fv, name, errs) {
+			continue
+		}
+
+		// Nested struct / slice of struct
+		inner := fv
+		if inner.Kind() == reflect.Ptr {
+			if inner.IsNil() {
+				continue
+			}
+			inner = inner.Elem()
+		}
+		switch {
+		case inner.Kind() == reflect.Struct && inner.Type().PkgPath() != "time":
+			check(inner, name+".", errs)
+		case inner.Kind() == reflect.Slice && elemStruct(inner.Type()):
+			for i := 0; i < inner.Len(); i++ {
+				el := inner.Index(i)
+				if el.Kind() == reflect.Ptr {
+					if el.IsNil() {
+						continue
+					}
+					el = el.Elem()
+				}
+				check(el, fmt.Sprintf("%s[%d].", name, i), errs)
+			}
+		}
+	}
+}
+
+func elemStruct(t reflect.Type) bool {
+	el := t.Elem()
+	if el.Kind() == reflect.Ptr {
+		el = el.Elem()
+	}
+	return el.Kind() == reflect.Struct && el.PkgPath() != "time"
+}
+
+// checkField menjalankan aturan satu field; berhenti di kesalahan pertama.
+// Mengembalikan false jika field salah atau tidak diisi.
+func checkField(sv reflect.Value, fields []field, f field, fv reflect.Value, name string, errs *[]api.FieldError) bool {
+	for _, rl := range f.rules {
+		if rl.name == "trim" {
+			trim(fv)
+		}
+	}
+
+	// Pointer dianggap diisi selama tidak nil, supaya field PATCH
+	// opsional yang dikirim kosong tetap diperiksa (lihat notblank)
+	value, provided := fv, true
+	if fv.Kind() == reflect.Ptr {
+		provided = !fv.IsNil()
+		if provided {
+			value = fv.Elem()
+		}
+	} else if isBlank(fv) {
+		provided = false
+	}
+
+	for _, rl := range f.rules {
+		switch rl.name {
+		case "trim":
+			continue
+		case "required":
+			if !provided || isBlank(value) || (fv.Kind() != reflect.Ptr && value.IsZero()) {
+				*errs = append(*errs, fieldError(name, "required", "%s wajib diisi", name))
+				return false
+			}
+			continue
+		}
+		if !provided {
+			return false
+		}
+		other := otherField(sv, fields, rl)
+		if fe := rules[rl.name](name, value, rl.param, other); fe != nil {
+			*errs = append(*errs, *fe)
+			return false
+		}
+	}
+	return true
+}
+
+// otherField mencari field pembanding untuk aturan *field (gtefield dst.)
+func otherField(sv reflect.Value, fields []field, rl rule) *namedValue {
+	if !strings.HasSuffix(rl.name, "field") {
+		return nil
+	}
+	st, ok := sv.Type().FieldByName(rl.param)
+	if !ok {
+		panic(fmt.Sprintf("validate: field %q tidak ada di %s", rl.param, sv.Type().Name()))
+	}
+	other := &namedValue{value: sv.FieldByIndex(st.Index), name: rl.param}
+	for _, f := range fields {
+		if f.index == st.Index[0] {
+			other.name = f.name
+		}
+	}
+	if other.value.Kind() == reflect.Ptr {
+		if other.value.IsNil() {
+			return nil
+		}
+		other.value = other.value.Elem()
+	}
+	if isBlank(other.value) {
+		return nil
+	}
+	return other
+}
+
+type namedValue struct {
+	value reflect.Value
+	name  string
+}
+
+func trim(v reflect.Value) {
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.String && v.CanSet() {
+		v.SetString(strings.TrimSpace(v.String()))
+	}
+}
+
+// isBlank: string kosong/spasi atau slice/map kosong
+func isBlank(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return false
+}
+
+func fieldError(name, code, format string, args ...interface{}) api.FieldError {
+	return api.FieldError{Field: name, Code: code, Message: format, Args: args}
+}
@@ -0,0 +1,176 @@
+package validate_test
+
+import (
+	"backend/cafes"
+	"backend/menus"
+	"backend/models"
+	"backend/reviews"
+	"backend/validate"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestRequestDTOs memvalidasi nilai kosong setiap DTO bernama. Tag yang
+// salah (aturan tidak dikenal, parameter bukan angka, field pembanding
+// tidak ada) membuat Struct panic saat tipe pertama kali dipakai. Struct
+// anonim di handler diperiksa TestSourceTags.
+func TestRequestDTOs(t *testing.T) {
+	dtos := []interface{}{
+		&models.NotificationPreference{},
+		&models.LoyaltySettings{},
+		&models.ProfileChanges{},
+		&models.DiscountChange{},
+		&models.CartItem{},
+		&models.Cart{},
+		&menus.NewMenu{},
+		&menus.Changes{},
+		&reviews.NewReview{},
+		&cafes.SocialMedia{},
+		&cafes.OperationalHour{},
+		&cafes.Facility{},
+	}
+	for _, dto := range dtos {
+		t.Run(fmt.Sprintf("%T", dto), func(t *testing.T) {
+			validate.Struct(dto)
+		})
+	}
+}
+
+// fields meringkas hasil Struct menjadi "field:code" per kesalahan
+func fields(v interface{}) string {
+	invalid := validate.Struct(v)
+	if invalid == nil {
+		return ""
+	}
+	var list []string
+	for _, f := range invalid.Fields {
+		list = append(list, f.Field+":"+f.Code)
+	}
+	return strings.Join(list, " ")
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestRules(t *testing.T) {
+	type item struct {
+		Name     string `json:"name" validate:"trim,required"`
+		Quantity int    `json:"quantity" validate:"min=1,max=99"`
+	}
+	type request struct {
+		Name      string   `json:"name" validate:"trim,required,min=3,max=5"`
+		Nickname  *string  `json:"nickname" validate:"trim,notblank,max=5"`
+		Tags      []string `json:"tags" validate:"max=2"`
+		Price     float64  `json:"price" validate:"gte=0,lt=100"`
+		Status    string   `json:"status" validate:"oneof=aktif nonaktif"`
+		StartDate string   `json:"start_date" validate:"date"`
+		EndDate   string   `json:"end_date" validate:"date,gtefield=StartDate"`
+		Min       int      `json:"min"`
+		Max       int      `json:"max" validate:"gtefield=Min"`
+		Old       string   `json:"old"`
+		New       string   `json:"new" validate:"nefield=Old"`
+		Items     []item   `json:"items"`
+		Extra     []*item  `json:"extra"`
+	}
+	valid := func() request {
+		return request{Name: "Budi", Status: "aktif", Items: []item{{Name: "Latte", Quantity: 1}}}
+	}
+
+	tests := []struct {
+		name   string
+		change func(*request)
+		want   string
+	}{
+		{"valid", func(r *request) {}, ""},
+		{"required", func(r *request) { r.Name = "" }, "name:required"},
+		{"required setelah trim", func(r *request) { r.Name = "   " }, "name:required"},
+		{"min karakter", func(r *request) { r.Name = "Bu" }, "name:min"},
+		{"min dihitung per rune", func(r *request) { r.Name = "Été" }, ""},
+		{"max karakter", func(r *request) { r.Name = "Budiman" }, "name:max"},
+		{"trim sebelum max", func(r *request) { r.Name = "  Budi  " }, ""},
+		{"pointer nil dilewati", func(r *request) { r.Nickname = nil }, ""},
+		{"notblank", func(r *request) { r.Nickname = ptr(" ") }, "nickname:notblank"},
+		{"max pointer", func(r *request) { r.Nickname = ptr("panjang") }, "nickname:max"},
+		{"max item", func(r *request) { r.Tags = []string{"a", "b", "c"} }, "tags:max"},
+		{"gte angka", func(r *request) { r.Price = -1 }, "price:gte"},
+		{"lt angka", func(r *request) { r.Price = 100 }, "price:lt"},
+		{"oneof", func(r *request) { r.Status = "hapus" }, "status:oneof"},
+		{"oneof kosong dilewati", func(r *request) { r.Status = "" }, ""},
+		{"date", func(r *request) { r.StartDate = "01-02-2026" }, "start_date:date"},
+		{"gtefield tanggal", func(r *request) { r.StartDate, r.EndDate = "2026-03-02", "2026-03-01" }, "end_date:gtefield"},
+		{"gtefield tanggal sama", func(r *request) { r.StartDate, r.EndDate = "2026-03-01", "2026-03-01" }, ""},
+		{"gtefield pembanding kosong", func(r *request) { r.EndDate = "2026-03-01" }, ""},
+		{"gtefield angka", func(r *request) { r.Min, r.Max = 5, 3 }, "max:gtefield"},
+		{"nefield", func(r *request) { r.Old, r.New = "rahasia", "rahasia" }, "new:nefield"},
+		{"nefield berbeda", func(r *request) { r.Old, r.New = "rahasia", "baru" }, ""},
+		{"slice struct", func(r *request) {
+			r.Items = append(r.Items, item{Name: " ", Quantity: 0}, item{Name: "Teh", Quantity: 100})
+		}, "items[1].name:required items[1].quantity:min items[2].quantity:max"},
+		{"slice pointer struct", func(r *request) { r.Extra = []*item{nil, {Name: "Teh"}} }, "extra[1].quantity:min"},
+		{"beberapa field", func(r *request) { r.Name, r.Status = "", "x" }, "name:required status:oneof"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid()
+			tt.change(&r)
+			if got := fields(&r); got != tt.want {
+				t.Fatalf("Struct = %q, mau %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrimUpdatesValue(t *testing.T) {
+	var r struct {
+		Name  string  `json:"name" validate:"trim"`
+		Notes *string `json:"notes" validate:"trim"`
+	}
+	r.Name, r.Notes = "  Kopi Susu ", ptr(" dingin ")
+	if invalid := validate.Struct(&r); invalid != nil {
+		t.Fatalf("Struct: %v", invalid)
+	}
+	if r.Name != "Kopi Susu" || *r.Notes != "dingin" {
+		t.Fatalf("setelah trim: %q, %q", r.Name, *r.Notes)
+	}
+}
+
+func TestInvalidTagsPanic(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"aturan tidak dikenal", &struct {
+			A string `validate:"requird"`
+		}{}},
+		{"parameter panjang bukan angka", &struct {
+			A string `validate:"max=lima"`
+		}{}},
+		{"parameter angka salah", &struct {
+			A int `validate:"gte=nol"`
+		}{}},
+		{"aturan angka di string", &struct {
+			A string `validate:"gt=0"`
+		}{}},
+		{"oneof tanpa pilihan", &struct {
+			A string `validate:"oneof="`
+		}{}},
+		{"field pembanding tidak ada", &struct {
+			A string `validate:"gtefield=B"`
+		}{}},
+		{"tag salah di struct dalam slice", &struct {
+			Items []struct {
+				A string `validate:"min=x"`
+			}
+		}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("Struct tidak panic")
+				}
+			}()
+			validate.Struct(tt.v)
+		})
+	}
+}